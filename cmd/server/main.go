@@ -1,39 +1,101 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	// "time"
 	// "github.com/gin-contrib/cors"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/app"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/charges"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/database"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/export"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/handlers"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/kite"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/logging"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/notify"
+	kcbroker "github.com/SM-Sclass/stock_client2-go_backend/internal/kite/broker"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/routes"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/services"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/stream"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
 	config.MustLoad()
+	setupLogging(config.ServerConfig)
+	config.OnReload(setupLogging)
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
+	// SIGTERM (container stop) and SIGINT start the graceful shutdown below
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	if path := config.ServerConfig.ChargesRatesFile; path != "" {
+		if err := charges.LoadRateTables(path); err != nil {
+			log.Fatalf("Failed to load charge rates: %v", err)
+		}
+		log.Printf("💰 Loaded charge rates from %s", path)
+	}
 
 	db := database.ConnectPostgresDB()
+	if config.ServerConfig.DBAutoMigrate {
+		migrator, err := database.NewMigrator(db)
+		if err != nil {
+			log.Fatalf("Failed to load migrations: %v", err)
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+	}
+
+	// Apply reloadable settings overridden from the admin API
+	configOverrideRepo := &repository.ConfigOverrideRepository{DB: db}
+	if overrides, err := configOverrideRepo.GetOverrideValues(context.Background()); err != nil {
+		log.Printf("⚠️ Failed to load config overrides: %v", err)
+	} else if _, err := config.Reload(overrides); err != nil {
+		log.Printf("⚠️ Ignoring invalid config overrides: %v", err)
+	}
+	log.Printf("⚙️ Configuration: %s", config.Current())
+
+	// Keep correlated log lines so each trade's log trail can be fetched
+	trail := logging.NewTrailWriter(&repository.LogRepository{DB: db}, 1000)
+	trail.Start()
+	defer trail.Stop()
+	logging.SetTrail(trail.Add)
+
 	kiteClient := kite.NewKiteClient()
+	brk := kcbroker.NewKiteBroker(kiteClient)
 
 	// Initialize repositories
 	userRepo := &repository.UserRepository{DB: db}
 	trackingStockRepo := &repository.TrackingStocksRepository{DB: db}
 	orderRepo := &repository.OrderRepository{DB: db}
 	instrumentRepo := &repository.InstrumentRepository{DB: db}
+	trackedStateRepo := &repository.TrackedStockStateRepository{DB: db}
+	tradeRepo := &repository.TradeRepository{DB: db}
+	notificationRepo := &repository.NotificationRepository{DB: db}
+	signalAlertRepo := &repository.SignalAlertRepository{DB: db}
 
 	instrumentSvc := &services.InstrumentService{
-		Kite: kiteClient,
-		Repo: instrumentRepo,
+		Kite:   kiteClient,
+		Broker: brk,
+		Repo:   instrumentRepo,
 	}
 	orderSvc := &services.OrderService{
 		OrderRepo:         orderRepo,
 		TrackingStockRepo: trackingStockRepo,
+		TradeRepo:         tradeRepo,
 	}
 
 	// Load instruments from DB or fetch fresh
@@ -42,14 +104,23 @@ func main() {
 	//Initialize Kite Client
 	runtime := &app.Runtime{
 		KiteClient:        kiteClient,
+		Broker:            brk,
 		TrackingStockRepo: trackingStockRepo,
+		TrackedStateRepo:  trackedStateRepo,
 		InstrumentSvc:     instrumentSvc,
 		OrderSvc:          orderSvc,
+		Stream:            stream.NewHub(),
+		Notifier: notify.NewDispatcher(notificationRepo, app.NotifierSettings(config.Current()),
+			config.Current().NotifyRateLimit, config.Current().NotifyRateWindow),
 	}
+	app.WatchConfig(runtime)
+	app.StartNotifications(runtime)
+	defer runtime.Notifier.Stop()
 
 	// Try to authenticate and start Kite runtime
 	if err := kiteClient.EnsureAuthenticated(); err != nil {
 		log.Printf("⚠️ Kite auth failed (non-fatal): %v", err)
+		app.NotifyTokenInvalid(runtime, err)
 	} else {
 		// Kite is authenticated
 		if err := app.StartKiteRuntime(runtime); err != nil {
@@ -67,6 +138,7 @@ func main() {
 			if err := app.RecoverPendingEntryOrdersOnStartup(runtime); err != nil {
 				log.Printf("⚠️ Failed to recover pending entry orders: %v", err)
 			}
+			app.RecoverPendingExitOrdersOnStartup(runtime)
 
 			// Start engines if market is currently open
 			app.StartEnginesIfMarketOpen(runtime)
@@ -74,10 +146,17 @@ func main() {
 	}
 
 
+	// Export positions and DB pool stats on /metrics
+	app.RegisterMetrics(runtime, db)
+
+	// Publish live state to dashboard stream clients
+	streamStop := make(chan struct{})
+	app.StartStreamPublishers(runtime, streamStop)
+	defer close(streamStop)
+
 	// Setup and start scheduler (cron jobs)
 	scheduler := app.SetupScheduler(runtime)
 	scheduler.Start()
-	defer scheduler.Stop()
 
 	// Initialize handlers
 	trackingStockHandler := &handlers.TrackingStockHandler{TrackingStockRepo: trackingStockRepo, Runtime: runtime}
@@ -85,7 +164,16 @@ func main() {
 	orderHandler := &handlers.OrderHandler{OrderRepo: orderRepo}
 	kiteCallbackHandler := &handlers.KiteCallbackHandler{Kc: kiteClient, Runtime: runtime, InstrumentService: instrumentSvc}
 	stockQueryHandler := &handlers.StockQueryHandler{InstrumentService: instrumentSvc}
-	systemHandler := &handlers.SystemHandler{InstrumentService: instrumentSvc, Kc: kiteClient, Runtime: runtime}
+	systemHandler := &handlers.SystemHandler{InstrumentService: instrumentSvc, Kc: kiteClient, Runtime: runtime, DB: db}
+	tradeHandler := &handlers.TradeHandler{TradeRepo: tradeRepo, LogRepo: &repository.LogRepository{DB: db}}
+	analyticsHandler := &handlers.AnalyticsHandler{AnalyticsSvc: &services.AnalyticsService{TradeRepo: tradeRepo}}
+	exportHandler := &handlers.ExportHandler{Tradebook: &export.Tradebook{Orders: orderRepo, Trades: tradeRepo}}
+	streamHandler := &handlers.StreamHandler{Hub: runtime.Stream}
+	notificationHandler := &handlers.NotificationHandler{Repo: notificationRepo, Dispatcher: runtime.Notifier}
+	signalWebhookHandler := &handlers.SignalWebhookHandler{AlertRepo: signalAlertRepo, Runtime: runtime}
+	configHandler := &handlers.ConfigHandler{Repo: configOverrideRepo}
+	apiKeyHandler := &handlers.APIKeyHandler{Repo: &repository.APIKeyRepository{DB: db}}
+	backtestHandler := &handlers.BacktestHandler{TrackingStockRepo: trackingStockRepo, Runtime: runtime}
 
 	router := gin.Default()
 	// router.Use(cors.New(cors.Config{
@@ -105,7 +193,16 @@ func main() {
 		kiteCallbackHandler,
 		orderHandler,
 		stockQueryHandler,
-		systemHandler)
+		systemHandler,
+		tradeHandler,
+		analyticsHandler,
+		exportHandler,
+		streamHandler,
+		notificationHandler,
+		signalWebhookHandler,
+		configHandler,
+		apiKeyHandler,
+		backtestHandler)
 
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -113,9 +210,50 @@ func main() {
 		})
 	})
 
+	router.GET("/healthz", systemHandler.Healthz)
+	router.GET("/readyz", systemHandler.Readyz)
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	port := config.ServerConfig.Port
-	log.Printf("🌍 Server starting on :%s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatal("Failed to run server: ", err)
+	srv := &http.Server{Addr: ":" + port, Handler: router}
+	go func() {
+		log.Printf("🌍 Server starting on :%s", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to run server: ", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	log.Println("🛑 Shutdown signal received, stopping...")
+
+	cfg := config.Current()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	app.Shutdown(shutdownCtx, runtime, cfg.ShutdownFlatten)
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("⚠️ HTTP server did not shut down cleanly: %v", err)
+	}
+	log.Println("👋 Server stopped")
+}
+
+// setupLogging switches the process to the structured logger configured by
+// LOG_FORMAT, LOG_LEVEL and LOG_LEVELS. It runs again when the levels are
+// reloaded.
+func setupLogging(cfg *config.Config) {
+	level, err := logging.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		log.Fatalf("Invalid LOG_LEVEL: %v", err)
+	}
+	levels, err := logging.ParseComponentLevels(cfg.LogLevels)
+	if err != nil {
+		log.Fatalf("Invalid LOG_LEVELS: %v", err)
 	}
+	logging.Setup(logging.Options{
+		Format:          cfg.LogFormat,
+		Level:           level,
+		ComponentLevels: levels,
+	})
 }
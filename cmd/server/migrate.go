@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/database"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up          apply every pending migration
+  down [n]    revert the last n applied migrations (default 1)
+  status      list migrations and when each was applied
+  version     print the current schema version`
+
+// runMigrate runs the migrate subcommand and returns the process exit code.
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	db := database.ConnectPostgresDB()
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Printf("❌ Failed to load migrations: %v", err)
+		return 1
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Printf("❌ Migration failed: %v", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			log.Println("✅ Database schema is up to date")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				fmt.Fprintf(os.Stderr, "invalid step count %q\n", args[1])
+				return 2
+			}
+			steps = n
+		}
+		if _, err := migrator.Down(ctx, steps); err != nil {
+			log.Printf("❌ Revert failed: %v", err)
+			return 1
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Printf("❌ Failed to read migration status: %v", err)
+			return 1
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s  %s\n", s.Version, s.Name, applied)
+		}
+	case "version":
+		version, err := database.SchemaVersion(ctx, db)
+		if err != nil {
+			log.Printf("❌ Failed to read schema version: %v", err)
+			return 1
+		}
+		fmt.Printf("%d (latest %d)\n", version, migrator.Latest())
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// client calls the server's /api/v1 routes with an API key.
+type client struct {
+	server string
+	key    string
+	http   *http.Client
+}
+
+func newClient(server, key string) *client {
+	return &client{
+		server: strings.TrimRight(server, "/"),
+		key:    key,
+		// Jobs and reconciliation run to completion before the server replies.
+		http: &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// do sends a request and decodes a JSON reply into out, if out is not nil.
+// Replies outside 2xx are returned as errors carrying the server's message.
+func (c *client) do(method, path string, query url.Values, body, out any) error {
+	resp, err := c.send(method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// stream sends a GET request and copies the reply body to w.
+func (c *client) stream(path string, query url.Values, w io.Writer) error {
+	resp, err := c.send(http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+func (c *client) send(method, path string, query url.Values, body any) (*http.Response, error) {
+	if c.key == "" {
+		return nil, fmt.Errorf("no API key: pass -key or set STOCKCTL_API_KEY")
+	}
+
+	u := c.server + "/api/v1" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = strings.NewReader(string(b))
+	}
+	req, err := http.NewRequest(method, u, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.key)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, replyError(resp)
+	}
+	return resp, nil
+}
+
+// replyError turns the handlers' {"message", "error"} replies into an error.
+func replyError(resp *http.Response) error {
+	var reply struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&reply)
+
+	parts := []string{}
+	for _, s := range []string{reply.Message, reply.Error} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, http.StatusText(resp.StatusCode))
+	}
+	return fmt.Errorf("server replied %d: %s", resp.StatusCode, strings.Join(parts, ": "))
+}
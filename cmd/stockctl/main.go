@@ -0,0 +1,386 @@
+// Command stockctl operates a running server through its API: tracking
+// stocks, live state, scheduler jobs, reconciliation, tradebook exports and
+// backtests.
+// It authenticates with an API key, which `stockctl keys create` issues
+// straight from the database to bootstrap the first one.
+//
+//	export STOCKCTL_API_KEY=$(stockctl keys create -phone 9876543210 -name ops)
+//	stockctl stocks list
+//	stockctl jobs run market_open
+//	stockctl export -from 2026-01-01 -to 2026-03-31 -format xlsx -out q4.xlsx
+//	stockctl backtest -stock 3 -from 2026-01-01 -to 2026-03-31
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/backtest"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/database"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/export"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/services"
+)
+
+const usage = `usage: stockctl [-server URL] [-key KEY] <command> [flags]
+
+commands:
+  stocks list                    list tracking stocks
+  stocks add -symbol ... ...     add a tracking stock
+  stocks start|stop ID           start or stop tracking a stock
+  state                          show the live runtime state
+  jobs list                      list scheduler jobs and their last outcome
+  jobs run NAME                  run a scheduler job now
+  reconcile                      compare tracking state with the broker now
+  export -from ... -to ...       export the tradebook (-db reads the database)
+  backtest -stock ID -from ...   replay the strategy on a stock's Kite candles
+  keys create -phone ... -name   issue an API key (reads the database)
+`
+
+func main() {
+	log.SetFlags(0)
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		log.Fatalf("stockctl: %v", err)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	global := flag.NewFlagSet("stockctl", flag.ContinueOnError)
+	global.Usage = func() { fmt.Fprint(global.Output(), usage) }
+	server := global.String("server", envOr("STOCKCTL_SERVER", "http://localhost:8080"), "server URL, or $STOCKCTL_SERVER")
+	key := global.String("key", os.Getenv("STOCKCTL_API_KEY"), "API key, or $STOCKCTL_API_KEY")
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+	args = global.Args()
+	if len(args) == 0 {
+		global.Usage()
+		return errors.New("no command given")
+	}
+
+	c := newClient(*server, *key)
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "stocks":
+		return runStocks(c, args, stdout)
+	case "state":
+		return printReply(c, http.MethodGet, "/system/status", "status", stdout)
+	case "jobs":
+		return runJobs(c, args, stdout)
+	case "reconcile":
+		return printReply(c, http.MethodPost, "/system/reconcile", "report", stdout)
+	case "export":
+		return runExport(c, args, stdout)
+	case "backtest":
+		return runBacktest(c, args, stdout)
+	case "keys":
+		return runKeys(args, stdout)
+	default:
+		global.Usage()
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// subcommand returns the first argument, or an error naming the choices.
+func subcommand(args []string, cmd string, choices string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("usage: stockctl %s %s", cmd, choices)
+	}
+	return args[0], args[1:], nil
+}
+
+func runStocks(c *client, args []string, stdout io.Writer) error {
+	sub, args, err := subcommand(args, "stocks", "list|add|start|stop")
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "list":
+		var stocks []models.TrackingStock
+		if err := c.do(http.MethodGet, "/tracking-stocks", nil, nil, &stocks); err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tSYMBOL\tEXCHANGE\tSTATUS\tQTY\tTARGET\tSTOPLOSS\tPRICE LIMIT\tTRADES/DAY")
+		for _, s := range stocks {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%.2f\t%.2f\t%.2f\t%d\n", s.ID, s.TradingSymbol, s.Exchange, s.Status,
+				s.Quantity, s.Target, s.StopLoss, s.OrderPriceLimit, s.AllowedTrades)
+		}
+		return w.Flush()
+
+	case "add":
+		fs := flag.NewFlagSet("stocks add", flag.ContinueOnError)
+		symbol := fs.String("symbol", "", "trading symbol (required)")
+		exchange := fs.String("exchange", "NSE", "exchange")
+		token := fs.Int64("token", 0, "instrument token (required)")
+		target := fs.Float64("target", 0, "target points (required)")
+		stoploss := fs.Float64("stoploss", 0, "stoploss points (required)")
+		priceLimit := fs.Float64("price-limit", 0, "order price limit (required)")
+		quantity := fs.Uint("qty", 0, "quantity per order (required)")
+		trades := fs.Uint("trades", 1, "entries allowed per day")
+		status := fs.String("status", "ACTIVE", "ACTIVE or INACTIVE")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if *symbol == "" || *token == 0 || *target == 0 || *stoploss == 0 || *priceLimit == 0 || *quantity == 0 {
+			return errors.New("stocks add needs -symbol, -token, -target, -stoploss, -price-limit and -qty")
+		}
+
+		body := map[string]any{
+			"trading_symbol":    *symbol,
+			"exchange":          *exchange,
+			"instrument_token":  *token,
+			"target":            *target,
+			"stoploss":          *stoploss,
+			"order_price_limit": *priceLimit,
+			"quantity":          *quantity,
+			"allowed_trades":    *trades,
+			"status":            *status,
+		}
+		var reply struct {
+			ID int64 `json:"id"`
+		}
+		if err := c.do(http.MethodPost, "/tracking-stocks", nil, body, &reply); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "added %s as tracking stock %d\n", *symbol, reply.ID)
+		return nil
+
+	case "start", "stop":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: stockctl stocks %s ID", sub)
+		}
+		var id int64
+		if _, err := fmt.Sscan(args[0], &id); err != nil {
+			return fmt.Errorf("invalid tracking stock ID %q", args[0])
+		}
+		return printMessage(c, http.MethodPatch, fmt.Sprintf("/tracking-stocks/%d/%s", id, sub), stdout)
+
+	default:
+		return fmt.Errorf("unknown stocks command %q", sub)
+	}
+}
+
+func runJobs(c *client, args []string, stdout io.Writer) error {
+	sub, args, err := subcommand(args, "jobs", "list|run NAME")
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "list":
+		var reply struct {
+			Jobs []struct {
+				Name       string    `json:"name"`
+				NextRun    time.Time `json:"next_run"`
+				LastRun    time.Time `json:"last_run"`
+				LastResult string    `json:"last_result"`
+				LastError  string    `json:"last_error"`
+			} `json:"jobs"`
+		}
+		if err := c.do(http.MethodGet, "/system/jobs", nil, nil, &reply); err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tNEXT RUN\tLAST RUN\tRESULT\tERROR")
+		for _, j := range reply.Jobs {
+			lastRun := "-"
+			if !j.LastRun.IsZero() {
+				lastRun = j.LastRun.Format(time.DateTime)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", j.Name, j.NextRun.Format(time.DateTime), lastRun, j.LastResult, j.LastError)
+		}
+		return w.Flush()
+
+	case "run":
+		if len(args) != 1 {
+			return errors.New("usage: stockctl jobs run NAME")
+		}
+		return printMessage(c, http.MethodPost, "/system/jobs/"+url.PathEscape(args[0])+"/run", stdout)
+
+	default:
+		return fmt.Errorf("unknown jobs command %q", sub)
+	}
+}
+
+// printMessage sends a request and prints the message of the reply.
+func printMessage(c *client, method, path string, stdout io.Writer) error {
+	var reply struct {
+		Message string `json:"message"`
+	}
+	if err := c.do(method, path, nil, nil, &reply); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, reply.Message)
+	return nil
+}
+
+// printReply sends a request and prints the field of the reply as indented JSON.
+func printReply(c *client, method, path, field string, stdout io.Writer) error {
+	var reply map[string]json.RawMessage
+	if err := c.do(method, path, nil, nil, &reply); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(reply[field])
+}
+
+func runExport(c *client, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fromFlag := fs.String("from", "", "first trading date, YYYY-MM-DD (required)")
+	toFlag := fs.String("to", "", "last trading date, YYYY-MM-DD, inclusive (required)")
+	format := fs.String("format", export.FormatCSV, "csv or xlsx")
+	sheet := fs.String("sheet", export.SheetOrders, "sheet to write as csv: orders or trades")
+	out := fs.String("out", "", "output file (default: stdout)")
+	direct := fs.Bool("db", false, "read the database directly instead of calling the server")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	from, err := time.ParseInLocation(time.DateOnly, *fromFlag, ist)
+	if err != nil {
+		return fmt.Errorf("invalid -from date %q, expected YYYY-MM-DD", *fromFlag)
+	}
+	to, err := time.ParseInLocation(time.DateOnly, *toFlag, ist)
+	if err != nil {
+		return fmt.Errorf("invalid -to date %q, expected YYYY-MM-DD", *toFlag)
+	}
+	if to.Before(from) {
+		return errors.New("-from must not be after -to")
+	}
+	if *format != export.FormatCSV && *format != export.FormatXLSX {
+		return errors.New("-format must be csv or xlsx")
+	}
+
+	w := stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if *direct {
+		config.MustLoad()
+		db := database.ConnectPostgresDB()
+		defer db.Close()
+		tradebook := &export.Tradebook{
+			Orders: &repository.OrderRepository{DB: db},
+			Trades: &repository.TradeRepository{DB: db},
+		}
+		// The tradebook takes an exclusive end.
+		end := to.AddDate(0, 0, 1)
+		if *format == export.FormatXLSX {
+			return tradebook.WriteXLSX(context.Background(), w, from, end)
+		}
+		return tradebook.WriteCSV(context.Background(), w, *sheet, from, end)
+	}
+
+	query := url.Values{
+		"from":   {*fromFlag},
+		"to":     {*toFlag},
+		"format": {*format},
+		"sheet":  {*sheet},
+	}
+	return c.stream("/exports/tradebook", query, w)
+}
+
+// runBacktest replays the strategy on a tracking stock through the server,
+// which fetches the candles from Kite.
+func runBacktest(c *client, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("backtest", flag.ContinueOnError)
+	stock := fs.Int64("stock", 0, "tracking stock ID (required)")
+	from := fs.String("from", "", "first trading date, YYYY-MM-DD (required)")
+	to := fs.String("to", "", "last trading date, YYYY-MM-DD, inclusive (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *stock == 0 || *from == "" || *to == "" {
+		return errors.New("backtest needs -stock, -from and -to")
+	}
+
+	var reply struct {
+		Report backtest.Report `json:"report"`
+	}
+	path := fmt.Sprintf("/tracking-stocks/%d/backtest", *stock)
+	if err := c.do(http.MethodGet, path, url.Values{"from": {*from}, "to": {*to}}, nil, &reply); err != nil {
+		return err
+	}
+
+	r := reply.Report
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATE\tDIRECTION\tQTY\tENTRY\tEXIT\tREASON\tGROSS\tCHARGES\tNET")
+	for _, t := range r.Trades {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%.2f\t%.2f\t%s\t%.2f\t%.2f\t%.2f\n", t.Date, t.Direction, t.Quantity,
+			t.EntryPrice, t.ExitPrice, t.ExitReason, t.GrossPnL, t.Charges, t.NetPnL)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s %s to %s: days=%d trades=%d won=%d lost=%d gross=%.2f charges=%.2f net=%.2f\n",
+		r.TradingSymbol, r.From, r.To, r.Days, len(r.Trades), r.Wins, r.Losses, r.GrossPnL, r.Charges, r.NetPnL)
+	return nil
+}
+
+// runKeys issues API keys straight from the database, since the first key
+// cannot be issued through the API without one.
+func runKeys(args []string, stdout io.Writer) error {
+	sub, args, err := subcommand(args, "keys", "create -phone PHONE -name NAME")
+	if err != nil {
+		return err
+	}
+	if sub != "create" {
+		return fmt.Errorf("unknown keys command %q", sub)
+	}
+
+	fs := flag.NewFlagSet("keys create", flag.ContinueOnError)
+	phone := fs.String("phone", "", "phone number of the user the key acts as (required)")
+	name := fs.String("name", "stockctl", "name to tell the key apart by")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *phone == "" {
+		return errors.New("keys create needs -phone")
+	}
+
+	config.MustLoad()
+	db := database.ConnectPostgresDB()
+	defer db.Close()
+
+	ctx := context.Background()
+	user, err := (&repository.UserRepository{DB: db}).GetByPhone(ctx, *phone)
+	if err != nil {
+		return fmt.Errorf("failed to find user %s: %w", *phone, err)
+	}
+	key, err := services.CreateAPIKey(ctx, &repository.APIKeyRepository{DB: db}, user.ID, *name)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	log.Printf("✅ Created API key %q (%s...) for %s; it is shown only once", key.Name, key.KeyPrefix, user.FullName)
+	fmt.Fprintln(stdout, key.Key)
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/app"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/handlers"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/middleware"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository/memory"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/scheduler"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// candleBroker serves one trading day of 5-min candles: an opening range of
+// 99-103, a breakout above it and a candle reaching the target.
+type candleBroker struct{ broker.Broker }
+
+func (candleBroker) GetHistorical(_ uint32, _ string, from, _ time.Time) ([]broker.Candle, error) {
+	open := from // 09:15 on the first day
+	return []broker.Candle{
+		{Time: open, Open: 100, High: 103, Low: 99, Close: 101},
+		{Time: open.Add(15 * time.Minute), Open: 101, High: 104, Low: 101, Close: 103.5},
+		{Time: open.Add(20 * time.Minute), Open: 103.5, High: 108, Low: 103, Close: 107},
+	}, nil
+}
+
+// newServer serves the routes stockctl uses behind the API key middleware.
+func newServer(t *testing.T, db *memory.DB) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	ran := 0
+	sched := scheduler.NewScheduler()
+	sched.AddJob("instrument_fetch", 8, 0, func() error { ran++; return nil })
+	runtime := &app.Runtime{Scheduler: sched}
+	stocks := &handlers.TrackingStockHandler{TrackingStockRepo: db.TrackingStocks(), Runtime: runtime}
+	system := &handlers.SystemHandler{Runtime: runtime}
+	backtest := &handlers.BacktestHandler{TrackingStockRepo: db.TrackingStocks(),
+		Runtime: &app.Runtime{KiteReady: true, Broker: candleBroker{}}}
+
+	r := gin.New()
+	api := r.Group("/api/v1", middleware.AuthMiddleware(db.APIKeys()))
+	api.GET("/tracking-stocks", stocks.GetAll)
+	api.POST("/tracking-stocks", stocks.Add)
+	api.GET("/system/jobs", system.Jobs)
+	api.POST("/system/jobs/:name/run", system.RunJob)
+	api.GET("/tracking-stocks/:id/backtest", backtest.Backtest)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() {
+		if ran != 1 {
+			t.Errorf("instrument_fetch ran %d times, want 1", ran)
+		}
+	})
+	return srv
+}
+
+func TestStockctlAgainstServer(t *testing.T) {
+	db := memory.New()
+	srv := newServer(t, db)
+	key, err := services.CreateAPIKey(context.Background(), db.APIKeys(), 1, "ops")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stockctl := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		err := run(append([]string{"-server", srv.URL, "-key", key.Key}, args...), &out)
+		return out.String(), err
+	}
+
+	out, err := stockctl("stocks", "add", "-symbol", "INFY", "-token", "408065", "-target", "10", "-stoploss", "5",
+		"-price-limit", "20000", "-qty", "10", "-status", "INACTIVE")
+	if err != nil || !strings.Contains(out, "tracking stock 1") {
+		t.Fatalf("stocks add = %q, %v", out, err)
+	}
+	out, err = stockctl("stocks", "list")
+	if err != nil || !strings.Contains(out, "INFY") || !strings.Contains(out, "INACTIVE") {
+		t.Fatalf("stocks list = %q, %v", out, err)
+	}
+
+	if out, err = stockctl("jobs", "run", "instrument_fetch"); err != nil || !strings.Contains(out, "job completed") {
+		t.Fatalf("jobs run = %q, %v", out, err)
+	}
+	if _, err = stockctl("jobs", "run", "no_such_job"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("jobs run of an unknown job error = %v, want a 404", err)
+	}
+	out, err = stockctl("jobs", "list")
+	if err != nil || !strings.Contains(out, "instrument_fetch") || !strings.Contains(out, "success") {
+		t.Fatalf("jobs list = %q, %v", out, err)
+	}
+
+	out, err = stockctl("backtest", "-stock", "1", "-from", "2026-03-02", "-to", "2026-03-02")
+	if err != nil || !strings.Contains(out, "TARGET_HIT") || !strings.Contains(out, "trades=1 won=1 lost=0") {
+		t.Fatalf("backtest = %q, %v", out, err)
+	}
+
+	if err := db.APIKeys().RevokeKey(context.Background(), 1, key.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = stockctl("stocks", "list"); err == nil || !strings.Contains(err.Error(), "invalid api key") {
+		t.Errorf("stocks list with a revoked key error = %v, want invalid api key", err)
+	}
+}
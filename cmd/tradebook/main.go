@@ -0,0 +1,77 @@
+// Command tradebook exports the tradebook straight from the database, for
+// when the API is not running.
+//
+//	tradebook -from 2026-01-01 -to 2026-03-31 -format xlsx -out q4.xlsx
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/database"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/export"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+)
+
+func main() {
+	fromFlag := flag.String("from", "", "first trading date, YYYY-MM-DD (required)")
+	toFlag := flag.String("to", "", "last trading date, YYYY-MM-DD, inclusive (required)")
+	format := flag.String("format", export.FormatCSV, "csv or xlsx")
+	sheet := flag.String("sheet", export.SheetOrders, "sheet to write as csv: orders or trades")
+	out := flag.String("out", "", "output file (default: stdout)")
+	flag.Parse()
+
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	from, err := time.ParseInLocation("2006-01-02", *fromFlag, ist)
+	if err != nil {
+		log.Fatalf("invalid -from date %q, expected YYYY-MM-DD", *fromFlag)
+	}
+	to, err := time.ParseInLocation("2006-01-02", *toFlag, ist)
+	if err != nil {
+		log.Fatalf("invalid -to date %q, expected YYYY-MM-DD", *toFlag)
+	}
+	to = to.AddDate(0, 0, 1)
+	if !from.Before(to) {
+		log.Fatal("-from must not be after -to")
+	}
+	if *format != export.FormatCSV && *format != export.FormatXLSX {
+		log.Fatal("-format must be csv or xlsx")
+	}
+
+	config.MustLoad()
+	db := database.ConnectPostgresDB()
+	defer db.Close()
+
+	tradebook := &export.Tradebook{
+		Orders: &repository.OrderRepository{DB: db},
+		Trades: &repository.TradeRepository{DB: db},
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", *out, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	ctx := context.Background()
+	if *format == export.FormatXLSX {
+		err = tradebook.WriteXLSX(ctx, w, from, to)
+	} else {
+		err = tradebook.WriteCSV(ctx, w, *sheet, from, to)
+	}
+	if err != nil {
+		log.Fatalf("Tradebook export failed: %v", err)
+	}
+	if *out != "" {
+		log.Printf("✅ Tradebook written to %s", *out)
+	}
+}
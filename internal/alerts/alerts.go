@@ -0,0 +1,145 @@
+// Package alerts verifies and parses trade alerts sent to the signal webhook
+// by outside tools such as TradingView.
+package alerts
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Request headers of a signed alert. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" under the user's secret, sent as "sha256=<hex>".
+const (
+	HeaderKeyID     = "X-Signal-Key"
+	HeaderTimestamp = "X-Signal-Timestamp"
+	HeaderSignature = "X-Signal-Signature"
+)
+
+// MaxIDLength is the longest sender's alert ID that is kept as it is.
+const MaxIDLength = 100
+
+// Alert sides.
+const (
+	SideBuy  = "BUY"
+	SideSell = "SELL"
+	SideExit = "EXIT"
+)
+
+var (
+	ErrMissingSignature = errors.New("missing signature headers")
+	ErrBadSignature     = errors.New("signature does not match")
+	ErrStale            = errors.New("timestamp is outside the accepted window")
+)
+
+// Payload is the JSON body of an alert. Stoploss and target are points from
+// the fill price, as for manual entries; price is a LIMIT price, zero for MARKET.
+type Payload struct {
+	ID       string  `json:"id"` // sender's alert ID, used to drop duplicates
+	Symbol   string  `json:"symbol"`
+	Side     string  `json:"side"` // BUY, SELL or EXIT
+	Qty      uint32  `json:"qty"`
+	Risk     float64 `json:"risk"` // rupees to lose at the stoploss; sizes qty when qty is not given
+	StopLoss float64 `json:"stoploss"`
+	Target   float64 `json:"target"`
+	Price    float64 `json:"price"`
+}
+
+// Sign returns the signature header value for body sent at timestamp.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks an alert's signature and that its timestamp, in Unix seconds,
+// is within tolerance of now, so a captured request cannot be replayed later.
+func Verify(secret, timestamp, signature string, body []byte, now time.Time, tolerance time.Duration) error {
+	if timestamp == "" || signature == "" {
+		return ErrMissingSignature
+	}
+	if !hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature)) {
+		return ErrBadSignature
+	}
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q", timestamp)
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > tolerance || d < -tolerance {
+		return ErrStale
+	}
+	return nil
+}
+
+// DedupKey identifies an alert for duplicate detection: the sender's ID when
+// it has one, otherwise a hash of the signed timestamp and body, which a
+// replay repeats but a fresh alert with the same body does not. An ID longer
+// than MaxIDLength is hashed, so that its rejection can still be logged.
+func DedupKey(p Payload, timestamp string, body []byte) string {
+	if len(p.ID) > MaxIDLength {
+		sum := sha256.Sum256([]byte(p.ID))
+		return "sha256:" + hex.EncodeToString(sum[:16])
+	}
+	if p.ID != "" {
+		return p.ID
+	}
+	sum := sha256.Sum256([]byte(timestamp + "." + string(body)))
+	return "sha256:" + hex.EncodeToString(sum[:16])
+}
+
+// Parse decodes and validates an alert body. Exchange prefixes such as
+// "NSE:" are stripped from the symbol.
+func Parse(body []byte) (Payload, error) {
+	var p Payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return p, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if i := strings.LastIndex(p.Symbol, ":"); i >= 0 {
+		p.Symbol = p.Symbol[i+1:]
+	}
+	p.Symbol = strings.ToUpper(strings.TrimSpace(p.Symbol))
+	p.Side = strings.ToUpper(strings.TrimSpace(p.Side))
+
+	switch {
+	case p.Symbol == "":
+		return p, errors.New("symbol is required")
+	case p.Side != SideBuy && p.Side != SideSell && p.Side != SideExit:
+		return p, fmt.Errorf("side must be BUY, SELL or EXIT, got %q", p.Side)
+	case p.Risk < 0 || p.StopLoss < 0 || p.Target < 0 || p.Price < 0:
+		return p, errors.New("risk, stoploss, target and price cannot be negative")
+	case p.Side != SideExit && p.Qty == 0 && p.Risk == 0:
+		return p, errors.New("qty or risk is required for an entry")
+	case len(p.ID) > MaxIDLength:
+		return p, fmt.Errorf("id is longer than %d characters", MaxIDLength)
+	}
+	return p, nil
+}
+
+// SizeFromRisk is the quantity that loses risk rupees when the stoploss,
+// stopLoss points away, is hit.
+func SizeFromRisk(risk, stopLoss float64) uint32 {
+	if risk <= 0 || stopLoss <= 0 {
+		return 0
+	}
+	return uint32(math.Floor(risk / stopLoss))
+}
+
+// NewKey generates a key ID and secret for a user's webhook.
+func NewKey() (keyID, secret string, err error) {
+	b := make([]byte, 40)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	return "sk_" + hex.EncodeToString(b[:8]), hex.EncodeToString(b[8:]), nil
+}
@@ -0,0 +1,85 @@
+package alerts
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1_741_600_000, 0)
+	body := []byte(`{"symbol":"NSE:INFY","side":"buy","qty":10}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := Sign("secret", ts, body)
+
+	if err := Verify("secret", ts, sig, body, now.Add(time.Minute), 5*time.Minute); err != nil {
+		t.Fatalf("valid alert rejected: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		secret string
+		ts     string
+		sig    string
+		body   []byte
+		now    time.Time
+		want   error
+	}{
+		{"wrong secret", "other", ts, sig, body, now, ErrBadSignature},
+		{"tampered body", "secret", ts, sig, []byte(`{"symbol":"INFY","side":"buy","qty":1000}`), now, ErrBadSignature},
+		{"timestamp swapped", "secret", strconv.FormatInt(now.Unix()+60, 10), sig, body, now, ErrBadSignature},
+		{"replayed later", "secret", ts, sig, body, now.Add(10 * time.Minute), ErrStale},
+		{"missing signature", "secret", ts, "", body, now, ErrMissingSignature},
+	}
+	for _, tc := range cases {
+		if err := Verify(tc.secret, tc.ts, tc.sig, tc.body, tc.now, 5*time.Minute); err != tc.want {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte(`{"symbol":"NSE:infy","side":"sell","risk":500,"stoploss":4}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Symbol != "INFY" || p.Side != SideSell {
+		t.Fatalf("parsed %+v", p)
+	}
+	if qty := SizeFromRisk(p.Risk, p.StopLoss); qty != 125 {
+		t.Fatalf("qty = %d, want 125", qty)
+	}
+
+	for _, body := range []string{
+		`not json`,
+		`{"side":"BUY","qty":1}`,
+		`{"symbol":"INFY","side":"HOLD","qty":1}`,
+		`{"symbol":"INFY","side":"BUY"}`,
+		`{"symbol":"INFY","side":"BUY","qty":1,"stoploss":-2}`,
+	} {
+		if _, err := Parse([]byte(body)); err == nil {
+			t.Errorf("%s accepted", body)
+		}
+	}
+	if _, err := Parse([]byte(`{"symbol":"INFY","side":"EXIT"}`)); err != nil {
+		t.Errorf("exit without qty rejected: %v", err)
+	}
+}
+
+func TestDedupKey(t *testing.T) {
+	body := []byte(`{"symbol":"INFY","side":"BUY","qty":1}`)
+	if got := DedupKey(Payload{ID: "tv-1"}, "100", body); got != "tv-1" {
+		t.Fatalf("key = %q, want the sender's ID", got)
+	}
+	if DedupKey(Payload{}, "100", body) != DedupKey(Payload{}, "100", body) {
+		t.Fatal("a replayed request must have the same key")
+	}
+	if DedupKey(Payload{}, "100", body) == DedupKey(Payload{}, "200", body) {
+		t.Fatal("a fresh alert with the same body must have a new key")
+	}
+	longID := strings.Repeat("x", MaxIDLength+1)
+	if got := DedupKey(Payload{ID: longID}, "100", body); len(got) > MaxIDLength || got != DedupKey(Payload{ID: longID}, "200", body) {
+		t.Fatalf("key of an over-long ID = %q, want a stable hash that fits", got)
+	}
+}
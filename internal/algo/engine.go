@@ -2,30 +2,34 @@ package algo
 
 import (
 	// "context"
+	"fmt"
 	"log"
 	"sync"
 	"time"
 
-	"github.com/SM-Sclass/stock_client2-go_backend/internal/kite"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/logging"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/metrics"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/tracking"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/utils"
-
-	kitemodels "github.com/zerodha/gokiteconnect/v4/models"
 )
 
+// Daily trade count, loss per trade and order value limits come from the
+// reloadable config: MAX_DAILY_TRADES, MAX_LOSS_PER_TRADE and MAX_ORDER_VALUE.
 const (
-	maxDailyTrades   = 2      // max entry trades per day across all stocks
-	maxLossPerTrade  = 4500.0 // ₹4500 max stoploss exposure per trade
 	maxDailyLoss     = 9000.0 // ₹9000 max total loss per day
 	minVolatilityPct = 0.35   // minimum (HIGH-LOW)/LOW*100 % required to enter
 )
 
+var signalLog = logging.For("algo")
+
 type AlgoEngine struct {
 	trackingManager *tracking.TrackingManager
-	broadcaster     *kite.TickBroadcaster
-	kiteClient      *kite.KiteClient
+	broadcaster     *broker.TickBroadcaster
+	broker          broker.Broker
 
-	tickChan   chan []kitemodels.Tick
+	tickChan   chan []broker.Tick
 	signalChan chan TradeSignal
 	stopChan   chan struct{}
 	wg         sync.WaitGroup
@@ -41,15 +45,15 @@ type AlgoEngine struct {
 
 func NewAlgoEngine(
 	trackingManager *tracking.TrackingManager,
-	broadcaster *kite.TickBroadcaster,
-	kiteClient *kite.KiteClient,
+	broadcaster *broker.TickBroadcaster,
+	brk broker.Broker,
 	signalChan chan TradeSignal,
 ) *AlgoEngine {
 	ist, _ := time.LoadLocation("Asia/Kolkata")
 	return &AlgoEngine{
 		trackingManager: trackingManager,
 		broadcaster:     broadcaster,
-		kiteClient:      kiteClient,
+		broker:          brk,
 		signalChan:      signalChan,
 		stopChan:        make(chan struct{}),
 		ist:             ist,
@@ -122,6 +126,21 @@ func (ae *AlgoEngine) DecrementOpenTrade() {
 	}
 }
 
+// IncrementOpenTrade counts a position the engine did not open itself, such as
+// an adopted broker position, so no new entry is taken alongside it.
+func (ae *AlgoEngine) IncrementOpenTrade() {
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+	ae.openTradeCount++
+}
+
+// OpenTradeCount returns the number of positions the engine considers open.
+func (ae *AlgoEngine) OpenTradeCount() int {
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+	return ae.openTradeCount
+}
+
 func (ae *AlgoEngine) DecrementDailyTrade() {
 	ae.mu.Lock()
 	defer ae.mu.Unlock()
@@ -130,6 +149,60 @@ func (ae *AlgoEngine) DecrementDailyTrade() {
 	}
 }
 
+// ReserveTrade applies the daily risk guards to an entry that did not come from
+// the strategy, e.g. a manual trade, and counts it when they pass.
+func (ae *AlgoEngine) ReserveTrade(quantity uint32, stopLoss float64) error {
+	cfg := config.Current()
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+
+	if ae.dailyTradeCount >= cfg.MaxDailyTrades {
+		return fmt.Errorf("daily trade limit of %d reached", cfg.MaxDailyTrades)
+	}
+	if ae.openTradeCount >= 1 {
+		return fmt.Errorf("another trade is already open")
+	}
+	if exposure := float64(quantity) * stopLoss; exposure > cfg.MaxLossPerTrade {
+		return fmt.Errorf("stoploss exposure %.0f exceeds %.0f per trade", exposure, cfg.MaxLossPerTrade)
+	}
+
+	ae.dailyTradeCount++
+	ae.openTradeCount++
+	return nil
+}
+
+// RiskState is the engine's daily risk counters and whether they currently
+// stop new entries.
+type RiskState struct {
+	DailyTrades     int     `json:"daily_trades"`
+	MaxDailyTrades  int     `json:"max_daily_trades"`
+	OpenTrades      int     `json:"open_trades"`
+	MaxLossPerTrade float64 `json:"max_loss_per_trade"`
+	EntriesBlocked  bool    `json:"entries_blocked"`
+	BlockedReason   string  `json:"blocked_reason,omitempty"`
+}
+
+// RiskState returns the current daily risk counters.
+func (ae *AlgoEngine) RiskState() RiskState {
+	cfg := config.Current()
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+
+	state := RiskState{
+		DailyTrades:     ae.dailyTradeCount,
+		MaxDailyTrades:  cfg.MaxDailyTrades,
+		OpenTrades:      ae.openTradeCount,
+		MaxLossPerTrade: cfg.MaxLossPerTrade,
+	}
+	switch {
+	case ae.dailyTradeCount >= cfg.MaxDailyTrades:
+		state.EntriesBlocked, state.BlockedReason = true, "daily trade limit reached"
+	case ae.openTradeCount >= 1:
+		state.EntriesBlocked, state.BlockedReason = true, "a trade is open"
+	}
+	return state
+}
+
 // ─── Tick loop ────────────────────────────────────────────────────────────────
 
 // tickLoop subscribes to the broadcaster and processes every incoming tick.
@@ -148,7 +221,7 @@ func (ae *AlgoEngine) tickLoop() {
 }
 
 // processTick updates the live Current candle and checks real-time stoploss.
-func (ae *AlgoEngine) processTick(tick kitemodels.Tick) {
+func (ae *AlgoEngine) processTick(tick broker.Tick) {
 	price := tick.LastPrice
 	if price == 0 {
 		return
@@ -157,15 +230,49 @@ func (ae *AlgoEngine) processTick(tick kitemodels.Tick) {
 
 	// Update the Current 5-min candle with this tick price.
 	ae.trackingManager.UpdateCurrentCandle(token, price)
+	ae.trackingManager.TrailStopLoss(token, price)
 
 	// Real-time stoploss check for any open position.
 	stock, exists := ae.trackingManager.GetStock(token)
-	if !exists || stock.Locked {
+	if !exists {
+		return
+	}
+	if stock.Locked {
+		// A target LIMIT exit may still be working; the stoploss must keep
+		// protecting the position until it fills.
+		if stock.PendingExitOrderID != "" {
+			ae.checkSlWithPendingExit(stock, price, token)
+		}
 		return
 	}
 	ae.checkTargetAndSl(stock, price, token)
 }
 
+// checkSlWithPendingExit escalates an unfilled target exit to MARKET when the
+// price falls back through the stoploss.
+func (ae *AlgoEngine) checkSlWithPendingExit(stock tracking.TrackedStock, price float64, token uint32) {
+	if stock.ExitEscalated || stock.BasePrice == 0 || (stock.StopLoss == 0 && stock.TrailingStopLoss == 0) {
+		return
+	}
+
+	slHit := false
+	switch stock.Direction {
+	case "BUY":
+		slHit = price <= stock.BasePrice-stock.StopLoss
+	case "SELL":
+		slHit = price >= stock.BasePrice+stock.StopLoss
+	}
+	if !slHit || !ae.trackingManager.TryEscalateExit(token) {
+		return
+	}
+
+	signal := ae.buildExitSignal(stock, token, price, SignalStopLossHit)
+	signal.PendingOrderID = stock.PendingExitOrderID
+	ae.emit(signal)
+	log.Printf("🛑 Stoploss hit for %s while target exit %s is pending: price=%.2f",
+		stock.TradingSymbol, stock.PendingExitOrderID, price)
+}
+
 // checkTargetAndSl sends signals for target and stoploss based on the stock's direction.
 func (ae *AlgoEngine) checkTargetAndSl(stock tracking.TrackedStock, price float64, token uint32) {
 	if stock.Direction == "" || stock.BasePrice == 0 {
@@ -174,44 +281,29 @@ func (ae *AlgoEngine) checkTargetAndSl(stock tracking.TrackedStock, price float6
 	sl := stock.StopLoss
 	target := stock.Target
 	basePrice := stock.BasePrice
-	if sl == 0 || target == 0 {
+	// In trailing mode the stop may sit exactly at the base price and the
+	// target may be switched off; otherwise both are required.
+	if stock.TrailingStopLoss == 0 && (sl == 0 || target == 0) {
 		return
 	}
-	// For BUY: target hit if price >= target, sl hit if price <= sl
-	if stock.Direction == "BUY" {
-		if price >= basePrice+target {
-			if !ae.trackingManager.TryLockStock(token) {
-				return
-			}
-			ae.signalChan <- ae.buildExitSignal(stock, token, price, SignalTargetHit)
-			log.Printf("🎯 Target hit for BUY direc. %s: price=%.2f target=%.2f", stock.TradingSymbol, price, basePrice+target)
-		} else if price <= basePrice-sl {
-			if !ae.trackingManager.TryLockStock(token) {
-				return
-			}
-			ae.signalChan <- ae.buildExitSignal(stock, token, price, SignalStopLossHit)
-			log.Printf("🛑 Stoploss hit for BUY direc. %s: price=%.2f sl=%.2f", stock.TradingSymbol, price, basePrice-sl)
-		}
+	signalType := ExitSignal(stock.Direction, basePrice, target, sl, price)
+	if signalType == SignalNone {
+		return
+	}
+	if !ae.trackingManager.TryLockStock(token) {
 		return
-
 	}
+	ae.emit(ae.buildExitSignal(stock, token, price, signalType))
 
-	// For SELL: target hit if price <= target, sl hit if price >= sl
+	// Levels in the position's favour and against it.
+	targetLevel, slLevel := basePrice+target, basePrice-sl
 	if stock.Direction == "SELL" {
-		if price <= basePrice-target {
-			if !ae.trackingManager.TryLockStock(token) {
-				return
-			}
-			ae.signalChan <- ae.buildExitSignal(stock, token, price, SignalTargetHit)
-			log.Printf("🎯 Target hit for SELL direc. %s: price=%.2f target=%.2f", stock.TradingSymbol, price, basePrice-target)
-		} else if price >= basePrice+sl {
-			if !ae.trackingManager.TryLockStock(token) {
-				return
-			}
-			ae.signalChan <- ae.buildExitSignal(stock, token, price, SignalStopLossHit)
-			log.Printf("🛑 Stoploss hit for SELL direc. %s: price=%.2f sl=%.2f", stock.TradingSymbol, price, basePrice+sl)
-		}
-		return
+		targetLevel, slLevel = basePrice-target, basePrice+sl
+	}
+	if signalType == SignalTargetHit {
+		log.Printf("🎯 Target hit for %s direc. %s: price=%.2f target=%.2f", stock.Direction, stock.TradingSymbol, price, targetLevel)
+	} else {
+		log.Printf("🛑 Stoploss hit for %s direc. %s: price=%.2f sl=%.2f", stock.Direction, stock.TradingSymbol, price, slLevel)
 	}
 }
 
@@ -240,6 +332,9 @@ func (ae *AlgoEngine) onCandleRoll() {
 	if phase == utils.PhasePreMarket || phase == utils.PhasePostMarket {
 		return
 	}
+	defer func(start time.Time) {
+		metrics.CandleRollDuration.Observe(time.Since(start).Seconds())
+	}(time.Now())
 
 	stocks := ae.trackingManager.GetAllStock()
 
@@ -260,13 +355,22 @@ func (ae *AlgoEngine) onCandleRoll() {
 			}
 
 		case utils.PhaseExit:
+			// A target exit still unfilled at 15:10 goes to MARKET.
+			if stock.PendingExitOrderID != "" && ae.trackingManager.TryEscalateExit(stock.InstrumentToken) {
+				signal := ae.buildExitSignal(stock, stock.InstrumentToken, stock.Candles.Previous.Close, SignalForceExit)
+				signal.PendingOrderID = stock.PendingExitOrderID
+				ae.emit(signal)
+				log.Printf("⏰ Force exit for %s: converting pending exit %s", stock.TradingSymbol, stock.PendingExitOrderID)
+				continue
+			}
+
 			// Force-close any open position at 15:10.
 			if stock.SellQuantity > 0 && !stock.Locked {
 				if ae.trackingManager.TryLockStock(stock.InstrumentToken) {
-					ae.signalChan <- ae.buildExitSignal(
+					ae.emit(ae.buildExitSignal(
 						stock, stock.InstrumentToken,
 						stock.Candles.Previous.Close, SignalForceExit,
-					)
+					))
 					log.Printf("⏰ Force exit for %s at 15:10", stock.TradingSymbol)
 				}
 			}
@@ -279,11 +383,13 @@ func (ae *AlgoEngine) onCandleRoll() {
 func (ae *AlgoEngine) checkEntryOnCandle(stock tracking.TrackedStock) {
 	if ae.openTradeCount != 0 {
 		log.Printf("⏸️ Skipping entry check for %s because there's already an open trade", stock.TradingSymbol)
+		metrics.SignalSkips.WithLabelValues("open_trade").Inc()
 		return
 	}
 
 	if stock.MaxExecutableOrders <= 0 {
 		log.Printf("⏸️ Max executable orders reached for %s", stock.TradingSymbol)
+		metrics.SignalSkips.WithLabelValues("max_orders").Inc()
 		return
 	}
 	fifteen := stock.FifteenCandle
@@ -294,17 +400,19 @@ func (ae *AlgoEngine) checkEntryOnCandle(stock tracking.TrackedStock) {
 
 	if !fifteen.IsValid() {
 		log.Printf("⚠️ Fifteen candle not valid for %s — skipping entry check", stock.TradingSymbol)
+		metrics.SignalSkips.WithLabelValues("invalid_fifteen_candle").Inc()
 		return
 	}
 
 	previous := stock.Candles.Previous
 	if !previous.IsValid() {
 		log.Printf("⚠️ Previous candle not valid for %s — skipping entry check", stock.TradingSymbol)
+		metrics.SignalSkips.WithLabelValues("invalid_previous_candle").Inc()
 		return
 	}
 
 	// Volatility filter: (HIGH - LOW) / LOW * 100 must exceed 0.35 %
-	rangeSize := fifteen.High - fifteen.Low
+	// rangeSize := fifteen.High - fifteen.Low
 	// if rangeSize <= 0 {
 	// 	log.Printf("⚠️ Invalid range size for %s — skipping entry check", stock.TradingSymbol)
 	// 	return
@@ -317,54 +425,43 @@ func (ae *AlgoEngine) checkEntryOnCandle(stock tracking.TrackedStock) {
 	// }
 
 	// Daily risk guards
+	cfg := config.Current()
 	ae.mu.Lock()
-	if ae.dailyTradeCount >= maxDailyTrades || ae.openTradeCount >= 1 {
+	if ae.dailyTradeCount >= cfg.MaxDailyTrades || ae.openTradeCount >= 1 {
 		ae.mu.Unlock()
+		metrics.SignalSkips.WithLabelValues("risk_limit").Inc()
 		return
 	}
 	ae.mu.Unlock()
 
 	// Entry direction based on previous candle's Close vs fifteen HIGH/LOW
-	prevClosePrice := previous.Close
-	var signalType SignalType
-	var direction string
-
-	switch {
-	case prevClosePrice > fifteen.High:
-		signalType = SignalEntryBuy
-		direction = "BUY"
-	case prevClosePrice < fifteen.Low:
-		signalType = SignalEntrySell
-		direction = "SELL"
-	default:
+	direction := EntryDirection(fifteen, previous)
+	if direction == "" {
+		metrics.SignalSkips.WithLabelValues("inside_range").Inc()
 		return // price inside range — skip this candle
 	}
+	signalType := SignalEntryBuy
+	if direction == "SELL" {
+		signalType = SignalEntrySell
+	}
 
-	// Position sizing: QUANTITY = 4500 / SL
-	target := rangeSize
-	sl := target * 0.5
-	quantity := uint32(maxLossPerTrade / sl)
+	// Position sizing: QUANTITY = MAX_LOSS_PER_TRADE / SL
+	target, sl := EntryLevels(fifteen)
 
 	ltp, exists := ae.trackingManager.GetTSLtpByToken(stock.InstrumentToken)
 	if !exists || ltp <= 0 {
 		log.Printf("⚠️ LTP is %.2f not available for %s — skipping entry", ltp, stock.TradingSymbol)
+		metrics.SignalSkips.WithLabelValues("no_ltp").Inc()
 		return
 	}
 
-	if ltp != 0 && stock.OrderPriceLimit != 0 && float64(quantity)*ltp > stock.OrderPriceLimit {
-		// If the order price limit is set and the current LTP exceeds it, we adjust the quantity downwards to respect the order price limit
-		quantity = uint32(stock.OrderPriceLimit / ltp)
-		log.Printf("⚠️ Adjusted quantity for %s due to order price limit: new qty=%d", stock.TradingSymbol, quantity)
-	} else if float64(quantity)*ltp > 200000 {
-		quantity = uint32(200000 / ltp)
-		log.Printf("⚠️ Adjusted quantity for %s due to max order value: new qty=%d", stock.TradingSymbol, quantity)
-	}
-
-	if quantity == 0 {
-		quantity = 1
+	quantity, sizingNote := EntryQuantity(cfg, stock.OrderPriceLimit, ltp, sl)
+	if sizingNote != "" {
+		log.Printf("⚠️ Adjusted quantity for %s: %s", stock.TradingSymbol, sizingNote)
 	}
 
 	if !ae.trackingManager.TryLockStock(stock.InstrumentToken) {
+		metrics.SignalSkips.WithLabelValues("locked").Inc()
 		return
 	}
 	ae.trackingManager.SetSignalFired(stock.InstrumentToken)
@@ -375,7 +472,7 @@ func (ae *AlgoEngine) checkEntryOnCandle(stock tracking.TrackedStock) {
 	ae.openTradeCount++
 	ae.mu.Unlock()
 
-	ae.signalChan <- TradeSignal{
+	ae.emit(TradeSignal{
 		TrackingStockID: stock.ID,
 		InstrumentToken: stock.InstrumentToken,
 		TradingSymbol:   stock.TradingSymbol,
@@ -388,24 +485,46 @@ func (ae *AlgoEngine) checkEntryOnCandle(stock tracking.TrackedStock) {
 		StopLoss:        sl,
 		Quantity:        quantity,
 		Timestamp:       time.Now(),
-	}
+		SizingNote:      sizingNote,
+	})
 
 	log.Printf("📈 Entry %s for %s: close=%.2f H=%.2f L=%.2f target=%.2f sl=%.2f qty=%d",
 		direction, stock.TradingSymbol, ltp,
 		fifteen.High, fifteen.Low, target, sl, quantity)
 }
 
+// emit gives a signal its correlation ID, logs and counts it, and sends it
+// to the OrderEngine.
+func (ae *AlgoEngine) emit(signal TradeSignal) {
+	signal.CorrelationID = logging.NewCorrelationID()
+	metrics.Signals.WithLabelValues(string(signal.SignalType)).Inc()
+	signalLog.Info("📡 Signal emitted",
+		logging.KeyCorrelationID, signal.CorrelationID,
+		"symbol", signal.TradingSymbol,
+		"signal_type", signal.SignalType,
+		"direction", signal.Direction,
+		"price", signal.TriggerPrice,
+		"base_price", signal.BasePrice,
+		"target", signal.Target,
+		"stoploss", signal.StopLoss,
+		"qty", signal.Quantity,
+		"pending_order_id", signal.PendingOrderID,
+		"sizing_note", signal.SizingNote)
+	ae.signalChan <- signal
+}
+
 // ─── Historical data loaders ──────────────────────────────────────────────────
 
 // loadFifteenCandles fetches the 9:15–9:30 opening-range candle from the Kite
 // historical API for every tracked stock and stores it in memory.
 func (ae *AlgoEngine) loadFifteenCandles() {
 	now := time.Now().In(ae.ist)
-	from := time.Date(now.Year(), now.Month(), now.Day(), 9, 15, 0, 0, ae.ist)
-	to := time.Date(now.Year(), now.Month(), now.Day(), 9, 30, 0, 0, ae.ist)
+	session := utils.CurrentSession()
+	from := session.At(now, session.Open)
+	to := session.At(now, session.OpeningRangeEnd)
 
 	for _, stock := range ae.trackingManager.GetAllStock() {
-		data, err := ae.kiteClient.GetHistoricOHLC(int64(stock.InstrumentToken), "15minute", from, to)
+		data, err := ae.broker.GetHistorical(stock.InstrumentToken, "15minute", from, to)
 		if err != nil {
 			log.Printf("⚠️ Cannot load fifteen candle for %s: %v", stock.TradingSymbol, err)
 			continue
@@ -430,7 +549,8 @@ func (ae *AlgoEngine) loadFifteenCandles() {
 // API for crash recovery. E.g., server restarts at 12:37 → fetches 12:35-12:37 data.
 func (ae *AlgoEngine) loadCurrentCandles() {
 	now := time.Now().In(ae.ist)
-	marketStart := time.Date(now.Year(), now.Month(), now.Day(), 9, 30, 0, 0, ae.ist)
+	session := utils.CurrentSession()
+	marketStart := session.At(now, session.OpeningRangeEnd)
 
 	elapsed := now.Sub(marketStart)
 	intervalIdx := int(elapsed.Minutes()) / 5
@@ -442,7 +562,7 @@ func (ae *AlgoEngine) loadCurrentCandles() {
 	}
 
 	for _, stock := range ae.trackingManager.GetAllStock() {
-		data, err := ae.kiteClient.GetHistoricOHLC(int64(stock.InstrumentToken), "5minute", intervalStart, now)
+		data, err := ae.broker.GetHistorical(stock.InstrumentToken, "5minute", intervalStart, now)
 		if err != nil {
 			log.Printf("⚠️ Cannot load current candle for %s: %v", stock.TradingSymbol, err)
 			continue
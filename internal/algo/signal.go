@@ -10,6 +10,7 @@ const (
 	SignalTargetHit   SignalType = "TARGET_HIT"   // close position at target (LIMIT)
 	SignalStopLossHit SignalType = "STOPLOSS_HIT" // close position at stoploss (MARKET)
 	SignalForceExit   SignalType = "FORCE_EXIT"   // 15:10 PM forced close (MARKET)
+	SignalManualExit  SignalType = "MANUAL_EXIT"  // close requested by a trader (LIMIT or MARKET)
 	SignalNone        SignalType = "NONE"
 )
 
@@ -26,4 +27,25 @@ type TradeSignal struct {
 	StopLoss        float64
 	Quantity        uint32
 	Timestamp       time.Time
+
+	// SizingNote explains why Quantity was reduced from the risk-based size, if it was.
+	SizingNote string
+
+	// PendingOrderID is the exit order already working at the broker when the
+	// signal escalates it (e.g. a stoploss hit while a target LIMIT is unfilled).
+	PendingOrderID string
+
+	// Manual is set for orders a trader requested through the API. LimitPrice,
+	// when non-zero, is the LIMIT price they asked for; without it a manual
+	// order goes as MARKET.
+	Manual     bool
+	LimitPrice float64
+
+	// Source overrides the order source recorded for a manual signal, e.g.
+	// WEBHOOK for an external alert.
+	Source string
+
+	// CorrelationID ties the signal to the orders placed for it and to every
+	// log line about them.
+	CorrelationID string
 }
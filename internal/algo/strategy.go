@@ -0,0 +1,72 @@
+package algo
+
+import (
+	"fmt"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/tracking"
+)
+
+// The strategy's rules, shared by the live engine and the backtest.
+
+// EntryDirection is the side a completed 5-min candle breaks the opening range
+// to: BUY when it closes above the range's HIGH, SELL below its LOW, and ""
+// while it closes inside the range.
+func EntryDirection(openingRange, completed tracking.Candle) string {
+	switch {
+	case completed.Close > openingRange.High:
+		return "BUY"
+	case completed.Close < openingRange.Low:
+		return "SELL"
+	default:
+		return ""
+	}
+}
+
+// EntryLevels are the target and stoploss points of an entry: the size of the
+// opening range and half of it.
+func EntryLevels(openingRange tracking.Candle) (target, stopLoss float64) {
+	target = openingRange.High - openingRange.Low
+	return target, target * 0.5
+}
+
+// EntryQuantity sizes an entry at price so a stoploss hit loses at most
+// MAX_LOSS_PER_TRADE, reduced to the stock's order price limit or else to
+// MAX_ORDER_VALUE. note says why the risk-based size was reduced, if it was.
+func EntryQuantity(cfg *config.Config, orderPriceLimit, price, stopLoss float64) (quantity uint32, note string) {
+	quantity = uint32(cfg.MaxLossPerTrade / stopLoss)
+	if price != 0 && orderPriceLimit != 0 && float64(quantity)*price > orderPriceLimit {
+		note = fmt.Sprintf("order price limit %.0f: qty %d -> %d", orderPriceLimit, quantity, uint32(orderPriceLimit/price))
+		quantity = uint32(orderPriceLimit / price)
+	} else if float64(quantity)*price > cfg.MaxOrderValue {
+		note = fmt.Sprintf("max order value %.0f: qty %d -> %d", cfg.MaxOrderValue, quantity, uint32(cfg.MaxOrderValue/price))
+		quantity = uint32(cfg.MaxOrderValue / price)
+	}
+	if quantity == 0 {
+		quantity = 1
+	}
+	return quantity, note
+}
+
+// ExitSignal is the exit a price triggers on a position opened at basePrice:
+// TARGET_HIT at target points in its favour, STOPLOSS_HIT at stopLoss points
+// against it, and NONE in between. A zero target is switched off.
+func ExitSignal(direction string, basePrice, target, stopLoss, price float64) SignalType {
+	switch direction {
+	case "BUY":
+		if target > 0 && price >= basePrice+target {
+			return SignalTargetHit
+		}
+		if price <= basePrice-stopLoss {
+			return SignalStopLossHit
+		}
+	case "SELL":
+		if target > 0 && price <= basePrice-target {
+			return SignalTargetHit
+		}
+		if price >= basePrice+stopLoss {
+			return SignalStopLossHit
+		}
+	}
+	return SignalNone
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/notify"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/order"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/utils"
+)
+
+// WatchConfig applies the current session times and pushes reloaded settings
+// to the parts of the runtime that keep their own copy: the market session,
+// the order engine's execution policies and the notifier. Risk limits and the
+// other reloadable settings are read from config.Current where they are used.
+func WatchConfig(runtime *Runtime) {
+	utils.SetSession(SessionFrom(config.Current()))
+
+	config.OnReload(func(cfg *config.Config) {
+		utils.SetSession(SessionFrom(cfg))
+
+		runtime.mu.RLock()
+		orderEngine := runtime.OrderEngine
+		runtime.mu.RUnlock()
+		if orderEngine != nil {
+			orderEngine.SetEntryPolicy(order.LoadEntryExecutionPolicy())
+			orderEngine.SetExitPolicy(order.LoadExitExecutionPolicy())
+		}
+
+		if runtime.Notifier != nil {
+			runtime.Notifier.Reconfigure(NotifierSettings(cfg), cfg.NotifyRateLimit, cfg.NotifyRateWindow)
+		}
+	})
+}
+
+// SessionFrom converts the configured session times to the market clock's
+// timetable.
+func SessionFrom(cfg *config.Config) utils.Session {
+	return utils.Session{
+		Open:            cfg.SessionOpen.Minutes(),
+		OpeningRangeEnd: cfg.OpeningRangeEnd.Minutes(),
+		SignalWindowEnd: cfg.SignalWindowEnd.Minutes(),
+		ForceExitAt:     cfg.ForceExitAt.Minutes(),
+		Close:           cfg.SessionClose.Minutes(),
+		MarketClose:     cfg.MarketClose.Minutes(),
+	}
+}
+
+// NotifierSettings returns the server-wide notification channel settings.
+func NotifierSettings(cfg *config.Config) notify.Settings {
+	return notify.Settings{
+		TelegramAPIURL:   cfg.TelegramAPIURL,
+		TelegramBotToken: cfg.TelegramBotToken,
+		SMTP: notify.SMTPSettings{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		},
+	}
+}
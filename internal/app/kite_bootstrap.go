@@ -7,8 +7,7 @@ import (
 	"time"
 
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/algo"
-	"github.com/SM-Sclass/stock_client2-go_backend/internal/kite"
-	kcws "github.com/SM-Sclass/stock_client2-go_backend/internal/kite/ws"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/order"
@@ -31,21 +30,38 @@ func StartKiteRuntime(runtime *Runtime) error {
 	}
 
 	if !runtime.KiteClient.IsTokenValid() {
-		return fmt.Errorf("kite token is not valid")
+		err := fmt.Errorf("kite token is not valid")
+		NotifyTokenInvalid(runtime, err)
+		return err
+	}
+
+	broadcaster := broker.NewTickBroadcaster()
+	if runtime.Stream != nil {
+		runtime.startTickStream(broadcaster)
 	}
 
-	broadcaster := kite.NewTickBroadcaster()
+	ticker, err := runtime.Broker.NewTicker(broadcaster, func(order broker.Order) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
 
-	kiteWs, err := kcws.NewKiteWS(runtime.KiteClient, broadcaster, runtime.OrderSvc)
+		if err := runtime.OrderSvc.ProcessOrderUpdate(ctx, order); err != nil {
+			log.Println(err)
+		}
+	})
 	if err != nil {
+		runtime.stopTickStream()
 		return err
 	}
 
 	// Start WebSocket connection
-	kiteWs.Start()
+	ticker.Start()
 	log.Println("🔌 WebSocket connection initiated")
 
-	trackingManager := tracking.NewTrackingManager(kiteWs, runtime.KiteClient)
+	trackingManager := tracking.NewTrackingManager(ticker, runtime.Broker)
+	loadStateSnapshots(runtime)
+	if runtime.TrackedStateRepo != nil {
+		trackingManager.StartStatePersistence(runtime.TrackedStateRepo, stateFlushInterval)
+	}
 
 	// Wire TrackingManager as the Manager for OrderService. This breaks the circular dependency by using an interface
 	runtime.OrderSvc.SetManager(trackingManager)
@@ -55,20 +71,25 @@ func StartKiteRuntime(runtime *Runtime) error {
 	algoEngine := algo.NewAlgoEngine(
 		trackingManager,
 		broadcaster,
-		runtime.KiteClient,
+		runtime.Broker,
 		signalChan,
 	)
 
 	orderEngine := order.NewOrderEngine(
-		runtime.KiteClient,
+		runtime.Broker,
 		trackingManager,
 		runtime.OrderSvc,
 		signalChan,
 		algoEngine,
 	)
+	if hub := runtime.Stream; hub != nil {
+		orderEngine.SetSignalListener(func(signal algo.TradeSignal) {
+			streamSignals(hub, signal)
+		})
+	}
 
 	runtime.Broadcaster = broadcaster
-	runtime.KiteWS = kiteWs
+	runtime.Ticker = ticker
 	runtime.TrackingManager = trackingManager
 	runtime.AlgoEngine = algoEngine
 	runtime.OrderEngine = orderEngine
@@ -115,7 +136,7 @@ func SetupScheduler(runtime *Runtime) *scheduler.Scheduler {
 			runtime.TrackingStockRepo,
 			runtime.TrackingManager,
 			func() {
-				runtime.KiteWS.Stop()
+				runtime.Ticker.Stop()
 			},
 			func() bool {
 				return runtime.KiteClient.IsTokenValid()
@@ -127,6 +148,12 @@ func SetupScheduler(runtime *Runtime) *scheduler.Scheduler {
 		),
 	)
 
+	if runtime.Notifier != nil {
+		sched.SetFailureHandler(func(job string, err error) {
+			notifyJobFailed(runtime, job, err)
+		})
+	}
+
 	runtime.Scheduler = sched
 	return sched
 }
@@ -139,7 +166,10 @@ func buildTrackedStock(stock *models.TrackingStock, stats repository.TradeStats,
 	buyQty := uint32(0)
 	sellQty := uint32(0)
 	signalFired := stats.EntryCount > 0
-	maxExecutableOrders := 1
+	maxExecutableOrders := int(stock.AllowedTrades)
+	if maxExecutableOrders < 1 {
+		maxExecutableOrders = 1
+	}
 
 	if stats.TotalBuy > stats.TotalSell {
 		direction = "BUY"
@@ -183,7 +213,7 @@ func SyncOrdersOnStartup(runtime *Runtime) error {
 		return nil
 	}
 
-	orders, err := runtime.KiteClient.GetOrders()
+	orders, err := runtime.Broker.GetOrders()
 	if err != nil {
 		return err
 	}
@@ -201,12 +231,14 @@ func SyncOrdersOnStartup(runtime *Runtime) error {
 	}
 
 	currentYear, currentMonth, currentDay := time.Now().In(istLoc).Date()
+	var todaysOrders []broker.Order
 	for _, order := range orders {
-		orderTimeIST := order.OrderTimestamp.Time.In(istLoc)
+		orderTimeIST := order.OrderTimestamp.In(istLoc)
 		orderYear, orderMonth, orderDay := orderTimeIST.Date()
 		if orderYear != currentYear || orderMonth != currentMonth || orderDay != currentDay {
 			continue
 		}
+		todaysOrders = append(todaysOrders, order)
 
 		dbFormatOrder := &models.Order{
 			OrderID:         order.OrderID,
@@ -221,8 +253,8 @@ func SyncOrdersOnStartup(runtime *Runtime) error {
 			TriggerPrice:    utils.ToNullFloat(order.TriggerPrice),
 			Exchange:        order.Exchange,
 			Product:         utils.ToNullString(order.Product),
-			Status:          order.Status,
-			PlacedAt:        order.OrderTimestamp.Time,
+			Status:          models.StoredOrderStatus(order.Status),
+			PlacedAt:        order.OrderTimestamp,
 		}
 		for _, stock := range stocks {
 			if stock.TradingSymbol == order.TradingSymbol {
@@ -236,6 +268,10 @@ func SyncOrdersOnStartup(runtime *Runtime) error {
 		}
 	}
 
+	fillCtx, fillCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer fillCancel()
+	runtime.OrderSvc.RecordSyncedFills(fillCtx, todaysOrders)
+
 	log.Printf("✅ Synced %d orders from Kite on startup", len(orders))
 	return nil
 }
@@ -262,7 +298,7 @@ func LoadTrackedStocksOnStartup(runtime *Runtime) error {
 	}
 	loadedCount := 0
 
-	allStocksLTP, err := runtime.KiteClient.KiteConnect.GetLTP(allStocksSymbols...)
+	allStocksLTP, err := runtime.Broker.GetLTP(allStocksSymbols...)
 	if err != nil {
 		return err
 	}
@@ -288,13 +324,13 @@ func LoadTrackedStocksOnStartup(runtime *Runtime) error {
 		}
 
 		instStr := fmt.Sprintf("%s:%s", stock.Exchange, stock.TradingSymbol)
-		quote, exists := allStocksLTP[instStr]
+		lastPrice, exists := allStocksLTP[instStr]
 		if !exists {
 			log.Printf("⚠️ No data returned for %s", instStr)
 			continue
 		}
 
-		trackedStock := buildTrackedStock(&stock, stats, quote.LastPrice, uint32(instrument.InstrumentToken))
+		trackedStock := buildTrackedStock(&stock, stats, lastPrice, uint32(instrument.InstrumentToken))
 
 		// Accumulate engine counters before attempting to add
 		totalDailyTrades += stats.EntryCount
@@ -314,6 +350,17 @@ func LoadTrackedStocksOnStartup(runtime *Runtime) error {
 		}
 	}
 
+	// Today's snapshots are more exact than what order history can rebuild:
+	// trailed stoplosses, adjusted levels and pending exits are not in orders.
+	if restoreTrackedState(runtime) > 0 {
+		totalOpenTrades = 0
+		for _, trackedStock := range runtime.TrackingManager.GetAllStock() {
+			if trackedStock.Direction != "" {
+				totalOpenTrades++
+			}
+		}
+	}
+
 	runtime.AlgoEngine.SyncCounters(totalDailyTrades, totalOpenTrades)
 	log.Printf("📈 Loaded %d stocks to tracking manager on startup", loadedCount)
 	return nil
@@ -330,11 +377,11 @@ func RecoverStockState(runtime *Runtime, stock *models.TrackingStock) error {
 
 	// 2. Get LTP
 	instStr := fmt.Sprintf("%s:%s", stock.Exchange, stock.TradingSymbol)
-	allStocksLTP, err := runtime.KiteClient.KiteConnect.GetLTP(instStr)
+	allStocksLTP, err := runtime.Broker.GetLTP(instStr)
 	if err != nil {
 		return fmt.Errorf("failed to get LTP for %s: %w", stock.TradingSymbol, err)
 	}
-	quote, exists := allStocksLTP[instStr]
+	lastPrice, exists := allStocksLTP[instStr]
 	if !exists {
 		return fmt.Errorf("no LTP data returned for %s", stock.TradingSymbol)
 	}
@@ -353,7 +400,7 @@ func RecoverStockState(runtime *Runtime, stock *models.TrackingStock) error {
 	}
 
 	// 5. Build and register TrackedStock
-	trackedStock := buildTrackedStock(stock, stats, quote.LastPrice, uint32(instrument.InstrumentToken))
+	trackedStock := buildTrackedStock(stock, stats, lastPrice, uint32(instrument.InstrumentToken))
 
 	if !runtime.TrackingManager.AddTrackingStock(trackedStock) {
 		return fmt.Errorf("failed to add %s to tracking manager", stock.TradingSymbol)
@@ -374,6 +421,27 @@ func RecoverStockState(runtime *Runtime, stock *models.TrackingStock) error {
 	return nil
 }
 
+// RecoverPendingExitOrdersOnStartup re-arms the supervision of the pending
+// exits restored from today's snapshots that are still open at the broker.
+func RecoverPendingExitOrdersOnStartup(runtime *Runtime) {
+	if !runtime.KiteReady {
+		return
+	}
+
+	recovered := 0
+	for _, stock := range runtime.TrackingManager.GetAllStock() {
+		if stock.PendingExitOrderID == "" {
+			continue
+		}
+		runtime.OrderEngine.RecoverPendingExitOrder(stock.ID, stock.PendingExitOrderID)
+		recovered++
+	}
+
+	if recovered > 0 {
+		log.Printf("♻️ Checked %d restored pending exits for supervision", recovered)
+	}
+}
+
 func RecoverPendingEntryOrdersOnStartup(runtime *Runtime) error {
 	orders, err := runtime.OrderSvc.GetRecoverableEntryOrders()
 	if err != nil {
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository/memory"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/services"
+)
+
+func TestBuildTrackedStockFromTodaysOrders(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	orderSvc := &services.OrderService{OrderRepo: db.Orders(), TrackingStockRepo: db.TrackingStocks()}
+
+	stocks := []*models.TrackingStock{
+		{TradingSymbol: "INFY", Exchange: "NSE", Target: 10, StopLoss: 5, Quantity: 10, AllowedTrades: 2, Status: "ACTIVE"},
+		{TradingSymbol: "TCS", Exchange: "NSE", Target: 20, StopLoss: 8, Quantity: 5, Status: "ACTIVE"},
+	}
+	for _, s := range stocks {
+		id, err := db.TrackingStocks().AddTrackingStock(ctx, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.ID = id
+	}
+
+	// INFY went long this morning; TCS has not traded.
+	buy, fillPrice := "BUY", 1510.5
+	if _, err := db.Orders().AddOrder(ctx, &models.Order{
+		TrackingStockID: stocks[0].ID, OrderID: "entry-1", OrderType: "LIMIT", EventType: "ENTRY_BUY",
+		TransactionType: &buy, Quantity: 10, BasePrice: 1510, PurchasePrice: &fillPrice, Status: "COMPLETE", PlacedAt: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	statsByStock, err := orderSvc.AllStocksTradeStats([]int64{stocks[0].ID, stocks[1].ID})
+	if err != nil {
+		t.Fatalf("AllStocksTradeStats() error = %v", err)
+	}
+
+	infy, _ := db.TrackingStocks().GetTrackingStockByID(ctx, stocks[0].ID)
+	long := buildTrackedStock(infy, statsByStock[infy.ID], 1520, 408065)
+	if long.Direction != "BUY" || long.BuyQuantity != 10 || long.BasePrice != fillPrice || !long.SignalFired {
+		t.Errorf("INFY = direction %q buy %d base %.2f fired %v, want BUY 10 %.2f true",
+			long.Direction, long.BuyQuantity, long.BasePrice, long.SignalFired, fillPrice)
+	}
+	if long.MaxExecutableOrders != 2 {
+		t.Errorf("INFY MaxExecutableOrders = %d, want its 2 allowed trades", long.MaxExecutableOrders)
+	}
+
+	tcs, _ := db.TrackingStocks().GetTrackingStockByID(ctx, stocks[1].ID)
+	flat := buildTrackedStock(tcs, statsByStock[tcs.ID], 3400, 2953217)
+	if flat.Direction != "" || flat.BasePrice != 3400 || flat.SignalFired {
+		t.Errorf("TCS = direction %q base %.2f fired %v, want flat at the LTP", flat.Direction, flat.BasePrice, flat.SignalFired)
+	}
+	if flat.MaxExecutableOrders != 1 {
+		t.Errorf("TCS MaxExecutableOrders = %d, want the default 1", flat.MaxExecutableOrders)
+	}
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/metrics"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RegisterMetrics exports the runtime's open positions and the database pool
+// on /metrics. Engine, feed and broker metrics are recorded where they happen.
+func RegisterMetrics(runtime *Runtime, pool *pgxpool.Pool) {
+	metrics.RegisterDBPool(pool)
+	metrics.RegisterPositionGauges(runtime.openPositions)
+}
+
+// openPositions counts tracked stocks holding a position and sums their
+// mark-to-market P&L.
+func (r *Runtime) openPositions() (open int, unrealized float64) {
+	r.mu.RLock()
+	tm := r.TrackingManager
+	r.mu.RUnlock()
+	if tm == nil {
+		return 0, 0
+	}
+
+	for _, ts := range tm.GetAllStock() {
+		s := snapshotOf(ts)
+		if s.Quantity > 0 {
+			open++
+			unrealized += s.UnrealizedPnL
+		}
+	}
+	return open, unrealized
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/algo"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/notify"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/services"
+)
+
+// StartNotifications starts the runtime's notification dispatcher and has
+// order transitions notify it. Scheduler job failures are wired up in
+// SetupScheduler.
+func StartNotifications(runtime *Runtime) {
+	if runtime.Notifier == nil {
+		return
+	}
+	runtime.Notifier.Start()
+
+	if runtime.OrderSvc != nil {
+		runtime.OrderSvc.AddOrderListener(func(t services.OrderTransition) {
+			if msg, ok := orderNotification(t); ok {
+				runtime.Notifier.Notify(msg)
+			}
+		})
+	}
+}
+
+// NotifyTokenInvalid tells users the Kite session needs a fresh login.
+func NotifyTokenInvalid(runtime *Runtime, err error) {
+	runtime.Notifier.Notify(notify.Message{
+		Event:    notify.EventTokenInvalid,
+		Severity: notify.SeverityCritical,
+		Title:    "Kite login required",
+		Text:     fmt.Sprintf("The Kite access token is not valid (%v). Log in again before the market opens.", err),
+	})
+}
+
+func notifyJobFailed(runtime *Runtime, job string, err error) {
+	runtime.Notifier.Notify(notify.Message{
+		Event:    notify.EventJobFailed,
+		Severity: notify.SeverityCritical,
+		Title:    "Scheduled job " + job + " failed",
+		Text:     err.Error(),
+	})
+}
+
+// orderNotification turns an order transition into a notification: filled
+// entries and exits, and rejections. Other transitions are not notified.
+func orderNotification(t services.OrderTransition) (notify.Message, bool) {
+	switch t.Status {
+	case broker.OrderStatusRejected:
+		return notify.Message{
+			Event:    notify.EventOrderRejected,
+			Severity: notify.SeverityCritical,
+			Title:    fmt.Sprintf("%s order rejected: %s", t.TransactionType, t.TradingSymbol),
+			Text:     fmt.Sprintf("Order %s (%s, qty %.0f) was rejected: %s", t.OrderID, t.EventType, t.Quantity, t.StatusMessage),
+			Time:     t.At,
+		}, true
+	case broker.OrderStatusComplete:
+	default:
+		return notify.Message{}, false
+	}
+
+	msg := notify.Message{
+		Severity: notify.SeverityInfo,
+		Text:     fmt.Sprintf("%s %.0f %s @ %.2f (order %s)", t.TransactionType, t.FilledQuantity, t.TradingSymbol, t.AveragePrice, t.OrderID),
+		Time:     t.At,
+	}
+	switch t.EventType {
+	case string(algo.SignalEntryBuy), string(algo.SignalEntrySell):
+		msg.Event = notify.EventEntry
+		msg.Title = "Entry filled: " + t.TradingSymbol
+	case string(algo.SignalTargetHit):
+		msg.Event = notify.EventTargetHit
+		msg.Title = "Target hit: " + t.TradingSymbol
+	case string(algo.SignalStopLossHit):
+		msg.Event = notify.EventStopLossHit
+		msg.Severity = notify.SeverityWarning
+		msg.Title = "Stoploss hit: " + t.TradingSymbol
+	default:
+		msg.Event = notify.EventExit
+		msg.Title = "Position exited: " + t.TradingSymbol
+	}
+	return msg, true
+}
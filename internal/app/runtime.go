@@ -4,12 +4,15 @@ import (
 	"sync"
 
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/algo"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/kite"
-	kcws "github.com/SM-Sclass/stock_client2-go_backend/internal/kite/ws"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/notify"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/order"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/scheduler"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/services"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/stream"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/tracking"
 )
 
@@ -17,8 +20,9 @@ type Runtime struct {
 	mu sync.RWMutex
 
 	KiteClient      *kite.KiteClient
-	Broadcaster     *kite.TickBroadcaster
-	KiteWS          *kcws.KiteWS
+	Broker          broker.Broker
+	Broadcaster     *broker.TickBroadcaster
+	Ticker          broker.Ticker
 	TrackingManager *tracking.TrackingManager
 
 	//services
@@ -33,8 +37,22 @@ type Runtime struct {
 	// Scheduler
 	Scheduler *scheduler.Scheduler
 
+	// Stream fans live events out to dashboard clients
+	Stream *stream.Hub
+
+	// Notifier sends trade and system events over users' notification routes
+	Notifier *notify.Dispatcher
+
 	// Repositories (needed for cron jobs)
-	TrackingStockRepo *repository.TrackingStocksRepository
+	TrackingStockRepo repository.TrackingStockStore
+	TrackedStateRepo  repository.TrackedStockStateStore
+
+	// Tracking state snapshots read at startup, and the report of restoring them
+	savedStates map[int64]models.TrackedStockState
+	stateReport *StateReport
+
+	// tickStreamStop ends the goroutine streaming ticks to the dashboard
+	tickStreamStop chan struct{}
 
 	KiteReady bool
 }
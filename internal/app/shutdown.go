@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"log"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/order"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/tracking"
+)
+
+// Shutdown tears the runtime down without leaving an order half placed:
+// entries are closed, open positions are flattened or reported, the engines
+// finish the signals already queued and their in-flight broker calls, tracking
+// state is written, and the websocket and scheduler are stopped. Waiting on the
+// engines gives up when ctx is done; the later steps still run.
+func Shutdown(ctx context.Context, runtime *Runtime, flatten bool) {
+	runtime.mu.RLock()
+	algoEngine := runtime.AlgoEngine
+	orderEngine := runtime.OrderEngine
+	tm := runtime.TrackingManager
+	ticker := runtime.Ticker
+	sched := runtime.Scheduler
+	runtime.mu.RUnlock()
+
+	// 1. Stop accepting new entries, from the strategy and from traders.
+	if orderEngine != nil {
+		orderEngine.CloseEntries()
+	}
+
+	// 2. Close or report open positions while the engines can still place exits.
+	if tm != nil {
+		closeOpenPositions(tm, orderEngine, flatten)
+	}
+
+	// 3. Drain queued signals and in-flight order calls.
+	if algoEngine != nil {
+		algoEngine.Stop()
+	}
+	if orderEngine != nil {
+		if err := orderEngine.Shutdown(ctx); err != nil {
+			log.Printf("⚠️ Order engine did not drain before the shutdown deadline: %v", err)
+		}
+	}
+
+	// 4. Persist tracking state.
+	if tm != nil {
+		tm.StopStatePersistence()
+	}
+
+	// 5. Close the websocket and the dashboard tick stream.
+	if ticker != nil {
+		ticker.Stop()
+	}
+	runtime.mu.Lock()
+	runtime.stopTickStream()
+	runtime.mu.Unlock()
+
+	// 6. Stop the scheduler.
+	if sched != nil {
+		sched.Stop()
+	}
+}
+
+// closeOpenPositions queues a MARKET exit for every open position when flatten
+// is set, and otherwise logs each one so it can be handled at the broker.
+func closeOpenPositions(tm *tracking.TrackingManager, orderEngine *order.OrderEngine, flatten bool) {
+	open := 0
+	for _, ts := range tm.GetAllStock() {
+		s := snapshotOf(ts)
+		if s.Quantity == 0 {
+			continue
+		}
+		open++
+
+		if !flatten || orderEngine == nil {
+			log.Printf("⚠️ Shutting down with an open %s position on %s: qty=%d base=%.2f ltp=%.2f",
+				s.Direction, s.TradingSymbol, s.Quantity, s.BasePrice, s.LTP)
+			continue
+		}
+		err := orderEngine.ManualExit(ts.ID, order.ManualExitRequest{Source: models.OrderSourceShutdown})
+		if err != nil {
+			log.Printf("❌ Failed to flatten %s on shutdown: %v", s.TradingSymbol, err)
+			continue
+		}
+		log.Printf("🧹 Flattening %s on shutdown: %s qty=%d at MARKET", s.TradingSymbol, s.Direction, s.Quantity)
+	}
+
+	if open > 0 && !flatten {
+		log.Printf("⚠️ %d open position(s) left at the broker; set SHUTDOWN_FLATTEN=true to close them on shutdown", open)
+	}
+}
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/tracking"
+)
+
+// stateFlushInterval is how often changed tracking state is written to the DB.
+const stateFlushInterval = time.Second
+
+// StateDiff is one disagreement between restored tracking state and the broker.
+type StateDiff struct {
+	TrackingStockID int64  `json:"tracking_stock_id"`
+	TradingSymbol   string `json:"trading_symbol"`
+	Field           string `json:"field"` // "position", "lock" or "pending_exit"
+	Restored        string `json:"restored"`
+	Broker          string `json:"broker"`
+	Action          string `json:"action,omitempty"` // what recovery did about it, if anything
+}
+
+// StateReport is the outcome of restoring tracking state on startup. When the
+// state is reconciled again on demand, ReconciledAt and Diffs are updated.
+type StateReport struct {
+	RestoredAt   time.Time   `json:"restored_at"`
+	Restored     int         `json:"restored"`
+	ReconciledAt *time.Time  `json:"reconciled_at,omitempty"`
+	Diffs        []StateDiff `json:"diffs"`
+}
+
+// loadStateSnapshots reads today's persisted tracking state. It must run
+// before persistence starts, since the first flush overwrites the snapshots
+// with the freshly rebuilt state.
+func loadStateSnapshots(runtime *Runtime) {
+	runtime.savedStates = nil
+	if runtime.TrackedStateRepo == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	states, err := runtime.TrackedStateRepo.GetStatesForDate(ctx, tracking.TradingDate(time.Now()))
+	if err != nil {
+		log.Printf("⚠️ Failed to load tracking state snapshots: %v", err)
+		return
+	}
+
+	runtime.savedStates = make(map[int64]models.TrackedStockState, len(states))
+	for _, state := range states {
+		runtime.savedStates[state.TrackingStockID] = state
+	}
+	log.Printf("💾 Loaded %d tracking state snapshots for today", len(states))
+}
+
+// restoreTrackedState applies today's snapshots to the stocks just rebuilt
+// from order history, so the in-memory state is exactly what it was before the
+// restart, and then checks it against the broker. Snapshots are used once.
+func restoreTrackedState(runtime *Runtime) int {
+	saved := runtime.savedStates
+	runtime.savedStates = nil
+
+	restored := 0
+	for _, stock := range runtime.TrackingManager.GetAllStock() {
+		state, exists := saved[stock.ID]
+		if !exists || state.InstrumentToken != stock.InstrumentToken {
+			continue
+		}
+		if runtime.TrackingManager.RestoreState(stock.InstrumentToken, state) {
+			restored++
+			log.Printf("💾 Restored %s: direction=%s, buyQty=%d, sellQty=%d, base=%.2f, target=%.2f, sl=%.2f, trail=%.2f",
+				stock.TradingSymbol, state.Direction, state.BuyQuantity, state.SellQuantity,
+				state.BasePrice, state.Target, state.StopLoss, state.TrailingStopLoss)
+		}
+	}
+
+	report := StateReport{RestoredAt: time.Now(), Restored: restored, Diffs: []StateDiff{}}
+	if restored > 0 {
+		diffs, err := reconcileTrackedState(runtime)
+		if err != nil {
+			log.Printf("⚠️ Failed to compare restored state with broker: %v", err)
+		} else {
+			report.Diffs = diffs
+		}
+	}
+	runtime.setStateReport(report)
+	return restored
+}
+
+// ErrKiteNotReady is returned by operations that need the Kite session.
+var ErrKiteNotReady = errors.New("kite is not ready")
+
+// ReconcileState compares the live tracking state with the broker on demand,
+// as is done after the startup restore, and makes the outcome the state report.
+func ReconcileState(runtime *Runtime) (*StateReport, error) {
+	if !runtime.KiteReady {
+		return nil, ErrKiteNotReady
+	}
+	diffs, err := reconcileTrackedState(runtime)
+	if err != nil {
+		return nil, err
+	}
+	report := StateReport{}
+	if last := runtime.StateReport(); last != nil {
+		report = *last
+	}
+	now := time.Now()
+	report.ReconciledAt, report.Diffs = &now, diffs
+	runtime.setStateReport(report)
+	return &report, nil
+}
+
+// reconcileTrackedState compares the restored state with the broker. Position
+// mismatches are only reported, since they need a human to decide; locks and
+// pending exits whose broker orders are no longer open are released, because
+// nothing would ever release them otherwise.
+func reconcileTrackedState(runtime *Runtime) ([]StateDiff, error) {
+	positions, err := runtime.Broker.GetPositions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch positions: %w", err)
+	}
+	orders, err := runtime.Broker.GetOrders()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch orders: %w", err)
+	}
+
+	netQty := make(map[string]int)
+	for _, p := range positions {
+		if p.Product == broker.ProductMIS {
+			netQty[p.Exchange+":"+p.TradingSymbol] += p.Quantity
+		}
+	}
+	openOrders := make(map[string]bool)
+	openOrderIDs := make(map[string]bool)
+	for _, o := range orders {
+		if !o.IsTerminal() {
+			openOrders[o.Exchange+":"+o.TradingSymbol] = true
+			openOrderIDs[o.OrderID] = true
+		}
+	}
+
+	diffs := []StateDiff{}
+	for _, stock := range runtime.TrackingManager.GetAllStock() {
+		key := stock.Exchange + ":" + stock.TradingSymbol
+
+		managed := int(stock.BuyQuantity) - int(stock.SellQuantity)
+		if managed != netQty[key] {
+			diffs = append(diffs, StateDiff{
+				TrackingStockID: stock.ID,
+				TradingSymbol:   stock.TradingSymbol,
+				Field:           "position",
+				Restored:        fmt.Sprintf("%d", managed),
+				Broker:          fmt.Sprintf("%d", netQty[key]),
+			})
+		}
+
+		if stock.PendingExitOrderID != "" && !openOrderIDs[stock.PendingExitOrderID] {
+			runtime.TrackingManager.ClearPendingExit(stock.InstrumentToken)
+			diffs = append(diffs, StateDiff{
+				TrackingStockID: stock.ID,
+				TradingSymbol:   stock.TradingSymbol,
+				Field:           "pending_exit",
+				Restored:        stock.PendingExitOrderID,
+				Broker:          "not open",
+				Action:          "cleared",
+			})
+		}
+
+		if stock.Locked && !openOrders[key] {
+			runtime.TrackingManager.UnlockStock(stock.InstrumentToken)
+			diffs = append(diffs, StateDiff{
+				TrackingStockID: stock.ID,
+				TradingSymbol:   stock.TradingSymbol,
+				Field:           "lock",
+				Restored:        "locked",
+				Broker:          "no open order",
+				Action:          "unlocked",
+			})
+		}
+	}
+
+	for _, diff := range diffs {
+		log.Printf("⚠️ State diff for %s: %s restored=%s broker=%s %s",
+			diff.TradingSymbol, diff.Field, diff.Restored, diff.Broker, diff.Action)
+	}
+	if len(diffs) == 0 {
+		log.Println("✅ Restored tracking state matches the broker")
+	}
+	return diffs, nil
+}
+
+func (r *Runtime) setStateReport(report StateReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stateReport = &report
+}
+
+// StateReport returns the report of the last startup state restore, or nil if
+// no restore has run yet.
+func (r *Runtime) StateReport() *StateReport {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.stateReport
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/algo"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/scheduler"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/utils"
+)
+
+// FeedStatus is the health of the live market data connection.
+type FeedStatus struct {
+	Connected          bool       `json:"connected"`
+	LastTickAt         *time.Time `json:"last_tick_at"`
+	LastTickAgeSeconds *float64   `json:"last_tick_age_seconds"`
+	SubscribedTokens   int        `json:"subscribed_tokens"`
+}
+
+// RuntimeStatus is a point-in-time view of the engines, the feed, the risk
+// counters and the scheduler. Parts that need Kite are nil until it is ready.
+type RuntimeStatus struct {
+	KiteReady     bool                  `json:"kite_ready"`
+	MarketPhase   string                `json:"market_phase"`
+	AlgoRunning   bool                  `json:"algo_running"`
+	OrderRunning  bool                  `json:"order_running"`
+	TrackedStocks int                   `json:"tracked_stocks"`
+	Feed          *FeedStatus           `json:"feed"`
+	Risk          *algo.RiskState       `json:"risk"`
+	Jobs          []scheduler.JobStatus `json:"jobs"`
+}
+
+// Status reports the runtime's current state.
+func (r *Runtime) Status() RuntimeStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	status := RuntimeStatus{
+		KiteReady:   r.KiteReady,
+		MarketPhase: utils.GetMarketPhase(now).String(),
+		Jobs:        []scheduler.JobStatus{},
+	}
+	if r.AlgoEngine != nil {
+		status.AlgoRunning = r.AlgoEngine.IsRunning()
+		risk := r.AlgoEngine.RiskState()
+		status.Risk = &risk
+	}
+	if r.OrderEngine != nil {
+		status.OrderRunning = r.OrderEngine.IsRunning()
+	}
+	if r.TrackingManager != nil {
+		status.TrackedStocks = len(r.TrackingManager.GetAllStock())
+	}
+	if r.Ticker != nil {
+		feed := &FeedStatus{
+			Connected:        r.Ticker.IsConnected(),
+			SubscribedTokens: r.Ticker.SubscribedCount(),
+		}
+		if last := r.Ticker.LastTickAt(); !last.IsZero() {
+			age := now.Sub(last).Seconds()
+			feed.LastTickAt, feed.LastTickAgeSeconds = &last, &age
+		}
+		status.Feed = feed
+	}
+	if r.Scheduler != nil {
+		status.Jobs = r.Scheduler.Status()
+	}
+	return status
+}
@@ -0,0 +1,258 @@
+package app
+
+import (
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/algo"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/services"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/stream"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/tracking"
+)
+
+const (
+	snapshotInterval = time.Second
+	tickThrottle     = 500 * time.Millisecond
+)
+
+// StockSnapshot is the live state of a tracked stock as held by the TrackingManager.
+type StockSnapshot struct {
+	TrackingStockID    int64           `json:"tracking_stock_id"`
+	TradingSymbol      string          `json:"trading_symbol"`
+	InstrumentToken    uint32          `json:"instrument_token"`
+	Exchange           string          `json:"exchange"`
+	LTP                float64         `json:"ltp"`
+	FifteenCandle      tracking.Candle `json:"fifteen_candle"`
+	CurrentCandle      tracking.Candle `json:"current_candle"`
+	PreviousCandle     tracking.Candle `json:"previous_candle"`
+	Direction          string          `json:"direction"`
+	Quantity           uint32          `json:"quantity"`
+	BasePrice          float64         `json:"base_price"`
+	Target             float64         `json:"target"`
+	StopLoss           float64         `json:"stop_loss"`
+	TrailingStopLoss   float64         `json:"trailing_stop_loss"`
+	Locked             bool            `json:"locked"`
+	SignalFired        bool            `json:"signal_fired"`
+	TradingAllowed     bool            `json:"trading_allowed"`
+	PendingExitOrderID string          `json:"pending_exit_order_id,omitempty"`
+	UnrealizedPnL      float64         `json:"unrealized_pnl"`
+}
+
+// TickUpdate is the latest price of an instrument within a throttle window.
+type TickUpdate struct {
+	InstrumentToken uint32    `json:"instrument_token"`
+	LastPrice       float64   `json:"last_price"`
+	Volume          uint32    `json:"volume"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// SignalEvent is a trade signal taken up by the OrderEngine.
+type SignalEvent struct {
+	TrackingStockID int64     `json:"tracking_stock_id"`
+	TradingSymbol   string    `json:"trading_symbol"`
+	SignalType      string    `json:"signal_type"`
+	Direction       string    `json:"direction"`
+	TriggerPrice    float64   `json:"trigger_price"`
+	Target          float64   `json:"target"`
+	StopLoss        float64   `json:"stop_loss"`
+	Quantity        uint32    `json:"quantity"`
+	Manual          bool      `json:"manual"`
+	SizingNote      string    `json:"sizing_note,omitempty"`
+	CorrelationID   string    `json:"correlation_id"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// EngineState is the running state of the Kite runtime and the engines.
+type EngineState struct {
+	KiteReady       bool `json:"kite_ready"`
+	TickerConnected bool `json:"ticker_connected"`
+	AlgoRunning     bool `json:"algo_running"`
+	OrderRunning    bool `json:"order_running"`
+	TrackedStocks   int  `json:"tracked_stocks"`
+}
+
+func snapshotOf(ts tracking.TrackedStock) StockSnapshot {
+	s := StockSnapshot{
+		TrackingStockID:    ts.ID,
+		TradingSymbol:      ts.TradingSymbol,
+		InstrumentToken:    ts.InstrumentToken,
+		Exchange:           ts.Exchange,
+		LTP:                ts.LastLTP,
+		FifteenCandle:      ts.FifteenCandle,
+		CurrentCandle:      ts.Candles.Current,
+		PreviousCandle:     ts.Candles.Previous,
+		Direction:          ts.Direction,
+		BasePrice:          ts.BasePrice,
+		Target:             ts.Target,
+		StopLoss:           ts.StopLoss,
+		TrailingStopLoss:   ts.TrailingStopLoss,
+		Locked:             ts.Locked,
+		SignalFired:        ts.SignalFired,
+		TradingAllowed:     ts.TradingAllowed,
+		PendingExitOrderID: ts.PendingExitOrderID,
+	}
+	switch ts.Direction {
+	case broker.TransactionTypeBuy:
+		s.Quantity = ts.BuyQuantity
+		s.UnrealizedPnL = (ts.LastLTP - ts.BasePrice) * float64(ts.BuyQuantity)
+	case broker.TransactionTypeSell:
+		s.Quantity = ts.SellQuantity
+		s.UnrealizedPnL = (ts.BasePrice - ts.LastLTP) * float64(ts.SellQuantity)
+	}
+	if ts.LastLTP == 0 || ts.BasePrice == 0 {
+		s.UnrealizedPnL = 0
+	}
+	return s
+}
+
+// StartStreamPublishers publishes tracked-stock snapshots and engine state
+// changes to the runtime's stream hub until stop is closed. Ticks, signals and
+// order transitions are pushed by the components that produce them.
+func StartStreamPublishers(runtime *Runtime, stop <-chan struct{}) {
+	hub := runtime.Stream
+	if hub == nil {
+		return
+	}
+
+	hub.PublishRetained(stream.TopicEngine, runtime.engineState())
+	if runtime.OrderSvc != nil {
+		runtime.OrderSvc.AddOrderListener(func(t services.OrderTransition) {
+			hub.Publish(stream.TopicOrders, t)
+		})
+	}
+
+	go func() {
+		ticker := time.NewTicker(snapshotInterval)
+		defer ticker.Stop()
+
+		last := runtime.engineState()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			if state := runtime.engineState(); state != last {
+				last = state
+				hub.PublishRetained(stream.TopicEngine, state)
+			}
+
+			if !hub.HasSubscribers(stream.TopicSnapshots) {
+				continue
+			}
+			runtime.mu.RLock()
+			tm := runtime.TrackingManager
+			runtime.mu.RUnlock()
+			if tm == nil {
+				continue
+			}
+			stocks := tm.GetAllStock()
+			snapshots := make([]StockSnapshot, 0, len(stocks))
+			for _, ts := range stocks {
+				snapshots = append(snapshots, snapshotOf(ts))
+			}
+			hub.Publish(stream.TopicSnapshots, snapshots)
+		}
+	}()
+}
+
+func (r *Runtime) engineState() EngineState {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state := EngineState{KiteReady: r.KiteReady}
+	if r.Ticker != nil {
+		state.TickerConnected = r.Ticker.IsConnected()
+	}
+	if r.AlgoEngine != nil {
+		state.AlgoRunning = r.AlgoEngine.IsRunning()
+	}
+	if r.OrderEngine != nil {
+		state.OrderRunning = r.OrderEngine.IsRunning()
+	}
+	if r.TrackingManager != nil {
+		state.TrackedStocks = r.TrackingManager.CountStocks()
+	}
+	return state
+}
+
+// streamSignals publishes every signal the OrderEngine takes up.
+func streamSignals(hub *stream.Hub, signal algo.TradeSignal) {
+	hub.Publish(stream.TopicSignals, SignalEvent{
+		TrackingStockID: signal.TrackingStockID,
+		TradingSymbol:   signal.TradingSymbol,
+		SignalType:      string(signal.SignalType),
+		Direction:       signal.Direction,
+		TriggerPrice:    signal.TriggerPrice,
+		Target:          signal.Target,
+		StopLoss:        signal.StopLoss,
+		Quantity:        signal.Quantity,
+		Manual:          signal.Manual,
+		SizingNote:      signal.SizingNote,
+		CorrelationID:   signal.CorrelationID,
+		Timestamp:       signal.Timestamp,
+	})
+}
+
+// startTickStream streams the broadcaster's ticks to the dashboard until the
+// runtime is torn down or started again. runtime.mu must be held.
+func (r *Runtime) startTickStream(broadcaster *broker.TickBroadcaster) {
+	r.stopTickStream()
+	stop := make(chan struct{})
+	r.tickStreamStop = stop
+
+	ticks := broadcaster.Subscribe(100)
+	go func() {
+		defer broadcaster.Unsubscribe(ticks)
+		streamTicks(r.Stream, ticks, stop)
+	}()
+}
+
+// stopTickStream ends the tick stream, if one is running. runtime.mu must be
+// held.
+func (r *Runtime) stopTickStream() {
+	if r.tickStreamStop != nil {
+		close(r.tickStreamStop)
+		r.tickStreamStop = nil
+	}
+}
+
+// streamTicks publishes the latest tick of each instrument at most once per
+// tickThrottle, batched, for as long as the broadcaster feeds ticks.
+func streamTicks(hub *stream.Hub, ticks <-chan []broker.Tick, stop <-chan struct{}) {
+	latest := make(map[uint32]TickUpdate)
+	flush := time.NewTicker(tickThrottle)
+	defer flush.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case batch, ok := <-ticks:
+			if !ok {
+				return
+			}
+			for _, t := range batch {
+				latest[t.InstrumentToken] = TickUpdate{
+					InstrumentToken: t.InstrumentToken,
+					LastPrice:       t.LastPrice,
+					Volume:          t.Volume,
+					Timestamp:       t.Timestamp,
+				}
+			}
+		case <-flush.C:
+			if len(latest) == 0 {
+				continue
+			}
+			if hub.HasSubscribers(stream.TopicTicks) {
+				updates := make([]TickUpdate, 0, len(latest))
+				for _, u := range latest {
+					updates = append(updates, u)
+				}
+				hub.Publish(stream.TopicTicks, updates)
+			}
+			clear(latest)
+		}
+	}
+}
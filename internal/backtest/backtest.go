@@ -0,0 +1,272 @@
+// Package backtest replays the opening-range breakout strategy over a
+// tracking stock's historical 5-min candles.
+//
+// Only candles are replayed, so within a candle the stoploss is assumed to be
+// hit before the target when the candle reaches both. Entries fill at the
+// close of the candle that signalled them, target exits at the target level,
+// stoploss exits at the stoploss level (or the open of a candle that gapped
+// through it) and force exits at the close of the candle ending at 15:10.
+// Each stock is replayed on its own, without the engine's limit of one open
+// trade across all stocks.
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/algo"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/charges"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/tracking"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/utils"
+)
+
+// MaxDays is the longest range one backtest covers; Kite serves at most 100
+// days of 5-min candles per request.
+const MaxDays = 100
+
+const candleInterval = 5 * time.Minute
+
+// ErrRange is returned for an empty range or one longer than MaxDays.
+var ErrRange = fmt.Errorf("the range must cover 1 to %d days", MaxDays)
+
+// Trade is one simulated round trip.
+type Trade struct {
+	Date       string    `json:"date"`
+	Direction  string    `json:"direction"`
+	Quantity   int       `json:"quantity"`
+	EntryPrice float64   `json:"entry_price"`
+	ExitPrice  float64   `json:"exit_price"`
+	Target     float64   `json:"target"`
+	StopLoss   float64   `json:"stoploss"`
+	ExitReason string    `json:"exit_reason"` // TARGET_HIT, STOPLOSS_HIT or FORCE_EXIT
+	GrossPnL   float64   `json:"gross_pnl"`
+	Charges    float64   `json:"charges"`
+	NetPnL     float64   `json:"net_pnl"`
+	OpenedAt   time.Time `json:"opened_at"`
+	ClosedAt   time.Time `json:"closed_at"`
+}
+
+// Report is the outcome of a backtest.
+type Report struct {
+	TrackingStockID int64   `json:"tracking_stock_id"`
+	TradingSymbol   string  `json:"trading_symbol"`
+	From            string  `json:"from"`
+	To              string  `json:"to"`
+	Days            int     `json:"days"` // days with candles
+	Trades          []Trade `json:"trades"`
+	Wins            int     `json:"wins"`
+	Losses          int     `json:"losses"`
+	GrossPnL        float64 `json:"gross_pnl"`
+	Charges         float64 `json:"charges"`
+	NetPnL          float64 `json:"net_pnl"`
+}
+
+// Runner runs backtests with candles from Broker, sized by the current
+// risk settings and charged at the rates of Charges.
+type Runner struct {
+	Broker  broker.Broker
+	Charges *charges.Calculator
+}
+
+// Run backtests stock on the trading days from from to to, both IST dates and
+// to inclusive.
+func (r *Runner) Run(stock models.TrackingStock, from, to time.Time) (*Report, error) {
+	days := int(to.Sub(from).Hours()/24) + 1
+	if days < 1 || days > MaxDays {
+		return nil, ErrRange
+	}
+
+	session := utils.CurrentSession()
+	candles, err := r.Broker.GetHistorical(uint32(stock.InstrumentToken), "5minute",
+		session.At(from, session.Open), session.At(to, session.MarketClose))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch candles for %s: %w", stock.TradingSymbol, err)
+	}
+
+	report := &Report{
+		TrackingStockID: stock.ID,
+		TradingSymbol:   stock.TradingSymbol,
+		From:            from.Format(time.DateOnly),
+		To:              to.Format(time.DateOnly),
+		Trades:          []Trade{},
+	}
+	ist := from.Location()
+	for _, day := range groupByDay(candles, ist) {
+		report.Days++
+		for _, trade := range r.replayDay(stock, session, day) {
+			report.Trades = append(report.Trades, trade)
+			if trade.NetPnL > 0 {
+				report.Wins++
+			} else {
+				report.Losses++
+			}
+			report.GrossPnL += trade.GrossPnL
+			report.Charges += trade.Charges
+			report.NetPnL += trade.NetPnL
+		}
+	}
+	report.GrossPnL = round2(report.GrossPnL)
+	report.Charges = round2(report.Charges)
+	report.NetPnL = round2(report.NetPnL)
+	return report, nil
+}
+
+// position is the simulated open position of a day.
+type position struct {
+	trade    Trade
+	openedAt time.Time // the boundary the entry was taken at
+}
+
+// replayDay walks one day's candles the way the AlgoEngine walks its 5-min
+// boundaries: exits are checked against every candle after the entry, and at
+// the end of each candle an entry is checked in the signal and monitor phases
+// or the position is force-exited in the exit phase.
+func (r *Runner) replayDay(stock models.TrackingStock, session utils.Session, day []broker.Candle) []Trade {
+	date := day[0].Time
+	openingRange, ok := rangeOf(day, session.At(date, session.Open), session.At(date, session.OpeningRangeEnd))
+	if !ok {
+		return nil
+	}
+	target, stopLoss := algo.EntryLevels(openingRange)
+	cfg := config.Current()
+	entriesLeft := min(int(stock.AllowedTrades), cfg.MaxDailyTrades)
+
+	var trades []Trade
+	var open *position
+	for _, c := range day {
+		end := c.Time.Add(candleInterval)
+		if end.After(session.At(date, session.Close)) {
+			break
+		}
+
+		if open != nil && !c.Time.Before(open.openedAt) {
+			if price, reason, hit := exitWithin(open.trade, c); hit {
+				trades = append(trades, r.close(stock, open.trade, price, reason, end))
+				open = nil
+			}
+		}
+
+		switch utils.GetMarketPhase(end) {
+		case utils.PhaseSignal, utils.PhaseMonitor:
+			if open != nil || entriesLeft == 0 || stopLoss <= 0 {
+				continue
+			}
+			direction := algo.EntryDirection(openingRange, tracking.Candle{Open: c.Open, High: c.High, Low: c.Low, Close: c.Close})
+			if direction == "" {
+				continue
+			}
+			quantity, _ := algo.EntryQuantity(cfg, stock.OrderPriceLimit, c.Close, stopLoss)
+			entriesLeft--
+			open = &position{
+				openedAt: end,
+				trade: Trade{
+					Date:       date.Format(time.DateOnly),
+					Direction:  direction,
+					Quantity:   int(quantity),
+					EntryPrice: c.Close,
+					Target:     target,
+					StopLoss:   stopLoss,
+					OpenedAt:   end,
+				},
+			}
+
+		case utils.PhaseExit:
+			if open != nil {
+				trades = append(trades, r.close(stock, open.trade, c.Close, string(algo.SignalForceExit), end))
+				open = nil
+			}
+		}
+	}
+	// A day whose candles stop before 15:10 closes at its last candle.
+	if open != nil {
+		last := day[len(day)-1]
+		trades = append(trades, r.close(stock, open.trade, last.Close, string(algo.SignalForceExit), last.Time.Add(candleInterval)))
+	}
+	return trades
+}
+
+// exitWithin returns the price and reason an open trade exits at within c,
+// taking the stoploss first when c reaches both levels.
+func exitWithin(t Trade, c broker.Candle) (float64, string, bool) {
+	adverse, favourable := c.Low, c.High
+	slLevel, targetLevel := t.EntryPrice-t.StopLoss, t.EntryPrice+t.Target
+	if t.Direction == "SELL" {
+		adverse, favourable = c.High, c.Low
+		slLevel, targetLevel = t.EntryPrice+t.StopLoss, t.EntryPrice-t.Target
+	}
+
+	if algo.ExitSignal(t.Direction, t.EntryPrice, t.Target, t.StopLoss, adverse) == algo.SignalStopLossHit {
+		// A stoploss MARKET order fills at the open of a candle that gapped through it.
+		if algo.ExitSignal(t.Direction, t.EntryPrice, t.Target, t.StopLoss, c.Open) == algo.SignalStopLossHit {
+			return c.Open, string(algo.SignalStopLossHit), true
+		}
+		return slLevel, string(algo.SignalStopLossHit), true
+	}
+	if algo.ExitSignal(t.Direction, t.EntryPrice, t.Target, t.StopLoss, favourable) == algo.SignalTargetHit {
+		return targetLevel, string(algo.SignalTargetHit), true
+	}
+	return 0, "", false
+}
+
+// close fills the exit of t and works out its P&L.
+func (r *Runner) close(stock models.TrackingStock, t Trade, price float64, reason string, at time.Time) Trade {
+	t.ExitPrice = price
+	t.ExitReason = reason
+	t.ClosedAt = at
+
+	points := price - t.EntryPrice
+	if t.Direction == "SELL" {
+		points = -points
+	}
+	t.GrossPnL = round2(points * float64(t.Quantity))
+	if r.Charges != nil {
+		t.Charges = r.Charges.RoundTrip(stock.Exchange, broker.ProductMIS, t.Direction, t.Quantity, t.EntryPrice, price).Total
+	}
+	t.NetPnL = round2(t.GrossPnL - t.Charges)
+	return t
+}
+
+// rangeOf merges the candles starting in [from, to) into the opening range.
+func rangeOf(day []broker.Candle, from, to time.Time) (tracking.Candle, bool) {
+	var r tracking.Candle
+	for _, c := range day {
+		if c.Time.Before(from) || !c.Time.Before(to) {
+			continue
+		}
+		if !r.IsValid() {
+			r = tracking.Candle{Open: c.Open, High: c.High, Low: c.Low}
+		}
+		r.High = math.Max(r.High, c.High)
+		r.Low = math.Min(r.Low, c.Low)
+		r.Close = c.Close
+	}
+	return r, r.IsValid()
+}
+
+// groupByDay splits candles into trading days in loc, each in time order.
+func groupByDay(candles []broker.Candle, loc *time.Location) [][]broker.Candle {
+	sorted := append([]broker.Candle(nil), candles...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	var days [][]broker.Candle
+	var lastDate string
+	for _, c := range sorted {
+		c.Time = c.Time.In(loc)
+		date := c.Time.Format(time.DateOnly)
+		if date != lastDate {
+			days = append(days, nil)
+			lastDate = date
+		}
+		days[len(days)-1] = append(days[len(days)-1], c)
+	}
+	return days
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
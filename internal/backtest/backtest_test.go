@@ -0,0 +1,98 @@
+package backtest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/charges"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+)
+
+type fakeBroker struct {
+	broker.Broker
+	candles []broker.Candle
+}
+
+func (b *fakeBroker) GetHistorical(_ uint32, _ string, from, to time.Time) ([]broker.Candle, error) {
+	var out []broker.Candle
+	for _, c := range b.candles {
+		if !c.Time.Before(from) && !c.Time.After(to) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func TestRunReplaysTheStrategy(t *testing.T) {
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	monday := time.Date(2026, 3, 2, 9, 15, 0, 0, ist)
+	tuesday := monday.AddDate(0, 0, 1)
+	at := func(day time.Time, minutes int) time.Time { return day.Add(time.Duration(minutes) * time.Minute) }
+
+	candles := []broker.Candle{
+		// Monday: range 99-103, a BUY that hits its target and a re-entry at that
+		// candle's close that is stopped out.
+		{Time: at(monday, 0), Open: 100, High: 102, Low: 99, Close: 101},
+		{Time: at(monday, 5), Open: 101, High: 103, Low: 100, Close: 102},
+		{Time: at(monday, 10), Open: 102, High: 103, Low: 100, Close: 101},
+		{Time: at(monday, 15), Open: 101, High: 104, Low: 101, Close: 103.5},
+		{Time: at(monday, 20), Open: 103.5, High: 107.6, Low: 103, Close: 107},
+		{Time: at(monday, 25), Open: 107, High: 108, Low: 106.5, Close: 107.8},
+		{Time: at(monday, 30), Open: 107.8, High: 108, Low: 105, Close: 105.5},
+		{Time: at(monday, 35), Open: 105.5, High: 106, Low: 90, Close: 91},
+		// Tuesday: range 198-202, a SELL that drifts until the 15:10 force exit.
+		{Time: at(tuesday, 0), Open: 200, High: 202, Low: 198, Close: 200},
+		{Time: at(tuesday, 15), Open: 199, High: 199, Low: 196.5, Close: 197},
+	}
+	for m := 20; m < 350; m += 5 {
+		candles = append(candles, broker.Candle{Time: at(tuesday, m), Open: 197, High: 197.5, Low: 196.5, Close: 196.8})
+	}
+	candles = append(candles,
+		broker.Candle{Time: at(tuesday, 350), Open: 196.8, High: 197, Low: 195.9, Close: 196}, // 15:05
+		broker.Candle{Time: at(tuesday, 355), Open: 196, High: 210, Low: 196, Close: 209},     // after the force exit
+	)
+
+	runner := &Runner{Broker: &fakeBroker{candles: candles}, Charges: charges.Default()}
+	stock := models.TrackingStock{ID: 7, TradingSymbol: "INFY", Exchange: "NSE", InstrumentToken: 408065,
+		OrderPriceLimit: 10350, AllowedTrades: 2}
+	report, err := runner.Run(stock, time.Date(2026, 3, 2, 0, 0, 0, 0, ist), time.Date(2026, 3, 3, 0, 0, 0, 0, ist))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		direction   string
+		quantity    int
+		entry, exit float64
+		reason      string
+		gross       float64
+	}{
+		{"BUY", 100, 103.5, 107.5, "TARGET_HIT", 400},
+		{"BUY", 96, 107, 105, "STOPLOSS_HIT", -192},
+		{"SELL", 52, 197, 196, "FORCE_EXIT", 52},
+	}
+	if report.Days != 2 || len(report.Trades) != len(want) {
+		t.Fatalf("report = %+v, want %d trades over 2 days", report, len(want))
+	}
+	var net float64
+	for i, w := range want {
+		got := report.Trades[i]
+		if got.Direction != w.direction || got.Quantity != w.quantity || got.EntryPrice != w.entry ||
+			got.ExitPrice != w.exit || got.ExitReason != w.reason || got.GrossPnL != w.gross {
+			t.Errorf("trade %d = %+v, want %+v", i, got, w)
+		}
+		if got.Charges <= 0 || got.NetPnL != round2(got.GrossPnL-got.Charges) {
+			t.Errorf("trade %d charges %.2f net %.2f, want charges taken off gross", i, got.Charges, got.NetPnL)
+		}
+		net += got.NetPnL
+	}
+	if report.GrossPnL != 260 || report.NetPnL != round2(net) || report.Wins+report.Losses != 3 {
+		t.Errorf("totals gross=%.2f net=%.2f wins=%d losses=%d", report.GrossPnL, report.NetPnL, report.Wins, report.Losses)
+	}
+
+	if _, err := runner.Run(stock, time.Date(2026, 3, 3, 0, 0, 0, 0, ist), time.Date(2026, 3, 2, 0, 0, 0, 0, ist)); !errors.Is(err, ErrRange) {
+		t.Errorf("reversed range err = %v, want ErrRange", err)
+	}
+}
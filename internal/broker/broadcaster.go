@@ -0,0 +1,51 @@
+package broker
+
+import (
+	"sync"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/metrics"
+)
+
+type TickBroadcaster struct {
+	mu          sync.RWMutex
+	subscribers []chan []Tick
+}
+
+func NewTickBroadcaster() *TickBroadcaster {
+	return &TickBroadcaster{
+		subscribers: make([]chan []Tick, 0),
+	}
+}
+
+func (b *TickBroadcaster) Subscribe(buffer int) chan []Tick {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan []Tick, buffer)
+	b.subscribers = append(b.subscribers, ch)
+	return ch
+}
+
+// Unsubscribe stops sending ticks to a channel returned by Subscribe.
+func (b *TickBroadcaster) Unsubscribe(ch chan []Tick) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, sub := range b.subscribers {
+		if sub == ch {
+			b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (b *TickBroadcaster) Broadcast(ticks []Tick) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.subscribers {
+		select {
+		case sub <- ticks:
+		default:
+			// drop if slow
+			metrics.BroadcastDrops.Inc()
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package broker
+
+import "time"
+
+// Broker is everything the engines need from a trading account. Kite is one
+// implementation; another broker or a local simulator can be plugged in
+// without touching the algo, order or tracking packages.
+type Broker interface {
+	PlaceOrder(params OrderParams) (orderID string, err error)
+	ModifyOrder(orderID string, params OrderParams) error
+	CancelOrder(orderID string) error
+	GetOrders() ([]Order, error)
+	GetOrderHistory(orderID string) ([]Order, error)
+	GetOrderFills(orderID string) ([]Fill, error)
+
+	GetPositions() ([]Position, error)
+	GetMargins() (Margins, error)
+	GetOrderMargin(params OrderParams) (OrderMargin, error)
+
+	// Instruments are keyed as "EXCHANGE:SYMBOL".
+	GetQuote(instruments ...string) (map[string]Quote, error)
+	GetLTP(instruments ...string) (map[string]float64, error)
+	GetHistorical(instrumentToken uint32, interval string, from, to time.Time) ([]Candle, error)
+	GetInstruments(exchange string) ([]Instrument, error)
+
+	// NewTicker creates the live feed. Ticks go to the broadcaster and order
+	// postbacks to onOrder.
+	NewTicker(bus *TickBroadcaster, onOrder func(Order)) (Ticker, error)
+}
+
+// Ticker is a live market data and order update connection.
+type Ticker interface {
+	Start()
+	Stop()
+	SubscribeToken(token uint32)
+	UnsubscribeToken(token uint32)
+	IsConnected() bool
+
+	// LastTickAt is when the last tick arrived, zero before the first one.
+	LastTickAt() time.Time
+	// SubscribedCount is the number of instrument tokens subscribed.
+	SubscribedCount() int
+}
@@ -0,0 +1,172 @@
+package broker
+
+import "time"
+
+// Order sides, types and products used by the engines. The values match the
+// strings most Indian brokers (and Kite) use on the wire.
+const (
+	TransactionTypeBuy  = "BUY"
+	TransactionTypeSell = "SELL"
+
+	OrderTypeMarket = "MARKET"
+	OrderTypeLimit  = "LIMIT"
+	OrderTypeSL     = "SL"
+	OrderTypeSLM    = "SL-M"
+
+	ProductMIS = "MIS"
+	ProductCNC = "CNC"
+
+	ValidityDay = "DAY"
+)
+
+// Order statuses reported by the broker.
+const (
+	OrderStatusOpen            = "OPEN"
+	OrderStatusComplete        = "COMPLETE"
+	OrderStatusCancelled       = "CANCELLED"
+	OrderStatusRejected        = "REJECTED"
+	OrderStatusPartiallyFilled = "PARTIALLY_FILLED"
+)
+
+// Tick is a single market data update for an instrument.
+type Tick struct {
+	InstrumentToken uint32
+	LastPrice       float64
+	LastQuantity    uint32
+	Volume          uint32
+	BestBid         float64
+	BestAsk         float64
+	Timestamp       time.Time
+}
+
+// OrderParams describes an order to place or the fields to change on modify.
+// Zero values are left out.
+type OrderParams struct {
+	Exchange         string
+	TradingSymbol    string
+	TransactionType  string
+	Quantity         int
+	Product          string
+	OrderType        string
+	Price            float64
+	TriggerPrice     float64
+	Validity         string
+	MarketProtection float64
+	Tag              string
+}
+
+// Order is the broker's view of an order, either from the order book, the
+// order history or a live postback.
+type Order struct {
+	OrderID         string
+	ExchangeOrderID string
+	ParentOrderID   string
+	Status          string
+	StatusMessage   string
+	Exchange        string
+	TradingSymbol   string
+	InstrumentToken uint32
+	OrderType       string
+	TransactionType string
+	Product         string
+	Quantity        float64
+	Price           float64
+	TriggerPrice    float64
+	AveragePrice    float64
+	FilledQuantity  float64
+	PendingQuantity float64
+	Tag             string
+	OrderTimestamp  time.Time
+	// ExchangeTimestamp is when the exchange last updated the order: for a
+	// filled order, when it filled. Zero until the order reaches the exchange.
+	ExchangeTimestamp time.Time
+}
+
+// IsTerminal reports whether the order can no longer change.
+func (o Order) IsTerminal() bool {
+	return o.Status == OrderStatusComplete || o.Status == OrderStatusCancelled || o.Status == OrderStatusRejected
+}
+
+// Fill is a single execution against an order.
+type Fill struct {
+	TradeID         string
+	OrderID         string
+	Exchange        string
+	TradingSymbol   string
+	InstrumentToken uint32
+	TransactionType string
+	Product         string
+	Quantity        float64
+	Price           float64
+	FilledAt        time.Time
+}
+
+// Instrument is a tradable contract. Field names follow the instrument dump so
+// cached JSON stays readable across adapters.
+type Instrument struct {
+	InstrumentToken int
+	ExchangeToken   int
+	Tradingsymbol   string
+	Name            string
+	LastPrice       float64
+	Expiry          time.Time
+	StrikePrice     float64
+	TickSize        float64
+	LotSize         float64
+	InstrumentType  string
+	Segment         string
+	Exchange        string
+}
+
+// Position is an open (or closed today) net position.
+type Position struct {
+	TradingSymbol   string
+	Exchange        string
+	InstrumentToken uint32
+	Product         string
+	Quantity        int // signed: positive long, negative short
+	AveragePrice    float64
+	LastPrice       float64
+	PnL             float64
+	BuyQuantity     int
+	BuyPrice        float64
+	SellQuantity    int
+	SellPrice       float64
+}
+
+// Margins is the account's equity margin.
+type Margins struct {
+	Net        float64
+	Cash       float64
+	Collateral float64
+	Utilised   float64
+}
+
+// OrderMargin is the margin an order would block.
+type OrderMargin struct {
+	Total    float64
+	Leverage float64
+}
+
+// Quote is a market snapshot with top of book.
+type Quote struct {
+	InstrumentToken uint32
+	LastPrice       float64
+	BestBid         float64
+	BestAsk         float64
+	Open            float64
+	High            float64
+	Low             float64
+	Close           float64
+	Volume          int
+}
+
+// Candle is one historical OHLC bar.
+type Candle struct {
+	Time   time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume int
+}
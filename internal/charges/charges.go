@@ -0,0 +1,197 @@
+// Package charges computes brokerage and statutory charges on equity orders.
+package charges
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+)
+
+// RateTable holds the charge rates for one exchange and product. Percentages
+// apply to the order turnover (quantity × price).
+type RateTable struct {
+	Exchange     string  `json:"exchange"`
+	Product      string  `json:"product"`
+	BrokeragePct float64 `json:"brokerage_pct"`
+	BrokerageMax float64 `json:"brokerage_max"` // cap per executed order, zero for none
+	STTBuyPct    float64 `json:"stt_buy_pct"`
+	STTSellPct   float64 `json:"stt_sell_pct"`
+	ExchangePct  float64 `json:"exchange_pct"`
+	SEBIPerCrore float64 `json:"sebi_per_crore"`
+	StampBuyPct  float64 `json:"stamp_buy_pct"`
+	GSTPct       float64 `json:"gst_pct"` // on brokerage, exchange charges and SEBI fees
+}
+
+// DefaultRateTables are Zerodha's equity rates for intraday (MIS) and
+// delivery (CNC) on NSE and BSE.
+func DefaultRateTables() []RateTable {
+	return []RateTable{
+		{Exchange: "NSE", Product: broker.ProductMIS, BrokeragePct: 0.03, BrokerageMax: 20, STTSellPct: 0.025, ExchangePct: 0.00297, SEBIPerCrore: 10, StampBuyPct: 0.003, GSTPct: 18},
+		{Exchange: "BSE", Product: broker.ProductMIS, BrokeragePct: 0.03, BrokerageMax: 20, STTSellPct: 0.025, ExchangePct: 0.00375, SEBIPerCrore: 10, StampBuyPct: 0.003, GSTPct: 18},
+		{Exchange: "NSE", Product: broker.ProductCNC, STTBuyPct: 0.1, STTSellPct: 0.1, ExchangePct: 0.00297, SEBIPerCrore: 10, StampBuyPct: 0.015, GSTPct: 18},
+		{Exchange: "BSE", Product: broker.ProductCNC, STTBuyPct: 0.1, STTSellPct: 0.1, ExchangePct: 0.00375, SEBIPerCrore: 10, StampBuyPct: 0.015, GSTPct: 18},
+	}
+}
+
+// Charges is the cost breakdown of one or more orders, in rupees.
+type Charges struct {
+	Brokerage       float64 `json:"brokerage"`
+	STT             float64 `json:"stt"`
+	ExchangeCharges float64 `json:"exchange_charges"`
+	SEBIFees        float64 `json:"sebi_fees"`
+	StampDuty       float64 `json:"stamp_duty"`
+	GST             float64 `json:"gst"`
+	Total           float64 `json:"total"`
+}
+
+// Add sums two breakdowns.
+func (c Charges) Add(o Charges) Charges {
+	return Charges{
+		Brokerage:       c.Brokerage + o.Brokerage,
+		STT:             c.STT + o.STT,
+		ExchangeCharges: c.ExchangeCharges + o.ExchangeCharges,
+		SEBIFees:        c.SEBIFees + o.SEBIFees,
+		StampDuty:       c.StampDuty + o.StampDuty,
+		GST:             c.GST + o.GST,
+		Total:           c.Total + o.Total,
+	}
+}
+
+// Calculator applies rate tables to orders and round trips.
+type Calculator struct {
+	tables map[string]RateTable
+}
+
+func NewCalculator(tables []RateTable) *Calculator {
+	c := &Calculator{tables: make(map[string]RateTable, len(tables))}
+	for _, t := range tables {
+		c.tables[t.Exchange+":"+t.Product] = t
+	}
+	return c
+}
+
+// Tables returns the configured rate tables.
+func (c *Calculator) Tables() []RateTable {
+	tables := make([]RateTable, 0, len(c.tables))
+	for _, t := range c.tables {
+		tables = append(tables, t)
+	}
+	return tables
+}
+
+// table finds the rates for an exchange and product, falling back to the NSE
+// rates of the product for other exchanges.
+func (c *Calculator) table(exchange, product string) (RateTable, bool) {
+	if t, ok := c.tables[exchange+":"+product]; ok {
+		return t, true
+	}
+	t, ok := c.tables["NSE:"+product]
+	return t, ok
+}
+
+// Order returns the charges on one executed order. side is the broker
+// transaction type. Unknown products cost nothing.
+func (c *Calculator) Order(exchange, product, side string, quantity int, price float64) Charges {
+	t, ok := c.table(exchange, product)
+	if !ok || quantity <= 0 || price <= 0 {
+		return Charges{}
+	}
+
+	turnover := float64(quantity) * price
+	ch := Charges{
+		Brokerage:       turnover * t.BrokeragePct / 100,
+		ExchangeCharges: turnover * t.ExchangePct / 100,
+		SEBIFees:        turnover * t.SEBIPerCrore / 1e7,
+	}
+	if t.BrokerageMax > 0 && ch.Brokerage > t.BrokerageMax {
+		ch.Brokerage = t.BrokerageMax
+	}
+	if side == broker.TransactionTypeBuy {
+		ch.STT = turnover * t.STTBuyPct / 100
+		ch.StampDuty = turnover * t.StampBuyPct / 100
+	} else {
+		ch.STT = turnover * t.STTSellPct / 100
+	}
+	ch.GST = (ch.Brokerage + ch.ExchangeCharges + ch.SEBIFees) * t.GSTPct / 100
+
+	ch.Brokerage = round2(ch.Brokerage)
+	ch.STT = round2(ch.STT)
+	ch.ExchangeCharges = round2(ch.ExchangeCharges)
+	ch.SEBIFees = round2(ch.SEBIFees)
+	ch.StampDuty = round2(ch.StampDuty)
+	ch.GST = round2(ch.GST)
+	ch.Total = round2(ch.Brokerage + ch.STT + ch.ExchangeCharges + ch.SEBIFees + ch.StampDuty + ch.GST)
+	return ch
+}
+
+// RoundTrip returns the charges on entering and exiting a position. direction
+// is the entry side: BUY for a long, SELL for a short.
+func (c *Calculator) RoundTrip(exchange, product, direction string, quantity int, entryPrice, exitPrice float64) Charges {
+	exitSide := broker.TransactionTypeSell
+	if direction == broker.TransactionTypeSell {
+		exitSide = broker.TransactionTypeBuy
+	}
+	return c.Order(exchange, product, direction, quantity, entryPrice).
+		Add(c.Order(exchange, product, exitSide, quantity, exitPrice))
+}
+
+// BreakEvenPoints is how far per share price must move for a round trip of
+// quantity at price to cover its charges.
+func (c *Calculator) BreakEvenPoints(exchange, product, direction string, quantity int, price float64) float64 {
+	if quantity <= 0 {
+		return 0
+	}
+	return c.RoundTrip(exchange, product, direction, quantity, price, price).Total / float64(quantity)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+var (
+	defaultMu   sync.RWMutex
+	defaultCalc = NewCalculator(DefaultRateTables())
+)
+
+// Default returns the calculator configured for the process.
+func Default() *Calculator {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultCalc
+}
+
+// LoadRateTables replaces the default rates with the tables in a JSON file.
+// Tables for an exchange and product not in the file keep their default rates.
+func LoadRateTables(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read charge rates: %w", err)
+	}
+	var overrides []RateTable
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return fmt.Errorf("failed to parse charge rates %s: %w", path, err)
+	}
+
+	tables := DefaultRateTables()
+	for _, o := range overrides {
+		replaced := false
+		for i, t := range tables {
+			if t.Exchange == o.Exchange && t.Product == o.Product {
+				tables[i] = o
+				replaced = true
+			}
+		}
+		if !replaced {
+			tables = append(tables, o)
+		}
+	}
+
+	defaultMu.Lock()
+	defaultCalc = NewCalculator(tables)
+	defaultMu.Unlock()
+	return nil
+}
@@ -0,0 +1,36 @@
+package charges
+
+import (
+	"math"
+	"testing"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+)
+
+func TestIntradayRoundTrip(t *testing.T) {
+	calc := NewCalculator(DefaultRateTables())
+
+	// 100 shares at 1000: 0.03% brokerage is 30, capped at 20 per order.
+	buy := calc.Order("NSE", broker.ProductMIS, broker.TransactionTypeBuy, 100, 1000)
+	want := Charges{Brokerage: 20, ExchangeCharges: 2.97, SEBIFees: 0.1, StampDuty: 3, GST: 4.15, Total: 30.22}
+	if buy != want {
+		t.Fatalf("buy charges = %+v, want %+v", buy, want)
+	}
+
+	sell := calc.Order("NSE", broker.ProductMIS, broker.TransactionTypeSell, 100, 1000)
+	if sell.STT != 25 || sell.StampDuty != 0 || sell.Total != 52.22 {
+		t.Fatalf("sell charges = %+v, want STT 25, no stamp duty, total 52.22", sell)
+	}
+
+	roundTrip := calc.RoundTrip("NSE", broker.ProductMIS, broker.TransactionTypeBuy, 100, 1000, 1000)
+	if math.Abs(roundTrip.Total-82.44) > 1e-9 {
+		t.Fatalf("round trip total = %.2f, want 82.44", roundTrip.Total)
+	}
+	if got := calc.BreakEvenPoints("NSE", broker.ProductMIS, broker.TransactionTypeBuy, 100, 1000); math.Abs(got-0.8244) > 1e-9 {
+		t.Fatalf("break-even points = %.4f, want 0.8244", got)
+	}
+
+	if got := calc.Order("NSE", "NRML", broker.TransactionTypeBuy, 100, 1000); got.Total != 0 {
+		t.Fatalf("unknown product charges = %+v, want none", got)
+	}
+}
@@ -1,45 +1,295 @@
+// Package config loads the server configuration in layers: built-in
+// defaults, then the optional config file, then the environment (including
+// .env), then overrides saved in the database. Each setting is declared once
+// on Config with its environment key and default. Settings tagged reload can
+// be changed while the server runs; the rest are read at startup only.
 package config
 
 import (
-	"github.com/joho/godotenv"
+	"errors"
+	"fmt"
 	"log"
-	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Config struct {
-	JWTSecret     string
-	DatabaseURL   string
-	FrontendURL   string
-	FrontendURL2  string
-	Port          string
-	ApiKey        string
-	ApiSecret     string
-	CallbackURL   string
-	TokenFilePath string
-	CookieDomain  string
-	GoEnv         string
+	JWTSecret     string `env:"JWT_SECRET" secret:"true"`
+	DatabaseURL   string `env:"DATABASE_URL" secret:"true"`
+	FrontendURL   string `env:"FRONTEND_URL"`
+	FrontendURL2  string `env:"FRONTEND_URL2"`
+	Port          string `env:"PORT" default:"8080"`
+	ApiKey        string `env:"KITE_API_KEY"`
+	ApiSecret     string `env:"KITE_API_SECRET" secret:"true"`
+	CallbackURL   string `env:"KITE_CALLBACK_URL"`
+	TokenFilePath string `env:"KITE_TOKEN_FILE" default:"./go_stock-tracker/.token.json"`
+	CookieDomain  string `env:"COOKIE_DOMAIN"`
+	GoEnv         string `env:"GO_ENV"`
+
+	// Kite Connect API, login page and ticker; empty uses Zerodha's. Tests
+	// point these at a fake Kite server
+	KiteAPIURL    string `env:"KITE_API_URL"`
+	KiteLoginURL  string `env:"KITE_LOGIN_URL"`
+	KiteTickerURL string `env:"KITE_TICKER_URL"`
+
+	// Users allowed to use the admin API, as comma-separated user IDs. Read at
+	// startup only, so the admin API cannot change who may use it; empty
+	// allows no one
+	AdminUserIDs string `env:"ADMIN_USER_IDS"`
+
+	// Apply pending schema migrations when the server starts
+	DBAutoMigrate bool `env:"DB_AUTO_MIGRATE" default:"true"`
+
+	// Entry execution policy (LIMIT chasing before fallback)
+	EntryInitialWait    time.Duration `env:"ENTRY_INITIAL_WAIT" default:"10s" reload:"true"`
+	EntryChaseInterval  time.Duration `env:"ENTRY_CHASE_INTERVAL" default:"3s" reload:"true"`
+	EntryChaseMaxSteps  int           `env:"ENTRY_CHASE_MAX_STEPS" default:"3" reload:"true"`
+	EntryMaxSlippagePct float64       `env:"ENTRY_MAX_SLIPPAGE_PCT" default:"0.15" reload:"true"`
+	EntryFallback       string        `env:"ENTRY_FALLBACK" default:"MARKET" reload:"true"`
+
+	// Exit supervision for target LIMIT orders
+	ExitLimitTimeout  time.Duration `env:"EXIT_LIMIT_TIMEOUT" default:"15s" reload:"true"`
+	ExitCheckInterval time.Duration `env:"EXIT_CHECK_INTERVAL" default:"2s" reload:"true"`
+	ExitRetracePct    float64       `env:"EXIT_RETRACE_PCT" default:"0.1" reload:"true"`
+
+	// Margin-aware position sizing
+	MarginReservePct         float64 `env:"MARGIN_RESERVE_PCT" default:"10" reload:"true"`
+	MarginReservePerPosition float64 `env:"MARGIN_RESERVE_PER_POSITION" default:"5000" reload:"true"`
+
+	// Daily risk limits of the strategy
+	MaxDailyTrades  int     `env:"MAX_DAILY_TRADES" default:"2" reload:"true"`
+	MaxLossPerTrade float64 `env:"MAX_LOSS_PER_TRADE" default:"4500" reload:"true"`
+	MaxOrderValue   float64 `env:"MAX_ORDER_VALUE" default:"200000" reload:"true"`
+
+	// Trading session in IST: the opening range, the signal window, the
+	// force-exit window and the end of market hours
+	SessionOpen     Clock `env:"SESSION_OPEN" default:"09:15" reload:"true"`
+	OpeningRangeEnd Clock `env:"OPENING_RANGE_END" default:"09:30" reload:"true"`
+	SignalWindowEnd Clock `env:"SIGNAL_WINDOW_END" default:"09:35" reload:"true"`
+	ForceExitAt     Clock `env:"FORCE_EXIT_AT" default:"15:10" reload:"true"`
+	SessionClose    Clock `env:"SESSION_CLOSE" default:"15:15" reload:"true"`
+	MarketClose     Clock `env:"MARKET_CLOSE" default:"15:25" reload:"true"`
+
+	// Brokerage and statutory charges
+	ChargesRatesFile      string `env:"CHARGES_RATES_FILE"`
+	TargetIncludesCharges bool   `env:"TARGET_INCLUDES_CHARGES" reload:"true"`
+
+	// Notification channels and per-route rate limit
+	TelegramBotToken string        `env:"TELEGRAM_BOT_TOKEN" secret:"true" reload:"true"`
+	TelegramAPIURL   string        `env:"TELEGRAM_API_URL" default:"https://api.telegram.org" reload:"true"`
+	SMTPHost         string        `env:"SMTP_HOST" reload:"true"`
+	SMTPPort         int           `env:"SMTP_PORT" default:"587" reload:"true"`
+	SMTPUsername     string        `env:"SMTP_USERNAME" reload:"true"`
+	SMTPPassword     string        `env:"SMTP_PASSWORD" secret:"true" reload:"true"`
+	SMTPFrom         string        `env:"SMTP_FROM" reload:"true"`
+	NotifyRateLimit  int           `env:"NOTIFY_RATE_LIMIT" default:"10" reload:"true"`
+	NotifyRateWindow time.Duration `env:"NOTIFY_RATE_WINDOW" default:"1m" reload:"true"`
+
+	// How far a signal webhook timestamp may be from now before it is a replay
+	SignalWebhookTolerance time.Duration `env:"SIGNAL_WEBHOOK_TOLERANCE" default:"5m" reload:"true"`
+
+	// Structured logging: json or text, the default level, and per-component
+	// overrides such as "order=debug,algo=warn"
+	LogFormat string `env:"LOG_FORMAT" default:"json"`
+	LogLevel  string `env:"LOG_LEVEL" default:"info" reload:"true"`
+	LogLevels string `env:"LOG_LEVELS" reload:"true"`
+
+	// Graceful shutdown: how long teardown may take, and whether open
+	// positions are closed at MARKET instead of only warned about
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"30s" reload:"true"`
+	ShutdownFlatten bool          `env:"SHUTDOWN_FLATTEN" reload:"true"`
+
+	// Layer each setting came from, by key
+	sources map[string]string
 }
 
+// ServerConfig is the configuration the server started with. Structural
+// settings are read from it; reloadable ones should be read through Current.
 var ServerConfig *Config
 
+var (
+	current   atomic.Pointer[Config]
+	applyMu   sync.Mutex
+	listeners []func(*Config)
+)
+
+// MustLoad builds the configuration from defaults, the config file and the
+// environment, and exits listing every invalid setting if it does not validate.
 func MustLoad() {
-	err := godotenv.Load(".env")
+	cfg, err := Build(nil)
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%s", formatErrors(err))
+	}
+	ServerConfig = cfg
+	current.Store(cfg)
+}
+
+// Current returns the configuration in effect, including reloaded settings.
+// Before MustLoad it returns the defaults.
+func Current() *Config {
+	if cfg := current.Load(); cfg != nil {
+		return cfg
+	}
+	cfg := &Config{sources: map[string]string{}}
+	for _, f := range fields {
+		if f.def != "" {
+			_ = f.set(cfg, f.def)
+		}
+	}
+	return cfg
+}
+
+// OnReload registers fn to be called with the new configuration whenever
+// Apply changes a setting.
+func OnReload(fn func(*Config)) {
+	applyMu.Lock()
+	defer applyMu.Unlock()
+	listeners = append(listeners, fn)
+}
+
+// Apply makes the reloadable settings of next current and returns the keys
+// that changed. Structural settings that differ are left alone and logged,
+// as they only take effect on restart.
+func Apply(next *Config) []string {
+	applyMu.Lock()
+	defer applyMu.Unlock()
+
+	prev := Current()
+	merged := prev.clone()
+	changed := []string{}
+	for _, f := range fields {
+		same := f.get(prev) == f.get(next)
+		if !f.reload {
+			if !same {
+				log.Printf("⚠️ %s changed, restart the server to apply it", f.key)
+			}
+			continue
+		}
+		merged.sources[f.key] = next.sources[f.key]
+		if !same {
+			f.copy(merged, next)
+			changed = append(changed, f.key)
+		}
+	}
+	current.Store(merged)
+	if len(changed) == 0 {
+		return changed
+	}
+
+	log.Printf("⚙️ Configuration reloaded: %s", strings.Join(changed, ", "))
+	for _, fn := range listeners {
+		fn(merged)
+	}
+	return changed
+}
+
+// Reload rebuilds the configuration with overrides on top and applies it.
+func Reload(overrides map[string]string) ([]string, error) {
+	next, err := Build(overrides)
 	if err != nil {
-		log.Fatal("No .env file found, relying on environment variables")
+		return nil, err
+	}
+	return Apply(next), nil
+}
+
+// Setting is one configuration value as shown to an admin.
+type Setting struct {
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+	Source     string `json:"source"`
+	Reloadable bool   `json:"reloadable"`
+	Secret     bool   `json:"secret"`
+}
+
+// Settings lists every setting with secrets redacted.
+func (c *Config) Settings() []Setting {
+	settings := make([]Setting, 0, len(fields))
+	for _, f := range fields {
+		settings = append(settings, Setting{
+			Key:        f.key,
+			Value:      f.redacted(c),
+			Source:     c.sources[f.key],
+			Reloadable: f.reload,
+			Secret:     f.secret,
+		})
+	}
+	return settings
+}
+
+// String prints every setting as KEY=value with secrets redacted.
+func (c *Config) String() string {
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		parts = append(parts, f.key+"="+f.redacted(c))
 	}
+	return strings.Join(parts, " ")
+}
 
-	ServerConfig = &Config{
-		JWTSecret:     os.Getenv("JWT_SECRET"),
-		DatabaseURL:   os.Getenv("DATABASE_URL"),
-		FrontendURL:   os.Getenv("FRONTEND_URL"),
-		FrontendURL2:  os.Getenv("FRONTEND_URL2"),
-		Port:          os.Getenv("PORT"),
-		ApiKey:        os.Getenv("KITE_API_KEY"),
-		ApiSecret:     os.Getenv("KITE_API_SECRET"),
-		CallbackURL:   os.Getenv("KITE_CALLBACK_URL"),
-		TokenFilePath: "./go_stock-tracker/.token.json",
-		CookieDomain:  os.Getenv("COOKIE_DOMAIN"),
-		GoEnv:         os.Getenv("GO_ENV"),
+// IsAdmin reports whether userID is listed in ADMIN_USER_IDS.
+func (c *Config) IsAdmin(userID int64) bool {
+	for _, part := range strings.Split(c.AdminUserIDs, ",") {
+		if id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64); err == nil && id == userID {
+			return true
+		}
 	}
+	return false
+}
 
+// IsReloadable reports whether key names a setting that can change at runtime.
+func IsReloadable(key string) bool {
+	f, ok := byKey[key]
+	return ok && f.reload
+}
+
+func (c *Config) clone() *Config {
+	next := *c
+	next.sources = make(map[string]string, len(c.sources))
+	for k, v := range c.sources {
+		next.sources[k] = v
+	}
+	return &next
+}
+
+// formatErrors prints a joined error one problem per line.
+func formatErrors(err error) string {
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) {
+		return "  - " + err.Error()
+	}
+	lines := make([]string, 0, len(joined.Unwrap()))
+	for _, e := range joined.Unwrap() {
+		lines = append(lines, "  - "+e.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Clock is a time of day in IST, written as "15:04".
+type Clock struct {
+	Hour   int
+	Minute int
+}
+
+// Minutes returns the minutes since midnight.
+func (c Clock) Minutes() int {
+	return c.Hour*60 + c.Minute
+}
+
+func (c Clock) String() string {
+	return fmt.Sprintf("%02d:%02d", c.Hour, c.Minute)
+}
+
+func (c Clock) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *Clock) UnmarshalText(text []byte) error {
+	t, err := time.Parse("15:04", strings.TrimSpace(string(text)))
+	if err != nil {
+		return fmt.Errorf("%q is not a time of day like 09:15", text)
+	}
+	c.Hour, c.Minute = t.Hour(), t.Minute()
+	return nil
 }
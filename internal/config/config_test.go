@@ -0,0 +1,155 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setBaseEnv sets the required settings and a config file for a test.
+func setBaseEnv(t *testing.T, file string) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "jwt-secret-value")
+	t.Setenv("DATABASE_URL", "postgres://user:pw@localhost/db")
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(file), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+}
+
+func TestBuildLayers(t *testing.T) {
+	setBaseEnv(t, `{"MAX_DAILY_TRADES": 3, "MAX_LOSS_PER_TRADE": 3000, "ENTRY_FALLBACK": "ABANDON"}`)
+	t.Setenv("MAX_LOSS_PER_TRADE", "2500")
+
+	cfg, err := Build(map[string]string{"ENTRY_FALLBACK": "MARKET"})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	tests := []struct {
+		key, value, source string
+	}{
+		{"EXIT_LIMIT_TIMEOUT", "15s", SourceDefault},
+		{"MAX_DAILY_TRADES", "3", SourceFile},
+		{"MAX_LOSS_PER_TRADE", "2500", SourceEnv},
+		{"ENTRY_FALLBACK", "MARKET", SourceOverride},
+		{"SESSION_OPEN", "09:15", SourceDefault},
+	}
+	for _, tt := range tests {
+		f := byKey[tt.key]
+		if got := f.get(cfg); got != tt.value {
+			t.Errorf("%s = %s, want %s", tt.key, got, tt.value)
+		}
+		if got := cfg.sources[tt.key]; got != tt.source {
+			t.Errorf("%s source = %s, want %s", tt.key, got, tt.source)
+		}
+	}
+	if cfg.ExitLimitTimeout != 15*time.Second {
+		t.Errorf("ExitLimitTimeout = %s", cfg.ExitLimitTimeout)
+	}
+}
+
+func TestBuildReportsEveryProblem(t *testing.T) {
+	setBaseEnv(t, `{"NOT_A_SETTING": "x"}`)
+	t.Setenv("ENTRY_CHASE_MAX_STEPS", "three")
+	t.Setenv("SESSION_OPEN", "9am")
+
+	_, err := Build(map[string]string{"PORT": "9090"})
+	if err == nil {
+		t.Fatal("Build() error = nil, want errors")
+	}
+	for _, want := range []string{"NOT_A_SETTING", "ENTRY_CHASE_MAX_STEPS", "SESSION_OPEN", "PORT: cannot be overridden"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	setBaseEnv(t, `{}`)
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("SIGNAL_WINDOW_END", "09:20")
+	t.Setenv("ENTRY_FALLBACK", "LIMIT")
+	t.Setenv("ADMIN_USER_IDS", "1,admin")
+
+	_, err := Build(nil)
+	if err == nil {
+		t.Fatal("Build() error = nil, want validation errors")
+	}
+	for _, want := range []string{"JWT_SECRET: is required", "SIGNAL_WINDOW_END", "ENTRY_FALLBACK", "ADMIN_USER_IDS"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestSecretsAreRedacted(t *testing.T) {
+	setBaseEnv(t, `{}`)
+	cfg, err := Build(nil)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	printed := cfg.String()
+	for _, secret := range []string{"jwt-secret-value", "pw@localhost"} {
+		if strings.Contains(printed, secret) {
+			t.Errorf("String() leaks %q", secret)
+		}
+	}
+	for _, s := range cfg.Settings() {
+		if s.Key == "JWT_SECRET" && s.Value != "********" {
+			t.Errorf("JWT_SECRET shown as %q", s.Value)
+		}
+	}
+}
+
+func TestApplyKeepsStructuralSettings(t *testing.T) {
+	setBaseEnv(t, `{}`)
+	start, err := Build(nil)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	current.Store(start)
+	t.Cleanup(func() { current.Store(nil) })
+
+	var reloaded *Config
+	OnReload(func(cfg *Config) { reloaded = cfg })
+	t.Cleanup(func() { listeners = nil })
+
+	t.Setenv("PORT", "9090")
+	changed, err := Reload(map[string]string{"MAX_DAILY_TRADES": "5"})
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if len(changed) != 1 || changed[0] != "MAX_DAILY_TRADES" {
+		t.Fatalf("changed = %v, want [MAX_DAILY_TRADES]", changed)
+	}
+	if got := Current(); got.MaxDailyTrades != 5 || got.Port != "8080" {
+		t.Errorf("Current() MaxDailyTrades = %d Port = %s, want 5 and 8080", got.MaxDailyTrades, got.Port)
+	}
+	if reloaded == nil || reloaded.MaxDailyTrades != 5 {
+		t.Error("reload listener not called with the new config")
+	}
+}
+
+func TestIsAdmin(t *testing.T) {
+	setBaseEnv(t, `{}`)
+	t.Setenv("ADMIN_USER_IDS", "3, 12")
+	cfg, err := Build(nil)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	for id, want := range map[int64]bool{3: true, 12: true, 1: false, 0: false} {
+		if got := cfg.IsAdmin(id); got != want {
+			t.Errorf("IsAdmin(%d) = %t, want %t", id, got, want)
+		}
+	}
+	if _, err := Build(map[string]string{"ADMIN_USER_IDS": "1"}); err == nil || !strings.Contains(err.Error(), "ADMIN_USER_IDS: cannot be overridden") {
+		t.Errorf("overriding ADMIN_USER_IDS error = %v, want it refused", err)
+	}
+}
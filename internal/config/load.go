@@ -0,0 +1,239 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Layers a setting can come from, lowest first.
+const (
+	SourceDefault  = "default"
+	SourceFile     = "file"
+	SourceEnv      = "env"
+	SourceOverride = "override"
+)
+
+// defaultConfigFile is read when CONFIG_FILE is not set, if it exists.
+const defaultConfigFile = "config.json"
+
+// field is a setting declared on Config.
+type field struct {
+	key    string
+	def    string
+	reload bool
+	secret bool
+	index  int
+}
+
+var fields, byKey = parseFields()
+
+func parseFields() ([]field, map[string]field) {
+	t := reflect.TypeOf(Config{})
+	var list []field
+	index := map[string]field{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := sf.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		f := field{
+			key:    key,
+			def:    sf.Tag.Get("default"),
+			reload: sf.Tag.Get("reload") == "true",
+			secret: sf.Tag.Get("secret") == "true",
+			index:  i,
+		}
+		list = append(list, f)
+		index[key] = f
+	}
+	return list, index
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func (f field) value(c *Config) reflect.Value {
+	return reflect.ValueOf(c).Elem().Field(f.index)
+}
+
+func (f field) set(c *Config, raw string) error {
+	v := f.value(c)
+	raw = strings.TrimSpace(raw)
+
+	if v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration like 30s or 5m", raw)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", raw)
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		v.SetFloat(n)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", raw)
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+func (f field) get(c *Config) string {
+	return fmt.Sprint(f.value(c).Interface())
+}
+
+func (f field) copy(dst, src *Config) {
+	f.value(dst).Set(f.value(src))
+}
+
+func (f field) redacted(c *Config) string {
+	v := f.get(c)
+	if f.secret && v != "" {
+		return "********"
+	}
+	return v
+}
+
+// Build assembles a configuration from the defaults, the config file, the
+// environment and overrides, in that order, and validates it. All problems
+// are returned together.
+func Build(overrides map[string]string) (*Config, error) {
+	cfg := &Config{sources: map[string]string{}}
+	var errs []error
+	set := func(key, raw, source string) {
+		f, ok := byKey[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown setting (from %s)", key, source))
+			return
+		}
+		if source == SourceOverride && !f.reload {
+			errs = append(errs, fmt.Errorf("%s: cannot be overridden at runtime", key))
+			return
+		}
+		if err := f.set(cfg, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w (from %s)", key, err, source))
+			return
+		}
+		cfg.sources[key] = source
+	}
+
+	for _, f := range fields {
+		if f.def != "" {
+			set(f.key, f.def, SourceDefault)
+		}
+	}
+
+	fileValues, err := readConfigFile()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for key, raw := range fileValues {
+		set(key, raw, SourceFile)
+	}
+
+	env, err := readEnv()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, f := range fields {
+		if raw, ok := env[f.key]; ok && raw != "" {
+			set(f.key, raw, SourceEnv)
+		}
+	}
+
+	for key, raw := range overrides {
+		set(key, raw, SourceOverride)
+	}
+
+	if len(errs) == 0 {
+		errs = cfg.validate()
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return cfg, nil
+}
+
+// readConfigFile reads the JSON file named by CONFIG_FILE, or config.json if
+// it exists. The file is an object keyed by the same names as the environment.
+func readConfigFile() (map[string]string, error) {
+	path, explicit := os.LookupEnv("CONFIG_FILE")
+	if !explicit || path == "" {
+		path = defaultConfigFile
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	values := make(map[string]string, len(raw))
+	for key, msg := range raw {
+		var s string
+		if err := json.Unmarshal(msg, &s); err == nil {
+			values[key] = s
+			continue
+		}
+		// Numbers and booleans are kept as written.
+		values[key] = string(msg)
+	}
+	return values, nil
+}
+
+// readEnv returns the .env file overlaid with the process environment, so a
+// variable set in the environment wins over the same one in .env. A missing
+// .env is not an error.
+func readEnv() (map[string]string, error) {
+	env := map[string]string{}
+	dotenv, err := godotenv.Read(".env")
+	switch {
+	case err == nil:
+		for k, v := range dotenv {
+			env[k] = v
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf(".env: %w", err)
+	}
+	for _, f := range fields {
+		if v, ok := os.LookupEnv(f.key); ok {
+			env[f.key] = v
+		}
+	}
+	return env, nil
+}
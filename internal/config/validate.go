@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// validate checks the settings against each other and their allowed ranges.
+func (c *Config) validate() []error {
+	var errs []error
+	fail := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: "+format, append([]any{key}, args...)...))
+	}
+
+	if c.JWTSecret == "" {
+		fail("JWT_SECRET", "is required")
+	}
+	if c.DatabaseURL == "" {
+		fail("DATABASE_URL", "is required")
+	}
+	if c.Port == "" {
+		fail("PORT", "is required")
+	}
+	for _, part := range strings.Split(c.AdminUserIDs, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		if id, err := strconv.ParseInt(part, 10, 64); err != nil || id <= 0 {
+			fail("ADMIN_USER_IDS", "%q is not a user ID", part)
+		}
+	}
+
+	if c.EntryInitialWait <= 0 {
+		fail("ENTRY_INITIAL_WAIT", "must be positive")
+	}
+	if c.EntryChaseInterval <= 0 {
+		fail("ENTRY_CHASE_INTERVAL", "must be positive")
+	}
+	if c.EntryChaseMaxSteps < 0 {
+		fail("ENTRY_CHASE_MAX_STEPS", "must not be negative")
+	}
+	if c.EntryMaxSlippagePct < 0 {
+		fail("ENTRY_MAX_SLIPPAGE_PCT", "must not be negative")
+	}
+	if fb := strings.ToUpper(c.EntryFallback); fb != "MARKET" && fb != "ABANDON" {
+		fail("ENTRY_FALLBACK", "must be MARKET or ABANDON, got %q", c.EntryFallback)
+	}
+	if c.ExitLimitTimeout <= 0 {
+		fail("EXIT_LIMIT_TIMEOUT", "must be positive")
+	}
+	if c.ExitCheckInterval <= 0 {
+		fail("EXIT_CHECK_INTERVAL", "must be positive")
+	}
+	if c.ExitRetracePct <= 0 {
+		fail("EXIT_RETRACE_PCT", "must be positive")
+	}
+
+	if c.MarginReservePct < 0 || c.MarginReservePct >= 100 {
+		fail("MARGIN_RESERVE_PCT", "must be from 0 to below 100")
+	}
+	if c.MarginReservePerPosition < 0 {
+		fail("MARGIN_RESERVE_PER_POSITION", "must not be negative")
+	}
+
+	if c.MaxDailyTrades < 0 {
+		fail("MAX_DAILY_TRADES", "must not be negative")
+	}
+	if c.MaxLossPerTrade <= 0 {
+		fail("MAX_LOSS_PER_TRADE", "must be positive")
+	}
+	if c.MaxOrderValue <= 0 {
+		fail("MAX_ORDER_VALUE", "must be positive")
+	}
+
+	session := []struct {
+		key   string
+		clock Clock
+	}{
+		{"SESSION_OPEN", c.SessionOpen},
+		{"OPENING_RANGE_END", c.OpeningRangeEnd},
+		{"SIGNAL_WINDOW_END", c.SignalWindowEnd},
+		{"FORCE_EXIT_AT", c.ForceExitAt},
+		{"SESSION_CLOSE", c.SessionClose},
+		{"MARKET_CLOSE", c.MarketClose},
+	}
+	for i := 1; i < len(session); i++ {
+		prev, next := session[i-1], session[i]
+		if next.clock.Minutes() < prev.clock.Minutes() ||
+			(next.key != "MARKET_CLOSE" && next.clock.Minutes() == prev.clock.Minutes()) {
+			fail(next.key, "%s must be after %s %s", next.clock, prev.key, prev.clock)
+		}
+	}
+
+	if c.SMTPPort <= 0 || c.SMTPPort > 65535 {
+		fail("SMTP_PORT", "must be a port number, got %d", c.SMTPPort)
+	}
+	if c.NotifyRateLimit < 0 {
+		fail("NOTIFY_RATE_LIMIT", "must not be negative")
+	}
+	if c.NotifyRateWindow <= 0 {
+		fail("NOTIFY_RATE_WINDOW", "must be positive")
+	}
+	if c.SignalWebhookTolerance <= 0 {
+		fail("SIGNAL_WEBHOOK_TOLERANCE", "must be positive")
+	}
+
+	if f := strings.ToLower(c.LogFormat); f != "json" && f != "text" {
+		fail("LOG_FORMAT", "must be json or text, got %q", c.LogFormat)
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		fail("LOG_LEVEL", "must be debug, info, warn or error, got %q", c.LogLevel)
+	}
+	for _, part := range strings.Split(c.LogLevels, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(name) == "" || level.UnmarshalText([]byte(strings.TrimSpace(value))) != nil {
+			fail("LOG_LEVELS", "%q is not component=level", strings.TrimSpace(part))
+		}
+	}
+
+	if c.ShutdownTimeout <= 0 {
+		fail("SHUTDOWN_TIMEOUT", "must be positive")
+	}
+	return errs
+}
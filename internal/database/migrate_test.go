@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/utils"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %d_%s: versions must run 1, 2, 3... without gaps", m.Version, m.Name)
+		}
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			t.Errorf("migration %d_%s has an empty up or down file", m.Version, m.Name)
+		}
+	}
+}
+
+func TestLoadMigrationsRejectsBadFiles(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"missing down": {
+			"m/0001_a.up.sql": {Data: []byte("SELECT 1")},
+		},
+		"bad name": {
+			"m/first.sql": {Data: []byte("SELECT 1")},
+		},
+		"two names": {
+			"m/0001_a.up.sql":   {Data: []byte("SELECT 1")},
+			"m/0001_b.down.sql": {Data: []byte("SELECT 1")},
+		},
+	}
+	for name, fsys := range tests {
+		if _, err := loadMigrations(fsys, "m"); err == nil {
+			t.Errorf("%s: loadMigrations() error = nil", name)
+		}
+	}
+}
+
+// TestMigrateBaselineDatabase adopts a database created by the original
+// scripts/init.sql, which has orders without the columns added since, and
+// checks the server can write orders to it afterwards. It needs a Postgres
+// database to create a scratch schema in, given as TEST_DATABASE_URL.
+func TestMigrateBaselineDatabase(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+
+	admin, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+	schema := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+
+	cfg, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema
+	db, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	baseline, err := os.ReadFile("testdata/baseline_init.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(ctx, string(baseline)); err != nil {
+		t.Fatalf("baseline init.sql: %v", err)
+	}
+	var stockID int64
+	err = db.QueryRow(ctx, `
+		INSERT INTO tracking_stocks (trading_symbol, instrument_token, target, stoploss, quantity)
+		VALUES ('INFY', 408065, 10, 5, 1) RETURNING id`).Scan(&stockID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(ctx, `
+		INSERT INTO orders (tracking_stock_id, order_id, order_type, event_type, quantity, base_price, status)
+		VALUES ($1, 'baseline-1', 'LIMIT', 'ENTRY', 1, 1500, 'COMPLETE')`, stockID); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if len(applied) != len(m.migrations) {
+		t.Fatalf("Up() applied %v, want all %d migrations", applied, len(m.migrations))
+	}
+
+	orders := &repository.OrderRepository{DB: db}
+	_, err = orders.AddOrder(ctx, &models.Order{
+		TrackingStockID: stockID,
+		OrderID:         "migrated-1",
+		OrderType:       "MARKET",
+		EventType:       "ENTRY",
+		Quantity:        1,
+		Source:          models.OrderSourceManual,
+		CorrelationID:   utils.ToNullString("corr-1"),
+		Status:          "PENDING",
+		PlacedAt:        time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("AddOrder() after migrating = %v", err)
+	}
+	old, err := orders.GetOrderByKiteOrderID(ctx, "baseline-1")
+	if err != nil || old.Source != models.OrderSourceAlgo {
+		t.Fatalf("baseline order = %+v, %v, want source %s", old, err, models.OrderSourceAlgo)
+	}
+
+	// Every down file must undo its up file.
+	if _, err := m.Down(ctx, len(m.migrations)); err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() after Down() error = %v", err)
+	}
+}
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS instruments;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS tracking_stocks;
+
+DROP TYPE IF EXISTS order_status;
+DROP TYPE IF EXISTS stock_tracking_status;
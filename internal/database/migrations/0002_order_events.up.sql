@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS order_events (
+    id SERIAL PRIMARY KEY,
+    tracking_stock_id INT REFERENCES tracking_stocks(id) ON DELETE SET NULL,
+    order_id VARCHAR(50) NOT NULL,
+    event VARCHAR(30) NOT NULL,
+    price DECIMAL(10, 2),
+    quantity DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    message VARCHAR(255),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_events_order_id
+ON order_events(order_id);
@@ -0,0 +1,2 @@
+-- Why an entry's quantity was clamped to the available margin
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS sizing_note VARCHAR(255);
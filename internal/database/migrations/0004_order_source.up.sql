@@ -0,0 +1,2 @@
+-- Who placed the order: ALGO, MANUAL, WEBHOOK or SHUTDOWN
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS source VARCHAR(10) NOT NULL DEFAULT 'ALGO';
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS position_adjustments (
+    id SERIAL PRIMARY KEY,
+    tracking_stock_id INT REFERENCES tracking_stocks(id) ON DELETE CASCADE,
+    direction VARCHAR(4) NOT NULL,
+    base_price DECIMAL(10, 2) NOT NULL,
+    ltp DECIMAL(10, 2) NOT NULL,
+    old_target DECIMAL(10, 2) NOT NULL,
+    new_target DECIMAL(10, 2) NOT NULL,
+    old_stoploss DECIMAL(10, 2) NOT NULL,
+    new_stoploss DECIMAL(10, 2) NOT NULL,
+    old_trailing_stoploss DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    new_trailing_stoploss DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_position_adjustments_stock
+ON position_adjustments(tracking_stock_id, created_at);
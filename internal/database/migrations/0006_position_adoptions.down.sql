@@ -0,0 +1,3 @@
+ALTER TABLE position_adjustments
+    DROP COLUMN IF EXISTS quantity,
+    DROP COLUMN IF EXISTS kind;
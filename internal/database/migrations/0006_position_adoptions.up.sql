@@ -0,0 +1,4 @@
+-- Adopted broker positions are logged alongside adjustments.
+ALTER TABLE position_adjustments
+    ADD COLUMN IF NOT EXISTS kind VARCHAR(10) NOT NULL DEFAULT 'ADJUST',
+    ADD COLUMN IF NOT EXISTS quantity INT NOT NULL DEFAULT 0;
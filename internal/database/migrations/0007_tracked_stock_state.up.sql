@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS tracked_stock_state (
+    tracking_stock_id INT REFERENCES tracking_stocks(id) ON DELETE CASCADE,
+    trading_date DATE NOT NULL,
+    instrument_token BIGINT NOT NULL,
+    trading_symbol VARCHAR(50) NOT NULL,
+    direction VARCHAR(4) NOT NULL DEFAULT '',
+    buy_quantity INT NOT NULL DEFAULT 0,
+    sell_quantity INT NOT NULL DEFAULT 0,
+    base_price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    target DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    stoploss DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    trailing_stoploss DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    signal_fired BOOLEAN NOT NULL DEFAULT FALSE,
+    locked BOOLEAN NOT NULL DEFAULT FALSE,
+    max_executable_orders INT NOT NULL DEFAULT 0,
+    pending_exit_order_id VARCHAR(50) NOT NULL DEFAULT '',
+    exit_escalated BOOLEAN NOT NULL DEFAULT FALSE,
+    fifteen_candle JSONB,
+    candles JSONB,
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (tracking_stock_id, trading_date)
+);
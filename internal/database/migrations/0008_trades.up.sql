@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS trades (
+    id SERIAL PRIMARY KEY,
+    tracking_stock_id INT REFERENCES tracking_stocks(id) ON DELETE SET NULL,
+    trading_symbol VARCHAR(50) NOT NULL,
+    exchange VARCHAR(10) NOT NULL DEFAULT 'NSE',
+    direction VARCHAR(4) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'OPEN',
+    source VARCHAR(10) NOT NULL DEFAULT 'ALGO',
+    quantity INT NOT NULL DEFAULT 0,
+    exit_quantity INT NOT NULL DEFAULT 0,
+    entry_price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    exit_price DECIMAL(10, 2),
+    risk_points DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    exit_reason VARCHAR(20),
+    gross_pnl DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    r_multiple DECIMAL(8, 2),
+    holding_seconds INT,
+    opened_at TIMESTAMPTZ DEFAULT NOW(),
+    closed_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS trade_orders (
+    order_id VARCHAR(50) PRIMARY KEY,
+    trade_id INT NOT NULL REFERENCES trades(id) ON DELETE CASCADE,
+    leg VARCHAR(5) NOT NULL,
+    event_type VARCHAR(20) NOT NULL DEFAULT '',
+    quantity INT NOT NULL DEFAULT 0,
+    average_price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    filled_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trades_open_stock
+ON trades(tracking_stock_id)
+WHERE status = 'OPEN';                    -- one open trade per stock; entry fills join it
+
+CREATE INDEX IF NOT EXISTS idx_trades_opened_at
+ON trades(opened_at);
+
+CREATE INDEX IF NOT EXISTS idx_trade_orders_trade
+ON trade_orders(trade_id);
@@ -0,0 +1,5 @@
+ALTER TABLE trade_orders DROP COLUMN IF EXISTS charges;
+
+ALTER TABLE trades
+    DROP COLUMN IF EXISTS net_pnl,
+    DROP COLUMN IF EXISTS charges;
@@ -0,0 +1,5 @@
+ALTER TABLE trades
+    ADD COLUMN IF NOT EXISTS charges DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS net_pnl DECIMAL(12, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE trade_orders ADD COLUMN IF NOT EXISTS charges DECIMAL(10, 2) NOT NULL DEFAULT 0;
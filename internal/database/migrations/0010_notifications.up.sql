@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS notification_routes (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(10) NOT NULL,                -- TELEGRAM, SLACK, EMAIL, WEBHOOK
+    target JSONB NOT NULL,
+    events TEXT[] NOT NULL DEFAULT '{}',         -- empty matches every event
+    min_severity VARCHAR(10) NOT NULL DEFAULT 'INFO',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    route_id INT REFERENCES notification_routes(id) ON DELETE SET NULL,
+    channel VARCHAR(10) NOT NULL,
+    event VARCHAR(30) NOT NULL,
+    severity VARCHAR(10) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    status VARCHAR(15) NOT NULL,                 -- SENT, FAILED, RATE_LIMITED
+    error TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user
+ON notifications(user_id, created_at);
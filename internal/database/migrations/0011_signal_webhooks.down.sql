@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS signal_alerts;
+DROP TABLE IF EXISTS signal_webhook_keys;
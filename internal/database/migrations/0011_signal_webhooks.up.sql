@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS signal_webhook_keys (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    key_id VARCHAR(32) UNIQUE NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS signal_alerts (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,  -- NULL when the key was unknown
+    alert_id VARCHAR(100) NOT NULL DEFAULT '',          -- sender's ID or a hash of the request
+    trading_symbol VARCHAR(50) NOT NULL DEFAULT '',
+    side VARCHAR(4) NOT NULL DEFAULT '',
+    quantity INT NOT NULL DEFAULT 0,
+    tracking_stock_id INT REFERENCES tracking_stocks(id) ON DELETE SET NULL,
+    status VARCHAR(10) NOT NULL,                        -- ACCEPTED, REJECTED, DUPLICATE
+    reason TEXT,
+    payload JSONB,
+    received_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_signal_alerts_accepted
+ON signal_alerts(user_id, alert_id)
+WHERE status = 'ACCEPTED';                -- an alert is acted on at most once
+
+CREATE INDEX IF NOT EXISTS idx_signal_alerts_user
+ON signal_alerts(user_id, received_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS log_entries;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS correlation_id;
@@ -0,0 +1,20 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS correlation_id VARCHAR(40);
+
+CREATE TABLE IF NOT EXISTS log_entries (
+    id BIGSERIAL PRIMARY KEY,
+    correlation_id VARCHAR(40) NOT NULL,
+    level VARCHAR(10) NOT NULL,
+    component VARCHAR(30) NOT NULL DEFAULT '',
+    message TEXT NOT NULL,
+    attrs JSONB NOT NULL DEFAULT '{}',
+    logged_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_correlation_id
+ON orders(correlation_id);
+
+CREATE INDEX IF NOT EXISTS idx_log_entries_correlation
+ON log_entries(correlation_id, logged_at);
+
+CREATE INDEX IF NOT EXISTS idx_log_entries_order
+ON log_entries((attrs->>'order_id'));
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS config_overrides (
+    key VARCHAR(64) PRIMARY KEY,                 -- same name as the environment variable
+    value TEXT NOT NULL,
+    updated_by INT REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+ALTER TABLE tracking_stocks
+    DROP CONSTRAINT IF EXISTS tracking_stocks_allowed_trades_positive,
+    ALTER COLUMN allowed_trades DROP NOT NULL;
@@ -0,0 +1,8 @@
+-- allowed_trades is now read into TrackingStock.AllowedTrades and seeds the
+-- number of entries a stock may take in a day, so it can no longer be NULL.
+UPDATE tracking_stocks SET allowed_trades = 1 WHERE allowed_trades IS NULL OR allowed_trades < 1;
+
+ALTER TABLE tracking_stocks
+    ALTER COLUMN allowed_trades SET NOT NULL,
+    DROP CONSTRAINT IF EXISTS tracking_stocks_allowed_trades_positive,
+    ADD CONSTRAINT tracking_stocks_allowed_trades_positive CHECK (allowed_trades >= 1);
@@ -0,0 +1,213 @@
+// Package export writes the tradebook and trade P&L as CSV or XLSX.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+)
+
+// Export formats and CSV sheets.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+
+	SheetOrders = "orders"
+	SheetTrades = "trades"
+)
+
+type OrderStreamer interface {
+	StreamTradebookOrders(ctx context.Context, from, to time.Time, fn func(repository.TradebookOrder) error) error
+}
+
+type TradeStreamer interface {
+	StreamTrades(ctx context.Context, from, to time.Time, fn func(models.Trade) error) error
+}
+
+// Tradebook exports executed orders, laid out like the broker's tradebook so
+// the two can be reconciled line by line, and the round-trip trades built
+// from them with charges and net P&L.
+type Tradebook struct {
+	Orders OrderStreamer
+	Trades TradeStreamer
+}
+
+// orderHeader follows the columns of the broker's tradebook download, with
+// the bot's own columns appended.
+var orderHeader = []any{
+	"symbol", "trade_date", "exchange", "segment", "product", "trade_type", "quantity", "price",
+	"order_id", "exchange_order_id", "order_execution_time", "event_type", "source", "trade_id", "charges",
+}
+
+var tradeHeader = []any{
+	"trade_id", "symbol", "exchange", "direction", "status", "source", "quantity", "entry_price",
+	"exit_quantity", "exit_price", "exit_reason", "opened_at", "closed_at", "holding_seconds",
+	"gross_pnl", "charges", "net_pnl", "r_multiple",
+}
+
+// ContentType returns the MIME type of an export format.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+// FileName returns the download name for an export of [from, to).
+func FileName(format, sheet string, from, to time.Time) string {
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	name := fmt.Sprintf("tradebook_%s_%s", from.In(ist).Format("2006-01-02"), to.Add(-time.Nanosecond).In(ist).Format("2006-01-02"))
+	if format == FormatCSV {
+		name += "_" + sheet
+	}
+	return name + "." + format
+}
+
+// WriteCSV streams one sheet, orders or trades, as CSV.
+func (t *Tradebook) WriteCSV(ctx context.Context, w io.Writer, sheet string, from, to time.Time) error {
+	cw := csv.NewWriter(w)
+	rows := &csvRows{w: cw}
+
+	var err error
+	switch sheet {
+	case SheetOrders:
+		err = t.writeOrders(ctx, rows, from, to)
+	case SheetTrades:
+		err = t.writeTrades(ctx, rows, from, to)
+	default:
+		return fmt.Errorf("unknown sheet %q", sheet)
+	}
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteXLSX streams a workbook with an orders sheet and a trades sheet.
+func (t *Tradebook) WriteXLSX(ctx context.Context, w io.Writer, from, to time.Time) error {
+	xw := newXLSXWriter(w)
+	if err := xw.StartSheet("Orders"); err != nil {
+		return err
+	}
+	if err := t.writeOrders(ctx, xw, from, to); err != nil {
+		return err
+	}
+	if err := xw.StartSheet("Trades"); err != nil {
+		return err
+	}
+	if err := t.writeTrades(ctx, xw, from, to); err != nil {
+		return err
+	}
+	return xw.Close()
+}
+
+type rowWriter interface {
+	WriteRow(cells []any) error
+}
+
+func (t *Tradebook) writeOrders(ctx context.Context, rows rowWriter, from, to time.Time) error {
+	if err := rows.WriteRow(orderHeader); err != nil {
+		return err
+	}
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	return t.Orders.StreamTradebookOrders(ctx, from, to, func(o repository.TradebookOrder) error {
+		executedAt := o.ExecutedAt.In(ist)
+		var tradeID any
+		if o.TradeID != nil {
+			tradeID = *o.TradeID
+		}
+		return rows.WriteRow([]any{
+			o.TradingSymbol,
+			executedAt.Format("2006-01-02"),
+			o.Exchange,
+			"EQ",
+			deref(o.Product),
+			strings.ToLower(deref(o.TransactionType)),
+			o.Quantity,
+			o.Price,
+			o.OrderID,
+			deref(o.ExchangeOrderID),
+			executedAt.Format("2006-01-02T15:04:05"),
+			o.EventType,
+			o.Source,
+			tradeID,
+			o.Charges,
+		})
+	})
+}
+
+func (t *Tradebook) writeTrades(ctx context.Context, rows rowWriter, from, to time.Time) error {
+	if err := rows.WriteRow(tradeHeader); err != nil {
+		return err
+	}
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	return t.Trades.StreamTrades(ctx, from, to, func(tr models.Trade) error {
+		var exitPrice, closedAt, holding, rMultiple any
+		if tr.ExitPrice != nil {
+			exitPrice = *tr.ExitPrice
+		}
+		if tr.ClosedAt != nil {
+			closedAt = tr.ClosedAt.In(ist).Format("2006-01-02T15:04:05")
+		}
+		if tr.HoldingSeconds != nil {
+			holding = *tr.HoldingSeconds
+		}
+		if tr.RMultiple != nil {
+			rMultiple = *tr.RMultiple
+		}
+		return rows.WriteRow([]any{
+			tr.ID,
+			tr.TradingSymbol,
+			tr.Exchange,
+			tr.Direction,
+			tr.Status,
+			tr.Source,
+			tr.Quantity,
+			tr.EntryPrice,
+			tr.ExitQuantity,
+			exitPrice,
+			deref(tr.ExitReason),
+			tr.OpenedAt.In(ist).Format("2006-01-02T15:04:05"),
+			closedAt,
+			holding,
+			tr.GrossPnL,
+			tr.Charges,
+			tr.NetPnL,
+			rMultiple,
+		})
+	})
+}
+
+// csvRows writes rows of mixed cells as CSV records.
+type csvRows struct {
+	w *csv.Writer
+}
+
+func (c *csvRows) WriteRow(cells []any) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		switch v := cell.(type) {
+		case nil:
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,108 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+)
+
+type fakeStreams struct {
+	orders []repository.TradebookOrder
+	trades []models.Trade
+}
+
+func (f *fakeStreams) StreamTradebookOrders(_ context.Context, _, _ time.Time, fn func(repository.TradebookOrder) error) error {
+	for _, o := range f.orders {
+		if err := fn(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeStreams) StreamTrades(_ context.Context, _, _ time.Time, fn func(models.Trade) error) error {
+	for _, t := range f.trades {
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func testTradebook() *Tradebook {
+	buy := "BUY"
+	reason := "TARGET_HIT"
+	exit := 106.0
+	opened := time.Date(2026, 3, 2, 4, 5, 0, 0, time.UTC)
+	streams := &fakeStreams{
+		orders: []repository.TradebookOrder{
+			{OrderID: "1001", TradingSymbol: "INFY", Exchange: "NSE", TransactionType: &buy, Quantity: 10, Price: 100, EventType: "ENTRY_BUY", Source: "ALGO", Charges: 3.5, ExecutedAt: opened},
+		},
+		trades: []models.Trade{
+			{ID: 7, TradingSymbol: "R&D <Co>", Direction: "BUY", Status: models.TradeStatusClosed, Quantity: 10, EntryPrice: 100, ExitPrice: &exit, ExitReason: &reason, GrossPnL: 60, Charges: 7, NetPnL: 53, OpenedAt: opened},
+		},
+	}
+	return &Tradebook{Orders: streams, Trades: streams}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testTradebook().WriteCSV(context.Background(), &buf, SheetOrders, time.Time{}, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || len(records[1]) != len(orderHeader) {
+		t.Fatalf("records = %v", records)
+	}
+	// 04:05 UTC is 09:35 IST; trade type is lower case like the broker's tradebook.
+	if got := records[1]; got[0] != "INFY" || got[5] != "buy" || got[10] != "2026-03-02T09:35:00" || got[13] != "" {
+		t.Fatalf("order row = %v", got)
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testTradebook().WriteXLSX(context.Background(), &buf, time.Time{}, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("not a zip: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(body)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("missing part %s", name)
+		}
+	}
+	trades := files["xl/worksheets/sheet2.xml"]
+	if !strings.Contains(trades, `<c r="A2"><v>7</v></c>`) || !strings.Contains(trades, "R&amp;D &lt;Co&gt;") {
+		t.Fatalf("trades sheet = %s", trades)
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `<sheet name="Trades" sheetId="2" r:id="rId2"/>`) {
+		t.Fatalf("workbook = %s", files["xl/workbook.xml"])
+	}
+}
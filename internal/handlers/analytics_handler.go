@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsHandler struct {
+	AnalyticsSvc *services.AnalyticsService
+}
+
+// defaultAnalyticsDays is the lookback when no from date is given.
+const defaultAnalyticsDays = 30
+
+// Performance reports P&L, equity curve, drawdown, trade statistics and
+// breakdowns over the trades closed between from and to (IST dates, to
+// inclusive). Without dates it covers the last 30 days.
+func (h *AnalyticsHandler) Performance(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	if to.IsZero() {
+		y, m, d := time.Now().In(ist).Date()
+		to = time.Date(y, m, d, 0, 0, 0, 0, ist).AddDate(0, 0, 1)
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -defaultAnalyticsDays)
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	report, err := h.AnalyticsSvc.Performance(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to compute performance", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"performance": report})
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type APIKeyHandler struct {
+	Repo repository.APIKeyStore
+}
+
+type createAPIKeyRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// CreateKey issues an API key for the user. The key is only ever returned
+// here; afterwards only its prefix is shown.
+func (h *APIKeyHandler) CreateKey(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user ID not found in context"})
+		return
+	}
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, err := services.CreateAPIKey(c.Request.Context(), h.Repo, userID, req.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to create api key", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"key": key})
+}
+
+// GetKeys lists the user's API keys, revoked ones included.
+func (h *APIKeyHandler) GetKeys(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user ID not found in context"})
+		return
+	}
+	keys, err := h.Repo.GetKeysByUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get api keys", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
+
+func (h *APIKeyHandler) RevokeKey(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user ID not found in context"})
+		return
+	}
+	var id int64
+	if _, err := fmt.Sscan(c.Param("id"), &id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID parameter"})
+		return
+	}
+
+	if err := h.Repo.RevokeKey(c.Request.Context(), userID, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "api key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to revoke api key", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "api key revoked"})
+}
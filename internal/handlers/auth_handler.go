@@ -13,7 +13,7 @@ import (
 )
 
 type AuthHandler struct {
-	UserRepo *repository.UserRepository
+	UserRepo repository.UserStore
 }
 
 type LoginRequest struct {
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/app"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/backtest"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/charges"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type BacktestHandler struct {
+	TrackingStockRepo repository.TrackingStockStore
+	Runtime           *app.Runtime
+}
+
+// Backtest replays the strategy on a tracking stock's Kite candles between
+// from and to (IST dates, to inclusive, at most backtest.MaxDays apart).
+func (h *BacktestHandler) Backtest(c *gin.Context) {
+	idParam := c.Param("id")
+	var id int64
+	_, err := fmt.Sscan(idParam, &id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if from.IsZero() || to.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to dates are required"})
+		return
+	}
+
+	if !h.Runtime.KiteReady || h.Runtime.Broker == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "kite runtime is not ready"})
+		return
+	}
+
+	stock, err := h.TrackingStockRepo.GetTrackingStockByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to retrieve tracking stock", "error": err.Error()})
+		return
+	}
+
+	runner := &backtest.Runner{Broker: h.Runtime.Broker, Charges: charges.Default()}
+	// parseDateRange makes to exclusive; the runner takes the last day.
+	report, err := runner.Run(*stock, from, to.AddDate(0, 0, -1))
+	if errors.Is(err, backtest.ErrRange) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"message": "backtest failed", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
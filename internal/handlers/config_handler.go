@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type ConfigHandler struct {
+	Repo repository.ConfigOverrideStore
+}
+
+// GetConfig lists every setting with the layer it came from, secrets
+// redacted, and the overrides saved in the database.
+func (h *ConfigHandler) GetConfig(c *gin.Context) {
+	overrides, err := h.Repo.GetOverrides(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get config overrides", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"settings": config.Current().Settings(), "overrides": overrides})
+}
+
+// UpdateConfig saves overrides for reloadable settings and applies them
+// without a restart. The settings are validated together with everything
+// else first, so one bad value changes nothing.
+func (h *ConfigHandler) UpdateConfig(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user ID not found in context"})
+		return
+	}
+
+	var req map[string]string
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid request, expected an object of setting names to values", "error": err.Error()})
+		return
+	}
+	if len(req) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no settings given"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	overrides, err := h.Repo.GetOverrideValues(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get config overrides", "error": err.Error()})
+		return
+	}
+	for key, value := range req {
+		overrides[key] = value
+	}
+
+	next, err := config.Build(overrides)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid configuration", "error": err.Error()})
+		return
+	}
+	if err := h.Repo.SetOverrides(ctx, req, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to save config overrides", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "configuration updated", "changed": config.Apply(next)})
+}
+
+// DeleteConfigOverride removes an override so the setting falls back to the
+// config file, the environment or its default.
+func (h *ConfigHandler) DeleteConfigOverride(c *gin.Context) {
+	key := c.Param("key")
+	ctx := c.Request.Context()
+
+	overrides, err := h.Repo.GetOverrideValues(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get config overrides", "error": err.Error()})
+		return
+	}
+	delete(overrides, key)
+
+	next, err := config.Build(overrides)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "configuration without the override is invalid", "error": err.Error()})
+		return
+	}
+	if err := h.Repo.DeleteOverride(ctx, key); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no override for " + key})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to delete config override", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "override removed", "changed": config.Apply(next)})
+}
+
+// ReloadConfig re-reads the config file, the environment and the saved
+// overrides and applies the reloadable settings.
+func (h *ConfigHandler) ReloadConfig(c *gin.Context) {
+	overrides, err := h.Repo.GetOverrideValues(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get config overrides", "error": err.Error()})
+		return
+	}
+
+	changed, err := config.Reload(overrides)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid configuration, nothing was reloaded", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "configuration reloaded", "changed": changed})
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/export"
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	Tradebook *export.Tradebook
+}
+
+// ExportTradebook streams the tradebook between from and to (IST dates, to
+// inclusive). format=xlsx gives a workbook with orders and trades sheets;
+// format=csv (the default) gives the sheet named by sheet=orders|trades.
+func (h *ExportHandler) ExportTradebook(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if from.IsZero() || to.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to dates are required"})
+		return
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	format := c.DefaultQuery("format", export.FormatCSV)
+	sheet := c.DefaultQuery("sheet", export.SheetOrders)
+	if format != export.FormatCSV && format != export.FormatXLSX {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or xlsx"})
+		return
+	}
+	if sheet != export.SheetOrders && sheet != export.SheetTrades {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sheet must be orders or trades"})
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.FileName(format, sheet, from, to)))
+	c.Status(http.StatusOK)
+
+	// Rows go out as they are read, so a failure can only cut the file short.
+	if format == export.FormatXLSX {
+		err = h.Tradebook.WriteXLSX(c.Request.Context(), c.Writer, from, to)
+	} else {
+		err = h.Tradebook.WriteCSV(c.Request.Context(), c.Writer, sheet, from, to)
+	}
+	if err != nil {
+		log.Printf("⚠️ Tradebook export failed: %v", err)
+	}
+}
@@ -52,6 +52,7 @@ func (h *KiteCallbackHandler) KiteCallback(c *gin.Context) {
 	if err := app.LoadTrackedStocksOnStartup(h.Runtime); err != nil {
 		log.Printf("⚠️ Failed to load tracked stocks: %v", err)
 	}
+	app.RecoverPendingExitOrdersOnStartup(h.Runtime)
 
 	// Start engines if market is currently open
 	app.StartEnginesIfMarketOpen(h.Runtime)
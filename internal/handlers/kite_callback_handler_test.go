@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/app"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/kite"
+	kcbroker "github.com/SM-Sclass/stock_client2-go_backend/internal/kite/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/kite/kitetest"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository/memory"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/services"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// TestKiteCallbackStartsTradingDay logs in through the fake Kite server's
+// login page and the callback, which starts the runtime and loads the day's
+// tracked stocks, as on a trading morning.
+func TestKiteCallbackStartsTradingDay(t *testing.T) {
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	utils.SetClock(func() time.Time { return time.Date(2026, 3, 2, 10, 0, 0, 0, ist) }) // a Monday
+	t.Cleanup(func() { utils.SetClock(nil) })
+
+	srv := kitetest.NewServer()
+	defer srv.Close()
+	srv.RedirectURL = "http://localhost/kite/callback"
+	srv.AddInstrument(kitetest.Instrument{InstrumentToken: 408065, TradingSymbol: "INFY", Name: "INFOSYS", LastPrice: 1500})
+	ticker := srv.NewTicker()
+	defer ticker.Close()
+
+	t.Setenv("JWT_SECRET", "jwt-secret-value")
+	t.Setenv("DATABASE_URL", "postgres://user:pw@localhost/db")
+	t.Setenv("KITE_API_URL", srv.URL)
+	t.Setenv("KITE_LOGIN_URL", srv.URL)
+	t.Setenv("KITE_TICKER_URL", ticker.WebSocketURL())
+	t.Setenv("KITE_API_KEY", srv.APIKey)
+	t.Setenv("KITE_API_SECRET", srv.APISecret)
+	t.Setenv("KITE_TOKEN_FILE", filepath.Join(t.TempDir(), "token.json"))
+	cfg, err := config.Build(nil)
+	if err != nil {
+		t.Fatalf("config.Build() error = %v", err)
+	}
+	prev := config.ServerConfig
+	config.ServerConfig = cfg
+	t.Cleanup(func() { config.ServerConfig = prev })
+
+	ctx := context.Background()
+	db := memory.New()
+	if _, err := db.TrackingStocks().AddTrackingStock(ctx, &models.TrackingStock{
+		TradingSymbol: "INFY", Exchange: "NSE", InstrumentToken: 408065, Target: 10, StopLoss: 5,
+		Quantity: 10, AllowedTrades: 1, Status: "AUTO_INACTIVE",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	client := kite.NewKiteClient()
+	brk := kcbroker.NewKiteBroker(client)
+	instruments := &services.InstrumentService{Kite: client, Broker: brk, Repo: db.Instruments()}
+	runtime := &app.Runtime{
+		KiteClient:        client,
+		Broker:            brk,
+		OrderSvc:          &services.OrderService{OrderRepo: db.Orders(), TrackingStockRepo: db.TrackingStocks(), TradeRepo: db.Trades()},
+		InstrumentSvc:     instruments,
+		TrackingStockRepo: db.TrackingStocks(),
+		TrackedStateRepo:  db.TrackedStates(),
+	}
+	defer app.Shutdown(ctx, runtime, false)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := &KiteCallbackHandler{Kc: client, Runtime: runtime, InstrumentService: instruments}
+	r.GET("/kite/callback", h.KiteCallback)
+
+	// The login page sends the browser back to the callback with a request token.
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := noRedirect.Get(client.GetLoginURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || callback.Query().Get("request_token") == "" {
+		t.Fatalf("login redirect = %q, want a request token", resp.Header.Get("Location"))
+	}
+
+	w := serve(r, http.MethodGet, callback.RequestURI(), "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("callback status = %d, body %s", w.Code, w.Body)
+	}
+	if !runtime.KiteReady || !client.IsTokenValid() {
+		t.Fatalf("after the callback KiteReady = %t, token valid = %t", runtime.KiteReady, client.IsTokenValid())
+	}
+
+	stock, exists := runtime.TrackingManager.GetStock(408065)
+	if !exists || stock.TradingSymbol != "INFY" || stock.BasePrice != 1500 {
+		t.Fatalf("tracked INFY = %+v, %t, want it loaded at the fake LTP", stock, exists)
+	}
+	if !runtime.OrderEngine.IsRunning() {
+		t.Error("order engine not started during market hours")
+	}
+
+	// The runtime's websocket follows the tracked stock and its ticks reach the broadcaster.
+	deadline := time.Now().Add(2 * time.Second)
+	for _, ok := ticker.Subscribed(408065); !ok; _, ok = ticker.Subscribed(408065) {
+		if time.Now().After(deadline) {
+			t.Fatal("INFY was not subscribed on the ticker")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	ticks := runtime.Broadcaster.Subscribe(10)
+	defer runtime.Broadcaster.Unsubscribe(ticks)
+	if err := srv.SetLTP("NSE:INFY", 1504.5); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case batch := <-ticks:
+		if len(batch) != 1 || batch[0].InstrumentToken != 408065 || batch[0].LastPrice != 1504.5 {
+			t.Errorf("ticks = %+v, want INFY at 1504.50", batch)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no tick reached the runtime after SetLTP")
+	}
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/notify"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type NotificationHandler struct {
+	Repo       repository.NotificationStore
+	Dispatcher *notify.Dispatcher
+}
+
+type createRouteRequest struct {
+	Channel     string                    `json:"channel" binding:"required"`
+	Target      models.NotificationTarget `json:"target"`
+	Events      []string                  `json:"events"`
+	MinSeverity string                    `json:"min_severity"`
+	Enabled     *bool                     `json:"enabled"`
+}
+
+// contextUserID returns the ID of the user the auth middleware let through.
+func contextUserID(c *gin.Context) (int64, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return 0, false
+	}
+	id, ok := userID.(float64)
+	return int64(id), ok
+}
+
+// redactTarget hides the credentials of a route before it is returned.
+func redactTarget(route models.NotificationRoute) models.NotificationRoute {
+	if route.Target.BotToken != "" {
+		route.Target.BotToken = "********"
+	}
+	if route.Target.Secret != "" {
+		route.Target.Secret = "********"
+	}
+	return route
+}
+
+// GetRoutes lists the user's notification routes.
+func (h *NotificationHandler) GetRoutes(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user ID not found in context"})
+		return
+	}
+
+	routes, err := h.Repo.GetRoutesByUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get notification routes", "error": err.Error()})
+		return
+	}
+	for i := range routes {
+		routes[i] = redactTarget(routes[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"routes": routes})
+}
+
+// CreateRoute adds a notification route for the user. Events defaults to all
+// events and min_severity to INFO.
+func (h *NotificationHandler) CreateRoute(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user ID not found in context"})
+		return
+	}
+
+	var req createRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	route := models.NotificationRoute{
+		UserID:      userID,
+		Channel:     req.Channel,
+		Target:      req.Target,
+		Events:      req.Events,
+		MinSeverity: req.MinSeverity,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+	}
+	if route.Events == nil {
+		route.Events = []string{}
+	}
+	if route.MinSeverity == "" {
+		route.MinSeverity = notify.SeverityInfo
+	}
+	if err := notify.ValidateRoute(route); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.Repo.CreateRoute(c.Request.Context(), &route); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to create notification route", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"route": redactTarget(route)})
+}
+
+// DeleteRoute removes one of the user's notification routes.
+func (h *NotificationHandler) DeleteRoute(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user ID not found in context"})
+		return
+	}
+	var id int64
+	if _, err := fmt.Sscan(c.Param("id"), &id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID parameter"})
+		return
+	}
+
+	if err := h.Repo.DeleteRoute(c.Request.Context(), userID, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "notification route not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to delete notification route", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "notification route deleted"})
+}
+
+// TestRoute sends a test message over one of the user's routes right away.
+func (h *NotificationHandler) TestRoute(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user ID not found in context"})
+		return
+	}
+	var id int64
+	if _, err := fmt.Sscan(c.Param("id"), &id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID parameter"})
+		return
+	}
+
+	route, err := h.Repo.GetRoute(c.Request.Context(), userID, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "notification route not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get notification route", "error": err.Error()})
+		return
+	}
+
+	msg := notify.Message{
+		Event:    "TEST",
+		Severity: notify.SeverityInfo,
+		Title:    "Test notification",
+		Text:     "Notifications over this route are working.",
+		Time:     time.Now(),
+	}
+	if err := h.Dispatcher.Send(c.Request.Context(), *route, msg); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"message": "failed to send test notification", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "test notification sent"})
+}
+
+// GetNotifications returns the user's notification history, newest first.
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user ID not found in context"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	resp, err := h.Repo.GetNotifications(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get notifications", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"notifications": resp.Notifications, "total_count": resp.TotalCount})
+}
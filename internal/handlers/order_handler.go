@@ -11,7 +11,7 @@ import (
 
 
 type OrderHandler struct {
-	OrderRepo *repository.OrderRepository
+	OrderRepo repository.OrderStore
 }
 
 func (h *OrderHandler) GetAllOrders(c *gin.Context) {
@@ -42,4 +42,21 @@ func (h *OrderHandler) GetStockOrders(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{"orders": orders.Orders, "total_count": orders.TotalCount})
-}
\ No newline at end of file
+}
+func (h *OrderHandler) GetPositionAdjustments(c *gin.Context) {
+	idParam := c.Param("id")
+
+	var id int64
+	_, err := fmt.Sscan(idParam, &id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+	adjustments, err := h.OrderRepo.GetPositionAdjustments(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get position adjustments", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"adjustments": adjustments})
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/alerts"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/app"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/order"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const maxAlertBody = 16 << 10
+
+type SignalWebhookHandler struct {
+	AlertRepo repository.SignalAlertStore
+	Runtime   *app.Runtime
+
+	// mu serializes the duplicate check with acting on the alert.
+	mu sync.Mutex
+}
+
+// Webhook turns a signed external alert into a manual entry or exit on a
+// tracked stock. The alert goes through the OrderEngine's manual path, so
+// the same locks, trade limits and margin sizing apply. Every alert is logged
+// in signal_alerts with the reason it was accepted or rejected.
+func (h *SignalWebhookHandler) Webhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAlertBody+1))
+	if err != nil || len(body) > maxAlertBody {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "alert body is too large"})
+		return
+	}
+
+	record := &models.SignalAlert{Status: models.AlertRejected}
+	if json.Valid(body) {
+		record.Payload = body
+	}
+	reject := func(status int, reason string) {
+		record.Reason = &reason
+		h.saveAlert(c, record)
+		log.Printf("🚫 Signal alert %s %s %s rejected: %s", record.AlertID, record.Side, record.TradingSymbol, reason)
+		c.JSON(status, gin.H{"message": "alert rejected", "error": reason})
+	}
+
+	key, err := h.AlertRepo.GetKeyByKeyID(ctx, c.GetHeader(alerts.HeaderKeyID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			reject(http.StatusUnauthorized, "unknown signal key")
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get signal key", "error": err.Error()})
+		return
+	}
+	record.UserID = &key.UserID
+
+	timestamp := c.GetHeader(alerts.HeaderTimestamp)
+	if err := alerts.Verify(key.Secret, timestamp, c.GetHeader(alerts.HeaderSignature), body,
+		time.Now(), config.Current().SignalWebhookTolerance); err != nil {
+		reject(http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	payload, err := alerts.Parse(body)
+	record.AlertID = alerts.DedupKey(payload, timestamp, body)
+	// A rejected alert may carry anything; keep it within the log's columns.
+	record.TradingSymbol = truncate(payload.Symbol, 50)
+	record.Side = truncate(payload.Side, 4)
+	if err != nil {
+		reject(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	seen, err := h.AlertRepo.IsAlertAccepted(ctx, key.UserID, record.AlertID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to check for duplicate alert", "error": err.Error()})
+		return
+	}
+	if seen {
+		record.Status = models.AlertDuplicate
+		reason := "alert was already accepted"
+		record.Reason = &reason
+		h.saveAlert(c, record)
+		log.Printf("🔁 Duplicate signal alert %s for %s ignored", record.AlertID, payload.Symbol)
+		c.JSON(http.StatusConflict, gin.H{"message": "duplicate alert", "alert_id": record.AlertID})
+		return
+	}
+
+	if !h.Runtime.KiteReady || h.Runtime.OrderEngine == nil || h.Runtime.TrackingManager == nil {
+		reject(http.StatusServiceUnavailable, "kite runtime is not ready")
+		return
+	}
+	stock, ok := h.Runtime.TrackingManager.GetStockByTradingSymbol(payload.Symbol)
+	if !ok {
+		reject(http.StatusNotFound, payload.Symbol+" is not being tracked")
+		return
+	}
+	record.TrackingStockID = &stock.ID
+
+	if payload.Side == alerts.SideExit {
+		record.Quantity = payload.Qty
+		err = h.Runtime.OrderEngine.ManualExit(stock.ID, order.ManualExitRequest{
+			Quantity:   payload.Qty,
+			LimitPrice: payload.Price,
+			Source:     models.OrderSourceWebhook,
+		})
+	} else {
+		qty := payload.Qty
+		if qty == 0 {
+			stopLoss := payload.StopLoss
+			if stopLoss <= 0 {
+				stopLoss = stock.StopLoss
+			}
+			qty = alerts.SizeFromRisk(payload.Risk, stopLoss)
+			if qty == 0 {
+				reject(http.StatusBadRequest, "risk is too small for one share at the stoploss")
+				return
+			}
+		}
+		record.Quantity = qty
+		err = h.Runtime.OrderEngine.ManualEntry(stock.ID, order.ManualEntryRequest{
+			Direction:  payload.Side,
+			Quantity:   qty,
+			LimitPrice: payload.Price,
+			Target:     payload.Target,
+			StopLoss:   payload.StopLoss,
+			Source:     models.OrderSourceWebhook,
+		})
+	}
+	if err != nil {
+		reject(orderEngineErrStatus(err), err.Error())
+		return
+	}
+
+	record.Status = models.AlertAccepted
+	h.saveAlert(c, record)
+	log.Printf("📨 Signal alert %s accepted: %s %s qty=%d", record.AlertID, payload.Side, payload.Symbol, record.Quantity)
+	c.JSON(http.StatusAccepted, gin.H{"message": "alert accepted", "alert_id": record.AlertID, "quantity": record.Quantity})
+}
+
+func (h *SignalWebhookHandler) saveAlert(c *gin.Context, record *models.SignalAlert) {
+	if _, err := h.AlertRepo.AddAlert(c.Request.Context(), record); err != nil {
+		log.Printf("⚠️ Failed to log signal alert %s: %v", record.AlertID, err)
+	}
+}
+
+// RotateKey issues a new webhook key for the user, replacing the old one.
+// The secret is only ever returned here.
+func (h *SignalWebhookHandler) RotateKey(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user ID not found in context"})
+		return
+	}
+
+	keyID, secret, err := alerts.NewKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to generate signal key", "error": err.Error()})
+		return
+	}
+	key := &models.SignalWebhookKey{UserID: userID, KeyID: keyID, Secret: secret}
+	if err := h.AlertRepo.SaveKey(c.Request.Context(), key); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to save signal key", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"key": key})
+}
+
+// GetAlerts returns the user's alert log, newest first.
+func (h *SignalWebhookHandler) GetAlerts(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user ID not found in context"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	resp, err := h.AlertRepo.GetAlerts(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get signal alerts", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"alerts": resp.Alerts, "total_count": resp.TotalCount})
+}
+
+// truncate cuts s to at most n bytes.
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/stream"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	streamBuffer    = 64
+	streamHeartbeat = 15 * time.Second
+)
+
+type StreamHandler struct {
+	Hub *stream.Hub
+}
+
+// Stream pushes live events to the client as Server-Sent Events. The topics
+// query parameter is a comma-separated list of topics; without it the client
+// gets all of them. Each event is named after its topic.
+func (h *StreamHandler) Stream(c *gin.Context) {
+	topics := stream.Topics
+	if param := c.Query("topics"); param != "" {
+		topics = nil
+		for _, t := range strings.Split(param, ",") {
+			t = strings.TrimSpace(t)
+			if !stream.IsTopic(t) {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "Unknown topic " + t, "topics": stream.Topics})
+				return
+			}
+			topics = append(topics, t)
+		}
+	}
+
+	sub := h.Hub.Subscribe(topics, streamBuffer)
+	defer h.Hub.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-sub.C:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Topic, event)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		}
+	})
+}
@@ -1,33 +1,175 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/app"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/database"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/kite"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/scheduler"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/services"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type SystemHandler struct {
 	InstrumentService *services.InstrumentService
 	Kc                *kite.KiteClient
 	Runtime           *app.Runtime
+	DB                *pgxpool.Pool
+
+	// The Kite token check is a profile API call; its result is reused for a while.
+	authMu        sync.Mutex
+	authOK        bool
+	authCheckedAt time.Time
+}
+
+// kiteAuthTTL is how long a Kite token check is reused by the status endpoint.
+const kiteAuthTTL = time.Minute
+
+type DatabaseStatus struct {
+	OK            bool    `json:"ok"`
+	LatencyMs     float64 `json:"latency_ms"`
+	Error         string  `json:"error,omitempty"`
+	TotalConns    int32   `json:"total_conns"`
+	IdleConns     int32   `json:"idle_conns"`
+	AcquiredConns int32   `json:"acquired_conns"`
+	// Applied schema_migrations version and the newest one this build embeds
+	SchemaVersion   int64 `json:"schema_version"`
+	LatestMigration int64 `json:"latest_migration"`
 }
 
 type SystemStatusResponse struct {
-	KiteAuthenticated bool `json:"kite_authenticated"`
-	TotalInstruments  int  `json:"total_instruments"`
-	IsRuntimeReady    bool `json:"is_runtime_ready"`
+	KiteAuthenticated bool              `json:"kite_authenticated"`
+	TotalInstruments  int               `json:"total_instruments"`
+	IsRuntimeReady    bool              `json:"is_runtime_ready"`
+	Runtime           app.RuntimeStatus `json:"runtime"`
+	Database          DatabaseStatus    `json:"database"`
 }
 
 func (h *SystemHandler) SystemStatus(c *gin.Context) {
-	isKiteAuth := h.Kc.IsTokenValid()
 	status := SystemStatusResponse{
-		KiteAuthenticated: isKiteAuth,
+		KiteAuthenticated: h.kiteAuthenticated(),
 		TotalInstruments:  len(h.InstrumentService.NSEInstruments),
 		IsRuntimeReady:    h.Runtime.KiteReady,
+		Runtime:           h.Runtime.Status(),
+		Database:          h.databaseStatus(c.Request.Context()),
 	}
 
 	c.JSON(http.StatusOK, gin.H{"status": status})
 }
+
+func (h *SystemHandler) kiteAuthenticated() bool {
+	h.authMu.Lock()
+	defer h.authMu.Unlock()
+	if time.Since(h.authCheckedAt) > kiteAuthTTL {
+		h.authOK = h.Kc.IsTokenValid()
+		h.authCheckedAt = time.Now()
+	}
+	return h.authOK
+}
+
+func (h *SystemHandler) databaseStatus(ctx context.Context) DatabaseStatus {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	err := h.DB.Ping(ctx)
+	stat := h.DB.Stat()
+	status := DatabaseStatus{
+		OK:            err == nil,
+		LatencyMs:     float64(time.Since(start).Microseconds()) / 1000,
+		TotalConns:    stat.TotalConns(),
+		IdleConns:     stat.IdleConns(),
+		AcquiredConns: stat.AcquiredConns(),
+	}
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	if status.SchemaVersion, err = database.SchemaVersion(ctx, h.DB); err != nil {
+		status.Error = err.Error()
+	}
+	if migrations, err := database.LoadMigrations(); err == nil && len(migrations) > 0 {
+		status.LatestMigration = migrations[len(migrations)-1].Version
+	}
+	return status
+}
+
+// Healthz is the liveness probe: the process is up and serving HTTP.
+func (h *SystemHandler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz is the readiness probe: the server can handle requests, which needs
+// the database. Kite is not required, since logging in to Kite goes through
+// this server.
+func (h *SystemHandler) Readyz(c *gin.Context) {
+	db := h.databaseStatus(c.Request.Context())
+	checks := gin.H{"database": "ok"}
+	if !db.OK {
+		checks["database"] = db.Error
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": checks})
+}
+
+// StateReport returns how the tracking state was restored on the last startup
+// and where it disagreed with the broker.
+func (h *SystemHandler) StateReport(c *gin.Context) {
+	report := h.Runtime.StateReport()
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Tracking state has not been restored yet"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// Jobs lists the scheduler's jobs with their next run and last outcome.
+func (h *SystemHandler) Jobs(c *gin.Context) {
+	if h.Runtime.Scheduler == nil {
+		c.JSON(http.StatusOK, gin.H{"jobs": []scheduler.JobStatus{}})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": h.Runtime.Scheduler.Status()})
+}
+
+// RunJob runs a scheduler job now and waits for it to finish.
+func (h *SystemHandler) RunJob(c *gin.Context) {
+	if h.Runtime.Scheduler == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "scheduler is not running"})
+		return
+	}
+	name := c.Param("name")
+	err := h.Runtime.Scheduler.RunJobNow(name)
+	if errors.Is(err, scheduler.ErrUnknownJob) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "job not found", "error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "job failed", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "job completed", "job": name})
+}
+
+// Reconcile compares the tracking state with the broker now, releasing stale
+// locks and pending exits, and returns the updated state report.
+func (h *SystemHandler) Reconcile(c *gin.Context) {
+	report, err := app.ReconcileState(h.Runtime)
+	if errors.Is(err, app.ErrKiteNotReady) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "Kite is not ready", "error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to reconcile tracking state", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
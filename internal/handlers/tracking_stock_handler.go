@@ -11,13 +11,14 @@ import (
 
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/app"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/order"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/tracking"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/utils"
 )
 
 type TrackingStockHandler struct {
-	TrackingStockRepo *repository.TrackingStocksRepository
+	TrackingStockRepo repository.TrackingStockStore
 	Runtime           *app.Runtime
 }
 
@@ -29,6 +30,7 @@ type NewStock struct {
 	StopLoss        float64 `json:"stoploss" binding:"required"`
 	OrderPriceLimit float64 `json:"order_price_limit" binding:"required"`
 	Quantity        uint32  `json:"quantity" binding:"required"`
+	AllowedTrades   uint32  `json:"allowed_trades"` // Entries allowed per day, defaults to 1
 	Status          string  `json:"status" binding:"required"`
 }
 
@@ -61,8 +63,12 @@ func (h *TrackingStockHandler) Add(c *gin.Context) {
 		StopLoss:        req.StopLoss,
 		OrderPriceLimit: req.OrderPriceLimit,
 		Quantity:        req.Quantity,
+		AllowedTrades:   req.AllowedTrades,
 		Status:          req.Status,
 	}
+	if newTrackingStock.AllowedTrades == 0 {
+		newTrackingStock.AllowedTrades = 1
+	}
 
 	var marketOpen = utils.IsTradingDay()
 
@@ -89,19 +95,19 @@ func (h *TrackingStockHandler) Add(c *gin.Context) {
 			OrderPriceLimit:     newTrackingStock.OrderPriceLimit,
 			BuyQuantity:         0,
 			SellQuantity:        0,
-			MaxExecutableOrders: 1, // Default to 1, can be updated later via Update endpoint
+			MaxExecutableOrders: newTrackingStock.AllowedTrades,
 			Locked:              false,
 			Exchange:            newTrackingStock.Exchange,
 		}
 
-		baseLTP, err := h.Runtime.KiteClient.KiteConnect.GetLTP(newTrackingStock.TradingSymbol)
+		baseLTP, err := h.Runtime.Broker.GetLTP(newTrackingStock.TradingSymbol)
 		if err != nil {
 			log.Printf("Error getting LTP for tracking stock: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get LTP for tracking stock", "error": err.Error()})
 			return
 		}
 
-		trackingStock.BasePrice = baseLTP[newTrackingStock.TradingSymbol].LastPrice
+		trackingStock.BasePrice = baseLTP[newTrackingStock.TradingSymbol]
 		if !h.Runtime.TrackingManager.AddTrackingStock(trackingStock) {
 			log.Printf("Error adding tracking stock to manager: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to add tracking stock to manager due volatility filter"})
@@ -346,3 +352,220 @@ func (h *TrackingStockHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "tracking stock deleted successfully"})
 }
+
+type ManualEntry struct {
+	Direction string  `json:"direction" binding:"required,oneof=BUY SELL"`
+	Quantity  uint32  `json:"quantity" binding:"required,gt=0"`
+	OrderType string  `json:"order_type" binding:"required,oneof=LIMIT MARKET"`
+	Price     float64 `json:"price"`
+	Target    float64 `json:"target"`
+	StopLoss  float64 `json:"stoploss"`
+}
+
+type ManualExit struct {
+	Quantity  uint32  `json:"quantity"` // 0 closes the whole position
+	OrderType string  `json:"order_type" binding:"required,oneof=LIMIT MARKET"`
+	Price     float64 `json:"price"`
+}
+
+// Enter opens a manual position on a tracked stock through the OrderEngine.
+func (h *TrackingStockHandler) Enter(c *gin.Context) {
+	idParam := c.Param("id")
+	var id int64
+	_, err := fmt.Sscan(idParam, &id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	var req ManualEntry
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.OrderType == "LIMIT" && req.Price <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "price is required for a LIMIT order"})
+		return
+	}
+	if req.OrderType == "MARKET" {
+		req.Price = 0
+	}
+
+	if !h.Runtime.KiteReady || h.Runtime.OrderEngine == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "kite runtime is not ready"})
+		return
+	}
+
+	err = h.Runtime.OrderEngine.ManualEntry(id, order.ManualEntryRequest{
+		Direction:  req.Direction,
+		Quantity:   req.Quantity,
+		LimitPrice: req.Price,
+		Target:     req.Target,
+		StopLoss:   req.StopLoss,
+	})
+	if err != nil {
+		log.Printf("Manual entry rejected for tracking stock %d: %v", id, err)
+		c.JSON(orderEngineErrStatus(err), gin.H{"message": "manual entry rejected", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "manual entry submitted"})
+}
+
+// Exit closes all or part of the open position on a tracked stock through the OrderEngine.
+func (h *TrackingStockHandler) Exit(c *gin.Context) {
+	idParam := c.Param("id")
+	var id int64
+	_, err := fmt.Sscan(idParam, &id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	var req ManualExit
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.OrderType == "LIMIT" && req.Price <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "price is required for a LIMIT order"})
+		return
+	}
+	if req.OrderType == "MARKET" {
+		req.Price = 0
+	}
+
+	if !h.Runtime.KiteReady || h.Runtime.OrderEngine == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "kite runtime is not ready"})
+		return
+	}
+
+	err = h.Runtime.OrderEngine.ManualExit(id, order.ManualExitRequest{
+		Quantity:   req.Quantity,
+		LimitPrice: req.Price,
+	})
+	if err != nil {
+		log.Printf("Manual exit rejected for tracking stock %d: %v", id, err)
+		c.JSON(orderEngineErrStatus(err), gin.H{"message": "manual exit rejected", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "manual exit submitted"})
+}
+
+func orderEngineErrStatus(err error) int {
+	switch {
+	case errors.Is(err, order.ErrStockNotTracked):
+		return http.StatusNotFound
+	case errors.Is(err, order.ErrEngineStopped), errors.Is(err, order.ErrEntriesClosed):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, order.ErrOutsideSession):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusConflict
+	}
+}
+
+type PositionUpdate struct {
+	Target           *float64 `json:"target"`
+	StopLoss         *float64 `json:"stoploss"`
+	TrailingStopLoss *float64 `json:"trailing_stoploss"` // 0 turns trailing off
+}
+
+// AdjustPosition changes the target, stoploss or trailing mode of the open position on a tracked stock.
+func (h *TrackingStockHandler) AdjustPosition(c *gin.Context) {
+	idParam := c.Param("id")
+	var id int64
+	_, err := fmt.Sscan(idParam, &id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	var req PositionUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Target == nil && req.StopLoss == nil && req.TrailingStopLoss == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "one of target, stoploss or trailing_stoploss is required"})
+		return
+	}
+
+	if !h.Runtime.KiteReady || h.Runtime.OrderEngine == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "kite runtime is not ready"})
+		return
+	}
+
+	adjustment, err := h.Runtime.OrderEngine.AdjustPosition(id, order.PositionAdjustment{
+		Target:           req.Target,
+		StopLoss:         req.StopLoss,
+		TrailingStopLoss: req.TrailingStopLoss,
+	})
+	if err != nil {
+		log.Printf("Position adjustment rejected for tracking stock %d: %v", id, err)
+		c.JSON(orderEngineErrStatus(err), gin.H{"message": "position adjustment rejected", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, adjustment)
+}
+
+type AdoptPosition struct {
+	BasePrice        float64 `json:"base_price"` // 0 uses the broker's average price
+	Target           float64 `json:"target"`
+	StopLoss         float64 `json:"stoploss" binding:"required"`
+	TrailingStopLoss float64 `json:"trailing_stoploss"`
+}
+
+// Positions lists open broker MIS positions and whether the bot is managing them.
+func (h *TrackingStockHandler) Positions(c *gin.Context) {
+	if !h.Runtime.KiteReady || h.Runtime.OrderEngine == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "kite runtime is not ready"})
+		return
+	}
+
+	positions, err := h.Runtime.OrderEngine.BrokerPositions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get positions", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"positions": positions})
+}
+
+// Adopt hands an open broker position on a tracked stock over to the bot.
+func (h *TrackingStockHandler) Adopt(c *gin.Context) {
+	idParam := c.Param("id")
+	var id int64
+	_, err := fmt.Sscan(idParam, &id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	var req AdoptPosition
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !h.Runtime.KiteReady || h.Runtime.OrderEngine == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "kite runtime is not ready"})
+		return
+	}
+
+	adoption, err := h.Runtime.OrderEngine.AdoptPosition(id, order.AdoptRequest{
+		BasePrice:        req.BasePrice,
+		Target:           req.Target,
+		StopLoss:         req.StopLoss,
+		TrailingStopLoss: req.TrailingStopLoss,
+	})
+	if err != nil {
+		log.Printf("Position adoption rejected for tracking stock %d: %v", id, err)
+		c.JSON(orderEngineErrStatus(err), gin.H{"message": "position adoption rejected", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, adoption)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/app"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository/memory"
+	"github.com/gin-gonic/gin"
+)
+
+func newTrackingStockRouter() (*gin.Engine, *memory.DB) {
+	gin.SetMode(gin.TestMode)
+	db := memory.New()
+	h := &TrackingStockHandler{TrackingStockRepo: db.TrackingStocks(), Runtime: &app.Runtime{}}
+
+	r := gin.New()
+	r.POST("/stocks", h.Add)
+	r.GET("/stocks", h.GetAll)
+	r.GET("/stocks/:id", h.GetDetail)
+	r.PUT("/stocks/:id", h.Update)
+	r.DELETE("/stocks/:id", h.Delete)
+	return r, db
+}
+
+func serve(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestTrackingStockLifecycle(t *testing.T) {
+	r, _ := newTrackingStockRouter()
+	const infy = `{"trading_symbol":"INFY","exchange":"NSE","instrument_token":408065,"target":10,"stoploss":5,"order_price_limit":20000,"quantity":10,"allowed_trades":2,"status":"INACTIVE"}`
+
+	w := serve(r, http.MethodPost, "/stocks", infy)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST status = %d, body %s", w.Code, w.Body)
+	}
+	if w := serve(r, http.MethodPost, "/stocks", infy); w.Code != http.StatusBadRequest {
+		t.Errorf("duplicate POST status = %d, want 400", w.Code)
+	}
+
+	w = serve(r, http.MethodPut, "/stocks/1", `{"target":12,"stoploss":6,"quantity":15,"order_price_limit":30000,"allowed_trades":3}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, body %s", w.Code, w.Body)
+	}
+
+	w = serve(r, http.MethodGet, "/stocks/1", "")
+	var got models.TrackingStock
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("GET body %s: %v", w.Body, err)
+	}
+	if got.Target != 12 || got.Quantity != 15 || got.AllowedTrades != 3 || got.Exchange != "NSE" {
+		t.Errorf("GET = %+v", got)
+	}
+
+	if w := serve(r, http.MethodDelete, "/stocks/1", ""); w.Code != http.StatusOK {
+		t.Fatalf("DELETE status = %d, body %s", w.Code, w.Body)
+	}
+	w = serve(r, http.MethodGet, "/stocks", "")
+	if body := strings.TrimSpace(w.Body.String()); body != "null" && body != "[]" {
+		t.Errorf("GET after delete = %s, want no stocks", body)
+	}
+}
+
+func TestTrackingStockAddDefaultsAllowedTrades(t *testing.T) {
+	r, db := newTrackingStockRouter()
+
+	w := serve(r, http.MethodPost, "/stocks", `{"trading_symbol":"TCS","exchange":"NSE","instrument_token":2953217,"target":20,"stoploss":8,"order_price_limit":20000,"quantity":5,"status":"INACTIVE"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST status = %d, body %s", w.Code, w.Body)
+	}
+	stock, err := db.TrackingStocks().GetTrackingStockByTradingSymbol(t.Context(), "TCS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stock.AllowedTrades != 1 {
+		t.Errorf("AllowedTrades = %d, want the default 1", stock.AllowedTrades)
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type TradeHandler struct {
+	TradeRepo repository.TradeStore
+	LogRepo   repository.LogStore
+}
+
+// tradeDateLayout is the format of the from and to query parameters.
+const tradeDateLayout = "2006-01-02"
+
+// parseDateRange reads the from and to query parameters as IST dates. The
+// returned range is [from, to+1 day); a missing bound is the zero time.
+func parseDateRange(c *gin.Context) (from, to time.Time, err error) {
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	if param := c.Query("from"); param != "" {
+		from, err = time.ParseInLocation(tradeDateLayout, param, ist)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid from date, expected YYYY-MM-DD")
+		}
+	}
+	if param := c.Query("to"); param != "" {
+		to, err = time.ParseInLocation(tradeDateLayout, param, ist)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid to date, expected YYYY-MM-DD")
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	return from, to, nil
+}
+
+// GetTrades lists round-trip trades. Filters: from and to (opened date,
+// YYYY-MM-DD, to inclusive), tracking_stock_id, symbol, direction, status,
+// exit_reason, source, page and limit.
+func (h *TradeHandler) GetTrades(c *gin.Context) {
+	filter := repository.TradeFilter{
+		TradingSymbol: c.Query("symbol"),
+		Direction:     c.Query("direction"),
+		Status:        c.Query("status"),
+		ExitReason:    c.Query("exit_reason"),
+		Source:        c.Query("source"),
+	}
+	filter.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	filter.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	var err error
+	filter.From, filter.To, err = parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if idParam := c.Query("tracking_stock_id"); idParam != "" {
+		if _, err = fmt.Sscan(idParam, &filter.TrackingStockID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tracking_stock_id parameter"})
+			return
+		}
+	}
+
+	trades, err := h.TradeRepo.GetTrades(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get trades", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"trades": trades.Trades, "total_count": trades.TotalCount})
+}
+
+// GetTrade returns one trade with the orders that make up its entry and exit.
+func (h *TradeHandler) GetTrade(c *gin.Context) {
+	idParam := c.Param("id")
+
+	var id int64
+	_, err := fmt.Sscan(idParam, &id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	trade, err := h.TradeRepo.GetTradeByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Trade not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get trade", "error": err.Error()})
+		return
+	}
+	trade.Orders, err = h.TradeRepo.GetTradeOrders(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get trade orders", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"trade": trade})
+}
+
+// GetTradeLogs returns a trade's log trail: every structured log line from
+// the signals behind its orders through their placement and fills.
+func (h *TradeHandler) GetTradeLogs(c *gin.Context) {
+	idParam := c.Param("id")
+
+	var id int64
+	_, err := fmt.Sscan(idParam, &id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	if _, err := h.TradeRepo.GetTradeByID(c.Request.Context(), id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Trade not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get trade", "error": err.Error()})
+		return
+	}
+
+	logs, err := h.LogRepo.GetTradeLogTrail(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to get trade logs", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"logs": logs})
+}
@@ -0,0 +1,203 @@
+package kcbroker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/kite"
+	kcws "github.com/SM-Sclass/stock_client2-go_backend/internal/kite/ws"
+	kiteconnect "github.com/zerodha/gokiteconnect/v4"
+)
+
+// KiteBroker adapts KiteClient and the Kite ticker to broker.Broker. All orders
+// are placed with the regular variety.
+type KiteBroker struct {
+	Client *kite.KiteClient
+}
+
+func NewKiteBroker(client *kite.KiteClient) *KiteBroker {
+	return &KiteBroker{Client: client}
+}
+
+var _ broker.Broker = (*KiteBroker)(nil)
+
+func (b *KiteBroker) PlaceOrder(params broker.OrderParams) (string, error) {
+	resp, err := b.Client.PlaceRegularOrder(kite.ToKiteOrderParams(params))
+	if err != nil {
+		return "", err
+	}
+	return resp.OrderID, nil
+}
+
+func (b *KiteBroker) ModifyOrder(orderID string, params broker.OrderParams) error {
+	_, err := b.Client.ModifyRegularOrder(orderID, kite.ToKiteOrderParams(params))
+	return err
+}
+
+func (b *KiteBroker) CancelOrder(orderID string) error {
+	_, err := b.Client.CancelRegularOrder(orderID)
+	return err
+}
+
+func (b *KiteBroker) GetOrders() ([]broker.Order, error) {
+	orders, err := b.Client.GetOrders()
+	if err != nil {
+		return nil, err
+	}
+	return toBrokerOrders(orders), nil
+}
+
+func (b *KiteBroker) GetOrderHistory(orderID string) ([]broker.Order, error) {
+	history, err := b.Client.GetOrderHistory(orderID)
+	if err != nil {
+		return nil, err
+	}
+	return toBrokerOrders(history), nil
+}
+
+func (b *KiteBroker) GetOrderFills(orderID string) ([]broker.Fill, error) {
+	trades, err := b.Client.KiteConnect.GetOrderTrades(orderID)
+	if err != nil {
+		return nil, err
+	}
+	fills := make([]broker.Fill, 0, len(trades))
+	for _, t := range trades {
+		fills = append(fills, broker.Fill{
+			TradeID:         t.TradeID,
+			OrderID:         t.OrderID,
+			Exchange:        t.Exchange,
+			TradingSymbol:   t.TradingSymbol,
+			InstrumentToken: t.InstrumentToken,
+			TransactionType: t.TransactionType,
+			Product:         t.Product,
+			Quantity:        t.Quantity,
+			Price:           t.AveragePrice,
+			FilledAt:        t.FillTimestamp.Time,
+		})
+	}
+	return fills, nil
+}
+
+func (b *KiteBroker) GetPositions() ([]broker.Position, error) {
+	positions, err := b.Client.KiteConnect.GetPositions()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]broker.Position, 0, len(positions.Net))
+	for _, p := range positions.Net {
+		result = append(result, kite.ToBrokerPosition(p))
+	}
+	return result, nil
+}
+
+func (b *KiteBroker) GetMargins() (broker.Margins, error) {
+	margins, err := b.Client.GetUserMargins()
+	if err != nil {
+		return broker.Margins{}, err
+	}
+	return broker.Margins{
+		Net:        margins.Equity.Net,
+		Cash:       margins.Equity.Available.Cash,
+		Collateral: margins.Equity.Available.Collateral,
+		Utilised:   margins.Equity.Used.Debits,
+	}, nil
+}
+
+func (b *KiteBroker) GetOrderMargin(params broker.OrderParams) (broker.OrderMargin, error) {
+	margins, err := b.Client.GetOrderMargins(kiteconnect.OrderMarginParam{
+		Exchange:        params.Exchange,
+		Tradingsymbol:   params.TradingSymbol,
+		TransactionType: params.TransactionType,
+		Variety:         kiteconnect.VarietyRegular,
+		Product:         params.Product,
+		OrderType:       params.OrderType,
+		Quantity:        float64(params.Quantity),
+		Price:           params.Price,
+		TriggerPrice:    params.TriggerPrice,
+	})
+	if err != nil {
+		return broker.OrderMargin{}, err
+	}
+	if len(margins) == 0 {
+		return broker.OrderMargin{}, fmt.Errorf("no margin data returned for %s", params.TradingSymbol)
+	}
+	return broker.OrderMargin{Total: margins[0].Total, Leverage: margins[0].Leverage}, nil
+}
+
+func (b *KiteBroker) GetQuote(instruments ...string) (map[string]broker.Quote, error) {
+	quotes, err := b.Client.GetQuote(instruments...)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]broker.Quote, len(quotes))
+	for key, q := range quotes {
+		result[key] = broker.Quote{
+			InstrumentToken: uint32(q.InstrumentToken),
+			LastPrice:       q.LastPrice,
+			BestBid:         q.Depth.Buy[0].Price,
+			BestAsk:         q.Depth.Sell[0].Price,
+			Open:            q.OHLC.Open,
+			High:            q.OHLC.High,
+			Low:             q.OHLC.Low,
+			Close:           q.OHLC.Close,
+			Volume:          q.Volume,
+		}
+	}
+	return result, nil
+}
+
+func (b *KiteBroker) GetLTP(instruments ...string) (map[string]float64, error) {
+	ltp, err := b.Client.KiteConnect.GetLTP(instruments...)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]float64, len(ltp))
+	for key, q := range ltp {
+		result[key] = q.LastPrice
+	}
+	return result, nil
+}
+
+func (b *KiteBroker) GetHistorical(instrumentToken uint32, interval string, from, to time.Time) ([]broker.Candle, error) {
+	data, err := b.Client.GetHistoricOHLC(int64(instrumentToken), interval, from, to)
+	if err != nil {
+		return nil, err
+	}
+	candles := make([]broker.Candle, 0, len(data))
+	for _, d := range data {
+		candles = append(candles, broker.Candle{
+			Time:   d.Date.Time,
+			Open:   d.Open,
+			High:   d.High,
+			Low:    d.Low,
+			Close:  d.Close,
+			Volume: d.Volume,
+		})
+	}
+	return candles, nil
+}
+
+func (b *KiteBroker) GetInstruments(exchange string) ([]broker.Instrument, error) {
+	instruments, err := b.Client.GetInstrumentsByExchange(exchange)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]broker.Instrument, 0, len(instruments))
+	for _, inst := range instruments {
+		result = append(result, kite.ToBrokerInstrument(inst))
+	}
+	return result, nil
+}
+
+func (b *KiteBroker) NewTicker(bus *broker.TickBroadcaster, onOrder func(broker.Order)) (broker.Ticker, error) {
+	return kcws.NewKiteWS(b.Client, bus, onOrder)
+}
+
+func toBrokerOrders(orders []kiteconnect.Order) []broker.Order {
+	result := make([]broker.Order, 0, len(orders))
+	for _, o := range orders {
+		result = append(result, kite.ToBrokerOrder(o))
+	}
+	return result
+}
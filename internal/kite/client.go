@@ -3,9 +3,11 @@ package kite
 import (
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/metrics"
 	kiteconnect "github.com/zerodha/gokiteconnect/v4"
 )
 
@@ -15,16 +17,23 @@ type KiteClient struct {
 	APISecret   string
 	CallbackURL string
 	AccessToken string
+	LoginURL    string
+	TickerURL   string
 }
 
 func NewKiteClient() *KiteClient {
 	kc := kiteconnect.New(config.ServerConfig.ApiKey)
+	if config.ServerConfig.KiteAPIURL != "" {
+		kc.SetBaseURI(strings.TrimSuffix(config.ServerConfig.KiteAPIURL, "/"))
+	}
 
 	return &KiteClient{
 		KiteConnect: kc,
 		APIKey:      config.ServerConfig.ApiKey,
 		APISecret:   config.ServerConfig.ApiSecret,
 		CallbackURL: config.ServerConfig.CallbackURL,
+		LoginURL:    strings.TrimSuffix(config.ServerConfig.KiteLoginURL, "/"),
+		TickerURL:   config.ServerConfig.KiteTickerURL,
 	}
 }
 
@@ -71,8 +80,23 @@ func (kc *KiteClient) EnsureAuthenticated() error {
 	return fmt.Errorf("token expired or missing")
 }
 
+// observe records the latency of a Kite API call and, when it failed, the
+// Kite error type (TokenException, OrderException, ...) it failed with.
+func observe(method string, start time.Time, errp *error) {
+	err := *errp
+	errorType := ""
+	if err != nil {
+		errorType = "Other"
+		if kerr, ok := err.(kiteconnect.Error); ok && kerr.ErrorType != "" {
+			errorType = kerr.ErrorType
+		}
+	}
+	metrics.ObserveKiteCall(method, start, err, errorType)
+}
+
 // Orders Methods
-func (kc *KiteClient) PlaceRegularOrder(orderParams kiteconnect.OrderParams) (kiteconnect.OrderResponse, error) {
+func (kc *KiteClient) PlaceRegularOrder(orderParams kiteconnect.OrderParams) (resp kiteconnect.OrderResponse, err error) {
+	defer observe("place_order", time.Now(), &err)
 	return kc.KiteConnect.PlaceOrder(kiteconnect.VarietyRegular, orderParams)
 }
 
@@ -81,11 +105,16 @@ func (kc *KiteClient) GetInstrumentsByExchange(exchange string) (kiteconnect.Ins
 }
 
 func (kc *KiteClient) GetLoginURL() string {
+	if kc.LoginURL != "" {
+		return fmt.Sprintf("%s/connect/login?api_key=%s&v=3", kc.LoginURL, kc.APIKey)
+	}
 	return kc.KiteConnect.GetLoginURL()
 }
 
 func (kc *KiteClient) IsTokenValid() bool {
+	start := time.Now()
 	_, err := kc.KiteConnect.GetUserProfile()
+	observe("get_user_profile", start, &err)
 	if err != nil {
 		return false
 	}
@@ -93,19 +122,43 @@ func (kc *KiteClient) IsTokenValid() bool {
 	return true
 }
 
-func (kc *KiteClient) GetOrders() ([]kiteconnect.Order, error) {
+func (kc *KiteClient) GetOrders() (orders []kiteconnect.Order, err error) {
+	defer observe("get_orders", time.Now(), &err)
 	return kc.KiteConnect.GetOrders()
 }
 
-func (kc *KiteClient) GetOrderHistory(orderID string) ([]kiteconnect.Order, error) {
+func (kc *KiteClient) GetOrderHistory(orderID string) (history []kiteconnect.Order, err error) {
+	defer observe("get_order_history", time.Now(), &err)
 	return kc.KiteConnect.GetOrderHistory(orderID)
 }
 
-func (kc *KiteClient) CancelRegularOrder(orderID string) (kiteconnect.OrderResponse, error) {
+func (kc *KiteClient) CancelRegularOrder(orderID string) (resp kiteconnect.OrderResponse, err error) {
+	defer observe("cancel_order", time.Now(), &err)
 	return kc.KiteConnect.CancelOrder(kiteconnect.VarietyRegular, orderID, nil)
 }
 
-func (kc *KiteClient) GetHistoricOHLC(instrumentToken int64, interval string, from time.Time, to time.Time) ([]kiteconnect.HistoricalData, error) {
+func (kc *KiteClient) ModifyRegularOrder(orderID string, orderParams kiteconnect.OrderParams) (resp kiteconnect.OrderResponse, err error) {
+	defer observe("modify_order", time.Now(), &err)
+	return kc.KiteConnect.ModifyOrder(kiteconnect.VarietyRegular, orderID, orderParams)
+}
+
+func (kc *KiteClient) GetQuote(instruments ...string) (quote kiteconnect.Quote, err error) {
+	defer observe("get_quote", time.Now(), &err)
+	return kc.KiteConnect.GetQuote(instruments...)
+}
+
+func (kc *KiteClient) GetUserMargins() (margins kiteconnect.AllMargins, err error) {
+	defer observe("get_user_margins", time.Now(), &err)
+	return kc.KiteConnect.GetUserMargins()
+}
+
+func (kc *KiteClient) GetOrderMargins(params ...kiteconnect.OrderMarginParam) (margins []kiteconnect.OrderMargins, err error) {
+	defer observe("get_order_margins", time.Now(), &err)
+	return kc.KiteConnect.GetOrderMargins(kiteconnect.GetMarginParams{OrderParams: params})
+}
+
+func (kc *KiteClient) GetHistoricOHLC(instrumentToken int64, interval string, from time.Time, to time.Time) (_ []kiteconnect.HistoricalData, err error) {
+	defer observe("get_historical_data", time.Now(), &err)
 	historicalData, err := kc.KiteConnect.GetHistoricalData(int(instrumentToken), interval, from, to, false, true)
 	if err != nil {
 		return nil, err
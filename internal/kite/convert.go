@@ -0,0 +1,110 @@
+package kite
+
+import (
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	kiteconnect "github.com/zerodha/gokiteconnect/v4"
+	kitemodels "github.com/zerodha/gokiteconnect/v4/models"
+)
+
+// ToBrokerOrder maps a Kite order (order book, history or postback) to the domain order.
+func ToBrokerOrder(o kiteconnect.Order) broker.Order {
+	return broker.Order{
+		OrderID:           o.OrderID,
+		ExchangeOrderID:   o.ExchangeOrderID,
+		ParentOrderID:     o.ParentOrderID,
+		Status:            o.Status,
+		StatusMessage:     o.StatusMessage,
+		Exchange:          o.Exchange,
+		TradingSymbol:     o.TradingSymbol,
+		InstrumentToken:   o.InstrumentToken,
+		OrderType:         o.OrderType,
+		TransactionType:   o.TransactionType,
+		Product:           o.Product,
+		Quantity:          o.Quantity,
+		Price:             o.Price,
+		TriggerPrice:      o.TriggerPrice,
+		AveragePrice:      o.AveragePrice,
+		FilledQuantity:    o.FilledQuantity,
+		PendingQuantity:   o.PendingQuantity,
+		Tag:               o.Tag,
+		OrderTimestamp:    o.OrderTimestamp.Time,
+		ExchangeTimestamp: exchangeTimestamp(o),
+	}
+}
+
+// exchangeTimestamp is the last exchange update of an order, falling back to
+// when the exchange accepted it.
+func exchangeTimestamp(o kiteconnect.Order) time.Time {
+	if !o.ExchangeUpdateTimestamp.IsZero() {
+		return o.ExchangeUpdateTimestamp.Time
+	}
+	return o.ExchangeTimestamp.Time
+}
+
+// ToKiteOrderParams maps domain order params to Kite's.
+func ToKiteOrderParams(p broker.OrderParams) kiteconnect.OrderParams {
+	return kiteconnect.OrderParams{
+		Exchange:         p.Exchange,
+		Tradingsymbol:    p.TradingSymbol,
+		TransactionType:  p.TransactionType,
+		Quantity:         p.Quantity,
+		Product:          p.Product,
+		OrderType:        p.OrderType,
+		Price:            p.Price,
+		TriggerPrice:     p.TriggerPrice,
+		Validity:         p.Validity,
+		MarketProtection: p.MarketProtection,
+		Tag:              p.Tag,
+	}
+}
+
+// ToBrokerTick maps a ticker packet to the domain tick.
+func ToBrokerTick(t kitemodels.Tick) broker.Tick {
+	return broker.Tick{
+		InstrumentToken: t.InstrumentToken,
+		LastPrice:       t.LastPrice,
+		LastQuantity:    t.LastTradedQuantity,
+		Volume:          t.VolumeTraded,
+		BestBid:         t.Depth.Buy[0].Price,
+		BestAsk:         t.Depth.Sell[0].Price,
+		Timestamp:       t.Timestamp.Time,
+	}
+}
+
+// ToBrokerInstrument maps an instrument dump row to the domain instrument.
+func ToBrokerInstrument(i kiteconnect.Instrument) broker.Instrument {
+	return broker.Instrument{
+		InstrumentToken: i.InstrumentToken,
+		ExchangeToken:   i.ExchangeToken,
+		Tradingsymbol:   i.Tradingsymbol,
+		Name:            i.Name,
+		LastPrice:       i.LastPrice,
+		Expiry:          i.Expiry.Time,
+		StrikePrice:     i.StrikePrice,
+		TickSize:        i.TickSize,
+		LotSize:         i.LotSize,
+		InstrumentType:  i.InstrumentType,
+		Segment:         i.Segment,
+		Exchange:        i.Exchange,
+	}
+}
+
+// ToBrokerPosition maps a net position to the domain position.
+func ToBrokerPosition(p kiteconnect.Position) broker.Position {
+	return broker.Position{
+		TradingSymbol:   p.Tradingsymbol,
+		Exchange:        p.Exchange,
+		InstrumentToken: p.InstrumentToken,
+		Product:         p.Product,
+		Quantity:        p.Quantity,
+		AveragePrice:    p.AveragePrice,
+		LastPrice:       p.LastPrice,
+		PnL:             p.PnL,
+		BuyQuantity:     p.BuyQuantity,
+		BuyPrice:        p.BuyPrice,
+		SellQuantity:    p.SellQuantity,
+		SellPrice:       p.SellPrice,
+	}
+}
@@ -0,0 +1,225 @@
+package kitetest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	kiteconnect "github.com/zerodha/gokiteconnect/v4"
+	kitemodels "github.com/zerodha/gokiteconnect/v4/models"
+)
+
+// Instrument is an equity in the fake instrument dump.
+type Instrument struct {
+	InstrumentToken uint32
+	Exchange        string
+	TradingSymbol   string
+	Name            string
+	LastPrice       float64
+	TickSize        float64
+	LotSize         int
+}
+
+type quote struct {
+	token    uint32
+	tickSize float64
+	last     float64
+	ohlc     kitemodels.OHLC
+	volume   int
+}
+
+// depth quotes one tick either side of the LTP.
+func (q *quote) depth() kitemodels.Depth {
+	depth := kitemodels.Depth{}
+	depth.Buy[0] = kitemodels.DepthItem{Price: q.last - q.tickSize, Quantity: 100, Orders: 1}
+	depth.Sell[0] = kitemodels.DepthItem{Price: q.last + q.tickSize, Quantity: 100, Orders: 1}
+	return depth
+}
+
+type candleKey struct {
+	token    uint32
+	interval string
+}
+
+// AddInstrument lists inst in the instrument dump and quotes it at its last
+// price, which also becomes the previous close.
+func (s *Server) AddInstrument(inst Instrument) {
+	if inst.Exchange == "" {
+		inst.Exchange = "NSE"
+	}
+	if inst.TickSize == 0 {
+		inst.TickSize = 0.05
+	}
+	if inst.LotSize == 0 {
+		inst.LotSize = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.instruments = append(s.instruments, inst)
+	s.quotes[inst.Exchange+":"+inst.TradingSymbol] = &quote{
+		token:    inst.InstrumentToken,
+		tickSize: inst.TickSize,
+		last:     inst.LastPrice,
+		ohlc:     kitemodels.OHLC{Close: inst.LastPrice},
+	}
+}
+
+// SetLTP moves the last traded price of instrument, given as EXCHANGE:SYMBOL,
+// fills the open orders the new price makes marketable and sends the tick on
+// the server's tickers.
+func (s *Server) SetLTP(instrument string, price float64) error {
+	var tick kitemodels.Tick
+	var tickers []*Ticker
+	err := s.update(func() error {
+		key := instrumentKey(instrument)
+		q, ok := s.quotes[key]
+		if !ok {
+			return fmt.Errorf("kitetest: unknown instrument %s", key)
+		}
+		q.last = price
+		q.volume++
+		if q.ohlc.Open == 0 {
+			q.ohlc.Open, q.ohlc.High, q.ohlc.Low = price, price, price
+		}
+		q.ohlc.High = max(q.ohlc.High, price)
+		q.ohlc.Low = min(q.ohlc.Low, price)
+
+		now := kitemodels.Time{Time: time.Now()}
+		tick = kitemodels.Tick{
+			InstrumentToken:    q.token,
+			IsTradable:         true,
+			Timestamp:          now,
+			LastTradeTime:      now,
+			LastPrice:          price,
+			LastTradedQuantity: 1,
+			VolumeTraded:       uint32(q.volume),
+			NetChange:          price - q.ohlc.Close,
+			OHLC:               q.ohlc,
+			Depth:              q.depth(),
+		}
+		tickers = s.tickers
+
+		s.matchOrders(key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, t := range tickers {
+		t.SendTicks(tick)
+	}
+	return nil
+}
+
+// LTP returns the last traded price of instrument.
+func (s *Server) LTP(instrument string) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, ok := s.quotes[instrumentKey(instrument)]
+	if !ok {
+		return 0, false
+	}
+	return q.last, true
+}
+
+// SetCandles sets the candles the historical API returns for the instrument
+// token at interval, such as "15minute". Requests get the candles between
+// their from and to times.
+func (s *Server) SetCandles(instrumentToken uint32, interval string, candles []broker.Candle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.candles[candleKey{token: instrumentToken, interval: interval}] = candles
+}
+
+func (s *Server) instrumentDump(w http.ResponseWriter, r *http.Request) {
+	exchange := r.PathValue("exchange")
+
+	s.mu.Lock()
+	rows := [][]string{{"instrument_token", "exchange_token", "tradingsymbol", "name", "last_price", "expiry", "strike", "tick_size", "lot_size", "instrument_type", "segment", "exchange"}}
+	for _, inst := range s.instruments {
+		if inst.Exchange != exchange {
+			continue
+		}
+		rows = append(rows, []string{
+			strconv.FormatUint(uint64(inst.InstrumentToken), 10),
+			strconv.FormatUint(uint64(inst.InstrumentToken>>8), 10),
+			inst.TradingSymbol,
+			inst.Name,
+			strconv.FormatFloat(inst.LastPrice, 'f', -1, 64),
+			"",
+			"0",
+			strconv.FormatFloat(inst.TickSize, 'f', -1, 64),
+			strconv.Itoa(inst.LotSize),
+			"EQ",
+			inst.Exchange,
+			inst.Exchange,
+		})
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/csv")
+	_ = csv.NewWriter(w).WriteAll(rows)
+}
+
+// quote serves both full quotes and LTP, which the client reads from the same
+// endpoint.
+func (s *Server) quote(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().In(s.ist).Format(kiteTimeLayout)
+	quotes := map[string]any{}
+	for _, instrument := range r.URL.Query()["i"] {
+		q, ok := s.quotes[instrumentKey(instrument)]
+		if !ok {
+			continue
+		}
+		quotes[instrument] = map[string]any{
+			"instrument_token": q.token,
+			"timestamp":        now,
+			"last_trade_time":  now,
+			"last_price":       q.last,
+			"volume":           q.volume,
+			"ohlc":             q.ohlc,
+			"net_change":       q.last - q.ohlc.Close,
+			"depth":            q.depth(),
+		}
+	}
+	writeData(w, quotes)
+}
+
+func (s *Server) historical(w http.ResponseWriter, r *http.Request) {
+	token, err := strconv.ParseUint(r.PathValue("token"), 10, 32)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, kiteconnect.InputError, "Invalid `instrument_token`.")
+		return
+	}
+	from, errFrom := time.ParseInLocation(kiteTimeLayout, r.URL.Query().Get("from"), s.ist)
+	to, errTo := time.ParseInLocation(kiteTimeLayout, r.URL.Query().Get("to"), s.ist)
+	if errFrom != nil || errTo != nil {
+		writeError(w, http.StatusBadRequest, kiteconnect.InputError, "Invalid `from` or `to` date.")
+		return
+	}
+	withOI := r.URL.Query().Get("oi") == "1"
+
+	s.mu.Lock()
+	candles := s.candles[candleKey{token: uint32(token), interval: r.PathValue("interval")}]
+	s.mu.Unlock()
+
+	rows := [][]any{}
+	for _, c := range candles {
+		if c.Time.Before(from) || c.Time.After(to) {
+			continue
+		}
+		row := []any{c.Time.In(s.ist).Format("2006-01-02T15:04:05-0700"), c.Open, c.High, c.Low, c.Close, c.Volume}
+		if withOI {
+			row = append(row, 0)
+		}
+		rows = append(rows, row)
+	}
+	writeData(w, map[string]any{"candles": rows})
+}
@@ -0,0 +1,532 @@
+package kitetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	kiteconnect "github.com/zerodha/gokiteconnect/v4"
+)
+
+// Order statuses as Kite reports them.
+const (
+	StatusOpen           = "OPEN"
+	StatusTriggerPending = "TRIGGER PENDING"
+	StatusComplete       = "COMPLETE"
+	StatusRejected       = "REJECTED"
+	StatusCancelled      = "CANCELLED"
+)
+
+// misLeverage is the intraday leverage the margin calls assume.
+const misLeverage = 5
+
+// FillMode controls when open orders fill.
+type FillMode int
+
+const (
+	// FillMarketable fills MARKET orders at the LTP and LIMIT orders once the
+	// LTP reaches their price. SL and SL-M orders trigger when the LTP
+	// crosses their trigger price.
+	FillMarketable FillMode = iota
+	// FillManual leaves orders open until Fill, Reject or a cancel.
+	FillManual
+)
+
+// SetFillMode sets when open orders fill.
+func (s *Server) SetFillMode(mode FillMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fillMode = mode
+}
+
+// SetCash sets the cash the margin calls report as available.
+func (s *Server) SetCash(cash float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cash = cash
+}
+
+// RejectNextOrder makes the next order placed come back REJECTED with reason,
+// as the RMS rejects an order after accepting it. Calls queue up.
+func (s *Server) RejectNextOrder(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejectFor = append(s.rejectFor, reason)
+}
+
+// OnOrderUpdate registers fn to be called with the order every time its
+// status changes, the way Kite sends postbacks. fn runs after the server
+// lock is released, so it may call back into the Server.
+func (s *Server) OnOrderUpdate(fn func(kiteconnect.Order)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onOrder = append(s.onOrder, fn)
+}
+
+// Fill completes an open order at price, or at the LTP when price is 0.
+func (s *Server) Fill(orderID string, price float64) error {
+	return s.update(func() error {
+		o, err := s.openOrder(orderID)
+		if err != nil {
+			return err
+		}
+		if price == 0 {
+			price = s.quotes[o.Exchange+":"+o.TradingSymbol].last
+		}
+		s.fill(o, price)
+		return nil
+	})
+}
+
+// Reject rejects an open order with reason, as the exchange would.
+func (s *Server) Reject(orderID, reason string) error {
+	return s.update(func() error {
+		o, err := s.openOrder(orderID)
+		if err != nil {
+			return err
+		}
+		o.StatusMessage = reason
+		s.setStatus(o, StatusRejected)
+		return nil
+	})
+}
+
+// Orders returns the order book in the order the orders were placed.
+func (s *Server) Orders() []kiteconnect.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	orders := make([]kiteconnect.Order, 0, len(s.orders))
+	for _, o := range s.orders {
+		orders = append(orders, *o)
+	}
+	return orders
+}
+
+// update runs fn under the lock and then sends the postbacks of the orders it
+// changed.
+func (s *Server) update(fn func() error) error {
+	s.mu.Lock()
+	err := fn()
+	updates, listeners := s.updates, s.onOrder
+	s.updates = nil
+	s.mu.Unlock()
+
+	for _, o := range updates {
+		for _, listener := range listeners {
+			listener(o)
+		}
+	}
+	return err
+}
+
+func (s *Server) findOrder(orderID string) *kiteconnect.Order {
+	for _, o := range s.orders {
+		if o.OrderID == orderID {
+			return o
+		}
+	}
+	return nil
+}
+
+func (s *Server) openOrder(orderID string) (*kiteconnect.Order, error) {
+	o := s.findOrder(orderID)
+	if o == nil {
+		return nil, fmt.Errorf("kitetest: unknown order %s", orderID)
+	}
+	if !isOpen(o) {
+		return nil, fmt.Errorf("kitetest: order %s is %s", orderID, o.Status)
+	}
+	return o, nil
+}
+
+func isOpen(o *kiteconnect.Order) bool {
+	return o.Status == StatusOpen || o.Status == StatusTriggerPending
+}
+
+// setStatus moves an order to status, adds the change to its history and
+// queues its postback.
+func (s *Server) setStatus(o *kiteconnect.Order, status string) {
+	now := time.Now().In(s.ist)
+	o.Status = status
+	o.ExchangeUpdateTimestamp.Time = now
+	o.ExchangeTimestamp.Time = now
+	s.history[o.OrderID] = append(s.history[o.OrderID], *o)
+	s.updates = append(s.updates, *o)
+}
+
+// matchOrders fills the open orders on the instrument key that its LTP now
+// makes marketable.
+func (s *Server) matchOrders(key string) {
+	if s.fillMode != FillMarketable {
+		return
+	}
+	for _, o := range s.orders {
+		if isOpen(o) && o.Exchange+":"+o.TradingSymbol == key {
+			s.tryFill(o)
+		}
+	}
+}
+
+// tryFill triggers and fills o if the LTP allows it.
+func (s *Server) tryFill(o *kiteconnect.Order) {
+	last := s.quotes[o.Exchange+":"+o.TradingSymbol].last
+	buy := o.TransactionType == kiteconnect.TransactionTypeBuy
+
+	if o.Status == StatusTriggerPending {
+		triggered := (buy && last >= o.TriggerPrice) || (!buy && last <= o.TriggerPrice)
+		if !triggered {
+			return
+		}
+		if o.OrderType == kiteconnect.OrderTypeSLM {
+			s.fill(o, last)
+			return
+		}
+		s.setStatus(o, StatusOpen)
+	}
+
+	switch o.OrderType {
+	case kiteconnect.OrderTypeMarket:
+		s.fill(o, last)
+	default:
+		if (buy && last <= o.Price) || (!buy && last >= o.Price) {
+			s.fill(o, last)
+		}
+	}
+}
+
+func (s *Server) fill(o *kiteconnect.Order, price float64) {
+	o.AveragePrice = price
+	o.FilledQuantity = o.Quantity
+	o.PendingQuantity = 0
+	s.setStatus(o, StatusComplete)
+
+	tradeID := strconv.Itoa(len(s.trades) + 1)
+	s.trades[o.OrderID] = append(s.trades[o.OrderID], kiteconnect.Trade{
+		TradeID:           tradeID,
+		OrderID:           o.OrderID,
+		ExchangeOrderID:   o.ExchangeOrderID,
+		Exchange:          o.Exchange,
+		TradingSymbol:     o.TradingSymbol,
+		InstrumentToken:   o.InstrumentToken,
+		TransactionType:   o.TransactionType,
+		Product:           o.Product,
+		Quantity:          o.Quantity,
+		AveragePrice:      price,
+		FillTimestamp:     o.ExchangeTimestamp,
+		ExchangeTimestamp: o.ExchangeTimestamp,
+	})
+}
+
+func (s *Server) placeOrder(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, kiteconnect.InputError, err.Error())
+		return
+	}
+	form := r.PostForm
+	quantity, _ := strconv.ParseFloat(form.Get("quantity"), 64)
+	price, _ := strconv.ParseFloat(form.Get("price"), 64)
+	triggerPrice, _ := strconv.ParseFloat(form.Get("trigger_price"), 64)
+
+	var orderID string
+	var inputErr string
+	_ = s.update(func() error {
+		key := form.Get("exchange") + ":" + form.Get("tradingsymbol")
+		q, ok := s.quotes[key]
+		switch {
+		case !ok:
+			inputErr = fmt.Sprintf("Instrument %s is not in the instrument dump.", key)
+		case quantity <= 0:
+			inputErr = "Quantity should be greater than 0."
+		case form.Get("transaction_type") != kiteconnect.TransactionTypeBuy && form.Get("transaction_type") != kiteconnect.TransactionTypeSell:
+			inputErr = "Invalid `transaction_type`."
+		case form.Get("order_type") == kiteconnect.OrderTypeLimit && price <= 0:
+			inputErr = "Price should be greater than 0 for a LIMIT order."
+		case (form.Get("order_type") == kiteconnect.OrderTypeSL || form.Get("order_type") == kiteconnect.OrderTypeSLM) && triggerPrice <= 0:
+			inputErr = "Trigger price should be greater than 0 for a stoploss order."
+		}
+		if inputErr != "" {
+			return nil
+		}
+
+		n := len(s.orders) + 1
+		now := time.Now().In(s.ist)
+		o := &kiteconnect.Order{
+			AccountID:       s.UserID,
+			PlacedBy:        s.UserID,
+			OrderID:         fmt.Sprintf("2510190%08d", n),
+			ExchangeOrderID: fmt.Sprintf("1100000%08d", n),
+			Variety:         r.PathValue("variety"),
+			Exchange:        form.Get("exchange"),
+			TradingSymbol:   form.Get("tradingsymbol"),
+			InstrumentToken: q.token,
+			OrderType:       form.Get("order_type"),
+			TransactionType: form.Get("transaction_type"),
+			Validity:        form.Get("validity"),
+			Product:         form.Get("product"),
+			Quantity:        quantity,
+			PendingQuantity: quantity,
+			Price:           price,
+			TriggerPrice:    triggerPrice,
+			Tag:             form.Get("tag"),
+		}
+		o.OrderTimestamp.Time = now
+		if o.Validity == "" {
+			o.Validity = kiteconnect.ValidityDay
+		}
+		s.orders = append(s.orders, o)
+		orderID = o.OrderID
+
+		if len(s.rejectFor) > 0 {
+			o.StatusMessage = s.rejectFor[0]
+			s.rejectFor = s.rejectFor[1:]
+			s.setStatus(o, StatusRejected)
+			return nil
+		}
+		if o.OrderType == kiteconnect.OrderTypeSL || o.OrderType == kiteconnect.OrderTypeSLM {
+			s.setStatus(o, StatusTriggerPending)
+		} else {
+			s.setStatus(o, StatusOpen)
+		}
+		if s.fillMode == FillMarketable {
+			s.tryFill(o)
+		}
+		return nil
+	})
+
+	if inputErr != "" {
+		writeError(w, http.StatusBadRequest, kiteconnect.InputError, inputErr)
+		return
+	}
+	writeData(w, map[string]string{"order_id": orderID})
+}
+
+func (s *Server) modifyOrder(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, kiteconnect.InputError, err.Error())
+		return
+	}
+	form := r.PostForm
+	orderID := r.PathValue("id")
+
+	status, errorType, message := http.StatusOK, "", ""
+	_ = s.update(func() error {
+		o := s.findOrder(orderID)
+		if o == nil {
+			status, errorType, message = http.StatusBadRequest, kiteconnect.InputError, "Couldn't find that `order_id`."
+			return nil
+		}
+		if !isOpen(o) {
+			status, errorType, message = http.StatusBadRequest, kiteconnect.OrderError, fmt.Sprintf("Order cannot be modified as it is in %s state.", o.Status)
+			return nil
+		}
+		if v, err := strconv.ParseFloat(form.Get("quantity"), 64); err == nil && v > 0 {
+			o.Quantity, o.PendingQuantity = v, v
+		}
+		if v, err := strconv.ParseFloat(form.Get("price"), 64); err == nil && v > 0 {
+			o.Price = v
+		}
+		if v, err := strconv.ParseFloat(form.Get("trigger_price"), 64); err == nil && v > 0 {
+			o.TriggerPrice = v
+		}
+		if v := form.Get("order_type"); v != "" {
+			o.OrderType = v
+		}
+		o.Modified = true
+		s.setStatus(o, o.Status)
+		if s.fillMode == FillMarketable {
+			s.tryFill(o)
+		}
+		return nil
+	})
+
+	if status != http.StatusOK {
+		writeError(w, status, errorType, message)
+		return
+	}
+	writeData(w, map[string]string{"order_id": orderID})
+}
+
+func (s *Server) cancelOrder(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("id")
+
+	errorType, message := "", ""
+	_ = s.update(func() error {
+		o := s.findOrder(orderID)
+		if o == nil {
+			errorType, message = kiteconnect.InputError, "Couldn't find that `order_id`."
+			return nil
+		}
+		if !isOpen(o) {
+			errorType, message = kiteconnect.OrderError, fmt.Sprintf("Order cannot be cancelled as it is in %s state.", o.Status)
+			return nil
+		}
+		o.CancelledQuantity = o.PendingQuantity
+		o.PendingQuantity = 0
+		s.setStatus(o, StatusCancelled)
+		return nil
+	})
+
+	if errorType != "" {
+		writeError(w, http.StatusBadRequest, errorType, message)
+		return
+	}
+	writeData(w, map[string]string{"order_id": orderID})
+}
+
+func (s *Server) orderBook(w http.ResponseWriter, _ *http.Request) {
+	writeData(w, s.Orders())
+}
+
+func (s *Server) orderHistory(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	history, ok := s.history[r.PathValue("id")]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusBadRequest, kiteconnect.InputError, "Couldn't find that `order_id`.")
+		return
+	}
+	writeData(w, history)
+}
+
+func (s *Server) orderTrades(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	_, ok := s.history[r.PathValue("id")]
+	trades := s.trades[r.PathValue("id")]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusBadRequest, kiteconnect.InputError, "Couldn't find that `order_id`.")
+		return
+	}
+	if trades == nil {
+		trades = []kiteconnect.Trade{}
+	}
+	writeData(w, trades)
+}
+
+// netPositions sums the filled orders into one position per instrument and
+// product, marked to the LTP.
+func (s *Server) netPositions() []kiteconnect.Position {
+	positions := []kiteconnect.Position{}
+	index := map[string]int{}
+	for _, o := range s.orders {
+		if o.Status != StatusComplete {
+			continue
+		}
+		key := o.Exchange + ":" + o.TradingSymbol + ":" + o.Product
+		i, ok := index[key]
+		if !ok {
+			i = len(positions)
+			index[key] = i
+			positions = append(positions, kiteconnect.Position{
+				Tradingsymbol:   o.TradingSymbol,
+				Exchange:        o.Exchange,
+				InstrumentToken: o.InstrumentToken,
+				Product:         o.Product,
+				Multiplier:      1,
+			})
+		}
+		p := &positions[i]
+		qty := int(o.FilledQuantity)
+		value := o.FilledQuantity * o.AveragePrice
+		if o.TransactionType == kiteconnect.TransactionTypeBuy {
+			p.BuyQuantity += qty
+			p.BuyValue += value
+		} else {
+			p.SellQuantity += qty
+			p.SellValue += value
+		}
+	}
+
+	for i := range positions {
+		p := &positions[i]
+		q := s.quotes[p.Exchange+":"+p.Tradingsymbol]
+		p.LastPrice, p.ClosePrice = q.last, q.ohlc.Close
+		if p.BuyQuantity > 0 {
+			p.BuyPrice = p.BuyValue / float64(p.BuyQuantity)
+		}
+		if p.SellQuantity > 0 {
+			p.SellPrice = p.SellValue / float64(p.SellQuantity)
+		}
+		p.Quantity = p.BuyQuantity - p.SellQuantity
+		switch {
+		case p.Quantity > 0:
+			p.AveragePrice = p.BuyPrice
+		case p.Quantity < 0:
+			p.AveragePrice = p.SellPrice
+		}
+		p.Value = p.SellValue - p.BuyValue
+		p.PnL = round2(p.Value + float64(p.Quantity)*p.LastPrice)
+		p.M2M = p.PnL
+		p.DayBuyQuantity, p.DayBuyPrice, p.DayBuyValue = p.BuyQuantity, p.BuyPrice, p.BuyValue
+		p.DaySellQuantity, p.DaySellPrice, p.DaySellValue = p.SellQuantity, p.SellPrice, p.SellValue
+	}
+	return positions
+}
+
+func (s *Server) positions(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	positions := s.netPositions()
+	s.mu.Unlock()
+
+	writeData(w, kiteconnect.Positions{Net: positions, Day: positions})
+}
+
+func (s *Server) margins(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	cash := s.cash
+	used := 0.0
+	for _, p := range s.netPositions() {
+		used += math.Abs(float64(p.Quantity)) * p.AveragePrice / misLeverage
+	}
+	s.mu.Unlock()
+
+	equity := kiteconnect.Margins{
+		Enabled:   true,
+		Net:       round2(cash - used),
+		Available: kiteconnect.AvailableMargins{Cash: cash, LiveBalance: round2(cash - used), OpeningBalance: cash},
+		Used:      kiteconnect.UsedMargins{Debits: round2(used), Span: round2(used)},
+	}
+	writeData(w, kiteconnect.AllMargins{Equity: equity})
+}
+
+func (s *Server) orderMargins(w http.ResponseWriter, r *http.Request) {
+	var params []kiteconnect.OrderMarginParam
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeError(w, http.StatusBadRequest, kiteconnect.InputError, "Invalid order margin parameters.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	margins := make([]kiteconnect.OrderMargins, 0, len(params))
+	for _, p := range params {
+		price := p.Price
+		if q, ok := s.quotes[p.Exchange+":"+p.Tradingsymbol]; ok && (price == 0 || p.OrderType == kiteconnect.OrderTypeMarket) {
+			price = q.last
+		}
+		leverage := 1.0
+		if p.Product == kiteconnect.ProductMIS {
+			leverage = misLeverage
+		}
+		total := round2(p.Quantity * price / leverage)
+		margins = append(margins, kiteconnect.OrderMargins{
+			Type:          "equity",
+			TradingSymbol: p.Tradingsymbol,
+			Exchange:      p.Exchange,
+			VAR:           total,
+			Leverage:      leverage,
+			Total:         total,
+		})
+	}
+	writeData(w, margins)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
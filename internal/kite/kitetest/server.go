@@ -0,0 +1,335 @@
+// Package kitetest runs a local HTTP server that imitates the Kite Connect
+// endpoints the app uses: login and session, profile, instruments, quotes,
+// historical candles, orders, positions and margins. Its state can be
+// scripted from a test (prices, fills, rejections, latency and API errors),
+// so the whole app can run against it without network by setting
+// KITE_API_URL and KITE_LOGIN_URL to the server's URL.
+package kitetest
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+	kiteconnect "github.com/zerodha/gokiteconnect/v4"
+)
+
+// Endpoint names, as used by FailNext and Calls. They match the method names
+// KiteClient reports in its metrics.
+const (
+	EndpointLogin        = "login"
+	EndpointSession      = "generate_session"
+	EndpointProfile      = "get_user_profile"
+	EndpointInstruments  = "get_instruments"
+	EndpointQuote        = "get_quote"
+	EndpointHistorical   = "get_historical_data"
+	EndpointOrders       = "get_orders"
+	EndpointOrderHistory = "get_order_history"
+	EndpointOrderTrades  = "get_order_trades"
+	EndpointPlaceOrder   = "place_order"
+	EndpointModifyOrder  = "modify_order"
+	EndpointCancelOrder  = "cancel_order"
+	EndpointPositions    = "get_positions"
+	EndpointMargins      = "get_user_margins"
+	EndpointOrderMargins = "get_order_margins"
+)
+
+// kiteTimeLayout is how Kite writes IST timestamps.
+const kiteTimeLayout = "2006-01-02 15:04:05"
+
+// Server is a fake Kite Connect API. Create it with NewServer and Close it
+// when done.
+type Server struct {
+	*httptest.Server
+
+	// APIKey and APISecret are the app credentials the session checksum is
+	// verified against.
+	APIKey    string
+	APISecret string
+
+	// RedirectURL is where the login page sends the browser back with a
+	// request token, like the redirect URL of a Kite Connect app.
+	RedirectURL string
+
+	// UserID and UserName are returned by the session and profile calls.
+	UserID   string
+	UserName string
+
+	mu            sync.Mutex
+	ist           *time.Location
+	accessToken   string
+	requestTokens map[string]bool
+	issued        int
+
+	instruments []Instrument
+	quotes      map[string]*quote
+	candles     map[candleKey][]broker.Candle
+
+	orders    []*kiteconnect.Order
+	history   map[string][]kiteconnect.Order
+	trades    map[string][]kiteconnect.Trade
+	fillMode  FillMode
+	rejectFor []string
+	cash      float64
+	onOrder   []func(kiteconnect.Order)
+	updates   []kiteconnect.Order
+	tickers   []*Ticker
+
+	latency time.Duration
+	faults  map[string][]fault
+	calls   map[string]int
+}
+
+type fault struct {
+	status    int
+	errorType string
+	message   string
+}
+
+// NewServer starts a fake Kite server with no instruments, ₹10,00,000 of
+// cash and orders filling as soon as they are marketable.
+func NewServer() *Server {
+	ist, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		ist = time.FixedZone("IST", 5*3600+1800)
+	}
+	s := &Server{
+		APIKey:        "kitetest_api_key",
+		APISecret:     "kitetest_api_secret",
+		UserID:        "AB1234",
+		UserName:      "Kite Test",
+		ist:           ist,
+		requestTokens: make(map[string]bool),
+		quotes:        make(map[string]*quote),
+		candles:       make(map[candleKey][]broker.Candle),
+		history:       make(map[string][]kiteconnect.Order),
+		trades:        make(map[string][]kiteconnect.Trade),
+		cash:          1000000,
+		faults:        make(map[string][]fault),
+		calls:         make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /connect/login", s.handle(EndpointLogin, false, s.login))
+	mux.HandleFunc("POST /session/token", s.handle(EndpointSession, false, s.generateSession))
+	mux.HandleFunc("GET /user/profile", s.handle(EndpointProfile, true, s.profile))
+	mux.HandleFunc("GET /user/margins", s.handle(EndpointMargins, true, s.margins))
+	mux.HandleFunc("POST /margins/orders", s.handle(EndpointOrderMargins, true, s.orderMargins))
+	mux.HandleFunc("GET /instruments/{exchange}", s.handle(EndpointInstruments, true, s.instrumentDump))
+	mux.HandleFunc("GET /instruments/historical/{token}/{interval}", s.handle(EndpointHistorical, true, s.historical))
+	mux.HandleFunc("GET /quote", s.handle(EndpointQuote, true, s.quote))
+	mux.HandleFunc("GET /orders", s.handle(EndpointOrders, true, s.orderBook))
+	mux.HandleFunc("GET /orders/{id}", s.handle(EndpointOrderHistory, true, s.orderHistory))
+	mux.HandleFunc("GET /orders/{id}/trades", s.handle(EndpointOrderTrades, true, s.orderTrades))
+	mux.HandleFunc("POST /orders/{variety}", s.handle(EndpointPlaceOrder, true, s.placeOrder))
+	mux.HandleFunc("PUT /orders/{variety}/{id}", s.handle(EndpointModifyOrder, true, s.modifyOrder))
+	mux.HandleFunc("DELETE /orders/{variety}/{id}", s.handle(EndpointCancelOrder, true, s.cancelOrder))
+	mux.HandleFunc("GET /portfolio/positions", s.handle(EndpointPositions, true, s.positions))
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// FailNext makes the next call to endpoint fail with a Kite error envelope,
+// for example FailNext(EndpointPlaceOrder, 400, "InputException", "...").
+// Calls queue up: each FailNext fails one more call.
+func (s *Server) FailNext(endpoint string, status int, errorType, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[endpoint] = append(s.faults[endpoint], fault{status: status, errorType: errorType, message: message})
+}
+
+// Calls returns how many requests endpoint has received.
+func (s *Server) Calls(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[endpoint]
+}
+
+// AccessToken returns the access token of the current session, or "" before
+// a session is generated.
+func (s *Server) AccessToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accessToken
+}
+
+// ExpireSession invalidates the access token, as Kite does every morning, so
+// authenticated calls fail with a TokenException until a new session.
+func (s *Server) ExpireSession() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accessToken = ""
+}
+
+// NewRequestToken issues a request token as a successful login would.
+func (s *Server) NewRequestToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.newRequestToken()
+}
+
+func (s *Server) newRequestToken() string {
+	s.issued++
+	token := fmt.Sprintf("request-%d", s.issued)
+	s.requestTokens[token] = true
+	return token
+}
+
+// handle wraps an endpoint with the call count, latency, scripted faults and,
+// when auth is set, the access token check.
+func (s *Server) handle(endpoint string, auth bool, fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.calls[endpoint]++
+		latency := s.latency
+		var next *fault
+		if queued := s.faults[endpoint]; len(queued) > 0 {
+			next = &queued[0]
+			s.faults[endpoint] = queued[1:]
+		}
+		valid := s.accessToken != "" && r.Header.Get("Authorization") == fmt.Sprintf("token %s:%s", s.APIKey, s.accessToken)
+		s.mu.Unlock()
+
+		if latency > 0 {
+			time.Sleep(latency)
+		}
+		if next != nil {
+			writeError(w, next.status, next.errorType, next.message)
+			return
+		}
+		if auth && !valid {
+			writeError(w, http.StatusForbidden, kiteconnect.TokenError, "Incorrect `api_key` or `access_token`.")
+			return
+		}
+		fn(w, r)
+	}
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("api_key") != s.APIKey {
+		writeError(w, http.StatusBadRequest, kiteconnect.InputError, "Invalid `api_key`.")
+		return
+	}
+
+	s.mu.Lock()
+	redirect := s.RedirectURL
+	token := s.newRequestToken()
+	s.mu.Unlock()
+
+	if redirect == "" {
+		writeData(w, map[string]string{"request_token": token})
+		return
+	}
+	target, err := url.Parse(redirect)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, kiteconnect.GeneralError, err.Error())
+		return
+	}
+	q := target.Query()
+	q.Set("request_token", token)
+	q.Set("action", "login")
+	q.Set("status", "success")
+	target.RawQuery = q.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func (s *Server) generateSession(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, kiteconnect.InputError, err.Error())
+		return
+	}
+	requestToken := r.PostForm.Get("request_token")
+	sum := sha256.Sum256([]byte(s.APIKey + requestToken + s.APISecret))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.PostForm.Get("api_key") != s.APIKey || r.PostForm.Get("checksum") != fmt.Sprintf("%x", sum) {
+		writeError(w, http.StatusForbidden, kiteconnect.TokenError, "Invalid `checksum`.")
+		return
+	}
+	if !s.requestTokens[requestToken] {
+		writeError(w, http.StatusForbidden, kiteconnect.TokenError, "Token is invalid or has expired.")
+		return
+	}
+	delete(s.requestTokens, requestToken)
+	s.accessToken = fmt.Sprintf("access-%d", s.issued)
+
+	writeData(w, map[string]any{
+		"user_id":       s.UserID,
+		"user_name":     s.UserName,
+		"user_type":     "individual",
+		"email":         "kitetest@example.com",
+		"broker":        "ZERODHA",
+		"exchanges":     []string{"NSE", "BSE"},
+		"products":      []string{"CNC", "MIS", "NRML"},
+		"order_types":   []string{"MARKET", "LIMIT", "SL", "SL-M"},
+		"api_key":       s.APIKey,
+		"access_token":  s.accessToken,
+		"public_token":  "public-" + s.accessToken,
+		"refresh_token": "",
+		"login_time":    time.Now().In(s.ist).Format(kiteTimeLayout),
+	})
+}
+
+func (s *Server) profile(w http.ResponseWriter, _ *http.Request) {
+	writeData(w, map[string]any{
+		"user_id":     s.UserID,
+		"user_name":   s.UserName,
+		"user_type":   "individual",
+		"email":       "kitetest@example.com",
+		"broker":      "ZERODHA",
+		"exchanges":   []string{"NSE", "BSE"},
+		"products":    []string{"CNC", "MIS", "NRML"},
+		"order_types": []string{"MARKET", "LIMIT", "SL", "SL-M"},
+	})
+}
+
+func writeData(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": data})
+}
+
+func writeError(w http.ResponseWriter, status int, errorType, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"status":     "error",
+		"error_type": errorType,
+		"message":    message,
+		"data":       nil,
+	})
+}
+
+// instrumentKey returns the EXCHANGE:SYMBOL key of an instrument as quotes
+// are asked for; a bare symbol is taken to be on NSE.
+func instrumentKey(instrument string) string {
+	if strings.Contains(instrument, ":") {
+		return instrument
+	}
+	return "NSE:" + instrument
+}
+
+// Configure points cfg at the server: the Kite API and login URLs and the
+// app credentials, so that kite.NewKiteClient talks to it.
+func (s *Server) Configure(cfg *config.Config) {
+	cfg.KiteAPIURL = s.URL
+	cfg.KiteLoginURL = s.URL
+	cfg.ApiKey = s.APIKey
+	cfg.ApiSecret = s.APISecret
+}
@@ -0,0 +1,236 @@
+package kitetest
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/kite"
+	kcbroker "github.com/SM-Sclass/stock_client2-go_backend/internal/kite/broker"
+	"github.com/gorilla/websocket"
+	kiteconnect "github.com/zerodha/gokiteconnect/v4"
+	kitemodels "github.com/zerodha/gokiteconnect/v4/models"
+	kiteticker "github.com/zerodha/gokiteconnect/v4/ticker"
+)
+
+// newLoggedInClient logs a KiteClient in to srv through the login redirect,
+// as the Kite callback handler does.
+func newLoggedInClient(t *testing.T, srv *Server) *kite.KiteClient {
+	t.Helper()
+	config.ServerConfig = &config.Config{TokenFilePath: filepath.Join(t.TempDir(), "token.json")}
+	srv.Configure(config.ServerConfig)
+	srv.RedirectURL = "http://localhost/api/kite/callback"
+
+	client := kite.NewKiteClient()
+	if !strings.HasPrefix(client.GetLoginURL(), srv.URL) {
+		t.Fatalf("login URL %s is not on the fake server", client.GetLoginURL())
+	}
+
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := noRedirect.Get(client.GetLoginURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || callback.Query().Get("request_token") == "" {
+		t.Fatalf("login redirect = %q, want a request token", resp.Header.Get("Location"))
+	}
+
+	if err := client.GenerateSession(callback.Query().Get("request_token")); err != nil {
+		t.Fatalf("GenerateSession() error = %v", err)
+	}
+	if !client.IsTokenValid() {
+		t.Fatal("token not valid after the session was generated")
+	}
+	return client
+}
+
+func TestServerTradesThroughKiteBroker(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddInstrument(Instrument{InstrumentToken: 408065, TradingSymbol: "INFY", Name: "INFOSYS", LastPrice: 1500})
+
+	var postbacks []string
+	srv.OnOrderUpdate(func(o kiteconnect.Order) { postbacks = append(postbacks, o.Status) })
+
+	b := kcbroker.NewKiteBroker(newLoggedInClient(t, srv))
+
+	instruments, err := b.GetInstruments("NSE")
+	if err != nil || len(instruments) != 1 || instruments[0].InstrumentToken != 408065 {
+		t.Fatalf("GetInstruments() = %+v, %v", instruments, err)
+	}
+	ltp, err := b.GetLTP("NSE:INFY")
+	if err != nil || ltp["NSE:INFY"] != 1500 {
+		t.Fatalf("GetLTP() = %v, %v", ltp, err)
+	}
+
+	orderID, err := b.PlaceOrder(broker.OrderParams{
+		Exchange: "NSE", TradingSymbol: "INFY", TransactionType: broker.TransactionTypeBuy,
+		Quantity: 10, Product: broker.ProductMIS, OrderType: broker.OrderTypeLimit, Price: 1490,
+	})
+	if err != nil {
+		t.Fatalf("PlaceOrder() error = %v", err)
+	}
+	if err := srv.SetLTP("NSE:INFY", 1489); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := b.GetOrderHistory(orderID)
+	if err != nil || len(history) != 2 || history[0].Status != StatusOpen || history[1].Status != StatusComplete {
+		t.Fatalf("GetOrderHistory() = %+v, %v", history, err)
+	}
+	if history[1].AveragePrice != 1489 || history[1].FilledQuantity != 10 {
+		t.Errorf("fill = %.2f x %.0f, want 1489 x 10", history[1].AveragePrice, history[1].FilledQuantity)
+	}
+	if strings.Join(postbacks, ",") != "OPEN,COMPLETE" {
+		t.Errorf("postbacks = %v", postbacks)
+	}
+
+	positions, err := b.GetPositions()
+	if err != nil || len(positions) != 1 || positions[0].Quantity != 10 {
+		t.Fatalf("GetPositions() = %+v, %v", positions, err)
+	}
+	margins, err := b.GetMargins()
+	if err != nil || margins.Utilised != 2978 {
+		t.Errorf("GetMargins() = %+v, %v, want 2978 utilised", margins, err)
+	}
+
+	srv.RejectNextOrder("Insufficient funds")
+	rejectedID, _ := b.PlaceOrder(broker.OrderParams{
+		Exchange: "NSE", TradingSymbol: "INFY", TransactionType: broker.TransactionTypeSell,
+		Quantity: 10, Product: broker.ProductMIS, OrderType: broker.OrderTypeMarket,
+	})
+	history, _ = b.GetOrderHistory(rejectedID)
+	if len(history) != 1 || history[0].Status != StatusRejected || history[0].StatusMessage != "Insufficient funds" {
+		t.Errorf("rejected order history = %+v", history)
+	}
+
+	srv.FailNext(EndpointPlaceOrder, http.StatusBadRequest, kiteconnect.InputError, "Markets are closed right now.")
+	_, err = b.PlaceOrder(broker.OrderParams{
+		Exchange: "NSE", TradingSymbol: "INFY", TransactionType: broker.TransactionTypeSell,
+		Quantity: 10, Product: broker.ProductMIS, OrderType: broker.OrderTypeMarket,
+	})
+	var kerr kiteconnect.Error
+	if !errors.As(err, &kerr) || kerr.ErrorType != kiteconnect.InputError {
+		t.Errorf("PlaceOrder() error = %v, want the scripted InputException", err)
+	}
+	if got := srv.Calls(EndpointPlaceOrder); got != 3 {
+		t.Errorf("place_order calls = %d, want 3", got)
+	}
+
+	srv.ExpireSession()
+	if b.Client.IsTokenValid() {
+		t.Error("token still valid after the session expired")
+	}
+}
+
+func TestServerHistoricalCandlesInRange(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddInstrument(Instrument{InstrumentToken: 2953217, TradingSymbol: "TCS", LastPrice: 3400})
+
+	ist := srv.ist
+	open := time.Date(2026, 10, 19, 9, 15, 0, 0, ist)
+	srv.SetCandles(2953217, "15minute", []broker.Candle{
+		{Time: open, Open: 3400, High: 3420, Low: 3395, Close: 3410, Volume: 1200},
+		{Time: open.Add(15 * time.Minute), Open: 3410, High: 3415, Low: 3390, Close: 3392, Volume: 900},
+	})
+
+	b := kcbroker.NewKiteBroker(newLoggedInClient(t, srv))
+	candles, err := b.GetHistorical(2953217, "15minute", open, open.Add(10*time.Minute))
+	if err != nil {
+		t.Fatalf("GetHistorical() error = %v", err)
+	}
+	if len(candles) != 1 || !candles[0].Time.Equal(open) || candles[0].High != 3420 || candles[0].Volume != 1200 {
+		t.Errorf("GetHistorical() = %+v, want the 09:15 candle", candles)
+	}
+}
+
+func TestServerTickerFollowsPricesAndOrders(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddInstrument(Instrument{InstrumentToken: 408065, TradingSymbol: "INFY", LastPrice: 1500})
+	ticker := srv.NewTicker()
+	defer ticker.Close()
+
+	b := kcbroker.NewKiteBroker(newLoggedInClient(t, srv))
+
+	ticks := make(chan kitemodels.Tick, 10)
+	orders := make(chan kiteconnect.Order, 10)
+	connected := make(chan struct{}, 1)
+	kt := kiteticker.New(srv.APIKey, srv.AccessToken())
+	u, _ := url.Parse(ticker.WebSocketURL())
+	kt.SetRootURL(*u)
+	kt.OnConnect(func() { connected <- struct{}{} })
+	kt.OnTick(func(tick kitemodels.Tick) { ticks <- tick })
+	kt.OnOrderUpdate(func(o kiteconnect.Order) { orders <- o })
+	go kt.Serve()
+	defer kt.Stop()
+
+	select {
+	case <-connected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("ticker did not connect")
+	}
+	_ = kt.Subscribe([]uint32{408065})
+	_ = kt.SetMode(kiteticker.ModeFull, []uint32{408065})
+	deadline := time.Now().Add(2 * time.Second)
+	for mode, _ := ticker.Subscribed(408065); mode != kiteticker.ModeFull; mode, _ = ticker.Subscribed(408065) {
+		if time.Now().After(deadline) {
+			t.Fatal("full mode subscription not seen")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := srv.SetLTP("NSE:INFY", 1504.4); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case tick := <-ticks:
+		if tick.Mode != string(kiteticker.ModeFull) || tick.LastPrice != 1504.4 || tick.Depth.Sell[0].Price != 1504.45 || tick.OHLC.Close != 1500 {
+			t.Errorf("tick = %+v", tick)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no tick after SetLTP")
+	}
+
+	orderID, err := b.PlaceOrder(broker.OrderParams{
+		Exchange: "NSE", TradingSymbol: "INFY", TransactionType: broker.TransactionTypeSell,
+		Quantity: 5, Product: broker.ProductMIS, OrderType: broker.OrderTypeMarket,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{StatusOpen, StatusComplete} {
+		select {
+		case o := <-orders:
+			if o.OrderID != orderID || o.Status != want {
+				t.Errorf("postback = %s %s, want %s %s", o.OrderID, o.Status, orderID, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no %s postback", want)
+		}
+	}
+}
+
+func TestTickerRefusesOtherSessions(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	ticker := srv.NewTicker()
+	defer ticker.Close()
+
+	_, resp, err := websocket.DefaultDialer.Dial(ticker.WebSocketURL()+"?api_key="+srv.APIKey+"&access_token=stale", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("dial with a stale token = %v, want a 403", err)
+	}
+	if ticker.Connects() != 0 {
+		t.Errorf("connects = %d, want 0", ticker.Connects())
+	}
+}
@@ -0,0 +1,401 @@
+package kitetest
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+	"github.com/gorilla/websocket"
+	kiteconnect "github.com/zerodha/gokiteconnect/v4"
+	kitemodels "github.com/zerodha/gokiteconnect/v4/models"
+	kiteticker "github.com/zerodha/gokiteconnect/v4/ticker"
+)
+
+// Ticker is a fake Kite ticker. It accepts websocket connections like
+// wss://ws.kite.trade, follows their subscribe and mode commands, and sends
+// ticks in the binary packet format, order postbacks and errors as text
+// messages, and a heartbeat every second. Create it with NewTicker or
+// Server.NewTicker and Close it when done.
+type Ticker struct {
+	*httptest.Server
+
+	// Authorize, when set, decides whether a connection's api_key and
+	// access_token may connect. Refused handshakes get a 403 like Kite's.
+	Authorize func(apiKey, accessToken string) bool
+
+	mu       sync.Mutex
+	conns    map[*tickerConn]struct{}
+	connects int
+	upgrader websocket.Upgrader
+}
+
+type tickerConn struct {
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+	modes   map[uint32]kiteticker.Mode
+	done    chan struct{}
+}
+
+// ScriptedTick is a tick to send After the one before it.
+type ScriptedTick struct {
+	After time.Duration
+	Tick  kitemodels.Tick
+}
+
+// NewTicker starts a fake ticker that lets every connection in.
+func NewTicker() *Ticker {
+	t := &Ticker{conns: make(map[*tickerConn]struct{})}
+	t.Server = httptest.NewServer(http.HandlerFunc(t.serve))
+	return t
+}
+
+// NewTicker starts a fake ticker that lets in only the current session of the
+// server, sends the server's order postbacks, and sends a tick whenever
+// SetLTP moves a price.
+func (s *Server) NewTicker() *Ticker {
+	t := NewTicker()
+	t.Authorize = func(apiKey, accessToken string) bool {
+		return apiKey == s.APIKey && accessToken != "" && accessToken == s.AccessToken()
+	}
+	s.OnOrderUpdate(t.SendOrderUpdate)
+
+	s.mu.Lock()
+	s.tickers = append(s.tickers, t)
+	s.mu.Unlock()
+	return t
+}
+
+// WebSocketURL returns the ws:// URL of the ticker.
+func (t *Ticker) WebSocketURL() string {
+	return "ws" + strings.TrimPrefix(t.URL, "http")
+}
+
+// Configure points cfg at the ticker, so the app's KiteWS connects to it.
+func (t *Ticker) Configure(cfg *config.Config) {
+	cfg.KiteTickerURL = t.WebSocketURL()
+}
+
+// Connects returns how many connections the ticker has accepted, counting
+// reconnections.
+func (t *Ticker) Connects() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.connects
+}
+
+// Subscribed returns the mode instrumentToken is subscribed in on any open
+// connection.
+func (t *Ticker) Subscribed(instrumentToken uint32) (kiteticker.Mode, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for c := range t.conns {
+		if mode, ok := c.modes[instrumentToken]; ok {
+			return mode, true
+		}
+	}
+	return "", false
+}
+
+// SendTicks sends each tick to the connections subscribed to its token, in
+// the mode they subscribed it in.
+func (t *Ticker) SendTicks(ticks ...kitemodels.Tick) {
+	t.mu.Lock()
+	type frame struct {
+		conn    *tickerConn
+		packets [][]byte
+	}
+	var frames []frame
+	for c := range t.conns {
+		var packets [][]byte
+		for _, tick := range ticks {
+			if mode, ok := c.modes[tick.InstrumentToken]; ok {
+				packets = append(packets, encodePacket(tick, mode))
+			}
+		}
+		if len(packets) > 0 {
+			frames = append(frames, frame{conn: c, packets: packets})
+		}
+	}
+	t.mu.Unlock()
+
+	for _, f := range frames {
+		_ = f.conn.write(websocket.BinaryMessage, encodeFrame(f.packets))
+	}
+}
+
+// Play sends the ticks of a script, waiting After before each one. It blocks
+// until the script is done.
+func (t *Ticker) Play(script []ScriptedTick) {
+	for _, st := range script {
+		if st.After > 0 {
+			time.Sleep(st.After)
+		}
+		t.SendTicks(st.Tick)
+	}
+}
+
+// SendOrderUpdate sends an order postback to every connection.
+func (t *Ticker) SendOrderUpdate(o kiteconnect.Order) {
+	// The client cannot parse zero timestamps, which Kite sends as null.
+	now := time.Now()
+	for _, ts := range []*kitemodels.Time{&o.OrderTimestamp, &o.ExchangeTimestamp, &o.ExchangeUpdateTimestamp} {
+		if ts.IsZero() {
+			ts.Time = now
+		}
+	}
+	t.broadcastText(map[string]any{"type": "order", "data": o})
+}
+
+// SendError sends an error message to every connection.
+func (t *Ticker) SendError(message string) {
+	t.broadcastText(map[string]any{"type": "error", "data": message})
+}
+
+// Disconnect drops every connection without a close frame, as a network
+// failure would. Clients are expected to reconnect.
+func (t *Ticker) Disconnect() {
+	t.mu.Lock()
+	conns := make([]*tickerConn, 0, len(t.conns))
+	for c := range t.conns {
+		conns = append(conns, c)
+	}
+	t.mu.Unlock()
+
+	for _, c := range conns {
+		_ = c.ws.Close()
+	}
+}
+
+// Close drops every connection and shuts the ticker down.
+func (t *Ticker) Close() {
+	t.Disconnect()
+	t.Server.Close()
+}
+
+func (t *Ticker) broadcastText(msg any) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	t.mu.Lock()
+	conns := make([]*tickerConn, 0, len(t.conns))
+	for c := range t.conns {
+		conns = append(conns, c)
+	}
+	t.mu.Unlock()
+
+	for _, c := range conns {
+		_ = c.write(websocket.TextMessage, payload)
+	}
+}
+
+func (t *Ticker) serve(w http.ResponseWriter, r *http.Request) {
+	if t.Authorize != nil && !t.Authorize(r.URL.Query().Get("api_key"), r.URL.Query().Get("access_token")) {
+		http.Error(w, "invalid api_key or access_token", http.StatusForbidden)
+		return
+	}
+	ws, err := t.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := &tickerConn{ws: ws, modes: make(map[uint32]kiteticker.Mode), done: make(chan struct{})}
+	t.mu.Lock()
+	t.conns[c] = struct{}{}
+	t.connects++
+	t.mu.Unlock()
+
+	go c.heartbeat()
+	defer func() {
+		close(c.done)
+		_ = ws.Close()
+		t.mu.Lock()
+		delete(t.conns, c)
+		t.mu.Unlock()
+	}()
+
+	for {
+		msgType, msg, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		if msgType != websocket.TextMessage {
+			continue
+		}
+		if err := t.command(c, msg); err != nil {
+			payload, _ := json.Marshal(map[string]any{"type": "error", "data": err.Error()})
+			_ = c.write(websocket.TextMessage, payload)
+		}
+	}
+}
+
+// command applies a subscribe, unsubscribe or mode message. Subscribing
+// alone streams in quote mode, as Kite does.
+func (t *Ticker) command(c *tickerConn, msg []byte) error {
+	var input struct {
+		Action string          `json:"a"`
+		Value  json.RawMessage `json:"v"`
+	}
+	if err := json.Unmarshal(msg, &input); err != nil {
+		return fmt.Errorf("invalid message: %v", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch input.Action {
+	case "subscribe", "unsubscribe":
+		var tokens []uint32
+		if err := json.Unmarshal(input.Value, &tokens); err != nil {
+			return fmt.Errorf("invalid %s tokens: %v", input.Action, err)
+		}
+		for _, token := range tokens {
+			if input.Action == "unsubscribe" {
+				delete(c.modes, token)
+			} else if _, ok := c.modes[token]; !ok {
+				c.modes[token] = kiteticker.ModeQuote
+			}
+		}
+	case "mode":
+		var value []json.RawMessage
+		var mode kiteticker.Mode
+		var tokens []uint32
+		if err := json.Unmarshal(input.Value, &value); err != nil || len(value) != 2 {
+			return fmt.Errorf("invalid mode message")
+		}
+		if err := json.Unmarshal(value[0], &mode); err != nil {
+			return fmt.Errorf("invalid mode: %v", err)
+		}
+		if mode != kiteticker.ModeLTP && mode != kiteticker.ModeQuote && mode != kiteticker.ModeFull {
+			return fmt.Errorf("invalid mode %q", mode)
+		}
+		if err := json.Unmarshal(value[1], &tokens); err != nil {
+			return fmt.Errorf("invalid mode tokens: %v", err)
+		}
+		for _, token := range tokens {
+			c.modes[token] = mode
+		}
+	default:
+		return fmt.Errorf("unknown action %q", input.Action)
+	}
+	return nil
+}
+
+func (c *tickerConn) write(msgType int, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.ws.WriteMessage(msgType, payload)
+}
+
+// heartbeat sends Kite's one byte heartbeat every second, which keeps the
+// client from timing the connection out when no ticks flow.
+func (c *tickerConn) heartbeat() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.write(websocket.BinaryMessage, []byte{0}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// encodeFrame joins packets into one binary message: the packet count, then
+// each packet after its length.
+func encodeFrame(packets [][]byte) []byte {
+	size := 2
+	for _, p := range packets {
+		size += 2 + len(p)
+	}
+	frame := make([]byte, 0, size)
+	frame = binary.BigEndian.AppendUint16(frame, uint16(len(packets)))
+	for _, p := range packets {
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(p)))
+		frame = append(frame, p...)
+	}
+	return frame
+}
+
+// encodePacket writes a tick as a packet of mode. Prices go on the wire as
+// integers scaled for the segment in the low byte of the token.
+func encodePacket(tick kitemodels.Tick, mode kiteticker.Mode) []byte {
+	seg := tick.InstrumentToken & 0xFF
+	price := func(v float64) uint32 {
+		switch seg {
+		case kiteticker.NseCD:
+			return uint32(v*10000000.0 + 0.5)
+		case kiteticker.BseCD:
+			return uint32(v*10000.0 + 0.5)
+		default:
+			return uint32(v*100.0 + 0.5)
+		}
+	}
+	put := func(b []byte, values ...uint32) []byte {
+		for _, v := range values {
+			b = binary.BigEndian.AppendUint32(b, v)
+		}
+		return b
+	}
+
+	b := put(nil, tick.InstrumentToken, price(tick.LastPrice))
+	if mode == kiteticker.ModeLTP {
+		return b
+	}
+
+	if seg == kiteticker.Indices {
+		b = put(b, price(tick.OHLC.High), price(tick.OHLC.Low), price(tick.OHLC.Open), price(tick.OHLC.Close), price(tick.NetChange))
+		if mode == kiteticker.ModeFull {
+			b = put(b, unix(tick.Timestamp))
+		}
+		return b
+	}
+
+	b = put(b,
+		tick.LastTradedQuantity,
+		price(tick.AverageTradePrice),
+		tick.VolumeTraded,
+		tick.TotalBuyQuantity,
+		tick.TotalSellQuantity,
+		price(tick.OHLC.Open),
+		price(tick.OHLC.High),
+		price(tick.OHLC.Low),
+		price(tick.OHLC.Close),
+	)
+	if mode != kiteticker.ModeFull {
+		return b
+	}
+
+	b = put(b,
+		unix(tick.LastTradeTime),
+		tick.OI,
+		tick.OIDayHigh,
+		tick.OIDayLow,
+		unix(tick.Timestamp),
+	)
+	for _, side := range [][5]kitemodels.DepthItem{tick.Depth.Buy, tick.Depth.Sell} {
+		for _, item := range side {
+			b = put(b, item.Quantity, price(item.Price))
+			b = binary.BigEndian.AppendUint16(b, uint16(item.Orders))
+			b = append(b, 0, 0)
+		}
+	}
+	return b
+}
+
+// unix returns t in Unix seconds, or 0 for the zero time.
+func unix(t kitemodels.Time) uint32 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint32(t.Unix())
+}
@@ -1,45 +1,59 @@
 package kcws
 
 import (
-	"context"
+	"fmt"
 	"log"
+	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	kiteconnect "github.com/zerodha/gokiteconnect/v4"
 	kitemodels "github.com/zerodha/gokiteconnect/v4/models"
 	kiteticker "github.com/zerodha/gokiteconnect/v4/ticker"
 
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/kite"
-	"github.com/SM-Sclass/stock_client2-go_backend/internal/services"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/metrics"
 )
 
 type KiteWS struct {
-	ws          *kiteticker.Ticker
-	tokens      map[uint32]struct{}
-	mu          sync.Mutex
-	bus         *kite.TickBroadcaster
-	OrderSvc    *services.OrderService
-	isConnected bool
+	ws            *kiteticker.Ticker
+	tokens        map[uint32]struct{}
+	mu            sync.Mutex
+	bus           *broker.TickBroadcaster
+	onOrderUpdate func(broker.Order)
+	isConnected   bool
+	lastTick      atomic.Int64 // unix nanoseconds
 }
 
-func NewKiteWS(kc *kite.KiteClient, bus *kite.TickBroadcaster, orderService *services.OrderService) (*KiteWS, error) {
+func NewKiteWS(kc *kite.KiteClient, bus *broker.TickBroadcaster, onOrderUpdate func(broker.Order)) (*KiteWS, error) {
 	ws := kiteticker.New(kc.APIKey, kc.AccessToken)
+	if kc.TickerURL != "" {
+		u, err := url.Parse(kc.TickerURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ticker URL %q: %w", kc.TickerURL, err)
+		}
+		ws.SetRootURL(*u)
+	}
 
 	k := &KiteWS{
-		ws:          ws,
-		tokens:      make(map[uint32]struct{}),
-		bus:         bus,
-		OrderSvc:    orderService,
-		isConnected: false,
+		ws:            ws,
+		tokens:        make(map[uint32]struct{}),
+		bus:           bus,
+		onOrderUpdate: onOrderUpdate,
+		isConnected:   false,
 	}
 
 	ws.OnTick(func(tick kitemodels.Tick) {
-		k.bus.Broadcast([]kitemodels.Tick{tick})
+		metrics.TicksReceived.Inc()
+		k.lastTick.Store(time.Now().UnixNano())
+		k.bus.Broadcast([]broker.Tick{kite.ToBrokerTick(tick)})
 	})
 
 	ws.OnConnect(func() {
 		k.isConnected = true
+		metrics.WSConnected.Set(1)
 		log.Println("WebSocket connected, resubscribing to tokens...")
 		k.ReSubscribeTokens()
 		log.Println("WebSocket connected")
@@ -47,11 +61,13 @@ func NewKiteWS(kc *kite.KiteClient, bus *kite.TickBroadcaster, orderService *ser
 
 	ws.OnClose(func(code int, reason string) {
 		k.isConnected = false
+		metrics.WSConnected.Set(0)
 		log.Printf("WebSocket closed: code=%d, reason=%s", code, reason)
 	})  
     
 	ws.OnError(func(err error) {
 		k.isConnected = false
+		metrics.WSConnected.Set(0)
 		log.Printf("WebSocket error: %v", err)
 	})
 
@@ -60,15 +76,13 @@ func NewKiteWS(kc *kite.KiteClient, bus *kite.TickBroadcaster, orderService *ser
 	// })
 
 	ws.OnReconnect(func(attempt int, delay time.Duration) {
+		metrics.WSReconnects.Inc()
 		log.Printf("Websocket reconnecting attempt: %d, delay: %d", attempt, delay)
 	})
 
 	ws.OnOrderUpdate(func(order kiteconnect.Order) {
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-		defer cancel()
-
-		if err := k.OrderSvc.ProcessOrderUpdate(ctx, order); err != nil {
-			log.Println(err)
+		if k.onOrderUpdate != nil {
+			k.onOrderUpdate(kite.ToBrokerOrder(order))
 		}
 	})
 
@@ -135,3 +149,17 @@ func (kws *KiteWS) IsConnected() bool {
 	defer kws.mu.Unlock()
 	return kws.isConnected
 }
+
+func (kws *KiteWS) LastTickAt() time.Time {
+	ns := kws.lastTick.Load()
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+func (kws *KiteWS) SubscribedCount() int {
+	kws.mu.Lock()
+	defer kws.mu.Unlock()
+	return len(kws.tokens)
+}
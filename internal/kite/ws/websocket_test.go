@@ -0,0 +1,129 @@
+package kcws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/kite"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/kite/kitetest"
+	kiteconnect "github.com/zerodha/gokiteconnect/v4"
+	kitemodels "github.com/zerodha/gokiteconnect/v4/models"
+	kiteticker "github.com/zerodha/gokiteconnect/v4/ticker"
+)
+
+const infyToken = 408065
+
+func startWS(t *testing.T, ticker *kitetest.Ticker) (*KiteWS, chan []broker.Tick, chan broker.Order) {
+	t.Helper()
+	bus := broker.NewTickBroadcaster()
+	ticks := bus.Subscribe(10)
+	orders := make(chan broker.Order, 10)
+
+	kc := &kite.KiteClient{APIKey: "api_key", AccessToken: "access_token", TickerURL: ticker.WebSocketURL()}
+	kws, err := NewKiteWS(kc, bus, func(o broker.Order) { orders <- o })
+	if err != nil {
+		t.Fatal(err)
+	}
+	kws.Start()
+	t.Cleanup(func() { kws.ws.Stop() })
+	return kws, ticks, orders
+}
+
+// waitFor polls cond until it holds or the timeout passes.
+func waitFor(t *testing.T, timeout time.Duration, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func nextTick(t *testing.T, ticks chan []broker.Tick) broker.Tick {
+	t.Helper()
+	select {
+	case batch := <-ticks:
+		return batch[0]
+	case <-time.After(2 * time.Second):
+		t.Fatal("no tick received")
+		return broker.Tick{}
+	}
+}
+
+func TestKiteWSStreamsTicksAndOrderUpdates(t *testing.T) {
+	ticker := kitetest.NewTicker()
+	defer ticker.Close()
+	kws, ticks, orders := startWS(t, ticker)
+
+	// Subscribed before the connection is up, so sent on connect.
+	kws.SubscribeToken(infyToken)
+	waitFor(t, 2*time.Second, "the quote subscription", func() bool {
+		mode, ok := ticker.Subscribed(infyToken)
+		return ok && mode == kiteticker.ModeQuote
+	})
+
+	ticker.SendTicks(kitemodels.Tick{
+		InstrumentToken:    infyToken,
+		LastPrice:          1512.35,
+		LastTradedQuantity: 25,
+		VolumeTraded:       120000,
+		OHLC:               kitemodels.OHLC{Open: 1500, High: 1515, Low: 1498.5, Close: 1495},
+	})
+	tick := nextTick(t, ticks)
+	if tick.InstrumentToken != infyToken || tick.LastPrice != 1512.35 || tick.LastQuantity != 25 || tick.Volume != 120000 {
+		t.Errorf("tick = %+v", tick)
+	}
+	if kws.LastTickAt().IsZero() {
+		t.Error("LastTickAt not set after a tick")
+	}
+
+	ticker.SendOrderUpdate(kiteconnect.Order{
+		OrderID: "order-1", Status: broker.OrderStatusComplete, TradingSymbol: "INFY",
+		InstrumentToken: infyToken, TransactionType: broker.TransactionTypeBuy, FilledQuantity: 10, AveragePrice: 1512.35,
+	})
+	select {
+	case o := <-orders:
+		if o.OrderID != "order-1" || o.Status != broker.OrderStatusComplete || o.AveragePrice != 1512.35 {
+			t.Errorf("order update = %+v", o)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no order update received")
+	}
+
+	kws.UnsubscribeToken(infyToken)
+	waitFor(t, 2*time.Second, "the unsubscribe", func() bool {
+		_, ok := ticker.Subscribed(infyToken)
+		return !ok
+	})
+}
+
+func TestKiteWSResubscribesAfterDisconnect(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for the ticker's reconnect delay")
+	}
+	ticker := kitetest.NewTicker()
+	defer ticker.Close()
+	kws, ticks, _ := startWS(t, ticker)
+
+	waitFor(t, 2*time.Second, "the connection", kws.IsConnected)
+	kws.SubscribeToken(infyToken)
+	waitFor(t, 2*time.Second, "the subscription", func() bool {
+		_, ok := ticker.Subscribed(infyToken)
+		return ok
+	})
+
+	ticker.Disconnect()
+	waitFor(t, 10*time.Second, "the reconnection", func() bool { return ticker.Connects() == 2 })
+	waitFor(t, 2*time.Second, "the resubscription", func() bool {
+		mode, ok := ticker.Subscribed(infyToken)
+		return ok && mode == kiteticker.ModeQuote
+	})
+
+	ticker.SendTicks(kitemodels.Tick{InstrumentToken: infyToken, LastPrice: 1490})
+	if tick := nextTick(t, ticks); tick.LastPrice != 1490 {
+		t.Errorf("tick after reconnect = %+v", tick)
+	}
+}
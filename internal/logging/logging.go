@@ -0,0 +1,283 @@
+// Package logging sets up the process-wide structured logger.
+//
+// Components get their own logger from For, tagged with a "component" field
+// and filtered at that component's level. Lines about one trade carry the
+// correlation ID of the signal that started it, set with WithCorrelationID or
+// as a correlation_id attribute, and are also handed to the trail sink so a
+// trade's full log can be fetched later. Output of the standard log package
+// is routed through the same handler at INFO.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Attribute keys shared by all components.
+const (
+	KeyComponent     = "component"
+	KeyCorrelationID = "correlation_id"
+)
+
+// Options configure Setup.
+type Options struct {
+	Format          string                // "json" (default) or "text"
+	Level           slog.Level            // level for components without their own
+	ComponentLevels map[string]slog.Level // per-component overrides
+	Output          io.Writer             // defaults to stderr
+}
+
+type state struct {
+	root   slog.Handler
+	level  slog.Level
+	levels map[string]slog.Level
+	trail  func(Entry)
+}
+
+var current atomic.Pointer[state]
+
+// Setup installs the structured handler and makes it the default for both
+// slog and the standard log package.
+func Setup(opts Options) {
+	out := opts.Output
+	if out == nil {
+		out = os.Stderr
+	}
+	// Levels are enforced per component, so the root handler lets everything through.
+	handlerOpts := &slog.HandlerOptions{Level: slog.LevelDebug}
+	var root slog.Handler
+	if strings.EqualFold(opts.Format, "text") {
+		root = slog.NewTextHandler(out, handlerOpts)
+	} else {
+		root = slog.NewJSONHandler(out, handlerOpts)
+	}
+
+	next := &state{root: root, level: opts.Level, levels: opts.ComponentLevels}
+	if prev := current.Load(); prev != nil {
+		next.trail = prev.trail
+	}
+	current.Store(next)
+
+	slog.SetDefault(slog.New(&handler{}))
+	log.SetFlags(0)
+}
+
+// SetTrail sends every log line that has a correlation ID to fn as well.
+// fn must not block.
+func SetTrail(fn func(Entry)) {
+	prev := current.Load()
+	if prev == nil {
+		prev = &state{}
+	}
+	next := *prev
+	next.trail = fn
+	current.Store(&next)
+}
+
+// For returns the logger of a component.
+func For(component string) *slog.Logger {
+	return slog.New(&handler{component: component})
+}
+
+// ParseLevel parses a level name: debug, info, warn or error.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(strings.TrimSpace(s)))
+	return level, err
+}
+
+// ParseComponentLevels parses per-component levels written as
+// "order=debug,algo=warn".
+func ParseComponentLevels(spec string) (map[string]slog.Level, error) {
+	levels := map[string]slog.Level{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid component level %q, expected component=level", part)
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid level for %s: %w", name, err)
+		}
+		levels[strings.TrimSpace(name)] = level
+	}
+	return levels, nil
+}
+
+type correlationKey struct{}
+
+// WithCorrelationID returns a context whose log lines carry id.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationID returns the correlation ID carried by ctx, if any.
+func CorrelationID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// NewCorrelationID returns a new random correlation ID.
+func NewCorrelationID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("sig-%x", time.Now().UnixNano())
+	}
+	return "sig-" + hex.EncodeToString(b)
+}
+
+// Entry is a log line kept in a trade's trail.
+type Entry struct {
+	CorrelationID string
+	Time          time.Time
+	Level         string
+	Component     string
+	Message       string
+	Attrs         map[string]any
+}
+
+// handler resolves the root handler and levels when a line is logged, so
+// loggers made before Setup pick up the configuration.
+type handler struct {
+	component string
+	attrs     []slog.Attr // attributes outside any group, checked for the correlation ID
+	groups    []string
+	chain     []func(slog.Handler) slog.Handler
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	st := current.Load()
+	if st == nil {
+		return level >= slog.LevelInfo
+	}
+	if l, ok := st.levels[h.component]; ok {
+		return level >= l
+	}
+	return level >= st.level
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	st := current.Load()
+	id := CorrelationID(ctx)
+	if id == "" {
+		id = h.correlationID(r)
+	} else if h.correlationID(r) == "" {
+		r.AddAttrs(slog.String(KeyCorrelationID, id))
+	}
+
+	// Before Setup (tests and tools) lines go to slog's own default handler.
+	root := slog.Default().Handler()
+	if st != nil {
+		root = st.root
+		if st.trail != nil && id != "" {
+			st.trail(h.entry(r, id))
+		}
+	}
+	if h.component != "" {
+		root = root.WithAttrs([]slog.Attr{slog.String(KeyComponent, h.component)})
+	}
+	for _, apply := range h.chain {
+		root = apply(root)
+	}
+	return root.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := *h
+	if len(h.groups) == 0 {
+		next.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	}
+	next.chain = append(append([]func(slog.Handler) slog.Handler{}, h.chain...),
+		func(root slog.Handler) slog.Handler { return root.WithAttrs(attrs) })
+	return &next
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	next := *h
+	next.groups = append(append([]string{}, h.groups...), name)
+	next.chain = append(append([]func(slog.Handler) slog.Handler{}, h.chain...),
+		func(root slog.Handler) slog.Handler { return root.WithGroup(name) })
+	return &next
+}
+
+func (h *handler) correlationID(r slog.Record) string {
+	for _, a := range h.attrs {
+		if a.Key == KeyCorrelationID {
+			return a.Value.String()
+		}
+	}
+	var id string
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == KeyCorrelationID {
+			id = a.Value.String()
+			return false
+		}
+		return true
+	})
+	return id
+}
+
+func (h *handler) entry(r slog.Record, id string) Entry {
+	e := Entry{
+		CorrelationID: id,
+		Time:          r.Time,
+		Level:         r.Level.String(),
+		Component:     h.component,
+		Message:       r.Message,
+		Attrs:         map[string]any{},
+	}
+	add := func(a slog.Attr) bool {
+		if a.Key != KeyCorrelationID {
+			e.Attrs[a.Key] = attrValue(a.Value)
+		}
+		return true
+	}
+	for _, a := range h.attrs {
+		add(a)
+	}
+	r.Attrs(add)
+	return e
+}
+
+func attrValue(v slog.Value) any {
+	v = v.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		m := map[string]any{}
+		for _, a := range v.Group() {
+			m[a.Key] = attrValue(a.Value)
+		}
+		return m
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+		return v.Any()
+	case slog.KindDuration:
+		return v.Duration().String()
+	default:
+		return v.Any()
+	}
+}
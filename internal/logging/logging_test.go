@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func setupTest(t *testing.T) (*bytes.Buffer, *[]Entry) {
+	t.Helper()
+	var buf bytes.Buffer
+	var trail []Entry
+	Setup(Options{Level: slog.LevelInfo, ComponentLevels: map[string]slog.Level{"order": slog.LevelDebug}, Output: &buf})
+	SetTrail(func(e Entry) { trail = append(trail, e) })
+	t.Cleanup(func() { SetTrail(nil) })
+	return &buf, &trail
+}
+
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, l := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if l == "" {
+			continue
+		}
+		var m map[string]any
+		if err := json.Unmarshal([]byte(l), &m); err != nil {
+			t.Fatalf("line is not JSON: %s", l)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestComponentLevels(t *testing.T) {
+	buf, _ := setupTest(t)
+
+	For("order").Debug("chase step")
+	For("algo").Debug("candle rolled")
+	For("algo").Info("signal emitted")
+
+	got := lines(t, buf)
+	if len(got) != 2 || got[0]["msg"] != "chase step" || got[1]["msg"] != "signal emitted" {
+		t.Fatalf("lines = %v", got)
+	}
+	if got[0][KeyComponent] != "order" || got[0]["level"] != "DEBUG" {
+		t.Fatalf("line = %v", got[0])
+	}
+}
+
+func TestCorrelationTrail(t *testing.T) {
+	buf, trail := setupTest(t)
+
+	logger := For("order").With(KeyCorrelationID, "sig-1", "symbol", "INFY")
+	logger.Info("placing entry", "qty", 10)
+	ctx := WithCorrelationID(context.Background(), "sig-1")
+	For("orders").WarnContext(ctx, "order rejected", "order_id", "42", "error", errors.New("margin"))
+	For("orders").Info("not correlated")
+
+	got := lines(t, buf)
+	if got[1][KeyCorrelationID] != "sig-1" {
+		t.Fatalf("context correlation ID not logged: %v", got[1])
+	}
+	if len(*trail) != 2 {
+		t.Fatalf("trail has %d entries, want 2", len(*trail))
+	}
+	first, second := (*trail)[0], (*trail)[1]
+	if first.Component != "order" || first.Attrs["symbol"] != "INFY" || first.Attrs["qty"] != int64(10) {
+		t.Fatalf("first entry = %+v", first)
+	}
+	if second.Level != "WARN" || second.Attrs["error"] != "margin" || second.Attrs["order_id"] != "42" {
+		t.Fatalf("second entry = %+v", second)
+	}
+}
+
+func TestStandardLogIsStructured(t *testing.T) {
+	buf, _ := setupTest(t)
+
+	log.Printf("🚀 OrderEngine started")
+
+	got := lines(t, buf)
+	if len(got) != 1 || got[0]["msg"] != "🚀 OrderEngine started" || got[0]["level"] != "INFO" {
+		t.Fatalf("lines = %v", got)
+	}
+}
+
+func TestParseComponentLevels(t *testing.T) {
+	levels, err := ParseComponentLevels("order=debug, algo=WARN")
+	if err != nil || levels["order"] != slog.LevelDebug || levels["algo"] != slog.LevelWarn {
+		t.Fatalf("levels = %v, err = %v", levels, err)
+	}
+	for _, spec := range []string{"order", "order=loud", "=info"} {
+		if _, err := ParseComponentLevels(spec); err == nil {
+			t.Errorf("%q accepted", spec)
+		}
+	}
+}
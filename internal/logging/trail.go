@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+)
+
+const (
+	trailBatchSize     = 100
+	trailFlushInterval = time.Second
+)
+
+// TrailStore persists log trail entries.
+type TrailStore interface {
+	AddLogEntries(ctx context.Context, entries []models.LogEntry) error
+}
+
+// TrailWriter saves correlated log lines in batches off the logging path.
+// Lines are dropped, never blocked on, when the store falls behind.
+type TrailWriter struct {
+	store TrailStore
+	queue chan Entry
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+func NewTrailWriter(store TrailStore, buffer int) *TrailWriter {
+	return &TrailWriter{
+		store: store,
+		queue: make(chan Entry, buffer),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// Add queues an entry. It is the function given to SetTrail.
+func (w *TrailWriter) Add(e Entry) {
+	select {
+	case w.queue <- e:
+	default:
+	}
+}
+
+// Start saves queued entries until Stop is called.
+func (w *TrailWriter) Start() {
+	go w.run()
+}
+
+// Stop saves what is queued and returns once the writer has exited.
+func (w *TrailWriter) Stop() {
+	close(w.stop)
+	<-w.done
+}
+
+func (w *TrailWriter) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(trailFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.LogEntry, 0, trailBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := w.store.AddLogEntries(ctx, batch); err != nil {
+			log.Printf("⚠️ Failed to save %d log trail entries: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case e := <-w.queue:
+			batch = append(batch, toModel(e))
+			if len(batch) >= trailBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-w.stop:
+			for {
+				select {
+				case e := <-w.queue:
+					batch = append(batch, toModel(e))
+					if len(batch) >= trailBatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func toModel(e Entry) models.LogEntry {
+	attrs, err := json.Marshal(e.Attrs)
+	if err != nil {
+		attrs = []byte("{}")
+	}
+	return models.LogEntry{
+		CorrelationID: e.CorrelationID,
+		Level:         e.Level,
+		Component:     e.Component,
+		Message:       e.Message,
+		Attrs:         attrs,
+		LoggedAt:      e.Time,
+	}
+}
@@ -0,0 +1,192 @@
+// Package metrics defines the Prometheus metrics exported on /metrics.
+// Collectors are registered with the default registry when the package is
+// loaded, so instrumented packages only need to import it.
+package metrics
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "trader"
+
+// Market data feed.
+var (
+	TicksReceived = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ticks_received_total",
+		Help:      "Ticks received from the Kite ticker.",
+	})
+	BroadcastDrops = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "broadcast_drops_total",
+		Help:      "Ticks dropped because a subscriber's channel was full.",
+	})
+	WSConnected = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ws_connected",
+		Help:      "1 while the Kite ticker websocket is connected.",
+	})
+	WSReconnects = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ws_reconnects_total",
+		Help:      "Kite ticker websocket reconnect attempts.",
+	})
+)
+
+// Strategy engine.
+var (
+	CandleRollDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "candle_roll_duration_seconds",
+		Help:      "Time spent processing a 5-minute candle close across all tracked stocks.",
+		Buckets:   []float64{.0001, .0005, .001, .005, .01, .05, .1, .5},
+	})
+	Signals = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "signals_total",
+		Help:      "Trade signals emitted by the algo engine, by signal type.",
+	}, []string{"type"})
+	SignalSkips = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "signal_skips_total",
+		Help:      "Candles that did not produce an entry, by reason.",
+	}, []string{"reason"})
+)
+
+// Orders and the Kite REST API.
+var (
+	OrderPlacementDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "order_placement_duration_seconds",
+		Help:      "Time for the broker to accept or reject an order, by kind.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 10),
+	}, []string{"kind"})
+	OrderPlacements = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "order_placements_total",
+		Help:      "Orders sent to the broker, by kind and outcome.",
+	}, []string{"kind", "outcome"})
+	KiteRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "kite_request_duration_seconds",
+		Help:      "Kite REST API call latency, by method.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 10),
+	}, []string{"method"})
+	KiteErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kite_errors_total",
+		Help:      "Failed Kite REST API calls, by method and Kite error type.",
+	}, []string{"method", "error_type"})
+)
+
+// Positions and P&L.
+var (
+	RealizedPnL = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "realized_pnl",
+		Help:      "Net P&L of trades closed since the process started, in rupees.",
+	})
+)
+
+// Scheduler.
+var (
+	SchedulerJobs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scheduler_jobs_total",
+		Help:      "Scheduled job runs, by job and outcome.",
+	}, []string{"job", "outcome"})
+	SchedulerJobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "scheduler_job_duration_seconds",
+		Help:      "Scheduled job run time, by job.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8),
+	}, []string{"job"})
+)
+
+// Outcome label values.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Outcome returns the outcome label for err.
+func Outcome(err error) string {
+	if err != nil {
+		return OutcomeFailure
+	}
+	return OutcomeSuccess
+}
+
+// ObserveKiteCall records a Kite API call that started at start. errorType
+// is the Kite error type of a failed call and is ignored when err is nil.
+func ObserveKiteCall(method string, start time.Time, err error, errorType string) {
+	KiteRequestDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		KiteErrors.WithLabelValues(method, errorType).Inc()
+	}
+}
+
+// RegisterPositionGauges exports open positions and their unrealized P&L,
+// read from fn at scrape time.
+func RegisterPositionGauges(fn func() (open int, unrealized float64)) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "open_positions",
+		Help:      "Tracked stocks with an open trade.",
+	}, func() float64 {
+		open, _ := fn()
+		return float64(open)
+	})
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "unrealized_pnl",
+		Help:      "Mark-to-market P&L of open trades at the last traded price, in rupees.",
+	}, func() float64 {
+		_, pnl := fn()
+		return pnl
+	})
+}
+
+// RegisterDBPool exports connection pool statistics for pool.
+func RegisterDBPool(pool *pgxpool.Pool) {
+	prometheus.MustRegister(&poolCollector{pool: pool})
+}
+
+var (
+	poolTotalDesc    = prometheus.NewDesc(namespace+"_db_pool_total_conns", "Connections in the pool.", nil, nil)
+	poolIdleDesc     = prometheus.NewDesc(namespace+"_db_pool_idle_conns", "Idle connections in the pool.", nil, nil)
+	poolAcquiredDesc = prometheus.NewDesc(namespace+"_db_pool_acquired_conns", "Connections currently in use.", nil, nil)
+	poolMaxDesc      = prometheus.NewDesc(namespace+"_db_pool_max_conns", "Maximum size of the pool.", nil, nil)
+	poolAcquiresDesc = prometheus.NewDesc(namespace+"_db_pool_acquires_total", "Successful connection acquires.", nil, nil)
+	poolWaitsDesc    = prometheus.NewDesc(namespace+"_db_pool_empty_acquires_total", "Acquires that had to wait for a connection.", nil, nil)
+	poolWaitDesc     = prometheus.NewDesc(namespace+"_db_pool_acquire_wait_seconds_total", "Total time spent waiting for a connection.", nil, nil)
+)
+
+type poolCollector struct {
+	pool *pgxpool.Pool
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolTotalDesc
+	ch <- poolIdleDesc
+	ch <- poolAcquiredDesc
+	ch <- poolMaxDesc
+	ch <- poolAcquiresDesc
+	ch <- poolWaitsDesc
+	ch <- poolWaitDesc
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(poolTotalDesc, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdleDesc, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquiredDesc, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolMaxDesc, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquiresDesc, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolWaitsDesc, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolWaitDesc, prometheus.CounterValue, s.AcquireDuration().Seconds())
+}
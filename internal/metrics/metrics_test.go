@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveKiteCall(t *testing.T) {
+	ObserveKiteCall("get_quote", time.Now(), nil, "")
+	ObserveKiteCall("get_quote", time.Now(), errors.New("Invalid access token"), "TokenException")
+
+	if n := testutil.CollectAndCount(KiteRequestDuration, "trader_kite_request_duration_seconds"); n != 1 {
+		t.Fatalf("duration series = %d, want 1", n)
+	}
+	if v := testutil.ToFloat64(KiteErrors.WithLabelValues("get_quote", "TokenException")); v != 1 {
+		t.Fatalf("TokenException errors = %v, want 1", v)
+	}
+}
+
+func TestPositionGauges(t *testing.T) {
+	RegisterPositionGauges(func() (int, float64) { return 2, -150.5 })
+
+	want := `
+# HELP trader_unrealized_pnl Mark-to-market P&L of open trades at the last traded price, in rupees.
+# TYPE trader_unrealized_pnl gauge
+trader_unrealized_pnl -150.5
+`
+	if err := testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(want), "trader_unrealized_pnl"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware lets through only the users listed in ADMIN_USER_IDS. It
+// runs after AuthMiddleware, which sets the user_id.
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		id, ok := userID.(float64)
+		if !ok || !config.Current().IsAdmin(int64(id)) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,15 +1,28 @@
 package middleware
 
 import (
+	"errors"
+	"log"
 	"net/http"
-	// "strings"
+	"strings"
+
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/services"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware accepts either the session cookie set by login or an API key
+// sent as "Authorization: Bearer <key>", as stockctl and scripts do.
+func AuthMiddleware(apiKeys repository.APIKeyStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if key, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			authenticateAPIKey(c, apiKeys, key)
+			return
+		}
+
 		var jwtSecret = []byte(config.ServerConfig.JWTSecret) // move to env later
 		// authHeader := c.GetHeader("Authorization")
 
@@ -47,3 +60,22 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func authenticateAPIKey(c *gin.Context, apiKeys repository.APIKeyStore, key string) {
+	apiKey, err := apiKeys.GetActiveKeyByHash(c.Request.Context(), services.HashAPIKey(strings.TrimSpace(key)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid api key"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to check api key", "error": err.Error()})
+		return
+	}
+	if err := apiKeys.TouchKey(c.Request.Context(), apiKey.ID); err != nil {
+		log.Printf("⚠️ Failed to record use of API key %s: %v", apiKey.KeyPrefix, err)
+	}
+
+	// Same type as the user_id claim of a session token
+	c.Set("user_id", float64(apiKey.UserID))
+	c.Next()
+}
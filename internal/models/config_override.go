@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// ConfigOverride is a reloadable setting changed from the admin API. It takes
+// precedence over the config file and the environment.
+type ConfigOverride struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	UpdatedBy *int64    `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// LogEntry is a structured log line about a signal and the orders placed for
+// it, kept so a trade's log trail can be fetched after the fact.
+type LogEntry struct {
+	ID            int64           `json:"id"`
+	CorrelationID string          `json:"correlation_id"`
+	Level         string          `json:"level"`
+	Component     string          `json:"component"`
+	Message       string          `json:"message"`
+	Attrs         json.RawMessage `json:"attrs"`
+	LoggedAt      time.Time       `json:"logged_at"`
+}
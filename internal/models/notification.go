@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Notification channels.
+const (
+	ChannelTelegram = "TELEGRAM"
+	ChannelSlack    = "SLACK"
+	ChannelEmail    = "EMAIL"
+	ChannelWebhook  = "WEBHOOK"
+)
+
+// Notification delivery statuses.
+const (
+	NotificationSent        = "SENT"
+	NotificationFailed      = "FAILED"
+	NotificationRateLimited = "RATE_LIMITED"
+)
+
+// NotificationTarget holds where a channel delivers. Only the fields of the
+// route's channel are used.
+type NotificationTarget struct {
+	ChatID     string   `json:"chat_id,omitempty"`     // Telegram chat
+	BotToken   string   `json:"bot_token,omitempty"`   // Telegram bot, instead of the server's
+	WebhookURL string   `json:"webhook_url,omitempty"` // Slack incoming webhook
+	URL        string   `json:"url,omitempty"`         // generic webhook
+	Secret     string   `json:"secret,omitempty"`      // generic webhook HMAC key
+	To         []string `json:"to,omitempty"`          // email recipients
+}
+
+// NotificationRoute is a user's rule for sending events to a channel. An
+// empty Events list matches every event; MinSeverity drops lesser ones.
+type NotificationRoute struct {
+	ID          int64              `json:"id"`
+	UserID      int64              `json:"user_id"`
+	Channel     string             `json:"channel"`
+	Target      NotificationTarget `json:"target"`
+	Events      []string           `json:"events"`
+	MinSeverity string             `json:"min_severity"`
+	Enabled     bool               `json:"enabled"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+// Notification is one delivery attempt of an event over a route.
+type Notification struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	RouteID   *int64    `json:"route_id"`
+	Channel   string    `json:"channel"`
+	Event     string    `json:"event"`
+	Severity  string    `json:"severity"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	Status    string    `json:"status"` // SENT, FAILED or RATE_LIMITED
+	Error     *string   `json:"error"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -2,6 +2,37 @@ package models
 
 import "time"
 
+// Order sources: who asked for the order to be placed.
+const (
+	OrderSourceAlgo     = "ALGO"
+	OrderSourceManual   = "MANUAL"
+	OrderSourceWebhook  = "WEBHOOK"  // an external alert through the signal webhook
+	OrderSourceShutdown = "SHUTDOWN" // a position flattened when the server stopped
+	OrderSourceAdopt    = "ADOPT"    // a broker position taken over by the bot; trades only
+)
+
+// Order statuses the orders table stores, the values of its order_status enum.
+const (
+	OrderStatusPending   = "PENDING"
+	OrderStatusOpen      = "OPEN"
+	OrderStatusComplete  = "COMPLETE"
+	OrderStatusCancelled = "CANCELLED"
+	OrderStatusRejected  = "REJECTED"
+)
+
+// StoredOrderStatus maps a broker order status to the one stored. Kite reports
+// a working order as OPEN, but also as UPDATE after a modification and as
+// OPEN PENDING, TRIGGER PENDING and the like on its way to the exchange; all
+// of them are stored as OPEN.
+func StoredOrderStatus(status string) string {
+	switch status {
+	case OrderStatusComplete, OrderStatusCancelled, OrderStatusRejected:
+		return status
+	default:
+		return OrderStatusOpen
+	}
+}
+
 // type Order struct {
 // 	ID              int64     `json:"id"`
 // 	TrackingStockID int64     `json:"tracking_stock_id"`
@@ -39,6 +70,9 @@ type Order struct {
 	TriggerPrice    *float64  `json:"trigger_price"`     // Pointer for NULL
 	PurchasePrice   *float64  `json:"purchase_price"`    // Pointer for NULL
 	StatusMessage   *string   `json:"status_message"`    // Pointer for NULL
+	SizingNote      *string   `json:"sizing_note"`       // Why the entry quantity was reduced, if it was
+	Source          string    `json:"source"`            // ALGO or MANUAL
+	CorrelationID   *string   `json:"correlation_id"`    // ID of the signal the order was placed for
 	Status          string    `json:"status"`
 	PlacedAt        time.Time `json:"placed_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// OrderEvent is a single step in the life of a broker order as driven by the
+// order engine (placed, price chased, cancelled, converted to market ...).
+type OrderEvent struct {
+	ID              int64     `json:"id"`
+	TrackingStockID int64     `json:"tracking_stock_id"`
+	OrderID         string    `json:"order_id"`
+	Event           string    `json:"event"`
+	Price           *float64  `json:"price"`
+	Quantity        float64   `json:"quantity"`
+	Message         *string   `json:"message"`
+	CreatedAt       time.Time `json:"created_at"`
+}
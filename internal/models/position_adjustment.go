@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Position adjustment kinds.
+const (
+	AdjustmentKindAdjust = "ADJUST" // exit levels of a managed position changed
+	AdjustmentKindAdopt  = "ADOPT"  // a broker position was taken over by the bot
+)
+
+// PositionAdjustment records a manual change to the exit levels of an open
+// position. Target and stoploss are in points from the position's base price.
+type PositionAdjustment struct {
+	ID                  int64     `json:"id"`
+	TrackingStockID     int64     `json:"tracking_stock_id"`
+	Kind                string    `json:"kind"`
+	Quantity            uint32    `json:"quantity"`
+	Direction           string    `json:"direction"`
+	BasePrice           float64   `json:"base_price"`
+	LTP                 float64   `json:"ltp"`
+	OldTarget           float64   `json:"old_target"`
+	NewTarget           float64   `json:"new_target"`
+	OldStopLoss         float64   `json:"old_stoploss"`
+	NewStopLoss         float64   `json:"new_stoploss"`
+	OldTrailingStopLoss float64   `json:"old_trailing_stoploss"`
+	NewTrailingStopLoss float64   `json:"new_trailing_stoploss"`
+	CreatedAt           time.Time `json:"created_at"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Signal alert statuses.
+const (
+	AlertAccepted  = "ACCEPTED"
+	AlertRejected  = "REJECTED"
+	AlertDuplicate = "DUPLICATE"
+)
+
+// SignalWebhookKey is the key a user's alerts are signed with.
+type SignalWebhookKey struct {
+	UserID    int64     `json:"user_id"`
+	KeyID     string    `json:"key_id"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SignalAlert is the log entry of an alert received on the signal webhook,
+// whether it was acted on or not.
+type SignalAlert struct {
+	ID              int64           `json:"id"`
+	UserID          *int64          `json:"user_id"`
+	AlertID         string          `json:"alert_id"`
+	TradingSymbol   string          `json:"trading_symbol"`
+	Side            string          `json:"side"`
+	Quantity        uint32          `json:"quantity"`
+	TrackingStockID *int64          `json:"tracking_stock_id"`
+	Status          string          `json:"status"` // ACCEPTED, REJECTED or DUPLICATE
+	Reason          *string         `json:"reason"`
+	Payload         json.RawMessage `json:"payload"`
+	ReceivedAt      time.Time       `json:"received_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TrackedStockState is a snapshot of one stock's intraday tracking state,
+// written as it changes so a restart can pick up exactly where it left off.
+// Candles are stored as JSON in the shape of tracking.Candle / CandleState.
+type TrackedStockState struct {
+	TrackingStockID     int64           `json:"tracking_stock_id"`
+	TradingDate         time.Time       `json:"trading_date"`
+	InstrumentToken     uint32          `json:"instrument_token"`
+	TradingSymbol       string          `json:"trading_symbol"`
+	Direction           string          `json:"direction"`
+	BuyQuantity         uint32          `json:"buy_quantity"`
+	SellQuantity        uint32          `json:"sell_quantity"`
+	BasePrice           float64         `json:"base_price"`
+	Target              float64         `json:"target"`
+	StopLoss            float64         `json:"stoploss"`
+	TrailingStopLoss    float64         `json:"trailing_stoploss"`
+	SignalFired         bool            `json:"signal_fired"`
+	Locked              bool            `json:"locked"`
+	MaxExecutableOrders uint32          `json:"max_executable_orders"`
+	PendingExitOrderID  string          `json:"pending_exit_order_id"`
+	ExitEscalated       bool            `json:"exit_escalated"`
+	FifteenCandle       json.RawMessage `json:"fifteen_candle"`
+	Candles             json.RawMessage `json:"candles"`
+	UpdatedAt           time.Time       `json:"updated_at"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Trade statuses.
+const (
+	TradeStatusOpen   = "OPEN"
+	TradeStatusClosed = "CLOSED"
+)
+
+// Trade order legs.
+const (
+	TradeLegEntry = "ENTRY"
+	TradeLegExit  = "EXIT"
+)
+
+// Trade is one round trip on a tracking stock: the entry order(s) that opened
+// a position and the exit order(s) that closed it. Prices are fill VWAPs and
+// RiskPoints is the stoploss distance per share when the trade was opened.
+type Trade struct {
+	ID              int64        `json:"id"`
+	TrackingStockID int64        `json:"tracking_stock_id"`
+	TradingSymbol   string       `json:"trading_symbol"`
+	Exchange        string       `json:"exchange"`
+	Direction       string       `json:"direction"` // BUY (long) or SELL (short)
+	Status          string       `json:"status"`    // OPEN or CLOSED
+	Source          string       `json:"source"`    // ALGO or MANUAL, from the first entry order
+	Quantity        int          `json:"quantity"`
+	ExitQuantity    int          `json:"exit_quantity"`
+	EntryPrice      float64      `json:"entry_price"`
+	ExitPrice       *float64     `json:"exit_price"`
+	RiskPoints      float64      `json:"risk_points"`
+	ExitReason      *string      `json:"exit_reason"` // TARGET_HIT, STOPLOSS_HIT, FORCE_EXIT, MANUAL_EXIT
+	GrossPnL        float64      `json:"gross_pnl"`
+	Charges         float64      `json:"charges"` // brokerage and statutory charges on all of its orders
+	NetPnL          float64      `json:"net_pnl"`
+	RMultiple       *float64     `json:"r_multiple"`
+	HoldingSeconds  *int64       `json:"holding_seconds"`
+	OpenedAt        time.Time    `json:"opened_at"`
+	ClosedAt        *time.Time   `json:"closed_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+	Orders          []TradeOrder `json:"orders,omitempty"`
+}
+
+// TradeOrder is a broker order's filled contribution to one leg of a trade.
+type TradeOrder struct {
+	TradeID      int64     `json:"trade_id"`
+	OrderID      string    `json:"order_id"`
+	Leg          string    `json:"leg"` // ENTRY or EXIT
+	EventType    string    `json:"event_type"`
+	Quantity     int       `json:"quantity"`
+	AveragePrice float64   `json:"average_price"`
+	Charges      float64   `json:"charges"`
+	FilledAt     time.Time `json:"filled_at"`
+}
@@ -0,0 +1,187 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+)
+
+const defaultTelegramAPIURL = "https://api.telegram.org"
+
+// SMTPSettings is the mail server email routes send through.
+type SMTPSettings struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Settings are the server-wide channel settings that routes build on.
+type Settings struct {
+	TelegramAPIURL   string
+	TelegramBotToken string
+	SMTP             SMTPSettings
+	Client           *http.Client
+}
+
+// NotifierFor builds the notifier that delivers over route.
+func (s Settings) NotifierFor(route models.NotificationRoute) (Notifier, error) {
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	t := route.Target
+
+	switch route.Channel {
+	case models.ChannelTelegram:
+		token := t.BotToken
+		if token == "" {
+			token = s.TelegramBotToken
+		}
+		if token == "" {
+			return nil, fmt.Errorf("no telegram bot token configured")
+		}
+		apiURL := s.TelegramAPIURL
+		if apiURL == "" {
+			apiURL = defaultTelegramAPIURL
+		}
+		return &Telegram{APIURL: apiURL, BotToken: token, ChatID: t.ChatID, Client: client}, nil
+	case models.ChannelSlack:
+		return &Slack{WebhookURL: t.WebhookURL, Client: client}, nil
+	case models.ChannelWebhook:
+		return &Webhook{URL: t.URL, Secret: t.Secret, Client: client}, nil
+	case models.ChannelEmail:
+		if s.SMTP.Host == "" {
+			return nil, fmt.Errorf("no smtp server configured")
+		}
+		return &Email{SMTP: s.SMTP, To: t.To}, nil
+	}
+	return nil, fmt.Errorf("unknown channel %q", route.Channel)
+}
+
+// Telegram sends messages through a bot to a chat.
+type Telegram struct {
+	APIURL   string
+	BotToken string
+	ChatID   string
+	Client   *http.Client
+}
+
+func (t *Telegram) Send(ctx context.Context, msg Message) error {
+	body := map[string]string{
+		"chat_id": t.ChatID,
+		"text":    msg.Title + "\n" + msg.Text,
+	}
+	return postJSON(ctx, t.Client, strings.TrimRight(t.APIURL, "/")+"/bot"+t.BotToken+"/sendMessage", body, nil)
+}
+
+// Slack posts messages to an incoming webhook.
+type Slack struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+func (s *Slack) Send(ctx context.Context, msg Message) error {
+	body := map[string]string{"text": "*" + msg.Title + "*\n" + msg.Text}
+	return postJSON(ctx, s.Client, s.WebhookURL, body, nil)
+}
+
+// Webhook posts the message as JSON. With a secret, the body is signed with
+// HMAC-SHA256 in the X-Signature-256 header as "sha256=<hex>".
+type Webhook struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+func (w *Webhook) Send(ctx context.Context, msg Message) error {
+	var sign func([]byte) map[string]string
+	if w.Secret != "" {
+		sign = func(body []byte) map[string]string {
+			mac := hmac.New(sha256.New, []byte(w.Secret))
+			mac.Write(body)
+			return map[string]string{"X-Signature-256": "sha256=" + hex.EncodeToString(mac.Sum(nil))}
+		}
+	}
+	return postJSON(ctx, w.Client, w.URL, msg, sign)
+}
+
+// Email sends plain-text mail through an SMTP server.
+type Email struct {
+	SMTP SMTPSettings
+	To   []string
+}
+
+func (e *Email) Send(ctx context.Context, msg Message) error {
+	addr := net.JoinHostPort(e.SMTP.Host, strconv.Itoa(e.SMTP.Port))
+	var auth smtp.Auth
+	if e.SMTP.Username != "" {
+		auth = smtp.PlainAuth("", e.SMTP.Username, e.SMTP.Password, e.SMTP.Host)
+	}
+
+	var b strings.Builder
+	b.WriteString("From: " + e.SMTP.From + "\r\n")
+	b.WriteString("To: " + strings.Join(e.To, ", ") + "\r\n")
+	b.WriteString("Subject: " + strings.NewReplacer("\r", "", "\n", " ").Replace(msg.Title) + "\r\n")
+	b.WriteString("Date: " + msg.Time.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Text, "\n", "\r\n") + "\r\n")
+
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(addr, auth, e.SMTP.From, e.To, []byte(b.String())) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func postJSON(ctx context.Context, client *http.Client, target string, payload any, sign func([]byte) map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if sign != nil {
+		for k, v := range sign(body) {
+			req.Header.Set(k, v)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// The URL can carry a token (Telegram), so only its host goes in the error.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("request to %s failed: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("%s responded %d: %s", req.URL.Host, resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	return nil
+}
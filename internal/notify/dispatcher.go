@@ -0,0 +1,227 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+)
+
+const (
+	queueSize   = 100
+	sendTimeout = 15 * time.Second
+)
+
+// Store holds the routing rules and the history of sent notifications.
+type Store interface {
+	GetEnabledRoutes(ctx context.Context) ([]models.NotificationRoute, error)
+	AddNotification(ctx context.Context, n *models.Notification) (int64, error)
+}
+
+// Dispatcher sends each event to every route that matches it, rate limited
+// per route, and records every attempt. Notify queues the event so callers on
+// the trading path never wait on a slow channel.
+type Dispatcher struct {
+	store    Store
+	settings Settings
+	limiter  *rateLimiter
+
+	queue    chan Message
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+	running  bool
+	mu       sync.Mutex
+}
+
+// NewDispatcher creates a dispatcher that sends at most limit notifications
+// per route within window. A limit of zero disables rate limiting.
+func NewDispatcher(store Store, settings Settings, limit int, window time.Duration) *Dispatcher {
+	return &Dispatcher{
+		store:    store,
+		settings: settings,
+		limiter:  newRateLimiter(limit, window),
+		queue:    make(chan Message, queueSize),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start begins delivering queued events.
+func (d *Dispatcher) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.running {
+		return
+	}
+	d.running = true
+	d.stopChan = make(chan struct{})
+
+	d.wg.Add(1)
+	go d.run()
+	log.Println("🔔 Notification dispatcher started")
+}
+
+// Stop delivers what is still queued and stops.
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	if !d.running {
+		d.mu.Unlock()
+		return
+	}
+	d.running = false
+	close(d.stopChan)
+	d.mu.Unlock()
+
+	d.wg.Wait()
+	log.Println("🔕 Notification dispatcher stopped")
+}
+
+func (d *Dispatcher) run() {
+	defer d.wg.Done()
+	for {
+		select {
+		case msg := <-d.queue:
+			d.Dispatch(context.Background(), msg)
+		case <-d.stopChan:
+			for {
+				select {
+				case msg := <-d.queue:
+					d.Dispatch(context.Background(), msg)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Notify queues an event for delivery. It never blocks; when the queue is
+// full the event is logged and dropped.
+func (d *Dispatcher) Notify(msg Message) {
+	if d == nil {
+		return
+	}
+	if msg.Time.IsZero() {
+		msg.Time = time.Now()
+	}
+	if msg.Severity == "" {
+		msg.Severity = SeverityInfo
+	}
+	select {
+	case d.queue <- msg:
+	default:
+		log.Printf("⚠️ Notification queue full, dropped %s: %s", msg.Event, msg.Title)
+	}
+}
+
+// Dispatch sends msg to every matching route and waits for the sends.
+func (d *Dispatcher) Dispatch(ctx context.Context, msg Message) {
+	routes, err := d.store.GetEnabledRoutes(ctx)
+	if err != nil {
+		log.Printf("⚠️ Failed to load notification routes for %s: %v", msg.Event, err)
+		return
+	}
+	for _, route := range routes {
+		if Matches(route, msg) {
+			d.Send(ctx, route, msg)
+		}
+	}
+}
+
+// Send delivers msg over one route, unless the route is over its rate limit,
+// records the attempt and returns the delivery error.
+func (d *Dispatcher) Send(ctx context.Context, route models.NotificationRoute, msg Message) error {
+	routeID := route.ID
+	record := &models.Notification{
+		UserID:   route.UserID,
+		RouteID:  &routeID,
+		Channel:  route.Channel,
+		Event:    msg.Event,
+		Severity: msg.Severity,
+		Title:    msg.Title,
+		Message:  msg.Text,
+		Status:   models.NotificationSent,
+	}
+
+	var err error
+	if !d.limiter.Allow(route.ID, msg.Time) {
+		record.Status = models.NotificationRateLimited
+		log.Printf("🚦 Notification %s to route %d rate limited", msg.Event, route.ID)
+	} else {
+		err = d.send(ctx, route, msg)
+		if err != nil {
+			record.Status = models.NotificationFailed
+			errText := err.Error()
+			record.Error = &errText
+			log.Printf("⚠️ Failed to send %s over %s route %d: %v", msg.Event, route.Channel, route.ID, err)
+		}
+	}
+
+	if _, storeErr := d.store.AddNotification(ctx, record); storeErr != nil {
+		log.Printf("⚠️ Failed to record notification %s for route %d: %v", msg.Event, route.ID, storeErr)
+	}
+	return err
+}
+
+// Reconfigure replaces the channel settings and the per-route rate limit.
+// Notifications already counted against a route still count.
+func (d *Dispatcher) Reconfigure(settings Settings, limit int, window time.Duration) {
+	d.mu.Lock()
+	d.settings = settings
+	d.mu.Unlock()
+	d.limiter.SetLimit(limit, window)
+}
+
+func (d *Dispatcher) send(ctx context.Context, route models.NotificationRoute, msg Message) error {
+	d.mu.Lock()
+	settings := d.settings
+	d.mu.Unlock()
+
+	notifier, err := settings.NotifierFor(route)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	return notifier.Send(ctx, msg)
+}
+
+// rateLimiter allows up to limit events per key within a sliding window.
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+	mu     sync.Mutex
+	sent   map[int64][]time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, sent: make(map[int64][]time.Time)}
+}
+
+func (l *rateLimiter) SetLimit(limit int, window time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit, l.window = limit, window
+}
+
+func (l *rateLimiter) Allow(key int64, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit <= 0 {
+		return true
+	}
+
+	recent := l.sent[key][:0]
+	for _, t := range l.sent[key] {
+		if now.Sub(t) < l.window {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= l.limit {
+		l.sent[key] = recent
+		return false
+	}
+	l.sent[key] = append(recent, now)
+	return true
+}
@@ -0,0 +1,112 @@
+// Package notify sends trade and system events to users over Telegram, Slack,
+// email and webhooks, following each user's routing rules.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+)
+
+// Events that can be notified.
+const (
+	EventEntry         = "ENTRY"          // entry order filled
+	EventTargetHit     = "TARGET_HIT"     // position closed at target
+	EventStopLossHit   = "STOPLOSS_HIT"   // position closed at stoploss
+	EventExit          = "EXIT"           // position closed by force or manual exit
+	EventOrderRejected = "ORDER_REJECTED" // broker rejected an order
+	EventTokenInvalid  = "TOKEN_INVALID"  // Kite access token missing or expired
+	EventJobFailed     = "JOB_FAILED"     // a scheduler job returned an error
+)
+
+var Events = []string{EventEntry, EventTargetHit, EventStopLossHit, EventExit, EventOrderRejected, EventTokenInvalid, EventJobFailed}
+
+// Severities, in increasing order.
+const (
+	SeverityInfo     = "INFO"
+	SeverityWarning  = "WARNING"
+	SeverityCritical = "CRITICAL"
+)
+
+var severities = []string{SeverityInfo, SeverityWarning, SeverityCritical}
+
+// Message is an event to notify.
+type Message struct {
+	Event    string    `json:"event"`
+	Severity string    `json:"severity"`
+	Title    string    `json:"title"`
+	Text     string    `json:"text"`
+	Time     time.Time `json:"time"`
+}
+
+// Notifier delivers messages over one channel.
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Matches reports whether route wants msg.
+func Matches(route models.NotificationRoute, msg Message) bool {
+	if !route.Enabled {
+		return false
+	}
+	if len(route.Events) > 0 && !slices.Contains(route.Events, msg.Event) {
+		return false
+	}
+	return slices.Index(severities, msg.Severity) >= slices.Index(severities, route.MinSeverity)
+}
+
+// ValidateRoute checks a route's channel, target, events and severity.
+func ValidateRoute(route models.NotificationRoute) error {
+	t := route.Target
+	switch route.Channel {
+	case models.ChannelTelegram:
+		if t.ChatID == "" {
+			return fmt.Errorf("telegram route needs a chat_id")
+		}
+	case models.ChannelSlack:
+		if err := checkURL(t.WebhookURL); err != nil {
+			return fmt.Errorf("slack route needs a webhook_url: %w", err)
+		}
+	case models.ChannelWebhook:
+		if err := checkURL(t.URL); err != nil {
+			return fmt.Errorf("webhook route needs a url: %w", err)
+		}
+	case models.ChannelEmail:
+		if len(t.To) == 0 {
+			return fmt.Errorf("email route needs at least one to address")
+		}
+		for _, addr := range t.To {
+			if !strings.Contains(addr, "@") || strings.ContainsAny(addr, "\r\n") {
+				return fmt.Errorf("invalid email address %q", addr)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown channel %q", route.Channel)
+	}
+
+	for _, e := range route.Events {
+		if !slices.Contains(Events, e) {
+			return fmt.Errorf("unknown event %q", e)
+		}
+	}
+	if !slices.Contains(severities, route.MinSeverity) {
+		return fmt.Errorf("unknown severity %q", route.MinSeverity)
+	}
+	return nil
+}
+
+func checkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%q is not an http(s) URL", raw)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+)
+
+type memStore struct {
+	mu            sync.Mutex
+	routes        []models.NotificationRoute
+	notifications []models.Notification
+}
+
+func (s *memStore) GetEnabledRoutes(ctx context.Context) ([]models.NotificationRoute, error) {
+	return s.routes, nil
+}
+
+func (s *memStore) AddNotification(ctx context.Context, n *models.Notification) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifications = append(s.notifications, *n)
+	return int64(len(s.notifications)), nil
+}
+
+// recorder is a fake HTTP endpoint that keeps the requests it receives.
+type recorder struct {
+	mu       sync.Mutex
+	paths    []string
+	bodies   []string
+	headers  []http.Header
+	response int
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	r.paths = append(r.paths, req.URL.Path)
+	r.bodies = append(r.bodies, string(body))
+	r.headers = append(r.headers, req.Header.Clone())
+	r.mu.Unlock()
+	if r.response != 0 {
+		w.WriteHeader(r.response)
+	}
+}
+
+var testMsg = Message{
+	Event:    EventTargetHit,
+	Severity: SeverityInfo,
+	Title:    "Target hit: INFY",
+	Text:     "SELL 10 INFY @ 1510.00",
+	Time:     time.Date(2025, 3, 10, 10, 15, 0, 0, time.UTC),
+}
+
+func TestHTTPChannels(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	settings := Settings{TelegramAPIURL: srv.URL, TelegramBotToken: "bot-token"}
+	routes := []models.NotificationRoute{
+		{Channel: models.ChannelTelegram, Target: models.NotificationTarget{ChatID: "42"}},
+		{Channel: models.ChannelSlack, Target: models.NotificationTarget{WebhookURL: srv.URL + "/slack"}},
+		{Channel: models.ChannelWebhook, Target: models.NotificationTarget{URL: srv.URL + "/hook", Secret: "s3cret"}},
+	}
+	for _, route := range routes {
+		n, err := settings.NotifierFor(route)
+		if err != nil {
+			t.Fatalf("%s: %v", route.Channel, err)
+		}
+		if err := n.Send(context.Background(), testMsg); err != nil {
+			t.Fatalf("%s: send failed: %v", route.Channel, err)
+		}
+	}
+
+	if rec.paths[0] != "/botbot-token/sendMessage" || !strings.Contains(rec.bodies[0], `"chat_id":"42"`) {
+		t.Errorf("telegram request = %s %s", rec.paths[0], rec.bodies[0])
+	}
+	if rec.paths[1] != "/slack" || !strings.Contains(rec.bodies[1], "Target hit: INFY") {
+		t.Errorf("slack request = %s %s", rec.paths[1], rec.bodies[1])
+	}
+
+	var got Message
+	if err := json.Unmarshal([]byte(rec.bodies[2]), &got); err != nil || got.Event != EventTargetHit {
+		t.Errorf("webhook body = %s (%v)", rec.bodies[2], err)
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(rec.bodies[2]))
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); rec.headers[2].Get("X-Signature-256") != want {
+		t.Errorf("webhook signature = %q, want %q", rec.headers[2].Get("X-Signature-256"), want)
+	}
+}
+
+func TestHTTPChannelErrorHidesToken(t *testing.T) {
+	srv := httptest.NewServer(&recorder{response: http.StatusUnauthorized})
+	defer srv.Close()
+
+	n, _ := Settings{TelegramAPIURL: srv.URL, TelegramBotToken: "bot-token"}.
+		NotifierFor(models.NotificationRoute{Channel: models.ChannelTelegram, Target: models.NotificationTarget{ChatID: "42"}})
+	err := n.Send(context.Background(), testMsg)
+	if err == nil || strings.Contains(err.Error(), "bot-token") {
+		t.Fatalf("err = %v, want a failure without the token", err)
+	}
+}
+
+// fakeSMTP accepts one message and returns its DATA section.
+func fakeSMTP(t *testing.T) (host string, port int, data <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	out := make(chan string, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 go ahead")
+				var b strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					b.WriteString(l)
+				}
+				out <- b.String()
+				reply("250 queued")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, out
+}
+
+func TestEmailChannel(t *testing.T) {
+	host, port, data := fakeSMTP(t)
+	settings := Settings{SMTP: SMTPSettings{Host: host, Port: port, From: "bot@example.com"}}
+
+	n, err := settings.NotifierFor(models.NotificationRoute{
+		Channel: models.ChannelEmail,
+		Target:  models.NotificationTarget{To: []string{"trader@example.com"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Send(context.Background(), testMsg); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+
+	mail := <-data
+	if !strings.Contains(mail, "Subject: Target hit: INFY") || !strings.Contains(mail, "SELL 10 INFY") {
+		t.Fatalf("mail = %q", mail)
+	}
+}
+
+func TestDispatcherRoutesAndRateLimits(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	store := &memStore{routes: []models.NotificationRoute{
+		{ID: 1, UserID: 1, Channel: models.ChannelSlack, Enabled: true, MinSeverity: SeverityInfo,
+			Target: models.NotificationTarget{WebhookURL: srv.URL + "/all"}},
+		{ID: 2, UserID: 2, Channel: models.ChannelSlack, Enabled: true, MinSeverity: SeverityCritical,
+			Target: models.NotificationTarget{WebhookURL: srv.URL + "/critical"}},
+		{ID: 3, UserID: 2, Channel: models.ChannelSlack, Enabled: true, MinSeverity: SeverityInfo,
+			Events: []string{EventJobFailed}, Target: models.NotificationTarget{WebhookURL: srv.URL + "/jobs"}},
+	}}
+	d := NewDispatcher(store, Settings{}, 2, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		d.Dispatch(context.Background(), testMsg)
+	}
+	d.Dispatch(context.Background(), Message{Event: EventJobFailed, Severity: SeverityCritical, Title: "job", Time: testMsg.Time})
+
+	if got := strings.Join(rec.paths, ","); got != "/all,/all,/critical,/jobs" {
+		t.Fatalf("delivered to %s", got)
+	}
+
+	statuses := map[string]int{}
+	for _, n := range store.notifications {
+		statuses[n.Status]++
+	}
+	if statuses[models.NotificationSent] != 4 || statuses[models.NotificationRateLimited] != 2 {
+		t.Fatalf("history statuses = %v", statuses)
+	}
+}
+
+func TestValidateRoute(t *testing.T) {
+	valid := models.NotificationRoute{Channel: models.ChannelWebhook, MinSeverity: SeverityInfo,
+		Target: models.NotificationTarget{URL: "https://example.com/hook"}}
+	if err := ValidateRoute(valid); err != nil {
+		t.Fatalf("valid route rejected: %v", err)
+	}
+
+	bad := []models.NotificationRoute{
+		{Channel: "SMS", MinSeverity: SeverityInfo},
+		{Channel: models.ChannelWebhook, MinSeverity: SeverityInfo, Target: models.NotificationTarget{URL: "ftp://x"}},
+		{Channel: models.ChannelEmail, MinSeverity: SeverityInfo, Target: models.NotificationTarget{To: []string{"a@b\r\nBcc: c@d"}}},
+		{Channel: models.ChannelTelegram, MinSeverity: SeverityInfo, Target: models.NotificationTarget{ChatID: "1"}, Events: []string{"NOPE"}},
+	}
+	for _, route := range bad {
+		if err := ValidateRoute(route); err == nil {
+			t.Errorf("route %+v accepted", route)
+		}
+	}
+}
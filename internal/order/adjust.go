@@ -0,0 +1,147 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+)
+
+// PositionAdjustment changes the exit levels of an open position. Nil fields
+// keep their current value. Target and StopLoss are points from the base price;
+// TrailingStopLoss is the trail distance in points, zero turns trailing off.
+type PositionAdjustment struct {
+	Target           *float64
+	StopLoss         *float64
+	TrailingStopLoss *float64
+}
+
+// exitLevels are the exit parameters of an open position.
+type exitLevels struct {
+	Target           float64
+	StopLoss         float64
+	TrailingStopLoss float64
+}
+
+// validateExitLevels checks new exit levels against the current LTP so that an
+// adjustment never fires an exit on the very next tick. A zero target is only
+// accepted in trailing mode, where the trailing stop closes the position. The
+// trailing stop needs no check of its own: it trails the trail distance behind
+// the price, on the protective side, and only ever tightens the stoploss.
+func validateExitLevels(direction string, basePrice, ltp float64, levels exitLevels) error {
+	if levels.TrailingStopLoss < 0 {
+		return fmt.Errorf("trailing stoploss cannot be negative")
+	}
+	if levels.Target < 0 {
+		return fmt.Errorf("target cannot be negative")
+	}
+	if levels.Target == 0 && levels.TrailingStopLoss == 0 {
+		return fmt.Errorf("target is required unless trailing stoploss is set")
+	}
+
+	switch direction {
+	case "BUY":
+		if levels.Target > 0 && basePrice+levels.Target <= ltp {
+			return fmt.Errorf("target %.2f is at or below LTP %.2f", basePrice+levels.Target, ltp)
+		}
+		if basePrice-levels.StopLoss >= ltp {
+			return fmt.Errorf("stoploss %.2f is at or above LTP %.2f", basePrice-levels.StopLoss, ltp)
+		}
+	case "SELL":
+		if levels.Target > 0 && basePrice-levels.Target >= ltp {
+			return fmt.Errorf("target %.2f is at or above LTP %.2f", basePrice-levels.Target, ltp)
+		}
+		if basePrice+levels.StopLoss <= ltp {
+			return fmt.Errorf("stoploss %.2f is at or below LTP %.2f", basePrice+levels.StopLoss, ltp)
+		}
+	default:
+		return fmt.Errorf("unknown position direction %q", direction)
+	}
+	return nil
+}
+
+// AdjustPosition changes the target, stoploss or trailing mode of the open
+// position on a tracked stock. The stock is locked while the levels are checked
+// and swapped, so no exit can fire on half-updated values. Stoplosses are
+// watched in memory, so there is no broker order to amend; while an entry or
+// exit order is in progress the adjustment is refused instead.
+func (oe *OrderEngine) AdjustPosition(trackingStockID int64, adj PositionAdjustment) (*models.PositionAdjustment, error) {
+	stock, exists := oe.trackingManager.GetTrackedStockByID(trackingStockID)
+	if !exists {
+		return nil, ErrStockNotTracked
+	}
+
+	openQty := stock.BuyQuantity
+	if stock.Direction == "SELL" {
+		openQty = stock.SellQuantity
+	}
+	if stock.Direction == "" || openQty == 0 || stock.BasePrice == 0 {
+		return nil, fmt.Errorf("%s has no open position", stock.TradingSymbol)
+	}
+
+	if !oe.trackingManager.TryLockStock(stock.InstrumentToken) {
+		return nil, fmt.Errorf("%s has an order in progress", stock.TradingSymbol)
+	}
+	defer oe.trackingManager.UnlockStock(stock.InstrumentToken)
+
+	// Re-read under the lock: the stoploss may have trailed since the first read.
+	stock, exists = oe.trackingManager.GetStock(stock.InstrumentToken)
+	if !exists {
+		return nil, ErrStockNotTracked
+	}
+	ltp, ok := oe.trackingManager.GetTSLtpByToken(stock.InstrumentToken)
+	if !ok || ltp <= 0 {
+		return nil, fmt.Errorf("LTP is not available for %s", stock.TradingSymbol)
+	}
+
+	levels := exitLevels{
+		Target:           stock.Target,
+		StopLoss:         stock.StopLoss,
+		TrailingStopLoss: stock.TrailingStopLoss,
+	}
+	if adj.Target != nil {
+		levels.Target = *adj.Target
+	}
+	if adj.StopLoss != nil {
+		levels.StopLoss = *adj.StopLoss
+	}
+	if adj.TrailingStopLoss != nil {
+		levels.TrailingStopLoss = *adj.TrailingStopLoss
+	}
+
+	if err := validateExitLevels(stock.Direction, stock.BasePrice, ltp, levels); err != nil {
+		return nil, err
+	}
+	if !oe.trackingManager.AdjustTradeParams(stock.InstrumentToken, levels.Target, levels.StopLoss, levels.TrailingStopLoss) {
+		return nil, ErrStockNotTracked
+	}
+
+	adjustment := &models.PositionAdjustment{
+		TrackingStockID:     stock.ID,
+		Kind:                models.AdjustmentKindAdjust,
+		Direction:           stock.Direction,
+		Quantity:            openQty,
+		BasePrice:           stock.BasePrice,
+		LTP:                 ltp,
+		OldTarget:           stock.Target,
+		NewTarget:           levels.Target,
+		OldStopLoss:         stock.StopLoss,
+		NewStopLoss:         levels.StopLoss,
+		OldTrailingStopLoss: stock.TrailingStopLoss,
+		NewTrailingStopLoss: levels.TrailingStopLoss,
+		CreatedAt:           time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := oe.OrderSvc.RecordPositionAdjustment(ctx, adjustment); err != nil {
+		log.Printf("⚠️ Failed to record position adjustment for %s: %v", stock.TradingSymbol, err)
+	}
+
+	log.Printf("🔧 Adjusted %s %s: target %.2f → %.2f, sl %.2f → %.2f, trail %.2f → %.2f (ltp=%.2f)",
+		stock.Direction, stock.TradingSymbol, stock.Target, levels.Target, stock.StopLoss, levels.StopLoss,
+		stock.TrailingStopLoss, levels.TrailingStopLoss, ltp)
+	return adjustment, nil
+}
@@ -0,0 +1,38 @@
+package order
+
+import "testing"
+
+func TestValidateExitLevels(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction string
+		ltp       float64
+		levels    exitLevels
+		wantErr   bool
+	}{
+		{"buy valid", "BUY", 102, exitLevels{Target: 5, StopLoss: 3}, false},
+		{"buy target already crossed", "BUY", 106, exitLevels{Target: 5, StopLoss: 3}, true},
+		{"buy stop above ltp", "BUY", 102, exitLevels{Target: 5, StopLoss: -3}, true},
+		{"buy stop trailed into profit", "BUY", 104, exitLevels{Target: 5, StopLoss: -2}, false},
+		{"buy trailing without target", "BUY", 102, exitLevels{StopLoss: 3, TrailingStopLoss: 1.5}, false},
+		{"buy trail wider than the stoploss", "BUY", 102, exitLevels{Target: 5, StopLoss: 3, TrailingStopLoss: 8}, false},
+		{"buy negative trail", "BUY", 102, exitLevels{Target: 5, StopLoss: 3, TrailingStopLoss: -1}, true},
+		{"no target without trailing", "BUY", 102, exitLevels{StopLoss: 3}, true},
+		{"sell valid", "SELL", 98, exitLevels{Target: 5, StopLoss: 3}, false},
+		{"sell target already crossed", "SELL", 94, exitLevels{Target: 5, StopLoss: 3}, true},
+		{"sell stop below ltp", "SELL", 98, exitLevels{Target: 5, StopLoss: -3}, true},
+		{"sell trailing without target", "SELL", 98, exitLevels{StopLoss: 3, TrailingStopLoss: 1.5}, false},
+		{"sell trail wider than the stoploss", "SELL", 98, exitLevels{Target: 5, StopLoss: 3, TrailingStopLoss: 8}, false},
+		{"sell negative trail", "SELL", 98, exitLevels{Target: 5, StopLoss: 3, TrailingStopLoss: -1}, true},
+		{"no position", "", 100, exitLevels{Target: 5, StopLoss: 3}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateExitLevels(tt.direction, 100, tt.ltp, tt.levels)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateExitLevels() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
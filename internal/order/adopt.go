@@ -0,0 +1,177 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+)
+
+// Position match statuses reported by BrokerPositions.
+const (
+	PositionManaged    = "MANAGED"     // the bot is managing exactly this quantity
+	PositionAdoptable  = "ADOPTABLE"   // tracked stock with no bot position: can be adopted
+	PositionMismatch   = "MISMATCH"    // the bot is managing a different quantity or side
+	PositionNotTracked = "NOT_TRACKED" // no active tracking stock for the symbol
+)
+
+// PositionMatch is an open broker MIS position matched against tracking.
+type PositionMatch struct {
+	TradingSymbol   string  `json:"trading_symbol"`
+	Exchange        string  `json:"exchange"`
+	Quantity        int     `json:"quantity"` // signed: positive long, negative short
+	AveragePrice    float64 `json:"average_price"`
+	LastPrice       float64 `json:"last_price"`
+	PnL             float64 `json:"pnl"`
+	TrackingStockID int64   `json:"tracking_stock_id,omitempty"`
+	ManagedQuantity int     `json:"managed_quantity"` // signed quantity the bot is managing
+	Status          string  `json:"status"`
+}
+
+// AdoptRequest sets the levels an adopted position is managed with. Target and
+// StopLoss are points from BasePrice; a zero BasePrice uses the broker's average.
+type AdoptRequest struct {
+	BasePrice        float64
+	Target           float64
+	StopLoss         float64
+	TrailingStopLoss float64
+}
+
+// BrokerPositions lists the open MIS positions at the broker and how each one
+// matches the positions the bot is managing.
+func (oe *OrderEngine) BrokerPositions() ([]PositionMatch, error) {
+	positions, err := oe.broker.GetPositions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch positions: %w", err)
+	}
+
+	matches := []PositionMatch{}
+	for _, p := range positions {
+		if p.Product != broker.ProductMIS || p.Quantity == 0 {
+			continue
+		}
+
+		match := PositionMatch{
+			TradingSymbol: p.TradingSymbol,
+			Exchange:      p.Exchange,
+			Quantity:      p.Quantity,
+			AveragePrice:  p.AveragePrice,
+			LastPrice:     p.LastPrice,
+			PnL:           p.PnL,
+			Status:        PositionNotTracked,
+		}
+
+		if stock, exists := oe.trackingManager.GetStockByTradingSymbol(p.TradingSymbol); exists && stock.Exchange == p.Exchange {
+			match.TrackingStockID = stock.ID
+			match.ManagedQuantity = int(stock.BuyQuantity) - int(stock.SellQuantity)
+			switch {
+			case match.ManagedQuantity == p.Quantity:
+				match.Status = PositionManaged
+			case match.ManagedQuantity == 0 && stock.Direction == "":
+				match.Status = PositionAdoptable
+			default:
+				match.Status = PositionMismatch
+			}
+		}
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// AdoptPosition hands an open broker position on a tracked stock over to the
+// AlgoEngine, which then manages its target, stoploss and 15:10 exit like a
+// position the bot opened itself.
+func (oe *OrderEngine) AdoptPosition(trackingStockID int64, req AdoptRequest) (*models.PositionAdjustment, error) {
+	stock, exists := oe.trackingManager.GetTrackedStockByID(trackingStockID)
+	if !exists {
+		return nil, ErrStockNotTracked
+	}
+	if stock.Direction != "" || stock.BuyQuantity > 0 || stock.SellQuantity > 0 {
+		return nil, fmt.Errorf("%s already has a %s position managed by the bot", stock.TradingSymbol, stock.Direction)
+	}
+
+	positions, err := oe.broker.GetPositions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch positions: %w", err)
+	}
+	var position *broker.Position
+	for i, p := range positions {
+		if p.TradingSymbol == stock.TradingSymbol && p.Exchange == stock.Exchange &&
+			p.Product == broker.ProductMIS && p.Quantity != 0 {
+			position = &positions[i]
+			break
+		}
+	}
+	if position == nil {
+		return nil, fmt.Errorf("no open MIS position for %s at the broker", stock.TradingSymbol)
+	}
+
+	direction := "BUY"
+	quantity := uint32(position.Quantity)
+	if position.Quantity < 0 {
+		direction = "SELL"
+		quantity = uint32(-position.Quantity)
+	}
+
+	basePrice := req.BasePrice
+	if basePrice <= 0 {
+		basePrice = position.AveragePrice
+	}
+	ltp, ok := oe.trackingManager.GetTSLtpByToken(stock.InstrumentToken)
+	if !ok || ltp <= 0 {
+		ltp = position.LastPrice
+	}
+
+	levels := exitLevels{
+		Target:           req.Target,
+		StopLoss:         req.StopLoss,
+		TrailingStopLoss: req.TrailingStopLoss,
+	}
+	if err := validateExitLevels(direction, basePrice, ltp, levels); err != nil {
+		return nil, err
+	}
+
+	if !oe.trackingManager.TryLockStock(stock.InstrumentToken) {
+		return nil, fmt.Errorf("%s has an order in progress", stock.TradingSymbol)
+	}
+	defer oe.trackingManager.UnlockStock(stock.InstrumentToken)
+
+	// An entry may have filled since the check above; the adoption is refused
+	// rather than overwriting that position.
+	if !oe.trackingManager.AdoptPosition(stock.InstrumentToken, direction, quantity, basePrice,
+		levels.Target, levels.StopLoss, levels.TrailingStopLoss) {
+		if current, exists := oe.trackingManager.GetStock(stock.InstrumentToken); exists {
+			return nil, fmt.Errorf("%s already has a %s position managed by the bot", current.TradingSymbol, current.Direction)
+		}
+		return nil, ErrStockNotTracked
+	}
+	oe.algoEngine.IncrementOpenTrade()
+
+	adoption := &models.PositionAdjustment{
+		TrackingStockID:     stock.ID,
+		Kind:                models.AdjustmentKindAdopt,
+		Direction:           direction,
+		Quantity:            quantity,
+		BasePrice:           basePrice,
+		LTP:                 ltp,
+		NewTarget:           levels.Target,
+		NewStopLoss:         levels.StopLoss,
+		NewTrailingStopLoss: levels.TrailingStopLoss,
+		CreatedAt:           time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := oe.OrderSvc.RecordPositionAdjustment(ctx, adoption); err != nil {
+		log.Printf("⚠️ Failed to record adoption of %s: %v", stock.TradingSymbol, err)
+	}
+	oe.OrderSvc.OpenAdoptedTrade(ctx, stock.ID, stock.InstrumentToken, stock.TradingSymbol, stock.Exchange,
+		direction, quantity, position.AveragePrice)
+
+	log.Printf("🤝 Adopted %s %s qty=%d base=%.2f target=%.2f sl=%.2f trail=%.2f",
+		direction, stock.TradingSymbol, quantity, basePrice, levels.Target, levels.StopLoss, levels.TrailingStopLoss)
+	return adoption, nil
+}
@@ -0,0 +1,31 @@
+package order
+
+import (
+	"log"
+	"math"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/algo"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/charges"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+)
+
+// widenTargetForCharges moves the target out by the round-trip charges per
+// share, rounded up to the tick, so that a target exit nets the configured
+// target points. It does nothing unless TARGET_INCLUDES_CHARGES is set.
+func widenTargetForCharges(signal *algo.TradeSignal, txType string, price float64) {
+	cfg := config.Current()
+	if !cfg.TargetIncludesCharges || signal.Target <= 0 {
+		return
+	}
+
+	costPoints := charges.Default().BreakEvenPoints(signal.Exchange, broker.ProductMIS, txType, int(signal.Quantity), price)
+	if costPoints <= 0 {
+		return
+	}
+	costPoints = math.Ceil(costPoints/tickSize-1e-9) * tickSize
+
+	log.Printf("💰 Target for %s widened by %.2f for round-trip charges: %.2f → %.2f",
+		signal.TradingSymbol, costPoints, signal.Target, signal.Target+costPoints)
+	signal.Target += costPoints
+}
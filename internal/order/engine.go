@@ -2,17 +2,21 @@ package order
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"log/slog"
 	"math"
 	"sync"
 	"time"
 
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/algo"
-	"github.com/SM-Sclass/stock_client2-go_backend/internal/kite"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/logging"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/metrics"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/services"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/tracking"
-	kiteconnect "github.com/zerodha/gokiteconnect/v4"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/utils"
 )
 
 const (
@@ -22,34 +26,78 @@ const (
 )
 
 type OrderEngine struct {
-	kiteClient      *kite.KiteClient
+	broker          broker.Broker
 	signalChan      chan algo.TradeSignal
 	trackingManager *tracking.TrackingManager
 	OrderSvc        *services.OrderService
 	algoEngine      *algo.AlgoEngine
+	entryPolicy     EntryExecutionPolicy
+	exitPolicy      ExitExecutionPolicy
+	onSignal        func(algo.TradeSignal)
+	logger          *slog.Logger
 	stopChan        chan struct{}
 	wg              sync.WaitGroup
+	supervisors     sync.WaitGroup // superviseEntry and superviseExit goroutines
 	running         bool
+	entriesClosed   bool
+	now             func() time.Time // clock for the trading window; nil uses time.Now
 	mu              sync.Mutex
 }
 
 func NewOrderEngine(
-	kiteClient *kite.KiteClient,
+	brk broker.Broker,
 	trackingManager *tracking.TrackingManager,
 	orderSvc *services.OrderService,
 	signalChan chan algo.TradeSignal,
 	algoEngine *algo.AlgoEngine,
 ) *OrderEngine {
 	return &OrderEngine{
-		kiteClient:      kiteClient,
+		broker:          brk,
 		signalChan:      signalChan,
 		trackingManager: trackingManager,
 		OrderSvc:        orderSvc,
 		algoEngine:      algoEngine,
+		entryPolicy:     LoadEntryExecutionPolicy(),
+		exitPolicy:      LoadExitExecutionPolicy(),
+		logger:          logging.For("order"),
 		stopChan:        make(chan struct{}),
 	}
 }
 
+// SetEntryPolicy replaces the execution policy used for new and recovered entries.
+func (oe *OrderEngine) SetEntryPolicy(policy EntryExecutionPolicy) {
+	oe.mu.Lock()
+	defer oe.mu.Unlock()
+	oe.entryPolicy = policy
+}
+
+func (oe *OrderEngine) getEntryPolicy() EntryExecutionPolicy {
+	oe.mu.Lock()
+	defer oe.mu.Unlock()
+	return oe.entryPolicy
+}
+
+// SetExitPolicy replaces the supervision policy used for target LIMIT exits.
+func (oe *OrderEngine) SetExitPolicy(policy ExitExecutionPolicy) {
+	oe.mu.Lock()
+	defer oe.mu.Unlock()
+	oe.exitPolicy = policy
+}
+
+func (oe *OrderEngine) getExitPolicy() ExitExecutionPolicy {
+	oe.mu.Lock()
+	defer oe.mu.Unlock()
+	return oe.exitPolicy
+}
+
+// SetSignalListener registers fn to be called with every signal the engine
+// takes up, algo or manual, before it is acted on.
+func (oe *OrderEngine) SetSignalListener(fn func(algo.TradeSignal)) {
+	oe.mu.Lock()
+	defer oe.mu.Unlock()
+	oe.onSignal = fn
+}
+
 // Start begins the order engine loop.
 func (oe *OrderEngine) Start() {
 	oe.mu.Lock()
@@ -79,6 +127,7 @@ func (oe *OrderEngine) Stop() {
 	oe.mu.Unlock()
 
 	oe.wg.Wait()
+	oe.supervisors.Wait()
 	log.Println("🛑 OrderEngine stopped")
 }
 
@@ -88,6 +137,41 @@ func (oe *OrderEngine) IsRunning() bool {
 	return oe.running
 }
 
+// CloseEntries makes the engine refuse new positions for the rest of the
+// process. Manual entries are rejected and queued entry signals are released
+// instead of placed; exits are unaffected.
+func (oe *OrderEngine) CloseEntries() {
+	oe.mu.Lock()
+	defer oe.mu.Unlock()
+	oe.entriesClosed = true
+}
+
+func (oe *OrderEngine) entriesAllowed() bool {
+	oe.mu.Lock()
+	defer oe.mu.Unlock()
+	return !oe.entriesClosed
+}
+
+// Shutdown closes entries and stops the engine once the signals already
+// queued have been handled and in-flight order calls have returned. It stops
+// waiting when ctx is done.
+func (oe *OrderEngine) Shutdown(ctx context.Context) error {
+	oe.CloseEntries()
+
+	done := make(chan struct{})
+	go func() {
+		oe.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // processLoop drains the signal channel and routes each signal to the correct handler.
 func (oe *OrderEngine) processLoop() {
 	defer oe.wg.Done()
@@ -95,6 +179,9 @@ func (oe *OrderEngine) processLoop() {
 	for {
 		select {
 		case <-oe.stopChan:
+			if !oe.entriesAllowed() {
+				oe.drainSignals()
+			}
 			return
 		case signal := <-oe.signalChan:
 			oe.processSignal(signal)
@@ -102,22 +189,69 @@ func (oe *OrderEngine) processLoop() {
 	}
 }
 
+// drainSignals handles the signals still queued when the engine shuts down,
+// so exits already decided on are placed before the process exits.
+func (oe *OrderEngine) drainSignals() {
+	for {
+		select {
+		case signal := <-oe.signalChan:
+			oe.processSignal(signal)
+		default:
+			return
+		}
+	}
+}
+
 // processSignal dispatches a trade signal to the appropriate order handler.
 func (oe *OrderEngine) processSignal(signal algo.TradeSignal) {
+	// Manual and webhook signals are queued here without an ID.
+	if signal.CorrelationID == "" {
+		signal.CorrelationID = logging.NewCorrelationID()
+	}
+
+	oe.mu.Lock()
+	onSignal := oe.onSignal
+	oe.mu.Unlock()
+	if onSignal != nil {
+		onSignal(signal)
+	}
+
+	isEntry := signal.SignalType == algo.SignalEntryBuy || signal.SignalType == algo.SignalEntrySell
+	if isEntry && !oe.entriesAllowed() {
+		oe.releaseEntry(signal)
+		return
+	}
+
 	switch signal.SignalType {
 	case algo.SignalEntryBuy:
-		oe.processEntry(signal, kiteconnect.TransactionTypeBuy)
+		oe.processEntry(signal, broker.TransactionTypeBuy)
 	case algo.SignalEntrySell:
-		oe.processEntry(signal, kiteconnect.TransactionTypeSell)
+		oe.processEntry(signal, broker.TransactionTypeSell)
 	case algo.SignalTargetHit:
-		oe.processExit(signal, kiteconnect.OrderTypeLimit)
+		oe.processExit(signal, broker.OrderTypeLimit)
 	case algo.SignalStopLossHit, algo.SignalForceExit:
-		oe.processExit(signal, kiteconnect.OrderTypeMarket)
+		oe.processExit(signal, broker.OrderTypeMarket)
+	case algo.SignalManualExit:
+		if signal.LimitPrice > 0 {
+			oe.processExit(signal, broker.OrderTypeLimit)
+		} else {
+			oe.processExit(signal, broker.OrderTypeMarket)
+		}
 	default:
-		log.Printf("⚠️ Unknown signal type: %s for %s", signal.SignalType, signal.TradingSymbol)
+		oe.signalLog(signal).Warn("⚠️ Unknown signal type", "signal_type", signal.SignalType)
 	}
 }
 
+// releaseEntry gives back what a queued entry signal reserved: the daily and
+// open trade slots, the stock's firing state and its lock.
+func (oe *OrderEngine) releaseEntry(signal algo.TradeSignal) {
+	oe.signalLog(signal).Warn("🚫 Entries are closed, dropping entry signal", "signal_type", signal.SignalType)
+	oe.algoEngine.DecrementDailyTrade()
+	oe.algoEngine.DecrementOpenTrade()
+	oe.trackingManager.ResetFiringAndDirection(signal.InstrumentToken)
+	oe.trackingManager.UnlockStock(signal.InstrumentToken)
+}
+
 // processEntry places a market order to open a new long or short position.
 func (oe *OrderEngine) processEntry(signal algo.TradeSignal, txType string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -130,30 +264,52 @@ func (oe *OrderEngine) processEntry(signal algo.TradeSignal, txType string) {
 	// }
 
 	limitPrice := signal.BasePrice
-	if txType == kiteconnect.TransactionTypeBuy {
+	if txType == broker.TransactionTypeBuy {
 		limitPrice = signal.BasePrice * (1 + entryLimitOffsetPct)
 	} else {
 		limitPrice = signal.BasePrice * (1 - entryLimitOffsetPct)
 	}
+	if signal.LimitPrice > 0 {
+		limitPrice = signal.LimitPrice
+	}
 	limitPrice = roundToTick(limitPrice, tickSize)
 
-	orderParams := kiteconnect.OrderParams{
+	if !oe.clampToMargin(&signal, txType, limitPrice) {
+		oe.signalLog(signal).Warn("❌ Insufficient margin for entry, dropping signal")
+		oe.algoEngine.DecrementDailyTrade()
+		oe.algoEngine.DecrementOpenTrade()
+		oe.trackingManager.ResetFiringAndDirection(signal.InstrumentToken)
+		oe.trackingManager.UnlockStock(signal.InstrumentToken)
+		return
+	}
+	widenTargetForCharges(&signal, txType, limitPrice)
+
+	orderParams := broker.OrderParams{
 		Exchange:        signal.Exchange,
-		Tradingsymbol:   signal.TradingSymbol,
+		TradingSymbol:   signal.TradingSymbol,
 		TransactionType: txType,
 		Quantity:        int(signal.Quantity),
-		Product:         kiteconnect.ProductMIS,
-		OrderType:       kiteconnect.OrderTypeLimit,
+		Product:         broker.ProductMIS,
+		OrderType:       broker.OrderTypeLimit,
 		Price:           limitPrice,
-		Validity:        kiteconnect.ValidityDay,
+		Validity:        broker.ValidityDay,
+	}
+	source := signalSource(signal)
+	if signal.Manual {
+		orderParams.Tag = manualOrderTag
+		if signal.LimitPrice == 0 {
+			orderParams.OrderType = broker.OrderTypeMarket
+			orderParams.Price = 0
+			orderParams.MarketProtection = 1
+		}
 	}
 
-	log.Printf("📤 Placing entry %s LIMIT order for %s qty=%d @ %.2f",
-		txType, signal.TradingSymbol, signal.Quantity, limitPrice)
+	oe.signalLog(signal).Info("📤 Placing entry order", "side", txType, "order_type", orderParams.OrderType,
+		"qty", signal.Quantity, "price", orderParams.Price)
 
-	orderResponse, err := oe.kiteClient.PlaceRegularOrder(orderParams)
+	orderID, err := oe.placeOrder("entry", orderParams)
 	if err != nil {
-		log.Printf("❌ Failed to place entry order for %s: %v", signal.TradingSymbol, err)
+		oe.signalLog(signal).Error("❌ Failed to place entry order", "error", err)
 		// Allow a new trade since this one failed
 		oe.algoEngine.DecrementDailyTrade()
 		oe.algoEngine.DecrementOpenTrade()
@@ -168,20 +324,30 @@ func (oe *OrderEngine) processEntry(signal algo.TradeSignal, txType string) {
 
 	order := &models.Order{
 		TrackingStockID: signal.TrackingStockID,
-		OrderID:         orderResponse.OrderID,
-		OrderType:       kiteconnect.OrderTypeLimit,
+		OrderID:         orderID,
+		OrderType:       orderParams.OrderType,
 		EventType:       string(signal.SignalType),
 		BasePrice:       signal.BasePrice,
 		Quantity:        float64(signal.Quantity),
+		SizingNote:      utils.ToNullString(signal.SizingNote),
+		Source:          source,
+		CorrelationID:   utils.ToNullString(signal.CorrelationID),
 		Status:          "PENDING",
 		PlacedAt:        time.Now(),
 	}
 
 	if err := oe.OrderSvc.AddPlacedOrder(ctx, order); err != nil {
-		log.Printf("⚠️ Failed to save entry order for %s: %v", signal.TradingSymbol, err)
+		oe.signalLog(signal).Warn("⚠️ Failed to save entry order", "order_id", orderID, "error", err)
 	}
+	oe.recordOrderEvent(signal, orderID, OrderEventPlaced, orderParams.Price, int(signal.Quantity), source)
 
-	go oe.ensureEntryCompletionAfter(signal, txType, orderResponse.OrderID, int(signal.Quantity), entryLimitTimeout)
+	// A manual LIMIT rests at the trader's price; only strategy entries are chased.
+	if signal.Manual {
+		return
+	}
+	oe.supervise(func() {
+		oe.superviseEntry(signal, txType, orderID, int(signal.Quantity), oe.getEntryPolicy().InitialWait)
+	})
 }
 
 func (oe *OrderEngine) RecoverPendingEntryOrder(order models.Order) {
@@ -191,14 +357,14 @@ func (oe *OrderEngine) RecoverPendingEntryOrder(order models.Order) {
 		return
 	}
 
-	txType := kiteconnect.TransactionTypeBuy
+	txType := broker.TransactionTypeBuy
 	direction := "BUY"
 	switch order.EventType {
 	case string(algo.SignalEntryBuy):
-		txType = kiteconnect.TransactionTypeBuy
+		txType = broker.TransactionTypeBuy
 		direction = "BUY"
 	case string(algo.SignalEntrySell):
-		txType = kiteconnect.TransactionTypeSell
+		txType = broker.TransactionTypeSell
 		direction = "SELL"
 	default:
 		return
@@ -209,7 +375,7 @@ func (oe *OrderEngine) RecoverPendingEntryOrder(order models.Order) {
 		return
 	}
 
-	delay := time.Until(order.PlacedAt.Add(entryLimitTimeout))
+	delay := time.Until(order.PlacedAt.Add(oe.getEntryPolicy().InitialWait))
 	if delay < 0 {
 		delay = 0
 	}
@@ -225,67 +391,179 @@ func (oe *OrderEngine) RecoverPendingEntryOrder(order models.Order) {
 		Quantity:        uint32(requestedQty),
 		Timestamp:       order.PlacedAt,
 	}
+	if order.CorrelationID != nil {
+		signal.CorrelationID = *order.CorrelationID
+	}
 
-	log.Printf("♻️ Recovery: re-arming entry timeout for %s order=%s in %s",
-		signal.TradingSymbol, order.OrderID, delay.Round(time.Second))
+	oe.signalLog(signal).Info("♻️ Recovery: re-arming entry timeout", "order_id", order.OrderID,
+		"delay", delay.Round(time.Second))
 
-	go oe.ensureEntryCompletionAfter(signal, txType, order.OrderID, requestedQty, delay)
+	oe.supervise(func() { oe.superviseEntry(signal, txType, order.OrderID, requestedQty, delay) })
 }
 
-func (oe *OrderEngine) ensureEntryCompletionAfter(signal algo.TradeSignal, txType, entryOrderID string, requestedQty int, wait time.Duration) {
-	if wait < 0 {
-		wait = 0
+// RecoverPendingExitOrder re-arms the supervision of a pending LIMIT exit that
+// was restored after a restart and is still working at the broker, so it is
+// again converted to MARKET on timeout or retrace. The timeout runs from when
+// the exit was placed. Manual exits are left at the trader's price, as when
+// they were placed, and only settled once the stoploss has converted them.
+func (oe *OrderEngine) RecoverPendingExitOrder(trackingStockID int64, orderID string) {
+	stock, exists := oe.trackingManager.GetTrackedStockByID(trackingStockID)
+	if !exists || stock.PendingExitOrderID != orderID {
+		return
 	}
 
-	select {
-	case <-oe.stopChan:
+	latest, ok := oe.latestOrderState(orderID)
+	if !ok || latest.IsTerminal() || latest.OrderType != broker.OrderTypeLimit {
 		return
-	case <-time.After(wait):
 	}
 
-	history, err := oe.kiteClient.GetOrderHistory(entryOrderID)
-	if err != nil {
-		log.Printf("⚠️ Cannot verify entry order %s status: %v", entryOrderID, err)
-		return
+	signal := algo.TradeSignal{
+		TrackingStockID: stock.ID,
+		InstrumentToken: stock.InstrumentToken,
+		TradingSymbol:   stock.TradingSymbol,
+		Exchange:        stock.Exchange,
+		SignalType:      algo.SignalTargetHit,
+		Direction:       stock.Direction,
+		BasePrice:       stock.BasePrice,
 	}
-	if len(history) == 0 {
-		log.Printf("⚠️ Empty order history for %s", entryOrderID)
-		return
+	placedAt := latest.OrderTimestamp
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if order, err := oe.OrderSvc.OrderRepo.GetOrderByKiteOrderID(ctx, orderID); err == nil {
+		if order.Source != "" && order.Source != models.OrderSourceAlgo {
+			signal.Manual = true
+			signal.Source = order.Source
+		}
+		signal.SignalType = algo.SignalType(order.EventType)
+		if order.CorrelationID != nil {
+			signal.CorrelationID = *order.CorrelationID
+		}
+		placedAt = order.PlacedAt
+	}
+	if placedAt.IsZero() {
+		placedAt = time.Now()
 	}
 
-	latest := history[len(history)-1]
-	if latest.Status == "COMPLETE" || latest.Status == "CANCELLED" || latest.Status == "REJECTED" {
-		return
+	oe.signalLog(signal).Info("♻️ Recovery: re-arming exit supervision", "order_id", orderID,
+		"limit", latest.Price, "placed_at", placedAt)
+
+	oe.supervise(func() { oe.superviseExit(signal, latest.TransactionType, orderID, latest.Price, placedAt) })
+}
+
+// supervise runs an order supervisor in the background. Stop waits for it, so
+// a broker call it has started is not cut off.
+func (oe *OrderEngine) supervise(fn func()) {
+	oe.supervisors.Add(1)
+	go func() {
+		defer oe.supervisors.Done()
+		fn()
+	}()
+}
+
+// superviseEntry follows an entry LIMIT order until it fills. Every StepInterval
+// the LIMIT price is modified toward the touch, within the slippage budget of the
+// entry policy. Once the steps or the budget run out, the rest of the order is
+// either sent as MARKET or abandoned. Each step is recorded as an order event.
+func (oe *OrderEngine) superviseEntry(signal algo.TradeSignal, txType, entryOrderID string, requestedQty int, wait time.Duration) {
+	policy := oe.getEntryPolicy()
+	if wait < 0 {
+		wait = 0
 	}
 
-	filledQty := int(latest.FilledQuantity)
-	remainingQty := requestedQty - filledQty
-	if remainingQty <= 0 {
+	for step := 0; ; step++ {
+		select {
+		case <-oe.stopChan:
+			return
+		case <-time.After(wait):
+		}
+		wait = policy.StepInterval
+
+		latest, ok := oe.latestOrderState(entryOrderID)
+		if !ok {
+			return
+		}
+		if latest.Status == "COMPLETE" || latest.Status == "CANCELLED" || latest.Status == "REJECTED" {
+			return
+		}
+
+		remainingQty := requestedQty - int(latest.FilledQuantity)
+		if remainingQty <= 0 {
+			return
+		}
+
+		if step >= policy.MaxSteps {
+			oe.finishStaleEntry(signal, txType, entryOrderID, remainingQty, latest.Price,
+				fmt.Sprintf("not filled after %d chase steps", policy.MaxSteps))
+			return
+		}
+
+		touch := oe.touchPrice(signal, txType)
+		price, ok := policy.nextChasePrice(txType, signal.BasePrice, latest.Price, touch)
+		if !ok {
+			oe.recordOrderEvent(signal, entryOrderID, OrderEventBudgetExceeded, latest.Price, remainingQty,
+				fmt.Sprintf("touch=%.2f cap=%.2f", touch, policy.priceCap(txType, signal.BasePrice)))
+			oe.finishStaleEntry(signal, txType, entryOrderID, remainingQty, latest.Price, "slippage budget exhausted")
+			return
+		}
+
+		if err := oe.broker.ModifyOrder(entryOrderID, broker.OrderParams{
+			OrderType: broker.OrderTypeLimit,
+			Price:     price,
+		}); err != nil {
+			oe.signalLog(signal).Warn("⚠️ Failed to modify entry order", "order_id", entryOrderID, "error", err)
+			continue
+		}
+
+		oe.signalLog(signal).Info("🏃 Chasing entry", "order_id", entryOrderID, "from", latest.Price, "to", price,
+			"step", step+1, "max_steps", policy.MaxSteps, "touch", touch)
+		oe.recordOrderEvent(signal, entryOrderID, OrderEventChaseStep, price, remainingQty,
+			fmt.Sprintf("step %d/%d from %.2f, touch=%.2f", step+1, policy.MaxSteps, latest.Price, touch))
+	}
+}
+
+// finishStaleEntry cancels an entry order that could not be filled by chasing and
+// applies the policy fallback to the remaining quantity.
+func (oe *OrderEngine) finishStaleEntry(signal algo.TradeSignal, txType, entryOrderID string, remainingQty int, lastPrice float64, reason string) {
+	policy := oe.getEntryPolicy()
+
+	if err := oe.broker.CancelOrder(entryOrderID); err != nil {
+		oe.signalLog(signal).Warn("⚠️ Failed to cancel stale entry order", "order_id", entryOrderID, "error", err)
 		return
 	}
+	oe.recordOrderEvent(signal, entryOrderID, OrderEventCancelled, lastPrice, remainingQty, reason)
+
+	if policy.Fallback == EntryFallbackAbandon {
+		oe.signalLog(signal).Info("🚫 Entry abandoned", "order_id", entryOrderID, "reason", reason,
+			"remaining_qty", remainingQty)
+		oe.recordOrderEvent(signal, entryOrderID, OrderEventAbandoned, lastPrice, remainingQty, reason)
 
-	if _, err := oe.kiteClient.CancelRegularOrder(entryOrderID); err != nil {
-		log.Printf("⚠️ Failed to cancel stale entry order %s: %v", entryOrderID, err)
+		// Nothing filled: free the daily slot the same way a failed placement does.
+		if remainingQty == int(signal.Quantity) {
+			oe.algoEngine.DecrementDailyTrade()
+			oe.algoEngine.DecrementOpenTrade()
+			oe.trackingManager.ResetFiringAndDirection(signal.InstrumentToken)
+		}
 		return
 	}
 
-	marketParams := kiteconnect.OrderParams{
+	marketParams := broker.OrderParams{
 		Exchange:         signal.Exchange,
-		Tradingsymbol:    signal.TradingSymbol,
+		TradingSymbol:    signal.TradingSymbol,
 		TransactionType:  txType,
 		Quantity:         remainingQty,
-		Product:          kiteconnect.ProductMIS,
-		OrderType:        kiteconnect.OrderTypeMarket,
-		Validity:         kiteconnect.ValidityDay,
+		Product:          broker.ProductMIS,
+		OrderType:        broker.OrderTypeMarket,
+		Validity:         broker.ValidityDay,
 		MarketProtection: 1,
 	}
 
-	log.Printf("⏱️ Entry %s not complete in 10s for %s. Placing MARKET for remaining qty=%d",
-		entryOrderID, signal.TradingSymbol, remainingQty)
+	oe.signalLog(signal).Info("⏱️ Entry not complete, placing MARKET for the rest", "order_id", entryOrderID,
+		"reason", reason, "remaining_qty", remainingQty)
 
-	marketResp, err := oe.kiteClient.PlaceRegularOrder(marketParams)
+	marketOrderID, err := oe.placeOrder("entry_fallback", marketParams)
 	if err != nil {
-		log.Printf("❌ Failed fallback market entry for %s: %v", signal.TradingSymbol, err)
+		oe.signalLog(signal).Error("❌ Failed fallback market entry", "error", err)
 		return
 	}
 
@@ -294,28 +572,100 @@ func (oe *OrderEngine) ensureEntryCompletionAfter(signal algo.TradeSignal, txTyp
 
 	marketOrder := &models.Order{
 		TrackingStockID: signal.TrackingStockID,
-		OrderID:         marketResp.OrderID,
-		OrderType:       kiteconnect.OrderTypeMarket,
+		OrderID:         marketOrderID,
+		OrderType:       broker.OrderTypeMarket,
 		EventType:       string(signal.SignalType),
 		BasePrice:       signal.BasePrice,
 		Quantity:        float64(remainingQty),
+		Source:          signalSource(signal),
+		CorrelationID:   utils.ToNullString(signal.CorrelationID),
 		Status:          "PENDING",
 		PlacedAt:        time.Now(),
 	}
 
 	if err := oe.OrderSvc.AddPlacedOrder(ctx, marketOrder); err != nil {
-		log.Printf("⚠️ Failed to save fallback market entry for %s: %v", signal.TradingSymbol, err)
+		oe.signalLog(signal).Warn("⚠️ Failed to save fallback market entry", "order_id", marketOrderID, "error", err)
+	}
+	oe.recordOrderEvent(signal, entryOrderID, OrderEventMarketFallback, 0, remainingQty,
+		fmt.Sprintf("replaced by market order %s", marketOrderID))
+}
+
+// latestOrderState returns the most recent entry of the order's history.
+func (oe *OrderEngine) latestOrderState(orderID string) (broker.Order, bool) {
+	history, err := oe.broker.GetOrderHistory(orderID)
+	if err != nil {
+		log.Printf("⚠️ Cannot verify order %s status: %v", orderID, err)
+		return broker.Order{}, false
+	}
+	if len(history) == 0 {
+		log.Printf("⚠️ Empty order history for %s", orderID)
+		return broker.Order{}, false
+	}
+	return history[len(history)-1], true
+}
+
+// touchPrice returns the price an order on the given side would trade at right
+// now: the best ask for a BUY, the best bid for a SELL, falling back to LTP.
+func (oe *OrderEngine) touchPrice(signal algo.TradeSignal, txType string) float64 {
+	instStr := fmt.Sprintf("%s:%s", signal.Exchange, signal.TradingSymbol)
+	quotes, err := oe.broker.GetQuote(instStr)
+	if err != nil {
+		oe.signalLog(signal).Warn("⚠️ Cannot fetch quote", "instrument", instStr, "error", err)
+		if ltp, ok := oe.trackingManager.GetTSLtpByToken(signal.InstrumentToken); ok {
+			return ltp
+		}
+		return 0
+	}
+
+	quote, exists := quotes[instStr]
+	if !exists {
+		return 0
+	}
+	if txType == broker.TransactionTypeBuy && quote.BestAsk > 0 {
+		return quote.BestAsk
+	}
+	if txType == broker.TransactionTypeSell && quote.BestBid > 0 {
+		return quote.BestBid
+	}
+	return quote.LastPrice
+}
+
+// recordOrderEvent stores one engine step on an order. Failures are only logged.
+func (oe *OrderEngine) recordOrderEvent(signal algo.TradeSignal, orderID, event string, price float64, qty int, message string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	orderEvent := &models.OrderEvent{
+		TrackingStockID: signal.TrackingStockID,
+		OrderID:         orderID,
+		Event:           event,
+		Price:           utils.ToNullFloat(price),
+		Quantity:        float64(qty),
+		Message:         utils.ToNullString(message),
+	}
+	if err := oe.OrderSvc.RecordOrderEvent(ctx, orderEvent); err != nil {
+		oe.signalLog(signal).Warn("⚠️ Failed to record order event", "event", event, "order_id", orderID, "error", err)
 	}
 }
 
 // processExit closes an open position with either a LIMIT (target) or MARKET (stoploss/force) order.
 func (oe *OrderEngine) processExit(signal algo.TradeSignal, orderType string) {
+	// An exit is already working at the broker: escalate it instead of sending a second one.
+	if signal.PendingOrderID != "" {
+		event := OrderEventExitStopLoss
+		if signal.SignalType == algo.SignalForceExit {
+			event = OrderEventExitTimeout
+		}
+		oe.convertExitToMarket(signal, signal.PendingOrderID, event, string(signal.SignalType))
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
 	trackedStock, exists := oe.trackingManager.GetStock(signal.InstrumentToken)
 	if !exists {
-		log.Printf("⚠️ No tracked stock for token %d", signal.InstrumentToken)
+		oe.signalLog(signal).Warn("⚠️ No tracked stock for token", "instrument_token", signal.InstrumentToken)
 		return
 	}
 
@@ -325,31 +675,39 @@ func (oe *OrderEngine) processExit(signal algo.TradeSignal, orderType string) {
 	}
 
 	if openQty == 0 {
-		log.Printf("⚠️ %s SellQuantity is 0, skipping stale exit signal", trackedStock.TradingSymbol)
+		oe.signalLog(signal).Warn("⚠️ No open quantity, skipping stale exit signal")
 		oe.trackingManager.UnlockStock(signal.InstrumentToken)
 		return
 	}
 
+	// A manual exit may close only part of the position.
+	exitQty := openQty
+	if signal.Quantity > 0 && signal.Quantity < openQty {
+		exitQty = signal.Quantity
+	}
+
 	// The closing side is the opposite of the open direction.
-	closeTxType := kiteconnect.TransactionTypeSell
+	closeTxType := broker.TransactionTypeSell
 	if signal.Direction == "SELL" {
-		closeTxType = kiteconnect.TransactionTypeBuy
+		closeTxType = broker.TransactionTypeBuy
 	}
 
-	orderParams := kiteconnect.OrderParams{
+	orderParams := broker.OrderParams{
 		Exchange:         signal.Exchange,
-		Tradingsymbol:    signal.TradingSymbol,
+		TradingSymbol:    signal.TradingSymbol,
 		TransactionType:  closeTxType,
-		Quantity:         int(openQty),
-		Product:          kiteconnect.ProductMIS,
+		Quantity:         int(exitQty),
+		Product:          broker.ProductMIS,
 		OrderType:        orderType,
 		Price:            signal.BasePrice, // For LIMIT orders, this is adjusted in processExit based on target/stoploss
 		MarketProtection: 1,                // Avoid orders getting executed at crazy prices due to stale signals or sudden price spikes
-		Validity:         kiteconnect.ValidityDay,
+		Validity:         broker.ValidityDay,
 	}
 
 	// For LIMIT orders (target hit), set the limit price.
-	if orderType == kiteconnect.OrderTypeLimit {
+	if orderType == broker.OrderTypeLimit && signal.LimitPrice > 0 {
+		orderParams.Price = roundToTick(signal.LimitPrice, tickSize)
+	} else if orderType == broker.OrderTypeLimit {
 		if signal.Direction == "SELL" {
 			orderParams.Price = signal.BasePrice - signal.Target
 		} else {
@@ -357,34 +715,175 @@ func (oe *OrderEngine) processExit(signal algo.TradeSignal, orderType string) {
 		}
 	}
 
-	log.Printf("📤 Placing exit %s %s order for %s qty=%d type=%s",
-		closeTxType, signal.SignalType, signal.TradingSymbol, openQty, orderType)
+	source := signalSource(signal)
+	if signal.Manual {
+		orderParams.Tag = manualOrderTag
+	}
+
+	oe.signalLog(signal).Info("📤 Placing exit order", "side", closeTxType, "signal_type", signal.SignalType,
+		"qty", exitQty, "open_qty", openQty, "order_type", orderType)
 
-	orderResponse, err := oe.kiteClient.PlaceRegularOrder(orderParams)
+	// Reserve the pending exit before placing, since the fill can be
+	// processed before placeOrder returns.
+	if orderType == broker.OrderTypeLimit {
+		oe.trackingManager.ReservePendingExit(signal.InstrumentToken)
+	}
+	orderID, err := oe.placeOrder("exit", orderParams)
 	if err != nil {
-		log.Printf("❌ Failed to place exit order for %s: %v", signal.TradingSymbol, err)
+		oe.signalLog(signal).Error("❌ Failed to place exit order", "error", err)
+		if orderType == broker.OrderTypeLimit {
+			oe.trackingManager.ReleasePendingExit(signal.InstrumentToken)
+		}
 		oe.trackingManager.UnlockStock(signal.InstrumentToken)
 		return
 	}
 
 	order := &models.Order{
 		TrackingStockID: signal.TrackingStockID,
-		OrderID:         orderResponse.OrderID,
+		OrderID:         orderID,
 		OrderType:       orderType,
 		EventType:       string(signal.SignalType),
 		BasePrice:       signal.BasePrice,
-		Quantity:        float64(openQty),
+		Quantity:        float64(exitQty),
+		Source:          source,
+		CorrelationID:   utils.ToNullString(signal.CorrelationID),
 		Status:          "PENDING",
 		PlacedAt:        time.Now(),
 	}
 
 	if err := oe.OrderSvc.AddPlacedOrder(ctx, order); err != nil {
-		log.Printf("⚠️ Failed to save exit order for %s: %v", signal.TradingSymbol, err)
+		oe.signalLog(signal).Warn("⚠️ Failed to save exit order", "order_id", orderID, "error", err)
+	}
+	oe.recordOrderEvent(signal, orderID, OrderEventPlaced, orderParams.Price, int(exitQty), string(signal.SignalType))
+
+	// Target exits are LIMIT orders and may never fill if price reverses. The
+	// stoploss keeps watching any pending LIMIT exit, but a manual one is left
+	// at the trader's price rather than converted on a timer.
+	if orderType == broker.OrderTypeLimit && oe.trackingManager.SetPendingExit(signal.InstrumentToken, orderID) {
+		oe.supervise(func() { oe.superviseExit(signal, closeTxType, orderID, orderParams.Price, time.Now()) })
 	}
 
 	// Notify the algo engine that the position is being closed so it can
 	// accept a new trade if the daily limit allows.
-	oe.algoEngine.DecrementOpenTrade()
+	if exitQty == openQty {
+		oe.algoEngine.DecrementOpenTrade()
+	}
+}
+
+// superviseExit watches a LIMIT exit until it is no longer pending. If it is
+// still open after the policy timeout from placedAt, or the LTP retraces past
+// the limit by the policy threshold, the order is converted to MARKET; a
+// manual exit is only converted by the stoploss. A conversion that fails is
+// retried on the next check, and a converted exit is settled from the broker
+// once it is complete, cancelled or rejected, should its postback be missed.
+func (oe *OrderEngine) superviseExit(signal algo.TradeSignal, closeTxType, orderID string, limitPrice float64, placedAt time.Time) {
+	policy := oe.getExitPolicy()
+	deadline := placedAt.Add(policy.Timeout)
+
+	for {
+		select {
+		case <-oe.stopChan:
+			return
+		case <-time.After(policy.CheckInterval):
+		}
+
+		stock, exists := oe.trackingManager.GetStock(signal.InstrumentToken)
+		if !exists || stock.PendingExitOrderID != orderID {
+			// Filled, cancelled or rejected.
+			return
+		}
+		if stock.ExitEscalated {
+			// Being converted, here or by a stoploss tick; a failed
+			// conversion re-arms the exit and is retried below. A converted
+			// exit is settled from the broker in case its postback is missed.
+			if latest, ok := oe.latestOrderState(orderID); ok && oe.settleExit(signal, orderID, latest) {
+				return
+			}
+			continue
+		}
+		if signal.Manual {
+			continue
+		}
+
+		ltp, _ := oe.trackingManager.GetTSLtpByToken(signal.InstrumentToken)
+		switch {
+		case policy.retraced(closeTxType, limitPrice, ltp):
+			if oe.trackingManager.TryEscalateExit(signal.InstrumentToken) {
+				oe.convertExitToMarket(signal, orderID, OrderEventExitRetrace,
+					fmt.Sprintf("ltp %.2f retraced past limit %.2f", ltp, limitPrice))
+			}
+		case time.Now().After(deadline):
+			if oe.trackingManager.TryEscalateExit(signal.InstrumentToken) {
+				oe.convertExitToMarket(signal, orderID, OrderEventExitTimeout,
+					fmt.Sprintf("unfilled after %s", policy.Timeout))
+			}
+		}
+	}
+}
+
+// convertExitToMarket modifies a working exit order to MARKET. The caller must
+// have claimed the escalation through TryEscalateExit.
+func (oe *OrderEngine) convertExitToMarket(signal algo.TradeSignal, orderID, reasonEvent, reason string) {
+	latest, ok := oe.latestOrderState(orderID)
+	if ok && latest.IsTerminal() {
+		// Nothing is working at the broker any more, and the postback may
+		// already have been missed. An exit that cannot be settled now stays
+		// escalated for its supervisor to settle.
+		oe.settleExit(signal, orderID, latest)
+		return
+	}
+	oe.recordOrderEvent(signal, orderID, reasonEvent, latest.Price, int(latest.PendingQuantity), reason)
+
+	if err := oe.broker.ModifyOrder(orderID, broker.OrderParams{
+		OrderType: broker.OrderTypeMarket,
+	}); err != nil {
+		oe.signalLog(signal).Error("❌ Failed to convert exit to MARKET", "order_id", orderID, "error", err)
+		// Re-arm so the exit's supervisor or the next stoploss tick tries again.
+		oe.trackingManager.RearmPendingExit(signal.InstrumentToken, orderID)
+		return
+	}
+
+	oe.signalLog(signal).Info("⚡ Exit converted to MARKET", "order_id", orderID, "reason", reason)
+	oe.recordOrderEvent(signal, orderID, OrderEventExitToMarket, 0, int(latest.PendingQuantity), reason)
+}
+
+// settleExit applies a pending exit's terminal state at the broker as its
+// postback would: a fill closes the position, a cancellation or rejection
+// clears the exit. It reports whether the exit is settled; an order still
+// working, or a state that could not be applied yet, is left to the caller.
+func (oe *OrderEngine) settleExit(signal algo.TradeSignal, orderID string, latest broker.Order) bool {
+	if !latest.IsTerminal() {
+		return false
+	}
+	if stock, exists := oe.trackingManager.GetStock(signal.InstrumentToken); !exists || stock.PendingExitOrderID != orderID {
+		// The postback got there first.
+		return true
+	}
+	oe.signalLog(signal).Warn("⚠️ Pending exit settled from the broker", "order_id", orderID, "status", latest.Status)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := oe.OrderSvc.ProcessOrderUpdate(ctx, latest); err != nil {
+		oe.signalLog(signal).Error("❌ Failed to settle pending exit", "order_id", orderID, "error", err)
+		return false
+	}
+	return true
+}
+
+// signalLog returns the engine's logger tagged with the signal's correlation
+// ID, so every line about the signal's orders joins the trade's log trail.
+func (oe *OrderEngine) signalLog(signal algo.TradeSignal) *slog.Logger {
+	return oe.logger.With(logging.KeyCorrelationID, signal.CorrelationID, "symbol", signal.TradingSymbol)
+}
+
+// placeOrder sends an order to the broker, recording its latency and outcome
+// under kind.
+func (oe *OrderEngine) placeOrder(kind string, params broker.OrderParams) (string, error) {
+	start := time.Now()
+	orderID, err := oe.broker.PlaceOrder(params)
+	metrics.OrderPlacementDuration.WithLabelValues(kind).Observe(time.Since(start).Seconds())
+	metrics.OrderPlacements.WithLabelValues(kind, metrics.Outcome(err)).Inc()
+	return orderID, err
 }
 
 func roundToTick(price float64, tick float64) float64 {
@@ -0,0 +1,163 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/algo"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/logging"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository/memory"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/services"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/tracking"
+)
+
+// exitBroker reports the exit order's latest state and an opening range wide
+// enough for the stock to be tracked.
+type exitBroker struct {
+	broker.Broker
+	exit broker.Order
+}
+
+func (b *exitBroker) GetOrderHistory(string) ([]broker.Order, error) {
+	return []broker.Order{b.exit}, nil
+}
+
+func (b *exitBroker) GetHistorical(uint32, string, time.Time, time.Time) ([]broker.Candle, error) {
+	return []broker.Candle{{Open: 1500, High: 1510, Low: 1490, Close: 1500}}, nil
+}
+
+type noSubscriber struct{}
+
+func (noSubscriber) SubscribeToken(uint32)   {}
+func (noSubscriber) UnsubscribeToken(uint32) {}
+
+func TestShutdownWaitsForSupervisors(t *testing.T) {
+	oe := &OrderEngine{
+		signalChan: make(chan algo.TradeSignal, 1),
+		logger:     logging.For("order"),
+	}
+	oe.Start()
+
+	supervised := make(chan struct{})
+	oe.supervise(func() {
+		<-oe.stopChan
+		time.Sleep(20 * time.Millisecond) // a broker call finishing after stop
+		close(supervised)
+	})
+
+	oe.CloseEntries()
+	if err := oe.ManualEntry(1, ManualEntryRequest{Direction: "BUY", Quantity: 1}); !errors.Is(err, ErrEntriesClosed) {
+		t.Fatalf("ManualEntry() error = %v, want %v", err, ErrEntriesClosed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := oe.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	select {
+	case <-supervised:
+	default:
+		t.Fatal("Shutdown returned before the supervisor finished")
+	}
+	if oe.IsRunning() {
+		t.Fatal("engine still running after Shutdown")
+	}
+}
+
+func TestShutdownGivesUpAtDeadline(t *testing.T) {
+	oe := &OrderEngine{
+		signalChan: make(chan algo.TradeSignal, 1),
+		logger:     logging.For("order"),
+	}
+	oe.Start()
+
+	release := make(chan struct{})
+	defer close(release)
+	oe.supervise(func() { <-release })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := oe.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestManualEntryOutsideTradingWindow(t *testing.T) {
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	oe := &OrderEngine{
+		signalChan: make(chan algo.TradeSignal, 1),
+		logger:     logging.For("order"),
+	}
+	oe.Start()
+	defer oe.Shutdown(context.Background())
+
+	for _, at := range []time.Time{
+		time.Date(2026, 3, 2, 9, 5, 0, 0, ist),   // before the open
+		time.Date(2026, 3, 2, 15, 12, 0, 0, ist), // after the force-exit sweep
+		time.Date(2026, 3, 2, 16, 0, 0, 0, ist),  // after the close
+	} {
+		oe.now = func() time.Time { return at }
+		if err := oe.ManualEntry(1, ManualEntryRequest{Direction: "BUY", Quantity: 1}); !errors.Is(err, ErrOutsideSession) {
+			t.Errorf("ManualEntry() at %s error = %v, want %v", at.Format("15:04"), err, ErrOutsideSession)
+		}
+	}
+}
+
+func TestSuperviseExitSettlesAMissedFill(t *testing.T) {
+	ctx := context.Background()
+	exit := broker.Order{OrderID: "exit-1", Status: broker.OrderStatusComplete, InstrumentToken: 408065,
+		TradingSymbol: "INFY", Exchange: "NSE", TransactionType: broker.TransactionTypeSell,
+		OrderType: broker.OrderTypeMarket, Quantity: 10, FilledQuantity: 10, AveragePrice: 1507}
+	brk := &exitBroker{exit: exit}
+
+	tm := tracking.NewTrackingManager(noSubscriber{}, brk)
+	tm.AddTrackingStock(tracking.TrackedStock{ID: 1, TradingSymbol: "INFY", Exchange: "NSE", InstrumentToken: 408065})
+	// A target exit already converted to MARKET, whose COMPLETE postback was missed.
+	tm.RestoreState(408065, models.TrackedStockState{Direction: "BUY", BuyQuantity: 10, BasePrice: 1500,
+		Locked: true, MaxExecutableOrders: 2, PendingExitOrderID: "exit-1", ExitEscalated: true})
+
+	db := memory.New()
+	svc := &services.OrderService{OrderRepo: db.Orders(), TrackingStockRepo: db.TrackingStocks(), TradeRepo: db.Trades()}
+	svc.SetManager(tm)
+	if err := svc.AddPlacedOrder(ctx, &models.Order{TrackingStockID: 1, OrderID: "exit-1", OrderType: broker.OrderTypeLimit,
+		EventType: string(algo.SignalTargetHit), Quantity: 10, Status: "PENDING", PlacedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	oe := &OrderEngine{broker: brk, trackingManager: tm, OrderSvc: svc, logger: logging.For("order"),
+		exitPolicy: ExitExecutionPolicy{Timeout: time.Hour, CheckInterval: 5 * time.Millisecond}}
+	oe.Start()
+	defer oe.Shutdown(ctx)
+
+	signal := algo.TradeSignal{TrackingStockID: 1, InstrumentToken: 408065, TradingSymbol: "INFY", Exchange: "NSE",
+		SignalType: algo.SignalTargetHit, Direction: "BUY", BasePrice: 1500}
+	done := make(chan struct{})
+	go func() {
+		oe.superviseExit(signal, broker.TransactionTypeSell, "exit-1", 1510, time.Now())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("superviseExit still polling an exit the broker reports complete")
+	}
+
+	stock, _ := tm.GetStock(408065)
+	if stock.Direction != "" || stock.BuyQuantity != 0 || stock.PendingExitOrderID != "" || stock.Locked {
+		t.Fatalf("after the fill: direction=%q buy=%d pending=%q locked=%v, want the position closed",
+			stock.Direction, stock.BuyQuantity, stock.PendingExitOrderID, stock.Locked)
+	}
+
+	// The postback arriving late must not apply the fill a second time.
+	if err := svc.ProcessOrderUpdate(ctx, exit); err != nil {
+		t.Fatal(err)
+	}
+	if stock, _ := tm.GetStock(408065); stock.MaxExecutableOrders != 1 {
+		t.Errorf("MaxExecutableOrders = %d, want the fill counted once", stock.MaxExecutableOrders)
+	}
+}
@@ -0,0 +1,154 @@
+package order
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+)
+
+// EntryFallback is what the engine does with an entry LIMIT order that is still
+// unfilled after the chase steps or slippage budget are exhausted.
+type EntryFallback string
+
+const (
+	EntryFallbackMarket  EntryFallback = "MARKET"  // cancel and send the remainder as MARKET
+	EntryFallbackAbandon EntryFallback = "ABANDON" // cancel and keep whatever has filled
+)
+
+// Order event names recorded in the order_events table.
+const (
+	OrderEventPlaced         = "PLACED"
+	OrderEventChaseStep      = "CHASE_STEP"
+	OrderEventBudgetExceeded = "SLIPPAGE_BUDGET_EXHAUSTED"
+	OrderEventCancelled      = "CANCELLED"
+	OrderEventMarketFallback = "MARKET_FALLBACK"
+	OrderEventAbandoned      = "ABANDONED"
+	OrderEventExitTimeout    = "EXIT_TIMEOUT"
+	OrderEventExitRetrace    = "EXIT_RETRACE"
+	OrderEventExitStopLoss   = "EXIT_STOPLOSS"
+	OrderEventExitToMarket   = "EXIT_TO_MARKET"
+)
+
+// EntryExecutionPolicy controls how an entry LIMIT order is chased toward the
+// market before the engine gives up on it.
+type EntryExecutionPolicy struct {
+	InitialWait    time.Duration // wait after placing before the first check
+	StepInterval   time.Duration // wait between two price modifications
+	MaxSteps       int           // number of price modifications allowed
+	MaxSlippagePct float64       // max distance from the signal price, in percent
+	Fallback       EntryFallback
+}
+
+// DefaultEntryExecutionPolicy chases three times, three seconds apart, within
+// 0.15% of the signal price and then goes to market.
+func DefaultEntryExecutionPolicy() EntryExecutionPolicy {
+	return EntryExecutionPolicy{
+		InitialWait:    entryLimitTimeout,
+		StepInterval:   3 * time.Second,
+		MaxSteps:       3,
+		MaxSlippagePct: 0.15,
+		Fallback:       EntryFallbackMarket,
+	}
+}
+
+// LoadEntryExecutionPolicy builds the policy from the server config, keeping the
+// defaults for anything that is unset or invalid.
+func LoadEntryExecutionPolicy() EntryExecutionPolicy {
+	policy := DefaultEntryExecutionPolicy()
+	cfg := config.Current()
+
+	if cfg.EntryInitialWait > 0 {
+		policy.InitialWait = cfg.EntryInitialWait
+	}
+	if cfg.EntryChaseInterval > 0 {
+		policy.StepInterval = cfg.EntryChaseInterval
+	}
+	if cfg.EntryChaseMaxSteps >= 0 {
+		policy.MaxSteps = cfg.EntryChaseMaxSteps
+	}
+	if cfg.EntryMaxSlippagePct >= 0 {
+		policy.MaxSlippagePct = cfg.EntryMaxSlippagePct
+	}
+	if strings.ToUpper(cfg.EntryFallback) == string(EntryFallbackAbandon) {
+		policy.Fallback = EntryFallbackAbandon
+	}
+	return policy
+}
+
+// priceCap returns the worst price the policy accepts for an entry, i.e. the
+// signal price moved against us by MaxSlippagePct.
+func (p EntryExecutionPolicy) priceCap(txType string, basePrice float64) float64 {
+	if txType == broker.TransactionTypeBuy {
+		return roundToTick(basePrice*(1+p.MaxSlippagePct/100), tickSize)
+	}
+	return roundToTick(basePrice*(1-p.MaxSlippagePct/100), tickSize)
+}
+
+// nextChasePrice returns the next LIMIT price for an unfilled entry. The order is
+// moved to the touch (best ask for a BUY, best bid for a SELL, LTP when depth is
+// missing) but never past the slippage cap. ok is false when the order cannot be
+// moved any closer to the market, meaning the budget is exhausted.
+func (p EntryExecutionPolicy) nextChasePrice(txType string, basePrice, currentPrice, touch float64) (price float64, ok bool) {
+	if touch <= 0 {
+		return currentPrice, false
+	}
+	limit := p.priceCap(txType, basePrice)
+
+	if txType == broker.TransactionTypeBuy {
+		price = math.Min(roundToTick(touch, tickSize), limit)
+		return price, price > currentPrice
+	}
+	price = math.Max(roundToTick(touch, tickSize), limit)
+	return price, price < currentPrice
+}
+
+// ExitExecutionPolicy controls how an unfilled target LIMIT exit is supervised.
+type ExitExecutionPolicy struct {
+	Timeout       time.Duration // convert to MARKET when still open after this long
+	CheckInterval time.Duration // how often the order and LTP are checked
+	RetracePct    float64       // convert when LTP moves back this far past the limit, in percent
+}
+
+// DefaultExitExecutionPolicy gives a target exit 15 seconds, or until price
+// retraces 0.1% past the limit, before it is converted to MARKET.
+func DefaultExitExecutionPolicy() ExitExecutionPolicy {
+	return ExitExecutionPolicy{
+		Timeout:       15 * time.Second,
+		CheckInterval: 2 * time.Second,
+		RetracePct:    0.1,
+	}
+}
+
+// LoadExitExecutionPolicy builds the exit policy from the server config.
+func LoadExitExecutionPolicy() ExitExecutionPolicy {
+	policy := DefaultExitExecutionPolicy()
+	cfg := config.Current()
+
+	if cfg.ExitLimitTimeout > 0 {
+		policy.Timeout = cfg.ExitLimitTimeout
+	}
+	if cfg.ExitCheckInterval > 0 {
+		policy.CheckInterval = cfg.ExitCheckInterval
+	}
+	if cfg.ExitRetracePct > 0 {
+		policy.RetracePct = cfg.ExitRetracePct
+	}
+	return policy
+}
+
+// retraced reports whether the LTP has moved back far enough past the exit
+// limit that waiting for a fill is no longer sensible. closeTxType is the side
+// of the exit order.
+func (p ExitExecutionPolicy) retraced(closeTxType string, limitPrice, ltp float64) bool {
+	if ltp <= 0 || limitPrice <= 0 {
+		return false
+	}
+	threshold := limitPrice * p.RetracePct / 100
+	if closeTxType == broker.TransactionTypeSell {
+		return ltp < limitPrice-threshold
+	}
+	return ltp > limitPrice+threshold
+}
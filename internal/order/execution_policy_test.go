@@ -0,0 +1,37 @@
+package order
+
+import (
+	"math"
+	"testing"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+)
+
+func TestNextChasePrice(t *testing.T) {
+	policy := EntryExecutionPolicy{MaxSlippagePct: 0.5}
+
+	tests := []struct {
+		name    string
+		txType  string
+		current float64
+		touch   float64
+		want    float64
+		wantOK  bool
+	}{
+		{"buy moves to ask", broker.TransactionTypeBuy, 100.0, 100.2, 100.2, true},
+		{"buy clamped to cap", broker.TransactionTypeBuy, 100.0, 101.5, 100.5, true},
+		{"buy already at cap", broker.TransactionTypeBuy, 100.5, 101.5, 100.5, false},
+		{"sell moves to bid", broker.TransactionTypeSell, 100.0, 99.8, 99.8, true},
+		{"sell clamped to cap", broker.TransactionTypeSell, 100.0, 98.0, 99.5, true},
+		{"no touch", broker.TransactionTypeBuy, 100.0, 0, 100.0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := policy.nextChasePrice(tt.txType, 100.0, tt.current, tt.touch)
+			if ok != tt.wantOK || math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("nextChasePrice() = %.2f, %t; want %.2f, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
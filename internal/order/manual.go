@@ -0,0 +1,227 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/algo"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/logging"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/utils"
+)
+
+// manualOrderTag is sent to the broker with every manual order so they can be
+// told apart from strategy orders in the broker's own order book too.
+const manualOrderTag = "manual"
+
+var (
+	ErrEngineStopped   = errors.New("order engine is not running")
+	ErrStockNotTracked = errors.New("stock is not being tracked")
+	ErrEntriesClosed   = errors.New("order engine is shutting down and not taking new entries")
+	ErrOutsideSession  = errors.New("entries are only taken between the market open and the force-exit time")
+)
+
+// ManualEntryRequest is a trader's request to open a position on a tracked stock.
+type ManualEntryRequest struct {
+	Direction  string // "BUY" or "SELL"
+	Quantity   uint32
+	LimitPrice float64 // zero places a MARKET order
+	Target     float64 // points from the fill price; zero keeps the stock's target
+	StopLoss   float64 // points from the fill price; zero keeps the stock's stoploss
+	Source     string  // order source to record; empty for MANUAL
+}
+
+// ManualExitRequest is a trader's request to close all or part of an open position.
+type ManualExitRequest struct {
+	Quantity   uint32  // zero closes the whole position
+	LimitPrice float64 // zero places a MARKET order
+	Source     string  // order source to record; empty for MANUAL
+}
+
+// signalSource is the order source recorded for orders placed on signal.
+func signalSource(signal algo.TradeSignal) string {
+	switch {
+	case !signal.Manual:
+		return models.OrderSourceAlgo
+	case signal.Source != "":
+		return signal.Source
+	default:
+		return models.OrderSourceManual
+	}
+}
+
+// ManualEntry validates a manual entry against the same locks and risk guards
+// as a strategy entry and queues it on the signal channel, so it is placed,
+// sized against margin and recorded by processEntry like any other entry.
+func (oe *OrderEngine) ManualEntry(trackingStockID int64, req ManualEntryRequest) error {
+	if !oe.IsRunning() {
+		return ErrEngineStopped
+	}
+	if !oe.entriesAllowed() {
+		return ErrEntriesClosed
+	}
+	if !oe.inTradingWindow() {
+		return ErrOutsideSession
+	}
+
+	stock, exists := oe.trackingManager.GetTrackedStockByID(trackingStockID)
+	if !exists {
+		return ErrStockNotTracked
+	}
+	if stock.Direction != "" || stock.BuyQuantity > 0 || stock.SellQuantity > 0 {
+		return fmt.Errorf("%s already has an open %s position", stock.TradingSymbol, stock.Direction)
+	}
+
+	ltp, ok := oe.trackingManager.GetTSLtpByToken(stock.InstrumentToken)
+	if !ok || ltp <= 0 {
+		return fmt.Errorf("LTP is not available for %s", stock.TradingSymbol)
+	}
+
+	target, stopLoss := req.Target, req.StopLoss
+	if target <= 0 {
+		target = stock.Target
+	}
+	if stopLoss <= 0 {
+		stopLoss = stock.StopLoss
+	}
+	if target <= 0 || stopLoss <= 0 {
+		return fmt.Errorf("target and stoploss are required for %s", stock.TradingSymbol)
+	}
+
+	basePrice := ltp
+	if req.LimitPrice > 0 {
+		basePrice = req.LimitPrice
+	}
+	if stock.OrderPriceLimit > 0 && float64(req.Quantity)*basePrice > stock.OrderPriceLimit {
+		return fmt.Errorf("order value %.0f exceeds the order price limit %.0f of %s",
+			float64(req.Quantity)*basePrice, stock.OrderPriceLimit, stock.TradingSymbol)
+	}
+
+	signalType := algo.SignalEntryBuy
+	if req.Direction == "SELL" {
+		signalType = algo.SignalEntrySell
+	}
+
+	if !oe.trackingManager.TryLockStock(stock.InstrumentToken) {
+		return fmt.Errorf("%s has an order in progress", stock.TradingSymbol)
+	}
+	if err := oe.algoEngine.ReserveTrade(req.Quantity, stopLoss); err != nil {
+		oe.trackingManager.UnlockStock(stock.InstrumentToken)
+		return err
+	}
+	oe.trackingManager.SetSignalFired(stock.InstrumentToken)
+	oe.trackingManager.SetDirection(stock.InstrumentToken, req.Direction)
+
+	signal := algo.TradeSignal{
+		TrackingStockID: stock.ID,
+		InstrumentToken: stock.InstrumentToken,
+		TradingSymbol:   stock.TradingSymbol,
+		Exchange:        stock.Exchange,
+		SignalType:      signalType,
+		Direction:       req.Direction,
+		TriggerPrice:    ltp,
+		BasePrice:       basePrice,
+		Target:          target,
+		StopLoss:        stopLoss,
+		Quantity:        req.Quantity,
+		Timestamp:       time.Now(),
+		Manual:          true,
+		LimitPrice:      req.LimitPrice,
+		Source:          req.Source,
+		CorrelationID:   logging.NewCorrelationID(),
+	}
+
+	select {
+	case oe.signalChan <- signal:
+	default:
+		oe.algoEngine.DecrementDailyTrade()
+		oe.algoEngine.DecrementOpenTrade()
+		oe.trackingManager.ResetFiringAndDirection(stock.InstrumentToken)
+		oe.trackingManager.UnlockStock(stock.InstrumentToken)
+		return fmt.Errorf("signal queue is full, try again")
+	}
+
+	oe.signalLog(signal).Info("✋ Manual entry queued", "side", req.Direction, "qty", req.Quantity,
+		"limit", req.LimitPrice, "target", target, "stoploss", stopLoss, "source", signalSource(signal))
+	return nil
+}
+
+// inTradingWindow reports whether an entry may be opened now: from the market
+// open until the force-exit time, so that the sweep closes every MIS position.
+func (oe *OrderEngine) inTradingWindow() bool {
+	now := time.Now()
+	if oe.now != nil {
+		now = oe.now()
+	}
+	switch utils.GetMarketPhase(now) {
+	case utils.PhaseFifteen, utils.PhaseSignal, utils.PhaseMonitor:
+		return true
+	default:
+		return false
+	}
+}
+
+// ManualExit queues a manual close of the open position on a tracked stock.
+// The exit takes the stock lock, so it cannot race a target or stoploss exit.
+func (oe *OrderEngine) ManualExit(trackingStockID int64, req ManualExitRequest) error {
+	if !oe.IsRunning() {
+		return ErrEngineStopped
+	}
+
+	stock, exists := oe.trackingManager.GetTrackedStockByID(trackingStockID)
+	if !exists {
+		return ErrStockNotTracked
+	}
+
+	openQty := stock.BuyQuantity
+	if stock.Direction == "SELL" {
+		openQty = stock.SellQuantity
+	}
+	if stock.Direction == "" || openQty == 0 {
+		return fmt.Errorf("%s has no open position", stock.TradingSymbol)
+	}
+
+	qty := req.Quantity
+	if qty == 0 {
+		qty = openQty
+	}
+	if qty > openQty {
+		return fmt.Errorf("exit quantity %d exceeds open quantity %d of %s", qty, openQty, stock.TradingSymbol)
+	}
+
+	if !oe.trackingManager.TryLockStock(stock.InstrumentToken) {
+		return fmt.Errorf("%s has an order in progress", stock.TradingSymbol)
+	}
+
+	ltp, _ := oe.trackingManager.GetTSLtpByToken(stock.InstrumentToken)
+	signal := algo.TradeSignal{
+		TrackingStockID: stock.ID,
+		InstrumentToken: stock.InstrumentToken,
+		TradingSymbol:   stock.TradingSymbol,
+		Exchange:        stock.Exchange,
+		SignalType:      algo.SignalManualExit,
+		Direction:       stock.Direction,
+		TriggerPrice:    ltp,
+		BasePrice:       stock.BasePrice,
+		Target:          stock.Target,
+		StopLoss:        stock.StopLoss,
+		Quantity:        qty,
+		Timestamp:       time.Now(),
+		Manual:          true,
+		LimitPrice:      req.LimitPrice,
+		Source:          req.Source,
+		CorrelationID:   logging.NewCorrelationID(),
+	}
+
+	select {
+	case oe.signalChan <- signal:
+	default:
+		oe.trackingManager.UnlockStock(stock.InstrumentToken)
+		return fmt.Errorf("signal queue is full, try again")
+	}
+
+	oe.signalLog(signal).Info("✋ Manual exit queued", "qty", qty, "open_qty", openQty,
+		"limit", req.LimitPrice, "source", signalSource(signal))
+	return nil
+}
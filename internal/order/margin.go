@@ -0,0 +1,98 @@
+package order
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/algo"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+)
+
+// MarginCheck is the outcome of the pre-trade margin check for one entry.
+type MarginCheck struct {
+	Available     float64 // net equity margin reported by the broker
+	Reserve       float64 // kept aside for open positions and MTM swings
+	MarginPerUnit float64 // MIS margin required for one share at the entry price
+	Leverage      float64 // MIS leverage the broker applies to the instrument
+	Affordable    uint32  // max quantity the remaining margin can carry
+}
+
+// marginReserve returns the amount of margin not to be spent on a new entry.
+// otherOpen is the number of positions already open besides this one.
+func marginReserve(available float64, otherOpen int) float64 {
+	cfg := config.Current()
+	return available*cfg.MarginReservePct/100 + cfg.MarginReservePerPosition*float64(otherOpen)
+}
+
+// checkMargin fetches the account margin and the MIS margin for the instrument
+// and works out the largest quantity the account can afford.
+func (oe *OrderEngine) checkMargin(signal algo.TradeSignal, txType string, price float64) (MarginCheck, error) {
+	margins, err := oe.broker.GetMargins()
+	if err != nil {
+		return MarginCheck{}, fmt.Errorf("failed to fetch user margins: %w", err)
+	}
+
+	orderMargin, err := oe.broker.GetOrderMargin(broker.OrderParams{
+		Exchange:        signal.Exchange,
+		TradingSymbol:   signal.TradingSymbol,
+		TransactionType: txType,
+		Product:         broker.ProductMIS,
+		OrderType:       broker.OrderTypeLimit,
+		Quantity:        int(signal.Quantity),
+		Price:           price,
+	})
+	if err != nil {
+		return MarginCheck{}, fmt.Errorf("failed to fetch order margins: %w", err)
+	}
+	if orderMargin.Total <= 0 || signal.Quantity == 0 {
+		return MarginCheck{}, fmt.Errorf("no margin data returned for %s", signal.TradingSymbol)
+	}
+
+	otherOpen := oe.algoEngine.OpenTradeCount() - 1
+	if otherOpen < 0 {
+		otherOpen = 0
+	}
+
+	check := MarginCheck{
+		Available:     margins.Net,
+		Reserve:       marginReserve(margins.Net, otherOpen),
+		MarginPerUnit: orderMargin.Total / float64(signal.Quantity),
+		Leverage:      orderMargin.Leverage,
+	}
+	spendable := check.Available - check.Reserve
+	if spendable > 0 {
+		check.Affordable = uint32(math.Floor(spendable / check.MarginPerUnit))
+	}
+	return check, nil
+}
+
+// clampToMargin reduces the signal quantity to what the account can afford. It
+// returns false when not even one share fits, in which case the entry must be dropped.
+func (oe *OrderEngine) clampToMargin(signal *algo.TradeSignal, txType string, price float64) bool {
+	check, err := oe.checkMargin(*signal, txType, price)
+	if err != nil {
+		// Without margin data we let the broker be the judge, as before.
+		log.Printf("⚠️ Margin check skipped for %s: %v", signal.TradingSymbol, err)
+		return true
+	}
+
+	if check.Affordable >= signal.Quantity {
+		return true
+	}
+
+	note := fmt.Sprintf("margin: available=%.0f reserve=%.0f per_unit=%.2f leverage=%.1fx qty %d -> %d",
+		check.Available, check.Reserve, check.MarginPerUnit, check.Leverage, signal.Quantity, check.Affordable)
+	if signal.SizingNote != "" {
+		note = signal.SizingNote + "; " + note
+	}
+	signal.SizingNote = note
+	log.Printf("💰 %s: %s", signal.TradingSymbol, note)
+
+	if check.Affordable == 0 {
+		return false
+	}
+	signal.Quantity = check.Affordable
+	return true
+}
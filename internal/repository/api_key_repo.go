@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type APIKeyRepository struct {
+	DB *pgxpool.Pool
+}
+
+func (r *APIKeyRepository) CreateKey(ctx context.Context, key *models.APIKey) (int64, error) {
+	query := `
+		INSERT INTO api_keys (user_id, name, key_prefix, key_hash, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, created_at`
+	err := r.DB.QueryRow(ctx, query, key.UserID, key.Name, key.KeyPrefix, key.KeyHash).Scan(&key.ID, &key.CreatedAt)
+	return key.ID, err
+}
+
+// GetActiveKeyByHash returns the unrevoked key with the given hash.
+func (r *APIKeyRepository) GetActiveKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	query := `
+		SELECT id, user_id, name, key_prefix, key_hash, created_at, last_used_at, revoked_at
+		FROM api_keys WHERE key_hash=$1 AND revoked_at IS NULL`
+	var k models.APIKey
+	err := r.DB.QueryRow(ctx, query, hash).
+		Scan(&k.ID, &k.UserID, &k.Name, &k.KeyPrefix, &k.KeyHash, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// GetKeysByUser lists a user's keys, revoked ones included, newest first.
+func (r *APIKeyRepository) GetKeysByUser(ctx context.Context, userID int64) ([]models.APIKey, error) {
+	query := `
+		SELECT id, user_id, name, key_prefix, key_hash, created_at, last_used_at, revoked_at
+		FROM api_keys WHERE user_id=$1 ORDER BY id DESC`
+	rows, err := r.DB.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var k models.APIKey
+		if err := rows.Scan(&k.ID, &k.UserID, &k.Name, &k.KeyPrefix, &k.KeyHash, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeKey revokes one of the user's keys. It returns pgx.ErrNoRows if the
+// user has no such unrevoked key.
+func (r *APIKeyRepository) RevokeKey(ctx context.Context, userID, id int64) error {
+	query := `UPDATE api_keys SET revoked_at = NOW() WHERE id=$1 AND user_id=$2 AND revoked_at IS NULL`
+	tag, err := r.DB.Exec(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) TouchKey(ctx context.Context, id int64) error {
+	_, err := r.DB.Exec(ctx, `UPDATE api_keys SET last_used_at = NOW() WHERE id=$1`, id)
+	return err
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ConfigOverrideRepository struct {
+	DB *pgxpool.Pool
+}
+
+func (r *ConfigOverrideRepository) GetOverrides(ctx context.Context) ([]models.ConfigOverride, error) {
+	rows, err := r.DB.Query(ctx, `SELECT key, value, updated_by, updated_at FROM config_overrides ORDER BY key`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := []models.ConfigOverride{}
+	for rows.Next() {
+		var o models.ConfigOverride
+		if err := rows.Scan(&o.Key, &o.Value, &o.UpdatedBy, &o.UpdatedAt); err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, o)
+	}
+	return overrides, rows.Err()
+}
+
+// GetOverrideValues returns the overrides as a map from key to value.
+func (r *ConfigOverrideRepository) GetOverrideValues(ctx context.Context) (map[string]string, error) {
+	overrides, err := r.GetOverrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(overrides))
+	for _, o := range overrides {
+		values[o.Key] = o.Value
+	}
+	return values, nil
+}
+
+// SetOverrides saves values in one transaction, replacing earlier overrides of
+// the same keys.
+func (r *ConfigOverrideRepository) SetOverrides(ctx context.Context, values map[string]string, userID int64) error {
+	return pgx.BeginFunc(ctx, r.DB, func(tx pgx.Tx) error {
+		for key, value := range values {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO config_overrides (key, value, updated_by, updated_at)
+				VALUES ($1, $2, $3, NOW())
+				ON CONFLICT (key) DO UPDATE SET
+					value = EXCLUDED.value,
+					updated_by = EXCLUDED.updated_by,
+					updated_at = EXCLUDED.updated_at`, key, value, userID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteOverride removes the override of key. It returns pgx.ErrNoRows when
+// there is none.
+func (r *ConfigOverrideRepository) DeleteOverride(ctx context.Context, key string) error {
+	tag, err := r.DB.Exec(ctx, `DELETE FROM config_overrides WHERE key=$1`, key)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
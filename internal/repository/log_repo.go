@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LogRepository struct {
+	DB *pgxpool.Pool
+}
+
+func (r *LogRepository) AddLogEntries(ctx context.Context, entries []models.LogEntry) error {
+	_, err := r.DB.CopyFrom(ctx,
+		pgx.Identifier{"log_entries"},
+		[]string{"correlation_id", "level", "component", "message", "attrs", "logged_at"},
+		pgx.CopyFromSlice(len(entries), func(i int) ([]any, error) {
+			e := entries[i]
+			return []any{e.CorrelationID, e.Level, e.Component, e.Message, string(e.Attrs), e.LoggedAt}, nil
+		}),
+	)
+	return err
+}
+
+// GetTradeLogTrail returns the log lines of a trade in the order they were
+// written: those of the signals its orders were placed for, and any others
+// that name one of its orders, such as websocket updates and escalations.
+func (r *LogRepository) GetTradeLogTrail(ctx context.Context, tradeID int64) ([]models.LogEntry, error) {
+	query := `
+		WITH trade_order_ids AS (
+			SELECT order_id FROM trade_orders WHERE trade_id = $1
+		)
+		SELECT id, correlation_id, level, component, message, attrs, logged_at
+		FROM log_entries
+		WHERE correlation_id IN (
+				SELECT o.correlation_id FROM orders o
+				JOIN trade_order_ids t ON t.order_id = o.order_id
+				WHERE o.correlation_id IS NOT NULL)
+		   OR attrs->>'order_id' IN (SELECT order_id FROM trade_order_ids)
+		ORDER BY logged_at, id`
+
+	rows, err := r.DB.Query(ctx, query, tradeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.LogEntry{}
+	for rows.Next() {
+		var e models.LogEntry
+		if err := rows.Scan(&e.ID, &e.CorrelationID, &e.Level, &e.Component, &e.Message, &e.Attrs, &e.LoggedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var _ repository.APIKeyStore = (*APIKeyStore)(nil)
+
+type APIKeyStore struct {
+	db *DB
+}
+
+func (s *APIKeyStore) CreateKey(_ context.Context, key *models.APIKey) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, k := range s.db.apiKeys {
+		if k.KeyHash == key.KeyHash {
+			return 0, uniqueViolation("api_keys", "key_hash", key.KeyHash)
+		}
+	}
+	key.ID = s.db.nextID("api_keys")
+	key.CreatedAt = s.db.now()
+	stored := *key
+	stored.Key = ""
+	s.db.apiKeys[key.ID] = stored
+	return key.ID, nil
+}
+
+// GetActiveKeyByHash returns the unrevoked key with the given hash.
+func (s *APIKeyStore) GetActiveKeyByHash(_ context.Context, hash string) (*models.APIKey, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, k := range s.db.apiKeys {
+		if k.KeyHash == hash && k.RevokedAt == nil {
+			return &k, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+// GetKeysByUser lists a user's keys, revoked ones included, newest first.
+func (s *APIKeyStore) GetKeysByUser(_ context.Context, userID int64) ([]models.APIKey, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	keys := []models.APIKey{}
+	for _, k := range s.db.apiKeys {
+		if k.UserID == userID {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID > keys[j].ID })
+	return keys, nil
+}
+
+// RevokeKey revokes one of the user's keys. It returns pgx.ErrNoRows if the
+// user has no such unrevoked key.
+func (s *APIKeyStore) RevokeKey(_ context.Context, userID, id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	k, ok := s.db.apiKeys[id]
+	if !ok || k.UserID != userID || k.RevokedAt != nil {
+		return pgx.ErrNoRows
+	}
+	now := s.db.now()
+	k.RevokedAt = &now
+	s.db.apiKeys[id] = k
+	return nil
+}
+
+func (s *APIKeyStore) TouchKey(_ context.Context, id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if k, ok := s.db.apiKeys[id]; ok {
+		now := s.db.now()
+		k.LastUsedAt = &now
+		s.db.apiKeys[id] = k
+	}
+	return nil
+}
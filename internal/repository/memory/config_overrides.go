@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var _ repository.ConfigOverrideStore = (*ConfigOverrideStore)(nil)
+
+type ConfigOverrideStore struct {
+	db *DB
+}
+
+func (s *ConfigOverrideStore) GetOverrides(_ context.Context) ([]models.ConfigOverride, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	overrides := []models.ConfigOverride{}
+	for _, o := range s.db.overrides {
+		overrides = append(overrides, o)
+	}
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].Key < overrides[j].Key })
+	return overrides, nil
+}
+
+// GetOverrideValues returns the overrides as a map from key to value.
+func (s *ConfigOverrideStore) GetOverrideValues(_ context.Context) (map[string]string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	values := make(map[string]string, len(s.db.overrides))
+	for key, o := range s.db.overrides {
+		values[key] = o.Value
+	}
+	return values, nil
+}
+
+// SetOverrides saves values, replacing earlier overrides of the same keys.
+func (s *ConfigOverrideStore) SetOverrides(_ context.Context, values map[string]string, userID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	now := s.db.now()
+	for key, value := range values {
+		updatedBy := userID
+		s.db.overrides[key] = models.ConfigOverride{Key: key, Value: value, UpdatedBy: &updatedBy, UpdatedAt: now}
+	}
+	return nil
+}
+
+// DeleteOverride removes the override of key. It returns pgx.ErrNoRows when
+// there is none.
+func (s *ConfigOverrideStore) DeleteOverride(_ context.Context, key string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.overrides[key]; !ok {
+		return pgx.ErrNoRows
+	}
+	delete(s.db.overrides, key)
+	return nil
+}
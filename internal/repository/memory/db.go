@@ -0,0 +1,140 @@
+// Package memory implements the repository stores in memory, for tests that
+// should not need Postgres. All stores of one DB share its tables, so queries
+// that join tables in Postgres, such as the tradebook export, see the same
+// rows here. Missing rows are reported with pgx.ErrNoRows like the Postgres
+// stores, and every store is safe for concurrent use.
+package memory
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+)
+
+// DB holds the tables behind the in-memory stores.
+type DB struct {
+	mu sync.Mutex
+
+	// Now is the clock used for timestamps and for "today" in the daily
+	// order queries. It defaults to time.Now.
+	Now func() time.Time
+
+	lastID map[string]int64
+
+	trackingStocks map[int64]models.TrackingStock
+	orders         map[int64]models.Order
+	orderEvents    []models.OrderEvent
+	adjustments    []models.PositionAdjustment
+	trades         map[int64]models.Trade
+	tradeOrders    map[string]models.TradeOrder
+	users          map[int64]models.User
+	instruments    map[string]models.Instrument
+	states         map[stateKey]models.TrackedStockState
+	routes         map[int64]models.NotificationRoute
+	notifications  []models.Notification
+	webhookKeys    map[int64]models.SignalWebhookKey
+	alerts         []models.SignalAlert
+	apiKeys        map[int64]models.APIKey
+	logEntries     []models.LogEntry
+	overrides      map[string]models.ConfigOverride
+}
+
+type stateKey struct {
+	trackingStockID int64
+	tradingDate     string
+}
+
+func New() *DB {
+	return &DB{
+		lastID:         map[string]int64{},
+		trackingStocks: map[int64]models.TrackingStock{},
+		orders:         map[int64]models.Order{},
+		trades:         map[int64]models.Trade{},
+		tradeOrders:    map[string]models.TradeOrder{},
+		users:          map[int64]models.User{},
+		instruments:    map[string]models.Instrument{},
+		states:         map[stateKey]models.TrackedStockState{},
+		routes:         map[int64]models.NotificationRoute{},
+		webhookKeys:    map[int64]models.SignalWebhookKey{},
+		apiKeys:        map[int64]models.APIKey{},
+		overrides:      map[string]models.ConfigOverride{},
+	}
+}
+
+func (db *DB) TrackingStocks() *TrackingStockStore { return &TrackingStockStore{db} }
+func (db *DB) Orders() *OrderStore                 { return &OrderStore{db} }
+func (db *DB) Trades() *TradeStore                 { return &TradeStore{db} }
+func (db *DB) Users() *UserStore                   { return &UserStore{db} }
+func (db *DB) Instruments() *InstrumentStore       { return &InstrumentStore{db} }
+func (db *DB) TrackedStates() *TrackedStockStateStore {
+	return &TrackedStockStateStore{db}
+}
+func (db *DB) Notifications() *NotificationStore     { return &NotificationStore{db} }
+func (db *DB) SignalAlerts() *SignalAlertStore       { return &SignalAlertStore{db} }
+func (db *DB) APIKeys() *APIKeyStore                 { return &APIKeyStore{db} }
+func (db *DB) Logs() *LogStore                       { return &LogStore{db} }
+func (db *DB) ConfigOverrides() *ConfigOverrideStore { return &ConfigOverrideStore{db} }
+
+// now must be called with db.mu held.
+func (db *DB) now() time.Time {
+	if db.Now != nil {
+		return db.Now()
+	}
+	return time.Now()
+}
+
+// nextID returns the next serial id of table. It must be called with db.mu held.
+func (db *DB) nextID(table string) int64 {
+	db.lastID[table]++
+	return db.lastID[table]
+}
+
+// isToday reports whether t falls on the current date, as CURRENT_DATE
+// would in Postgres. It must be called with db.mu held.
+func (db *DB) isToday(t time.Time) bool {
+	now := db.now()
+	y1, m1, d1 := t.In(now.Location()).Date()
+	y2, m2, d2 := now.Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
+
+// uniqueViolation is returned where Postgres would reject a duplicate key.
+func uniqueViolation(table, key string, value any) error {
+	return fmt.Errorf("duplicate key value violates unique constraint on %s (%s)=(%v)", table, key, value)
+}
+
+// orderStatuses are the values of the order_status enum.
+var orderStatuses = map[string]bool{
+	models.OrderStatusPending:   true,
+	models.OrderStatusOpen:      true,
+	models.OrderStatusComplete:  true,
+	models.OrderStatusCancelled: true,
+	models.OrderStatusRejected:  true,
+}
+
+// invalidOrderStatus is returned where Postgres would reject a status that is
+// not in the order_status enum.
+func invalidOrderStatus(status string) error {
+	if orderStatuses[status] {
+		return nil
+	}
+	return fmt.Errorf("invalid input value for enum order_status: %q", status)
+}
+
+// page returns the bounds of a 1-based page of a list of n items.
+func page(n, pageNumber, limit int) (int, int) {
+	start := (pageNumber - 1) * limit
+	if start < 0 {
+		start = 0
+	}
+	if start > n {
+		start = n
+	}
+	end := start + limit
+	if end > n {
+		end = n
+	}
+	return start, end
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var _ repository.InstrumentStore = (*InstrumentStore)(nil)
+
+type InstrumentStore struct {
+	db *DB
+}
+
+func (s *InstrumentStore) UpsertInstruments(_ context.Context, exchange string, instrumentsData []byte) (int64, error) {
+	if !json.Valid(instrumentsData) {
+		return 0, errors.New("invalid input syntax for type json")
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	inst, ok := s.db.instruments[exchange]
+	if !ok {
+		inst = models.Instrument{ID: s.db.nextID("instruments"), Exchange: exchange}
+	}
+	inst.InstrumentsData = append(json.RawMessage(nil), instrumentsData...)
+	inst.StoredAt = s.db.now()
+	s.db.instruments[exchange] = inst
+	return inst.ID, nil
+}
+
+func (s *InstrumentStore) GetStoredDateByExchange(_ context.Context, exchange string) (*models.Instrument, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	inst, ok := s.db.instruments[exchange]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &models.Instrument{ID: inst.ID, StoredAt: inst.StoredAt}, nil
+}
+
+func (s *InstrumentStore) GetInstrumentsByExchange(_ context.Context, exchange string) (*models.Instrument, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	inst, ok := s.db.instruments[exchange]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &inst, nil
+}
+
+func (s *InstrumentStore) UpdateInstruments(_ context.Context, exchange string, instrumentsData string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if inst, ok := s.db.instruments[exchange]; ok {
+		inst.InstrumentsData = json.RawMessage(instrumentsData)
+		inst.StoredAt = s.db.now()
+		s.db.instruments[exchange] = inst
+	}
+	return nil
+}
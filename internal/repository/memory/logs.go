@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+)
+
+var _ repository.LogStore = (*LogStore)(nil)
+
+type LogStore struct {
+	db *DB
+}
+
+func (s *LogStore) AddLogEntries(_ context.Context, entries []models.LogEntry) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, e := range entries {
+		e.ID = s.db.nextID("log_entries")
+		s.db.logEntries = append(s.db.logEntries, e)
+	}
+	return nil
+}
+
+// GetTradeLogTrail returns the log lines of a trade in the order they were
+// written: those correlated with its orders and those naming one of them.
+func (s *LogStore) GetTradeLogTrail(_ context.Context, tradeID int64) ([]models.LogEntry, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	orderIDs := map[string]bool{}
+	for id, o := range s.db.tradeOrders {
+		if o.TradeID == tradeID {
+			orderIDs[id] = true
+		}
+	}
+	correlationIDs := map[string]bool{}
+	for _, o := range s.db.orders {
+		if orderIDs[o.OrderID] && o.CorrelationID != nil {
+			correlationIDs[*o.CorrelationID] = true
+		}
+	}
+
+	entries := []models.LogEntry{}
+	for _, e := range s.db.logEntries {
+		var attrs struct {
+			OrderID string `json:"order_id"`
+		}
+		_ = json.Unmarshal(e.Attrs, &attrs)
+		if correlationIDs[e.CorrelationID] || orderIDs[attrs.OrderID] {
+			entries = append(entries, e)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].LoggedAt.Before(entries[j].LoggedAt) })
+	return entries, nil
+}
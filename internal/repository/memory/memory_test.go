@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestAttachOrderFollowsTheOpenTrade(t *testing.T) {
+	ctx := context.Background()
+	trades := New().Trades()
+	seed := &models.Trade{TrackingStockID: 7, TradingSymbol: "INFY", Direction: "BUY"}
+	now := time.Now()
+
+	if _, err := trades.AttachOrder(ctx, seed, models.TradeOrder{OrderID: "x0", Leg: models.TradeLegExit}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("exit without an open trade error = %v, want pgx.ErrNoRows", err)
+	}
+
+	first, err := trades.AttachOrder(ctx, seed, models.TradeOrder{OrderID: "e1", Leg: models.TradeLegEntry, FilledAt: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := trades.AttachOrder(ctx, seed, models.TradeOrder{OrderID: "e2", Leg: models.TradeLegEntry, FilledAt: now})
+	exit, _ := trades.AttachOrder(ctx, seed, models.TradeOrder{OrderID: "x1", Leg: models.TradeLegExit, FilledAt: now})
+	if second != first || exit != first {
+		t.Fatalf("trade ids = %d, %d, %d, want one trade", first, second, exit)
+	}
+
+	if err := trades.UpdateTradeSummary(ctx, &models.Trade{ID: first, Status: models.TradeStatusClosed}); err != nil {
+		t.Fatal(err)
+	}
+	next, _ := trades.AttachOrder(ctx, seed, models.TradeOrder{OrderID: "e3", Leg: models.TradeLegEntry, FilledAt: now})
+	if next == first {
+		t.Error("entry after the trade closed joined the closed trade")
+	}
+	again, _ := trades.AttachOrder(ctx, seed, models.TradeOrder{OrderID: "e1", Leg: models.TradeLegEntry, FilledAt: now})
+	if again != first {
+		t.Error("an order already linked moved to another trade")
+	}
+}
+
+func TestStoresAreSafeForConcurrentUse(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	orders := db.Orders()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := fmt.Sprintf("order-%d", i)
+			if _, err := orders.AddOrder(ctx, &models.Order{TrackingStockID: 1, OrderID: id, Status: "OPEN", PlacedAt: time.Now()}); err != nil {
+				t.Error(err)
+			}
+			_, _ = orders.UpsertOrder(ctx, &models.Order{OrderID: id, Status: "COMPLETE"})
+			_, _ = orders.GetDailyTradeStats(ctx, []int64{1})
+		}()
+	}
+	wg.Wait()
+
+	all, _ := orders.GetAllOrders(ctx)
+	if len(all) != 20 {
+		t.Fatalf("orders = %d, want 20", len(all))
+	}
+	for _, o := range all {
+		if o.Status != "COMPLETE" {
+			t.Errorf("%s status = %s", o.OrderID, o.Status)
+		}
+	}
+	if _, err := orders.AddOrder(ctx, &models.Order{OrderID: "order-0"}); err == nil {
+		t.Error("duplicate order id accepted")
+	}
+}
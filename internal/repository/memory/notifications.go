@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var _ repository.NotificationStore = (*NotificationStore)(nil)
+
+type NotificationStore struct {
+	db *DB
+}
+
+func (s *NotificationStore) CreateRoute(_ context.Context, route *models.NotificationRoute) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	route.ID = s.db.nextID("notification_routes")
+	route.CreatedAt = s.db.now()
+	s.db.routes[route.ID] = *route
+	return route.ID, nil
+}
+
+// DeleteRoute removes one of a user's routes. It returns pgx.ErrNoRows when the
+// user has no such route.
+func (s *NotificationStore) DeleteRoute(_ context.Context, userID, id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if r, ok := s.db.routes[id]; !ok || r.UserID != userID {
+		return pgx.ErrNoRows
+	}
+	delete(s.db.routes, id)
+	return nil
+}
+
+func (s *NotificationStore) GetRoute(_ context.Context, userID, id int64) (*models.NotificationRoute, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	r, ok := s.db.routes[id]
+	if !ok || r.UserID != userID {
+		return nil, pgx.ErrNoRows
+	}
+	return &r, nil
+}
+
+func (s *NotificationStore) GetRoutesByUser(_ context.Context, userID int64) ([]models.NotificationRoute, error) {
+	return s.routes(func(r models.NotificationRoute) bool { return r.UserID == userID }), nil
+}
+
+// GetEnabledRoutes returns the enabled routes of every user.
+func (s *NotificationStore) GetEnabledRoutes(_ context.Context) ([]models.NotificationRoute, error) {
+	return s.routes(func(r models.NotificationRoute) bool { return r.Enabled }), nil
+}
+
+func (s *NotificationStore) AddNotification(_ context.Context, n *models.Notification) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	n.ID = s.db.nextID("notifications")
+	n.CreatedAt = s.db.now()
+	s.db.notifications = append(s.db.notifications, *n)
+	return n.ID, nil
+}
+
+// GetNotifications returns a user's notification history, newest first.
+func (s *NotificationStore) GetNotifications(_ context.Context, userID int64, pageNumber, limit int) (repository.NotificationsResponse, error) {
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var mine []models.Notification
+	for i := len(s.db.notifications) - 1; i >= 0; i-- {
+		if n := s.db.notifications[i]; n.UserID == userID {
+			mine = append(mine, n)
+		}
+	}
+	start, end := page(len(mine), pageNumber, limit)
+	resp := repository.NotificationsResponse{Notifications: []models.Notification{}, TotalCount: len(mine)}
+	resp.Notifications = append(resp.Notifications, mine[start:end]...)
+	return resp, nil
+}
+
+func (s *NotificationStore) routes(keep func(models.NotificationRoute) bool) []models.NotificationRoute {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	routes := []models.NotificationRoute{}
+	for _, r := range s.db.routes {
+		if keep(r) {
+			routes = append(routes, r)
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].ID < routes[j].ID })
+	return routes
+}
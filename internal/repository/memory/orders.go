@@ -0,0 +1,406 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var _ repository.OrderStore = (*OrderStore)(nil)
+
+type OrderStore struct {
+	db *DB
+}
+
+func (s *OrderStore) AddOrder(_ context.Context, o *models.Order) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.findLocked(o.OrderID); ok {
+		return 0, uniqueViolation("orders", "order_id", o.OrderID)
+	}
+	if err := invalidOrderStatus(o.Status); err != nil {
+		return 0, err
+	}
+	row := *o
+	if row.Source == "" {
+		row.Source = models.OrderSourceAlgo
+	}
+	row.ID = s.db.nextID("orders")
+	s.db.orders[row.ID] = row
+	return row.ID, nil
+}
+
+func (s *OrderStore) UpdateOrder(_ context.Context, o *models.Order, id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.orders[id]
+	if !ok {
+		return nil
+	}
+	if err := invalidOrderStatus(o.Status); err != nil {
+		return err
+	}
+	applyOrderUpdate(&row, o)
+	row.UpdatedAt = o.UpdatedAt
+	s.db.orders[id] = row
+	return nil
+}
+
+// UpsertOrder inserts the order, or updates the broker-reported fields of the
+// one with the same order ID.
+func (s *OrderStore) UpsertOrder(_ context.Context, o *models.Order) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if err := invalidOrderStatus(o.Status); err != nil {
+		return 0, err
+	}
+	if row, ok := s.findLocked(o.OrderID); ok {
+		applyOrderUpdate(&row, o)
+		row.UpdatedAt = s.db.now()
+		s.db.orders[row.ID] = row
+		return row.ID, nil
+	}
+	row := *o
+	if row.Source == "" {
+		row.Source = models.OrderSourceAlgo
+	}
+	row.ID = s.db.nextID("orders")
+	row.UpdatedAt = s.db.now()
+	s.db.orders[row.ID] = row
+	return row.ID, nil
+}
+
+func (s *OrderStore) GetOrderByKiteOrderID(_ context.Context, orderID string) (*models.Order, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.findLocked(orderID)
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &row, nil
+}
+
+func (s *OrderStore) GetOrdersByTrackingStockID(_ context.Context, trackingStockID int64, pageNumber int, limit int) (repository.StockOrdersResponse, error) {
+	orders := s.list(func(o models.Order) bool { return o.TrackingStockID == trackingStockID })
+	start, end := page(len(orders), pageNumber, limit)
+	resp := repository.StockOrdersResponse{TotalCount: len(orders)}
+	if start < end {
+		resp.Orders = orders[start:end]
+	}
+	return resp, nil
+}
+
+func (s *OrderStore) GetOrderByID(_ context.Context, id int64) (*models.Order, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.orders[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &row, nil
+}
+
+func (s *OrderStore) GetAllOrders(_ context.Context) ([]models.Order, error) {
+	return s.list(func(models.Order) bool { return true }), nil
+}
+
+// GetDailyTradeStats sums today's live orders per stock, with the price of
+// each stock's latest live order on any day.
+func (s *OrderStore) GetDailyTradeStats(_ context.Context, trackingStockIDs []int64) ([]repository.TradeStats, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	wanted := idSet(trackingStockIDs)
+	byStock := map[int64]*repository.TradeStats{}
+	lastAt := map[int64]time.Time{}
+	lastPrice := map[int64]float64{}
+	var ids []int64
+
+	for _, o := range s.db.orders {
+		if !wanted[o.TrackingStockID] || o.Status == "CANCELLED" || o.Status == "REJECTED" {
+			continue
+		}
+		if at, ok := lastAt[o.TrackingStockID]; !ok || o.PlacedAt.After(at) {
+			lastAt[o.TrackingStockID] = o.PlacedAt
+			lastPrice[o.TrackingStockID] = o.BasePrice
+			if o.PurchasePrice != nil {
+				lastPrice[o.TrackingStockID] = *o.PurchasePrice
+			}
+		}
+	}
+
+	for _, o := range s.db.orders {
+		if !wanted[o.TrackingStockID] || !s.db.isToday(o.PlacedAt) {
+			continue
+		}
+		st, ok := byStock[o.TrackingStockID]
+		if !ok {
+			st = &repository.TradeStats{TrackingStockID: o.TrackingStockID}
+			if price, ok := lastPrice[o.TrackingStockID]; ok {
+				st.LastPrice = &price
+			}
+			byStock[o.TrackingStockID] = st
+			ids = append(ids, o.TrackingStockID)
+		}
+		if o.Status == "CANCELLED" || o.Status == "REJECTED" {
+			continue
+		}
+		switch transactionType(o) {
+		case "BUY":
+			st.TotalBuy += int(o.Quantity)
+		case "SELL":
+			st.TotalSell += int(o.Quantity)
+		}
+		if o.EventType == "ENTRY_BUY" || o.EventType == "ENTRY_SELL" {
+			st.EntryCount++
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	stats := make([]repository.TradeStats, 0, len(ids))
+	for _, id := range ids {
+		stats = append(stats, *byStock[id])
+	}
+	return stats, nil
+}
+
+// GetAllStocksOrderImbalance returns bought minus sold quantity of today's
+// completed orders per stock.
+func (s *OrderStore) GetAllStocksOrderImbalance(_ context.Context, trackingStockIDs []int64) ([]repository.OrderImabalance, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	wanted := idSet(trackingStockIDs)
+	byStock := map[int64]int{}
+	var ids []int64
+	for _, o := range s.db.orders {
+		if !wanted[o.TrackingStockID] || o.Status != "COMPLETE" || !s.db.isToday(o.PlacedAt) {
+			continue
+		}
+		if _, ok := byStock[o.TrackingStockID]; !ok {
+			byStock[o.TrackingStockID] = 0
+			ids = append(ids, o.TrackingStockID)
+		}
+		switch transactionType(o) {
+		case "BUY":
+			byStock[o.TrackingStockID] += int(o.Quantity)
+		case "SELL":
+			byStock[o.TrackingStockID] -= int(o.Quantity)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	imbalances := make([]repository.OrderImabalance, 0, len(ids))
+	for _, id := range ids {
+		imbalances = append(imbalances, repository.OrderImabalance{TrackingStockID: id, Imbalance: byStock[id]})
+	}
+	return imbalances, nil
+}
+
+// GetRecoverableEntryOrders returns today's LIMIT entry orders still open at
+// the broker.
+func (s *OrderStore) GetRecoverableEntryOrders(_ context.Context) ([]models.Order, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var orders []models.Order
+	for _, o := range s.sortedLocked() {
+		if s.db.isToday(o.PlacedAt) && o.OrderType == "LIMIT" &&
+			(o.EventType == "ENTRY_BUY" || o.EventType == "ENTRY_SELL") &&
+			o.Status != "COMPLETE" && o.Status != "CANCELLED" && o.Status != "REJECTED" {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+func (s *OrderStore) AddOrderEvent(_ context.Context, e *models.OrderEvent) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row := *e
+	row.ID = s.db.nextID("order_events")
+	row.CreatedAt = s.db.now()
+	s.db.orderEvents = append(s.db.orderEvents, row)
+	return row.ID, nil
+}
+
+func (s *OrderStore) GetOrderEvents(_ context.Context, orderID string) ([]models.OrderEvent, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var events []models.OrderEvent
+	for _, e := range s.db.orderEvents {
+		if e.OrderID == orderID {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (s *OrderStore) AddPositionAdjustment(_ context.Context, a *models.PositionAdjustment) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row := *a
+	if row.Kind == "" {
+		row.Kind = models.AdjustmentKindAdjust
+	}
+	row.ID = s.db.nextID("position_adjustments")
+	row.CreatedAt = s.db.now()
+	s.db.adjustments = append(s.db.adjustments, row)
+	return row.ID, nil
+}
+
+// GetPositionAdjustments returns a stock's adjustments, newest first.
+func (s *OrderStore) GetPositionAdjustments(_ context.Context, trackingStockID int64) ([]models.PositionAdjustment, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var adjustments []models.PositionAdjustment
+	for i := len(s.db.adjustments) - 1; i >= 0; i-- {
+		if a := s.db.adjustments[i]; a.TrackingStockID == trackingStockID {
+			adjustments = append(adjustments, a)
+		}
+	}
+	return adjustments, nil
+}
+
+func (s *OrderStore) UpdateOrderStatus(_ context.Context, id int64, status string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if row, ok := s.db.orders[id]; ok {
+		row.Status, row.UpdatedAt = status, s.db.now()
+		s.db.orders[id] = row
+	}
+	return nil
+}
+
+func (s *OrderStore) DeleteOrder(_ context.Context, id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	delete(s.db.orders, id)
+	return nil
+}
+
+// StreamTradebookOrders calls fn for every order with fills placed in
+// [from, to), oldest first.
+func (s *OrderStore) StreamTradebookOrders(ctx context.Context, from, to time.Time, fn func(repository.TradebookOrder) error) error {
+	s.db.mu.Lock()
+	var rows []repository.TradebookOrder
+	var placed []time.Time
+	for _, o := range s.sortedLocked() {
+		if o.PlacedAt.Before(from) || !o.PlacedAt.Before(to) || o.Quantity <= 0 ||
+			o.PurchasePrice == nil || *o.PurchasePrice <= 0 || (o.Status != "COMPLETE" && o.Status != "CANCELLED") {
+			continue
+		}
+		row := repository.TradebookOrder{
+			OrderID:         o.OrderID,
+			ExchangeOrderID: o.ExchangeOrderID,
+			TradingSymbol:   s.db.trackingStocks[o.TrackingStockID].TradingSymbol,
+			Exchange:        o.Exchange,
+			Product:         o.Product,
+			TransactionType: o.TransactionType,
+			Quantity:        o.Quantity,
+			Price:           *o.PurchasePrice,
+			EventType:       o.EventType,
+			Source:          o.Source,
+			ExecutedAt:      o.PlacedAt,
+		}
+		if !o.UpdatedAt.IsZero() {
+			row.ExecutedAt = o.UpdatedAt
+		}
+		if to, ok := s.db.tradeOrders[o.OrderID]; ok {
+			tradeID := to.TradeID
+			row.Charges, row.TradeID, row.ExecutedAt = to.Charges, &tradeID, to.FilledAt
+		}
+		rows = append(rows, row)
+		placed = append(placed, o.PlacedAt)
+	}
+	s.db.mu.Unlock()
+
+	sort.SliceStable(rows, func(i, j int) bool { return placed[i].Before(placed[j]) })
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findLocked returns the order with the broker order ID. It must be called
+// with db.mu held.
+func (s *OrderStore) findLocked(orderID string) (models.Order, bool) {
+	for _, o := range s.db.orders {
+		if o.OrderID == orderID {
+			return o, true
+		}
+	}
+	return models.Order{}, false
+}
+
+// sortedLocked returns every order by id. It must be called with db.mu held.
+func (s *OrderStore) sortedLocked() []models.Order {
+	orders := make([]models.Order, 0, len(s.db.orders))
+	for _, o := range s.db.orders {
+		orders = append(orders, o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders
+}
+
+func (s *OrderStore) list(keep func(models.Order) bool) []models.Order {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var orders []models.Order
+	for _, o := range s.sortedLocked() {
+		if keep(o) {
+			orders = append(orders, o)
+		}
+	}
+	return orders
+}
+
+// applyOrderUpdate copies the fields the broker reports on an order.
+func applyOrderUpdate(row, o *models.Order) {
+	row.ExchangeOrderID = o.ExchangeOrderID
+	row.ParentOrderID = o.ParentOrderID
+	row.TransactionType = o.TransactionType
+	row.Exchange = o.Exchange
+	row.Product = o.Product
+	row.Quantity = o.Quantity
+	row.TriggerPrice = o.TriggerPrice
+	row.PurchasePrice = o.PurchasePrice
+	row.StatusMessage = o.StatusMessage
+	row.Status = o.Status
+}
+
+func transactionType(o models.Order) string {
+	if o.TransactionType == nil {
+		return ""
+	}
+	return *o.TransactionType
+}
+
+func idSet(ids []int64) map[int64]bool {
+	set := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
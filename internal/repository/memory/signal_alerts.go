@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var _ repository.SignalAlertStore = (*SignalAlertStore)(nil)
+
+type SignalAlertStore struct {
+	db *DB
+}
+
+// SaveKey sets a user's webhook key, replacing any previous one.
+func (s *SignalAlertStore) SaveKey(_ context.Context, key *models.SignalWebhookKey) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for userID, k := range s.db.webhookKeys {
+		if k.KeyID == key.KeyID && userID != key.UserID {
+			return uniqueViolation("signal_webhook_keys", "key_id", key.KeyID)
+		}
+	}
+	key.CreatedAt = s.db.now()
+	s.db.webhookKeys[key.UserID] = *key
+	return nil
+}
+
+func (s *SignalAlertStore) GetKeyByKeyID(_ context.Context, keyID string) (*models.SignalWebhookKey, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, k := range s.db.webhookKeys {
+		if k.KeyID == keyID {
+			return &k, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+// IsAlertAccepted reports whether the user already had an alert with this ID acted on.
+func (s *SignalAlertStore) IsAlertAccepted(_ context.Context, userID int64, alertID string) (bool, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, a := range s.db.alerts {
+		if a.UserID != nil && *a.UserID == userID && a.AlertID == alertID && a.Status == models.AlertAccepted {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *SignalAlertStore) AddAlert(_ context.Context, a *models.SignalAlert) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	a.ID = s.db.nextID("signal_alerts")
+	a.ReceivedAt = s.db.now()
+	s.db.alerts = append(s.db.alerts, *a)
+	return a.ID, nil
+}
+
+// GetAlerts returns a user's alert log, newest first.
+func (s *SignalAlertStore) GetAlerts(_ context.Context, userID int64, pageNumber, limit int) (repository.SignalAlertsResponse, error) {
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var mine []models.SignalAlert
+	for i := len(s.db.alerts) - 1; i >= 0; i-- {
+		if a := s.db.alerts[i]; a.UserID != nil && *a.UserID == userID {
+			mine = append(mine, a)
+		}
+	}
+	start, end := page(len(mine), pageNumber, limit)
+	resp := repository.SignalAlertsResponse{Alerts: []models.SignalAlert{}, TotalCount: len(mine)}
+	resp.Alerts = append(resp.Alerts, mine[start:end]...)
+	return resp, nil
+}
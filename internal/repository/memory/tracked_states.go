@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+)
+
+var _ repository.TrackedStockStateStore = (*TrackedStockStateStore)(nil)
+
+type TrackedStockStateStore struct {
+	db *DB
+}
+
+// SaveStates keeps one snapshot per stock and trading day, replacing the
+// previous one.
+func (s *TrackedStockStateStore) SaveStates(_ context.Context, states []models.TrackedStockState) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, st := range states {
+		s.db.states[stateKey{st.TrackingStockID, st.TradingDate.Format(time.DateOnly)}] = st
+	}
+	return nil
+}
+
+func (s *TrackedStockStateStore) GetStatesForDate(_ context.Context, tradingDate time.Time) ([]models.TrackedStockState, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	date := tradingDate.Format(time.DateOnly)
+	var states []models.TrackedStockState
+	for key, st := range s.db.states {
+		if key.tradingDate == date {
+			states = append(states, st)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].TrackingStockID < states[j].TrackingStockID })
+	return states, nil
+}
@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var _ repository.TrackingStockStore = (*TrackingStockStore)(nil)
+
+type TrackingStockStore struct {
+	db *DB
+}
+
+// AddTrackingStock inserts a stock, or revives and replaces the one with the
+// same trading symbol.
+func (s *TrackingStockStore) AddTrackingStock(_ context.Context, ts *models.TrackingStock) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row := *ts
+	if row.Exchange == "" {
+		row.Exchange = "NSE"
+	}
+	if row.AllowedTrades == 0 {
+		row.AllowedTrades = 1
+	}
+	row.IsDeleted, row.DeletedAt = false, nil
+	row.UpdatedAt = s.db.now()
+
+	for id, existing := range s.db.trackingStocks {
+		if existing.TradingSymbol == ts.TradingSymbol {
+			row.ID, row.CreatedAt = id, existing.CreatedAt
+			s.db.trackingStocks[id] = row
+			return id, nil
+		}
+	}
+	row.ID = s.db.nextID("tracking_stocks")
+	row.CreatedAt = row.UpdatedAt
+	s.db.trackingStocks[row.ID] = row
+	return row.ID, nil
+}
+
+func (s *TrackingStockStore) GetAllTrackingStocks(_ context.Context) ([]models.TrackingStock, error) {
+	return s.list(func(models.TrackingStock) bool { return true }), nil
+}
+
+func (s *TrackingStockStore) GetAllActiveTrackingStocks(_ context.Context) ([]models.TrackingStock, error) {
+	return s.list(func(ts models.TrackingStock) bool {
+		return ts.Status == "ACTIVE" || ts.Status == "AUTO_ACTIVE"
+	}), nil
+}
+
+func (s *TrackingStockStore) GetAllAutoInactiveTrackingStocks(_ context.Context) ([]models.TrackingStock, error) {
+	return s.list(func(ts models.TrackingStock) bool { return ts.Status == "AUTO_INACTIVE" }), nil
+}
+
+func (s *TrackingStockStore) GetTrackingStockByID(_ context.Context, id int64) (*models.TrackingStock, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	ts, ok := s.db.trackingStocks[id]
+	if !ok || ts.IsDeleted {
+		return nil, pgx.ErrNoRows
+	}
+	return &ts, nil
+}
+
+func (s *TrackingStockStore) GetTrackingStockByTradingSymbol(_ context.Context, tradingSymbol string) (*models.TrackingStock, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, ts := range s.db.trackingStocks {
+		if ts.TradingSymbol == tradingSymbol && !ts.IsDeleted {
+			return &ts, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (s *TrackingStockStore) UpdateTrackingStock(_ context.Context, ts *models.TrackingStock, id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.trackingStocks[id]
+	if !ok || row.IsDeleted {
+		return nil
+	}
+	row.Target, row.StopLoss, row.Quantity, row.OrderPriceLimit = ts.Target, ts.StopLoss, ts.Quantity, ts.OrderPriceLimit
+	if ts.AllowedTrades > 0 {
+		row.AllowedTrades = ts.AllowedTrades
+	}
+	row.UpdatedAt = s.db.now()
+	s.db.trackingStocks[id] = row
+	return nil
+}
+
+func (s *TrackingStockStore) UpdateTrackingStockStatus(_ context.Context, id int64, status string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.trackingStocks[id]
+	if !ok || row.IsDeleted {
+		return nil
+	}
+	row.Status, row.UpdatedAt = status, s.db.now()
+	s.db.trackingStocks[id] = row
+	return nil
+}
+
+// DeleteTrackingStock soft-deletes the stock, as the Postgres store does.
+func (s *TrackingStockStore) DeleteTrackingStock(_ context.Context, id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.trackingStocks[id]
+	if !ok || row.IsDeleted {
+		return nil
+	}
+	now := s.db.now()
+	row.IsDeleted, row.DeletedAt, row.UpdatedAt = true, &now, now
+	s.db.trackingStocks[id] = row
+	return nil
+}
+
+// list returns the stocks that are not deleted and match keep, by id.
+func (s *TrackingStockStore) list(keep func(models.TrackingStock) bool) []models.TrackingStock {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var stocks []models.TrackingStock
+	for _, ts := range s.db.trackingStocks {
+		if !ts.IsDeleted && keep(ts) {
+			stocks = append(stocks, ts)
+		}
+	}
+	sort.Slice(stocks, func(i, j int) bool { return stocks[i].ID < stocks[j].ID })
+	return stocks
+}
@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var _ repository.TradeStore = (*TradeStore)(nil)
+
+type TradeStore struct {
+	db *DB
+}
+
+// AttachOrder links a filled order to a trade the way the Postgres store
+// does: an order already linked keeps its trade and its first fill time, an
+// entry joins or opens the stock's open trade, and an exit without an open
+// trade fails with pgx.ErrNoRows.
+func (s *TradeStore) AttachOrder(_ context.Context, seed *models.Trade, o models.TradeOrder) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	tradeID := int64(0)
+	if linked, ok := s.db.tradeOrders[o.OrderID]; ok {
+		tradeID = linked.TradeID
+		o.FilledAt = linked.FilledAt
+	} else {
+		tradeID = s.openTradeLocked(seed.TrackingStockID)
+		if tradeID == 0 && o.Leg == models.TradeLegEntry {
+			tradeID = s.db.nextID("trades")
+			s.db.trades[tradeID] = models.Trade{
+				ID:              tradeID,
+				TrackingStockID: seed.TrackingStockID,
+				TradingSymbol:   seed.TradingSymbol,
+				Exchange:        seed.Exchange,
+				Direction:       seed.Direction,
+				Status:          models.TradeStatusOpen,
+				Source:          seed.Source,
+				RiskPoints:      seed.RiskPoints,
+				OpenedAt:        o.FilledAt,
+				UpdatedAt:       s.db.now(),
+			}
+		}
+		if tradeID == 0 {
+			return 0, pgx.ErrNoRows
+		}
+	}
+
+	o.TradeID = tradeID
+	s.db.tradeOrders[o.OrderID] = o
+	return tradeID, nil
+}
+
+func (s *TradeStore) UpdateTradeSummary(_ context.Context, t *models.Trade) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.trades[t.ID]
+	if !ok {
+		return nil
+	}
+	row.Status, row.Quantity, row.ExitQuantity = t.Status, t.Quantity, t.ExitQuantity
+	row.EntryPrice, row.ExitPrice, row.ExitReason = t.EntryPrice, t.ExitPrice, t.ExitReason
+	row.GrossPnL, row.Charges, row.NetPnL = t.GrossPnL, t.Charges, t.NetPnL
+	row.RMultiple, row.HoldingSeconds = t.RMultiple, t.HoldingSeconds
+	row.OpenedAt, row.ClosedAt = t.OpenedAt, t.ClosedAt
+	row.UpdatedAt = s.db.now()
+	s.db.trades[t.ID] = row
+	return nil
+}
+
+func (s *TradeStore) GetTradeByID(_ context.Context, id int64) (*models.Trade, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	t, ok := s.db.trades[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &t, nil
+}
+
+func (s *TradeStore) GetTradeOrders(_ context.Context, tradeID int64) ([]models.TradeOrder, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var orders []models.TradeOrder
+	for _, o := range s.db.tradeOrders {
+		if o.TradeID == tradeID {
+			orders = append(orders, o)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].FilledAt.Before(orders[j].FilledAt) })
+	return orders, nil
+}
+
+// GetTrades lists trades matching the filter, newest first.
+func (s *TradeStore) GetTrades(_ context.Context, f repository.TradeFilter) (repository.TradesResponse, error) {
+	trades := s.list(func(t models.Trade) bool {
+		return (f.From.IsZero() || !t.OpenedAt.Before(f.From)) &&
+			(f.To.IsZero() || t.OpenedAt.Before(f.To)) &&
+			(f.TrackingStockID == 0 || t.TrackingStockID == f.TrackingStockID) &&
+			(f.TradingSymbol == "" || t.TradingSymbol == f.TradingSymbol) &&
+			(f.Direction == "" || t.Direction == f.Direction) &&
+			(f.Status == "" || t.Status == f.Status) &&
+			(f.ExitReason == "" || (t.ExitReason != nil && *t.ExitReason == f.ExitReason)) &&
+			(f.Source == "" || t.Source == f.Source)
+	})
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].OpenedAt.After(trades[j].OpenedAt) })
+
+	limit, pageNumber := f.Limit, f.Page
+	if limit <= 0 {
+		limit = 20
+	}
+	if pageNumber <= 0 {
+		pageNumber = 1
+	}
+	start, end := page(len(trades), pageNumber, limit)
+	resp := repository.TradesResponse{Trades: []models.Trade{}, TotalCount: len(trades)}
+	resp.Trades = append(resp.Trades, trades[start:end]...)
+	return resp, nil
+}
+
+// GetClosedTrades returns the trades closed in [from, to), oldest first.
+func (s *TradeStore) GetClosedTrades(_ context.Context, from, to time.Time) ([]models.Trade, error) {
+	trades := s.list(func(t models.Trade) bool {
+		return t.Status == models.TradeStatusClosed && t.ClosedAt != nil &&
+			!t.ClosedAt.Before(from) && t.ClosedAt.Before(to)
+	})
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].ClosedAt.Before(*trades[j].ClosedAt) })
+	return trades, nil
+}
+
+// StreamTrades calls fn for every trade opened in [from, to), oldest first.
+func (s *TradeStore) StreamTrades(ctx context.Context, from, to time.Time, fn func(models.Trade) error) error {
+	trades := s.list(func(t models.Trade) bool { return !t.OpenedAt.Before(from) && t.OpenedAt.Before(to) })
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].OpenedAt.Before(trades[j].OpenedAt) })
+	for _, t := range trades {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openTradeLocked returns the id of the stock's open trade, or zero. It must
+// be called with db.mu held.
+func (s *TradeStore) openTradeLocked(trackingStockID int64) int64 {
+	for id, t := range s.db.trades {
+		if t.TrackingStockID == trackingStockID && t.Status == models.TradeStatusOpen {
+			return id
+		}
+	}
+	return 0
+}
+
+// list returns the trades that match keep, by id.
+func (s *TradeStore) list(keep func(models.Trade) bool) []models.Trade {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var trades []models.Trade
+	for _, t := range s.db.trades {
+		if keep(t) {
+			trades = append(trades, t)
+		}
+	}
+	sort.Slice(trades, func(i, j int) bool { return trades[i].ID < trades[j].ID })
+	return trades
+}
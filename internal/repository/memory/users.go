@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var _ repository.UserStore = (*UserStore)(nil)
+
+type UserStore struct {
+	db *DB
+}
+
+func (s *UserStore) Create(_ context.Context, user *models.User) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, u := range s.db.users {
+		if u.Phone == user.Phone {
+			return uniqueViolation("users", "phone", user.Phone)
+		}
+	}
+	user.ID = s.db.nextID("users")
+	s.db.users[user.ID] = *user
+	return nil
+}
+
+func (s *UserStore) GetByPhone(_ context.Context, phone string) (*models.User, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, u := range s.db.users {
+		if u.Phone == phone {
+			return &u, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+// GetUserProfile returns the user without the password hash.
+func (s *UserStore) GetUserProfile(_ context.Context, id int64) (*models.User, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	u, ok := s.db.users[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &models.User{ID: u.ID, FullName: u.FullName, Phone: u.Phone}, nil
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type NotificationRepository struct {
+	DB *pgxpool.Pool
+}
+
+type NotificationsResponse struct {
+	Notifications []models.Notification `json:"notifications"`
+	TotalCount    int                   `json:"total_count"`
+}
+
+const notificationRouteColumns = `id, user_id, channel, target, events, min_severity, enabled, created_at`
+
+func scanNotificationRoute(row pgx.Row) (models.NotificationRoute, error) {
+	var r models.NotificationRoute
+	err := row.Scan(&r.ID, &r.UserID, &r.Channel, &r.Target, &r.Events, &r.MinSeverity, &r.Enabled, &r.CreatedAt)
+	return r, err
+}
+
+func (r *NotificationRepository) CreateRoute(ctx context.Context, route *models.NotificationRoute) (int64, error) {
+	query := `
+		INSERT INTO notification_routes (user_id, channel, target, events, min_severity, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+	err := r.DB.QueryRow(ctx, query, route.UserID, route.Channel, route.Target, route.Events, route.MinSeverity, route.Enabled).
+		Scan(&route.ID, &route.CreatedAt)
+	return route.ID, err
+}
+
+// DeleteRoute removes one of a user's routes. It returns pgx.ErrNoRows when the
+// user has no such route.
+func (r *NotificationRepository) DeleteRoute(ctx context.Context, userID, id int64) error {
+	tag, err := r.DB.Exec(ctx, `DELETE FROM notification_routes WHERE id=$1 AND user_id=$2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *NotificationRepository) GetRoute(ctx context.Context, userID, id int64) (*models.NotificationRoute, error) {
+	query := `SELECT ` + notificationRouteColumns + ` FROM notification_routes WHERE id=$1 AND user_id=$2`
+	route, err := scanNotificationRoute(r.DB.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		return nil, err
+	}
+	return &route, nil
+}
+
+func (r *NotificationRepository) GetRoutesByUser(ctx context.Context, userID int64) ([]models.NotificationRoute, error) {
+	query := `SELECT ` + notificationRouteColumns + ` FROM notification_routes WHERE user_id=$1 ORDER BY id`
+	return r.queryRoutes(ctx, query, userID)
+}
+
+// GetEnabledRoutes returns the enabled routes of every user.
+func (r *NotificationRepository) GetEnabledRoutes(ctx context.Context) ([]models.NotificationRoute, error) {
+	query := `SELECT ` + notificationRouteColumns + ` FROM notification_routes WHERE enabled ORDER BY id`
+	return r.queryRoutes(ctx, query)
+}
+
+func (r *NotificationRepository) queryRoutes(ctx context.Context, query string, args ...any) ([]models.NotificationRoute, error) {
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	routes := []models.NotificationRoute{}
+	for rows.Next() {
+		route, err := scanNotificationRoute(rows)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	return routes, rows.Err()
+}
+
+func (r *NotificationRepository) AddNotification(ctx context.Context, n *models.Notification) (int64, error) {
+	query := `
+		INSERT INTO notifications (user_id, route_id, channel, event, severity, title, message, status, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+	err := r.DB.QueryRow(ctx, query, n.UserID, n.RouteID, n.Channel, n.Event, n.Severity, n.Title, n.Message, n.Status, n.Error).
+		Scan(&n.ID)
+	return n.ID, err
+}
+
+// GetNotifications returns a user's notification history, newest first.
+func (r *NotificationRepository) GetNotifications(ctx context.Context, userID int64, page, limit int) (NotificationsResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	query := `
+		SELECT id, user_id, route_id, channel, event, severity, title, message, status, error, created_at
+		FROM notifications WHERE user_id=$1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
+	rows, err := r.DB.Query(ctx, query, userID, limit, (page-1)*limit)
+	if err != nil {
+		return NotificationsResponse{}, err
+	}
+	defer rows.Close()
+
+	resp := NotificationsResponse{Notifications: []models.Notification{}}
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.RouteID, &n.Channel, &n.Event, &n.Severity, &n.Title, &n.Message,
+			&n.Status, &n.Error, &n.CreatedAt); err != nil {
+			return NotificationsResponse{}, err
+		}
+		resp.Notifications = append(resp.Notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return NotificationsResponse{}, err
+	}
+
+	err = r.DB.QueryRow(ctx, `SELECT count(*) FROM notifications WHERE user_id=$1`, userID).Scan(&resp.TotalCount)
+	return resp, err
+}
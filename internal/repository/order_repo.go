@@ -31,9 +31,13 @@ type TradeStats struct {
 }
 
 func (r *OrderRepository) AddOrder(ctx context.Context, o *models.Order) (ID int64, err error) {
-	query := `INSERT INTO orders (tracking_stock_id, order_id, order_type, event_type, base_price, quantity, purchase_price, status, placed_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+	source := o.Source
+	if source == "" {
+		source = models.OrderSourceAlgo
+	}
+	query := `INSERT INTO orders (tracking_stock_id, order_id, order_type, event_type, base_price, quantity, purchase_price, sizing_note, source, correlation_id, status, placed_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`
 
-	err = r.DB.QueryRow(ctx, query, o.TrackingStockID, o.OrderID, o.OrderType, o.EventType, o.BasePrice, o.Quantity, o.PurchasePrice, o.Status, o.PlacedAt).Scan(&ID)
+	err = r.DB.QueryRow(ctx, query, o.TrackingStockID, o.OrderID, o.OrderType, o.EventType, o.BasePrice, o.Quantity, o.PurchasePrice, o.SizingNote, source, o.CorrelationID, o.Status, o.PlacedAt).Scan(&ID)
 	if err != nil {
 		return 0, err
 	}
@@ -82,11 +86,11 @@ func (r *OrderRepository) UpsertOrder(ctx context.Context, o *models.Order) (int
 }
 
 func (r *OrderRepository) GetOrderByKiteOrderID(ctx context.Context, orderID string) (*models.Order, error) {
-	query := `SELECT id, tracking_stock_id, order_id, order_type, event_type, base_price, quantity, status, placed_at FROM orders WHERE order_id=$1`
+	query := `SELECT id, tracking_stock_id, order_id, order_type, event_type, base_price, quantity, source, correlation_id, status, placed_at FROM orders WHERE order_id=$1`
 	var o models.Order
 
 	err := r.DB.QueryRow(ctx, query, orderID).
-		Scan(&o.ID, &o.TrackingStockID, &o.OrderID, &o.OrderType, &o.EventType, &o.BasePrice, &o.Quantity, &o.Status, &o.PlacedAt)
+		Scan(&o.ID, &o.TrackingStockID, &o.OrderID, &o.OrderType, &o.EventType, &o.BasePrice, &o.Quantity, &o.Source, &o.CorrelationID, &o.Status, &o.PlacedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -94,7 +98,7 @@ func (r *OrderRepository) GetOrderByKiteOrderID(ctx context.Context, orderID str
 }
 
 func (r *OrderRepository) GetOrdersByTrackingStockID(ctx context.Context, trackingStockID int64, pageNumber int, limit int) (StockOrdersResponse, error) {
-	query := `SELECT id, tracking_stock_id, order_id, order_type, event_type, transaction_type, base_price, quantity, purchase_price, status, placed_at FROM orders WHERE tracking_stock_id=$1 LIMIT $2 OFFSET $3`
+	query := `SELECT id, tracking_stock_id, order_id, order_type, event_type, transaction_type, base_price, quantity, purchase_price, sizing_note, source, correlation_id, status, placed_at FROM orders WHERE tracking_stock_id=$1 LIMIT $2 OFFSET $3`
 	query2 := `SELECT count(*) FROM orders WHERE tracking_stock_id=$1`
 
 	rows, err := r.DB.Query(ctx, query, trackingStockID, limit, (pageNumber-1)*limit)
@@ -106,7 +110,7 @@ func (r *OrderRepository) GetOrdersByTrackingStockID(ctx context.Context, tracki
 	var orders []models.Order
 	for rows.Next() {
 		var o models.Order
-		err := rows.Scan(&o.ID, &o.TrackingStockID, &o.OrderID, &o.OrderType, &o.EventType, &o.TransactionType, &o.BasePrice, &o.Quantity, &o.PurchasePrice, &o.Status, &o.PlacedAt)
+		err := rows.Scan(&o.ID, &o.TrackingStockID, &o.OrderID, &o.OrderType, &o.EventType, &o.TransactionType, &o.BasePrice, &o.Quantity, &o.PurchasePrice, &o.SizingNote, &o.Source, &o.CorrelationID, &o.Status, &o.PlacedAt)
 		if err != nil {
 			return StockOrdersResponse{}, err
 		}
@@ -135,7 +139,7 @@ func (r *OrderRepository) GetOrderByID(ctx context.Context, id int64) (*models.O
 }
 
 func (r *OrderRepository) GetAllOrders(ctx context.Context) (orders []models.Order, err error) {
-	query := `SELECT id, tracking_stock_id, order_id, order_type, event_type, transaction_type, base_price, quantity, purchase_price, status, placed_at FROM orders`
+	query := `SELECT id, tracking_stock_id, order_id, order_type, event_type, transaction_type, base_price, quantity, purchase_price, sizing_note, source, status, placed_at FROM orders`
 	rows, err := r.DB.Query(ctx, query)
 	if err != nil {
 		return nil, err
@@ -144,7 +148,7 @@ func (r *OrderRepository) GetAllOrders(ctx context.Context) (orders []models.Ord
 
 	for rows.Next() {
 		var o models.Order
-		err := rows.Scan(&o.ID, &o.TrackingStockID, &o.OrderID, &o.OrderType, &o.EventType, &o.TransactionType, &o.BasePrice, &o.Quantity, &o.PurchasePrice, &o.Status, &o.PlacedAt)
+		err := rows.Scan(&o.ID, &o.TrackingStockID, &o.OrderID, &o.OrderType, &o.EventType, &o.TransactionType, &o.BasePrice, &o.Quantity, &o.PurchasePrice, &o.SizingNote, &o.Source, &o.Status, &o.PlacedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -240,7 +244,7 @@ func (r *OrderRepository) GetAllStocksOrderImbalance(ctx context.Context, tracki
 }
 
 func (r *OrderRepository) GetRecoverableEntryOrders(ctx context.Context) (orders []models.Order, err error) {
-	query := `SELECT tracking_stock_id, order_id, event_type, base_price, quantity, status, placed_at
+	query := `SELECT tracking_stock_id, order_id, event_type, base_price, quantity, correlation_id, status, placed_at
 		FROM orders
 		WHERE placed_at::date = CURRENT_DATE
 		  AND order_type = 'LIMIT'
@@ -255,7 +259,7 @@ func (r *OrderRepository) GetRecoverableEntryOrders(ctx context.Context) (orders
 
 	for rows.Next() {
 		var o models.Order
-		if err := rows.Scan(&o.TrackingStockID, &o.OrderID, &o.EventType, &o.BasePrice, &o.Quantity, &o.Status, &o.PlacedAt); err != nil {
+		if err := rows.Scan(&o.TrackingStockID, &o.OrderID, &o.EventType, &o.BasePrice, &o.Quantity, &o.CorrelationID, &o.Status, &o.PlacedAt); err != nil {
 			return nil, err
 		}
 		orders = append(orders, o)
@@ -264,6 +268,58 @@ func (r *OrderRepository) GetRecoverableEntryOrders(ctx context.Context) (orders
 	return orders, nil
 }
 
+func (r *OrderRepository) AddOrderEvent(ctx context.Context, e *models.OrderEvent) (ID int64, err error) {
+	query := `INSERT INTO order_events (tracking_stock_id, order_id, event, price, quantity, message) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	err = r.DB.QueryRow(ctx, query, e.TrackingStockID, e.OrderID, e.Event, e.Price, e.Quantity, e.Message).Scan(&ID)
+	return ID, err
+}
+
+func (r *OrderRepository) GetOrderEvents(ctx context.Context, orderID string) (events []models.OrderEvent, err error) {
+	query := `SELECT id, tracking_stock_id, order_id, event, price, quantity, message, created_at FROM order_events WHERE order_id=$1 ORDER BY created_at`
+	rows, err := r.DB.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.OrderEvent
+		if err := rows.Scan(&e.ID, &e.TrackingStockID, &e.OrderID, &e.Event, &e.Price, &e.Quantity, &e.Message, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+func (r *OrderRepository) AddPositionAdjustment(ctx context.Context, a *models.PositionAdjustment) (ID int64, err error) {
+	kind := a.Kind
+	if kind == "" {
+		kind = models.AdjustmentKindAdjust
+	}
+	query := `INSERT INTO position_adjustments (tracking_stock_id, kind, direction, quantity, base_price, ltp, old_target, new_target, old_stoploss, new_stoploss, old_trailing_stoploss, new_trailing_stoploss) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`
+	err = r.DB.QueryRow(ctx, query, a.TrackingStockID, kind, a.Direction, a.Quantity, a.BasePrice, a.LTP, a.OldTarget, a.NewTarget, a.OldStopLoss, a.NewStopLoss, a.OldTrailingStopLoss, a.NewTrailingStopLoss).Scan(&ID)
+	return ID, err
+}
+
+func (r *OrderRepository) GetPositionAdjustments(ctx context.Context, trackingStockID int64) (adjustments []models.PositionAdjustment, err error) {
+	query := `SELECT id, tracking_stock_id, kind, direction, quantity, base_price, ltp, old_target, new_target, old_stoploss, new_stoploss, old_trailing_stoploss, new_trailing_stoploss, created_at FROM position_adjustments WHERE tracking_stock_id=$1 ORDER BY created_at DESC`
+	rows, err := r.DB.Query(ctx, query, trackingStockID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a models.PositionAdjustment
+		if err := rows.Scan(&a.ID, &a.TrackingStockID, &a.Kind, &a.Direction, &a.Quantity, &a.BasePrice, &a.LTP, &a.OldTarget, &a.NewTarget, &a.OldStopLoss, &a.NewStopLoss, &a.OldTrailingStopLoss, &a.NewTrailingStopLoss, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, a)
+	}
+	return adjustments, nil
+}
+
 func (r *OrderRepository) UpdateOrderStatus(ctx context.Context, id int64, status string) error {
 	query := `UPDATE orders SET status=$1, updated_at=NOW() WHERE id=$2`
 	_, err := r.DB.Exec(ctx, query, status, id)
@@ -275,3 +331,54 @@ func (r *OrderRepository) DeleteOrder(ctx context.Context, id int64) error {
 	_, err := r.DB.Exec(ctx, query, id)
 	return err
 }
+
+// TradebookOrder is an executed order as it appears in the tradebook export.
+type TradebookOrder struct {
+	OrderID         string
+	ExchangeOrderID *string
+	TradingSymbol   string
+	Exchange        string
+	Product         *string
+	TransactionType *string
+	Quantity        float64
+	Price           float64
+	EventType       string
+	Source          string
+	Charges         float64
+	TradeID         *int64
+	ExecutedAt      time.Time
+}
+
+// StreamTradebookOrders calls fn for every order with fills placed in
+// [from, to), oldest first, without loading them all into memory.
+func (r *OrderRepository) StreamTradebookOrders(ctx context.Context, from, to time.Time, fn func(TradebookOrder) error) error {
+	query := `
+		SELECT o.order_id, o.exchange_order_id, COALESCE(ts.trading_symbol, ''), o.exchange, o.product,
+			o.transaction_type, o.quantity, o.purchase_price, o.event_type, o.source,
+			COALESCE(tor.charges, 0), tor.trade_id, COALESCE(tor.filled_at, o.updated_at, o.placed_at)
+		FROM orders o
+		LEFT JOIN tracking_stocks ts ON ts.id = o.tracking_stock_id
+		LEFT JOIN trade_orders tor ON tor.order_id = o.order_id
+		WHERE o.placed_at >= $1 AND o.placed_at < $2
+			AND o.quantity > 0 AND o.purchase_price > 0
+			AND o.status IN ('COMPLETE', 'CANCELLED')
+		ORDER BY o.placed_at`
+	rows, err := r.DB.Query(ctx, query, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o TradebookOrder
+		if err := rows.Scan(&o.OrderID, &o.ExchangeOrderID, &o.TradingSymbol, &o.Exchange, &o.Product,
+			&o.TransactionType, &o.Quantity, &o.Price, &o.EventType, &o.Source,
+			&o.Charges, &o.TradeID, &o.ExecutedAt); err != nil {
+			return err
+		}
+		if err := fn(o); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SignalAlertRepository struct {
+	DB *pgxpool.Pool
+}
+
+type SignalAlertsResponse struct {
+	Alerts     []models.SignalAlert `json:"alerts"`
+	TotalCount int                  `json:"total_count"`
+}
+
+// SaveKey sets a user's webhook key, replacing any previous one.
+func (r *SignalAlertRepository) SaveKey(ctx context.Context, key *models.SignalWebhookKey) error {
+	query := `
+		INSERT INTO signal_webhook_keys (user_id, key_id, secret, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			key_id = EXCLUDED.key_id,
+			secret = EXCLUDED.secret,
+			created_at = EXCLUDED.created_at
+		RETURNING created_at`
+	return r.DB.QueryRow(ctx, query, key.UserID, key.KeyID, key.Secret).Scan(&key.CreatedAt)
+}
+
+func (r *SignalAlertRepository) GetKeyByKeyID(ctx context.Context, keyID string) (*models.SignalWebhookKey, error) {
+	query := `SELECT user_id, key_id, secret, created_at FROM signal_webhook_keys WHERE key_id=$1`
+	var k models.SignalWebhookKey
+	if err := r.DB.QueryRow(ctx, query, keyID).Scan(&k.UserID, &k.KeyID, &k.Secret, &k.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// IsAlertAccepted reports whether the user already had an alert with this ID acted on.
+func (r *SignalAlertRepository) IsAlertAccepted(ctx context.Context, userID int64, alertID string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM signal_alerts WHERE user_id=$1 AND alert_id=$2 AND status='ACCEPTED')`
+	var exists bool
+	err := r.DB.QueryRow(ctx, query, userID, alertID).Scan(&exists)
+	return exists, err
+}
+
+func (r *SignalAlertRepository) AddAlert(ctx context.Context, a *models.SignalAlert) (int64, error) {
+	query := `
+		INSERT INTO signal_alerts (user_id, alert_id, trading_symbol, side, quantity, tracking_stock_id, status, reason, payload)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, received_at`
+	err := r.DB.QueryRow(ctx, query, a.UserID, a.AlertID, a.TradingSymbol, a.Side, a.Quantity, a.TrackingStockID,
+		a.Status, a.Reason, a.Payload).Scan(&a.ID, &a.ReceivedAt)
+	return a.ID, err
+}
+
+// GetAlerts returns a user's alert log, newest first.
+func (r *SignalAlertRepository) GetAlerts(ctx context.Context, userID int64, page, limit int) (SignalAlertsResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	query := `
+		SELECT id, user_id, alert_id, trading_symbol, side, quantity, tracking_stock_id, status, reason, payload, received_at
+		FROM signal_alerts WHERE user_id=$1
+		ORDER BY received_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
+	rows, err := r.DB.Query(ctx, query, userID, limit, (page-1)*limit)
+	if err != nil {
+		return SignalAlertsResponse{}, err
+	}
+	defer rows.Close()
+
+	resp := SignalAlertsResponse{Alerts: []models.SignalAlert{}}
+	for rows.Next() {
+		var a models.SignalAlert
+		if err := rows.Scan(&a.ID, &a.UserID, &a.AlertID, &a.TradingSymbol, &a.Side, &a.Quantity, &a.TrackingStockID,
+			&a.Status, &a.Reason, &a.Payload, &a.ReceivedAt); err != nil {
+			return SignalAlertsResponse{}, err
+		}
+		resp.Alerts = append(resp.Alerts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return SignalAlertsResponse{}, err
+	}
+
+	err = r.DB.QueryRow(ctx, `SELECT count(*) FROM signal_alerts WHERE user_id=$1`, userID).Scan(&resp.TotalCount)
+	return resp, err
+}
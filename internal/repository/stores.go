@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+)
+
+// The stores below are what the rest of the server depends on. The
+// *Repository types implement them on Postgres; package memory has
+// in-memory implementations for tests.
+
+type TrackingStockStore interface {
+	AddTrackingStock(ctx context.Context, ts *models.TrackingStock) (int64, error)
+	GetAllTrackingStocks(ctx context.Context) ([]models.TrackingStock, error)
+	GetAllActiveTrackingStocks(ctx context.Context) ([]models.TrackingStock, error)
+	GetAllAutoInactiveTrackingStocks(ctx context.Context) ([]models.TrackingStock, error)
+	GetTrackingStockByID(ctx context.Context, id int64) (*models.TrackingStock, error)
+	GetTrackingStockByTradingSymbol(ctx context.Context, tradingSymbol string) (*models.TrackingStock, error)
+	UpdateTrackingStock(ctx context.Context, ts *models.TrackingStock, id int64) error
+	UpdateTrackingStockStatus(ctx context.Context, id int64, status string) error
+	DeleteTrackingStock(ctx context.Context, id int64) error
+}
+
+type OrderStore interface {
+	AddOrder(ctx context.Context, o *models.Order) (int64, error)
+	UpdateOrder(ctx context.Context, o *models.Order, id int64) error
+	UpsertOrder(ctx context.Context, o *models.Order) (int64, error)
+	GetOrderByKiteOrderID(ctx context.Context, orderID string) (*models.Order, error)
+	GetOrdersByTrackingStockID(ctx context.Context, trackingStockID int64, pageNumber int, limit int) (StockOrdersResponse, error)
+	GetOrderByID(ctx context.Context, id int64) (*models.Order, error)
+	GetAllOrders(ctx context.Context) ([]models.Order, error)
+	GetDailyTradeStats(ctx context.Context, trackingStockIDs []int64) ([]TradeStats, error)
+	GetAllStocksOrderImbalance(ctx context.Context, trackingStockIDs []int64) ([]OrderImabalance, error)
+	GetRecoverableEntryOrders(ctx context.Context) ([]models.Order, error)
+	AddOrderEvent(ctx context.Context, e *models.OrderEvent) (int64, error)
+	GetOrderEvents(ctx context.Context, orderID string) ([]models.OrderEvent, error)
+	AddPositionAdjustment(ctx context.Context, a *models.PositionAdjustment) (int64, error)
+	GetPositionAdjustments(ctx context.Context, trackingStockID int64) ([]models.PositionAdjustment, error)
+	UpdateOrderStatus(ctx context.Context, id int64, status string) error
+	DeleteOrder(ctx context.Context, id int64) error
+	StreamTradebookOrders(ctx context.Context, from, to time.Time, fn func(TradebookOrder) error) error
+}
+
+type TradeStore interface {
+	AttachOrder(ctx context.Context, seed *models.Trade, o models.TradeOrder) (int64, error)
+	UpdateTradeSummary(ctx context.Context, t *models.Trade) error
+	GetTradeByID(ctx context.Context, id int64) (*models.Trade, error)
+	GetTradeOrders(ctx context.Context, tradeID int64) ([]models.TradeOrder, error)
+	GetTrades(ctx context.Context, f TradeFilter) (TradesResponse, error)
+	GetClosedTrades(ctx context.Context, from, to time.Time) ([]models.Trade, error)
+	StreamTrades(ctx context.Context, from, to time.Time, fn func(models.Trade) error) error
+}
+
+type UserStore interface {
+	Create(ctx context.Context, user *models.User) error
+	GetByPhone(ctx context.Context, phone string) (*models.User, error)
+	GetUserProfile(ctx context.Context, id int64) (*models.User, error)
+}
+
+type InstrumentStore interface {
+	UpsertInstruments(ctx context.Context, exchange string, instrumentsData []byte) (int64, error)
+	GetStoredDateByExchange(ctx context.Context, exchange string) (*models.Instrument, error)
+	GetInstrumentsByExchange(ctx context.Context, exchange string) (*models.Instrument, error)
+	UpdateInstruments(ctx context.Context, exchange string, instrumentsData string) error
+}
+
+type TrackedStockStateStore interface {
+	SaveStates(ctx context.Context, states []models.TrackedStockState) error
+	GetStatesForDate(ctx context.Context, tradingDate time.Time) ([]models.TrackedStockState, error)
+}
+
+type NotificationStore interface {
+	CreateRoute(ctx context.Context, route *models.NotificationRoute) (int64, error)
+	DeleteRoute(ctx context.Context, userID, id int64) error
+	GetRoute(ctx context.Context, userID, id int64) (*models.NotificationRoute, error)
+	GetRoutesByUser(ctx context.Context, userID int64) ([]models.NotificationRoute, error)
+	GetEnabledRoutes(ctx context.Context) ([]models.NotificationRoute, error)
+	AddNotification(ctx context.Context, n *models.Notification) (int64, error)
+	GetNotifications(ctx context.Context, userID int64, page, limit int) (NotificationsResponse, error)
+}
+
+type SignalAlertStore interface {
+	SaveKey(ctx context.Context, key *models.SignalWebhookKey) error
+	GetKeyByKeyID(ctx context.Context, keyID string) (*models.SignalWebhookKey, error)
+	IsAlertAccepted(ctx context.Context, userID int64, alertID string) (bool, error)
+	AddAlert(ctx context.Context, a *models.SignalAlert) (int64, error)
+	GetAlerts(ctx context.Context, userID int64, page, limit int) (SignalAlertsResponse, error)
+}
+
+type APIKeyStore interface {
+	CreateKey(ctx context.Context, key *models.APIKey) (int64, error)
+	GetActiveKeyByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	GetKeysByUser(ctx context.Context, userID int64) ([]models.APIKey, error)
+	RevokeKey(ctx context.Context, userID, id int64) error
+	TouchKey(ctx context.Context, id int64) error
+}
+
+type LogStore interface {
+	AddLogEntries(ctx context.Context, entries []models.LogEntry) error
+	GetTradeLogTrail(ctx context.Context, tradeID int64) ([]models.LogEntry, error)
+}
+
+type ConfigOverrideStore interface {
+	GetOverrides(ctx context.Context) ([]models.ConfigOverride, error)
+	GetOverrideValues(ctx context.Context) (map[string]string, error)
+	SetOverrides(ctx context.Context, values map[string]string, userID int64) error
+	DeleteOverride(ctx context.Context, key string) error
+}
+
+var (
+	_ TrackingStockStore     = (*TrackingStocksRepository)(nil)
+	_ OrderStore             = (*OrderRepository)(nil)
+	_ TradeStore             = (*TradeRepository)(nil)
+	_ UserStore              = (*UserRepository)(nil)
+	_ InstrumentStore        = (*InstrumentRepository)(nil)
+	_ TrackedStockStateStore = (*TrackedStockStateRepository)(nil)
+	_ NotificationStore      = (*NotificationRepository)(nil)
+	_ SignalAlertStore       = (*SignalAlertRepository)(nil)
+	_ APIKeyStore            = (*APIKeyRepository)(nil)
+	_ LogStore               = (*LogRepository)(nil)
+	_ ConfigOverrideStore    = (*ConfigOverrideRepository)(nil)
+)
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TrackedStockStateRepository struct {
+	DB *pgxpool.Pool
+}
+
+// SaveStates upserts one snapshot row per stock and trading day in a single batch.
+func (r *TrackedStockStateRepository) SaveStates(ctx context.Context, states []models.TrackedStockState) error {
+	query := `
+		INSERT INTO tracked_stock_state (
+			tracking_stock_id, trading_date, instrument_token, trading_symbol,
+			direction, buy_quantity, sell_quantity, base_price, target, stoploss,
+			trailing_stoploss, signal_fired, locked, max_executable_orders,
+			pending_exit_order_id, exit_escalated, fifteen_candle, candles, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		ON CONFLICT (tracking_stock_id, trading_date) DO UPDATE SET
+			instrument_token = EXCLUDED.instrument_token,
+			trading_symbol = EXCLUDED.trading_symbol,
+			direction = EXCLUDED.direction,
+			buy_quantity = EXCLUDED.buy_quantity,
+			sell_quantity = EXCLUDED.sell_quantity,
+			base_price = EXCLUDED.base_price,
+			target = EXCLUDED.target,
+			stoploss = EXCLUDED.stoploss,
+			trailing_stoploss = EXCLUDED.trailing_stoploss,
+			signal_fired = EXCLUDED.signal_fired,
+			locked = EXCLUDED.locked,
+			max_executable_orders = EXCLUDED.max_executable_orders,
+			pending_exit_order_id = EXCLUDED.pending_exit_order_id,
+			exit_escalated = EXCLUDED.exit_escalated,
+			fifteen_candle = EXCLUDED.fifteen_candle,
+			candles = EXCLUDED.candles,
+			updated_at = EXCLUDED.updated_at`
+
+	batch := &pgx.Batch{}
+	for _, s := range states {
+		batch.Queue(query,
+			s.TrackingStockID, s.TradingDate, s.InstrumentToken, s.TradingSymbol,
+			s.Direction, s.BuyQuantity, s.SellQuantity, s.BasePrice, s.Target, s.StopLoss,
+			s.TrailingStopLoss, s.SignalFired, s.Locked, s.MaxExecutableOrders,
+			s.PendingExitOrderID, s.ExitEscalated, s.FifteenCandle, s.Candles, s.UpdatedAt,
+		)
+	}
+	return r.DB.SendBatch(ctx, batch).Close()
+}
+
+// GetStatesForDate returns every snapshot written on the given trading day.
+func (r *TrackedStockStateRepository) GetStatesForDate(ctx context.Context, tradingDate time.Time) (states []models.TrackedStockState, err error) {
+	query := `SELECT tracking_stock_id, trading_date, instrument_token, trading_symbol,
+			direction, buy_quantity, sell_quantity, base_price, target, stoploss,
+			trailing_stoploss, signal_fired, locked, max_executable_orders,
+			pending_exit_order_id, exit_escalated, fifteen_candle, candles, updated_at
+		FROM tracked_stock_state WHERE trading_date=$1`
+	rows, err := r.DB.Query(ctx, query, tradingDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s models.TrackedStockState
+		if err := rows.Scan(&s.TrackingStockID, &s.TradingDate, &s.InstrumentToken, &s.TradingSymbol,
+			&s.Direction, &s.BuyQuantity, &s.SellQuantity, &s.BasePrice, &s.Target, &s.StopLoss,
+			&s.TrailingStopLoss, &s.SignalFired, &s.Locked, &s.MaxExecutableOrders,
+			&s.PendingExitOrderID, &s.ExitEscalated, &s.FifteenCandle, &s.Candles, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		states = append(states, s)
+	}
+	return states, rows.Err()
+}
@@ -13,30 +13,43 @@ type TrackingStocksRepository struct {
 func (r *TrackingStocksRepository) AddTrackingStock(ctx context.Context, ts *models.TrackingStock) (ID int64, err error) {
 	query := `
         INSERT INTO tracking_stocks (
-            trading_symbol, instrument_token, target, stoploss, 
-            order_price_limit, quantity, status, is_deleted, deleted_at
+            trading_symbol, exchange, instrument_token, target, stoploss, 
+            order_price_limit, quantity, allowed_trades, status, is_deleted, deleted_at
         ) 
-        VALUES ($1, $2, $3, $4, $5, $6, $7, FALSE, NULL)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, FALSE, NULL)
         ON CONFLICT (trading_symbol) 
         DO UPDATE SET 
+            exchange = EXCLUDED.exchange,
             instrument_token = EXCLUDED.instrument_token,
             target = EXCLUDED.target,
             stoploss = EXCLUDED.stoploss,
             order_price_limit = EXCLUDED.order_price_limit,
             quantity = EXCLUDED.quantity,
+            allowed_trades = EXCLUDED.allowed_trades,
             status = EXCLUDED.status,
             is_deleted = FALSE,
             deleted_at = NULL,
             updated_at = NOW()
         RETURNING id`
 
+	exchange := ts.Exchange
+	if exchange == "" {
+		exchange = "NSE"
+	}
+	allowedTrades := ts.AllowedTrades
+	if allowedTrades == 0 {
+		allowedTrades = 1
+	}
+
 	err = r.DB.QueryRow(ctx, query,
 		ts.TradingSymbol,
+		exchange,
 		ts.InstrumentToken,
 		ts.Target,
 		ts.StopLoss,
 		ts.OrderPriceLimit,
 		ts.Quantity,
+		allowedTrades,
 		ts.Status,
 	).Scan(&ID)
 
@@ -48,7 +61,7 @@ func (r *TrackingStocksRepository) AddTrackingStock(ctx context.Context, ts *mod
 
 func (r *TrackingStocksRepository) GetAllTrackingStocks(ctx context.Context) (trackingStocks []models.TrackingStock, err error) {
 	// Added WHERE is_deleted = FALSE
-	query := `SELECT id, trading_symbol, exchange, quantity, instrument_token, target, stoploss, order_price_limit, status, created_at 
+	query := `SELECT id, trading_symbol, exchange, quantity, allowed_trades, instrument_token, target, stoploss, order_price_limit, status, created_at 
               FROM tracking_stocks 
               WHERE is_deleted = FALSE`
 	rows, err := r.DB.Query(ctx, query)
@@ -59,7 +72,7 @@ func (r *TrackingStocksRepository) GetAllTrackingStocks(ctx context.Context) (tr
 
 	for rows.Next() {
 		var ts models.TrackingStock
-		err := rows.Scan(&ts.ID, &ts.TradingSymbol, &ts.Exchange, &ts.Quantity, &ts.InstrumentToken, &ts.Target, &ts.StopLoss, &ts.OrderPriceLimit, &ts.Status, &ts.CreatedAt)
+		err := rows.Scan(&ts.ID, &ts.TradingSymbol, &ts.Exchange, &ts.Quantity, &ts.AllowedTrades, &ts.InstrumentToken, &ts.Target, &ts.StopLoss, &ts.OrderPriceLimit, &ts.Status, &ts.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -71,7 +84,7 @@ func (r *TrackingStocksRepository) GetAllTrackingStocks(ctx context.Context) (tr
 
 func (r *TrackingStocksRepository) GetAllActiveTrackingStocks(ctx context.Context) (trackingStocks []models.TrackingStock, err error) {
 	// Added WHERE is_deleted = FALSE
-	query := `SELECT id, trading_symbol, exchange, quantity, instrument_token, target, stoploss, order_price_limit, status, created_at 
+	query := `SELECT id, trading_symbol, exchange, quantity, allowed_trades, instrument_token, target, stoploss, order_price_limit, status, created_at 
               FROM tracking_stocks 
               WHERE is_deleted = FALSE AND (status = 'ACTIVE' OR status = 'AUTO_ACTIVE')`
 	rows, err := r.DB.Query(ctx, query)
@@ -82,7 +95,7 @@ func (r *TrackingStocksRepository) GetAllActiveTrackingStocks(ctx context.Contex
 
 	for rows.Next() {
 		var ts models.TrackingStock
-		err := rows.Scan(&ts.ID, &ts.TradingSymbol, &ts.Exchange, &ts.Quantity, &ts.InstrumentToken, &ts.Target, &ts.StopLoss, &ts.OrderPriceLimit, &ts.Status, &ts.CreatedAt)
+		err := rows.Scan(&ts.ID, &ts.TradingSymbol, &ts.Exchange, &ts.Quantity, &ts.AllowedTrades, &ts.InstrumentToken, &ts.Target, &ts.StopLoss, &ts.OrderPriceLimit, &ts.Status, &ts.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -94,7 +107,7 @@ func (r *TrackingStocksRepository) GetAllActiveTrackingStocks(ctx context.Contex
 
 func (r *TrackingStocksRepository) GetAllAutoInactiveTrackingStocks(ctx context.Context) (trackingStocks []models.TrackingStock, err error) {
 	// Added AND is_deleted = FALSE
-	query := `SELECT id, trading_symbol, exchange, quantity, instrument_token, target, stoploss, order_price_limit, status, created_at 
+	query := `SELECT id, trading_symbol, exchange, quantity, allowed_trades, instrument_token, target, stoploss, order_price_limit, status, created_at 
               FROM tracking_stocks 
               WHERE status = 'AUTO_INACTIVE' AND is_deleted = FALSE`
 	rows, err := r.DB.Query(ctx, query)
@@ -105,7 +118,7 @@ func (r *TrackingStocksRepository) GetAllAutoInactiveTrackingStocks(ctx context.
 
 	for rows.Next() {
 		var ts models.TrackingStock
-		err := rows.Scan(&ts.ID, &ts.TradingSymbol, &ts.Exchange, &ts.Quantity, &ts.InstrumentToken, &ts.Target, &ts.StopLoss, &ts.OrderPriceLimit, &ts.Status, &ts.CreatedAt)
+		err := rows.Scan(&ts.ID, &ts.TradingSymbol, &ts.Exchange, &ts.Quantity, &ts.AllowedTrades, &ts.InstrumentToken, &ts.Target, &ts.StopLoss, &ts.OrderPriceLimit, &ts.Status, &ts.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -117,13 +130,13 @@ func (r *TrackingStocksRepository) GetAllAutoInactiveTrackingStocks(ctx context.
 
 func (r *TrackingStocksRepository) GetTrackingStockByID(ctx context.Context, id int64) (*models.TrackingStock, error) {
 	// Added AND is_deleted = FALSE
-	query := `SELECT id, trading_symbol, instrument_token, target, stoploss, order_price_limit, status, created_at 
+	query := `SELECT id, trading_symbol, exchange, quantity, allowed_trades, instrument_token, target, stoploss, order_price_limit, status, created_at 
               FROM tracking_stocks 
               WHERE id=$1 AND is_deleted = FALSE`
 
 	var ts models.TrackingStock
 	err := r.DB.QueryRow(ctx, query, id).
-		Scan(&ts.ID, &ts.TradingSymbol, &ts.InstrumentToken, &ts.Target, &ts.StopLoss, &ts.OrderPriceLimit, &ts.Status, &ts.CreatedAt)
+		Scan(&ts.ID, &ts.TradingSymbol, &ts.Exchange, &ts.Quantity, &ts.AllowedTrades, &ts.InstrumentToken, &ts.Target, &ts.StopLoss, &ts.OrderPriceLimit, &ts.Status, &ts.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -132,13 +145,13 @@ func (r *TrackingStocksRepository) GetTrackingStockByID(ctx context.Context, id
 
 func (r *TrackingStocksRepository) GetTrackingStockByTradingSymbol(ctx context.Context, trading_symbol string) (*models.TrackingStock, error) {
 	// Added AND is_deleted = FALSE
-	query := `SELECT id, trading_symbol, instrument_token, target, stoploss, order_price_limit, status, created_at 
+	query := `SELECT id, trading_symbol, exchange, quantity, allowed_trades, instrument_token, target, stoploss, order_price_limit, status, created_at 
               FROM tracking_stocks 
               WHERE trading_symbol=$1 AND is_deleted = FALSE`
 
 	var ts models.TrackingStock
 	err := r.DB.QueryRow(ctx, query, trading_symbol).
-		Scan(&ts.ID, &ts.TradingSymbol, &ts.InstrumentToken, &ts.Target, &ts.StopLoss, &ts.OrderPriceLimit, &ts.Status, &ts.CreatedAt)
+		Scan(&ts.ID, &ts.TradingSymbol, &ts.Exchange, &ts.Quantity, &ts.AllowedTrades, &ts.InstrumentToken, &ts.Target, &ts.StopLoss, &ts.OrderPriceLimit, &ts.Status, &ts.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -147,9 +160,11 @@ func (r *TrackingStocksRepository) GetTrackingStockByTradingSymbol(ctx context.C
 
 func (r *TrackingStocksRepository) UpdateTrackingStock(ctx context.Context, ts *models.TrackingStock, ID int64) error {
 	// Added AND is_deleted = FALSE to prevent updating "deleted" records
-	query := `UPDATE tracking_stocks SET target=$1, stoploss=$2, quantity=$3, order_price_limit=$4, updated_at=NOW() 
-              WHERE id=$5 AND is_deleted = FALSE`
-	_, err := r.DB.Exec(ctx, query, ts.Target, ts.StopLoss, ts.Quantity, ts.OrderPriceLimit, ID)
+	// allowed_trades is left unchanged when not given
+	query := `UPDATE tracking_stocks SET target=$1, stoploss=$2, quantity=$3, order_price_limit=$4,
+              allowed_trades=COALESCE(NULLIF($5, 0), allowed_trades), updated_at=NOW() 
+              WHERE id=$6 AND is_deleted = FALSE`
+	_, err := r.DB.Exec(ctx, query, ts.Target, ts.StopLoss, ts.Quantity, ts.OrderPriceLimit, int64(ts.AllowedTrades), ID)
 	return err
 }
 
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TradeRepository struct {
+	DB *pgxpool.Pool
+}
+
+// TradeFilter narrows GetTrades. Zero values match everything.
+type TradeFilter struct {
+	From            time.Time // opened at or after
+	To              time.Time // opened before
+	TrackingStockID int64
+	TradingSymbol   string
+	Direction       string
+	Status          string
+	ExitReason      string
+	Source          string
+	Page            int
+	Limit           int
+}
+
+type TradesResponse struct {
+	Trades     []models.Trade `json:"trades"`
+	TotalCount int            `json:"total_count"`
+}
+
+const tradeColumns = `id, tracking_stock_id, trading_symbol, exchange, direction, status, source, quantity, exit_quantity, entry_price, exit_price, risk_points, exit_reason, gross_pnl, charges, net_pnl, r_multiple, holding_seconds, opened_at, closed_at, updated_at`
+
+func scanTrade(row pgx.Row) (models.Trade, error) {
+	var t models.Trade
+	var trackingStockID *int64
+	err := row.Scan(&t.ID, &trackingStockID, &t.TradingSymbol, &t.Exchange, &t.Direction, &t.Status, &t.Source,
+		&t.Quantity, &t.ExitQuantity, &t.EntryPrice, &t.ExitPrice, &t.RiskPoints, &t.ExitReason, &t.GrossPnL,
+		&t.Charges, &t.NetPnL, &t.RMultiple, &t.HoldingSeconds, &t.OpenedAt, &t.ClosedAt, &t.UpdatedAt)
+	if trackingStockID != nil {
+		t.TrackingStockID = *trackingStockID
+	}
+	return t, err
+}
+
+// AttachOrder links a filled order to a trade and records its fill. An order
+// already linked keeps its trade and its first fill time. Otherwise an entry joins the stock's open
+// trade, opening one from seed if there is none, and an exit joins the open
+// trade or fails with pgx.ErrNoRows.
+func (r *TradeRepository) AttachOrder(ctx context.Context, seed *models.Trade, o models.TradeOrder) (tradeID int64, err error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `SELECT trade_id FROM trade_orders WHERE order_id=$1`, o.OrderID).Scan(&tradeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if o.Leg == models.TradeLegEntry {
+			_, err = tx.Exec(ctx, `INSERT INTO trades (tracking_stock_id, trading_symbol, exchange, direction, status, source, risk_points, opened_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (tracking_stock_id) WHERE status = 'OPEN' DO NOTHING`,
+				seed.TrackingStockID, seed.TradingSymbol, seed.Exchange, seed.Direction, models.TradeStatusOpen, seed.Source, seed.RiskPoints, o.FilledAt)
+			if err != nil {
+				return 0, err
+			}
+		}
+		err = tx.QueryRow(ctx, `SELECT id FROM trades WHERE tracking_stock_id=$1 AND status=$2 FOR UPDATE`,
+			seed.TrackingStockID, models.TradeStatusOpen).Scan(&tradeID)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO trade_orders (order_id, trade_id, leg, event_type, quantity, average_price, charges, filled_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (order_id) DO UPDATE SET
+			event_type = EXCLUDED.event_type,
+			quantity = EXCLUDED.quantity,
+			average_price = EXCLUDED.average_price,
+			charges = EXCLUDED.charges`,
+		o.OrderID, tradeID, o.Leg, o.EventType, o.Quantity, o.AveragePrice, o.Charges, o.FilledAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return tradeID, tx.Commit(ctx)
+}
+
+// UpdateTradeSummary stores the figures computed from a trade's orders.
+func (r *TradeRepository) UpdateTradeSummary(ctx context.Context, t *models.Trade) error {
+	query := `UPDATE trades SET status=$1, quantity=$2, exit_quantity=$3, entry_price=$4, exit_price=$5, exit_reason=$6, gross_pnl=$7, charges=$8, net_pnl=$9, r_multiple=$10, holding_seconds=$11, opened_at=$12, closed_at=$13, updated_at=NOW() WHERE id=$14`
+	_, err := r.DB.Exec(ctx, query, t.Status, t.Quantity, t.ExitQuantity, t.EntryPrice, t.ExitPrice, t.ExitReason,
+		t.GrossPnL, t.Charges, t.NetPnL, t.RMultiple, t.HoldingSeconds, t.OpenedAt, t.ClosedAt, t.ID)
+	return err
+}
+
+func (r *TradeRepository) GetTradeByID(ctx context.Context, id int64) (*models.Trade, error) {
+	t, err := scanTrade(r.DB.QueryRow(ctx, `SELECT `+tradeColumns+` FROM trades WHERE id=$1`, id))
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TradeRepository) GetTradeOrders(ctx context.Context, tradeID int64) (orders []models.TradeOrder, err error) {
+	query := `SELECT trade_id, order_id, leg, event_type, quantity, average_price, charges, filled_at FROM trade_orders WHERE trade_id=$1 ORDER BY filled_at`
+	rows, err := r.DB.Query(ctx, query, tradeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o models.TradeOrder
+		if err := rows.Scan(&o.TradeID, &o.OrderID, &o.Leg, &o.EventType, &o.Quantity, &o.AveragePrice, &o.Charges, &o.FilledAt); err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	return orders, rows.Err()
+}
+
+// GetTrades lists trades matching the filter, newest first.
+func (r *TradeRepository) GetTrades(ctx context.Context, f TradeFilter) (TradesResponse, error) {
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if !f.From.IsZero() {
+		add("opened_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		add("opened_at < $%d", f.To)
+	}
+	if f.TrackingStockID != 0 {
+		add("tracking_stock_id = $%d", f.TrackingStockID)
+	}
+	if f.TradingSymbol != "" {
+		add("trading_symbol = $%d", f.TradingSymbol)
+	}
+	if f.Direction != "" {
+		add("direction = $%d", f.Direction)
+	}
+	if f.Status != "" {
+		add("status = $%d", f.Status)
+	}
+	if f.ExitReason != "" {
+		add("exit_reason = $%d", f.ExitReason)
+	}
+	if f.Source != "" {
+		add("source = $%d", f.Source)
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var resp TradesResponse
+	if err := r.DB.QueryRow(ctx, `SELECT count(*) FROM trades`+where, args...).Scan(&resp.TotalCount); err != nil {
+		return TradesResponse{}, err
+	}
+
+	limit, page := f.Limit, f.Page
+	if limit <= 0 {
+		limit = 20
+	}
+	if page <= 0 {
+		page = 1
+	}
+	query := fmt.Sprintf(`SELECT %s FROM trades%s ORDER BY opened_at DESC LIMIT $%d OFFSET $%d`,
+		tradeColumns, where, len(args)+1, len(args)+2)
+	rows, err := r.DB.Query(ctx, query, append(args, limit, (page-1)*limit)...)
+	if err != nil {
+		return TradesResponse{}, err
+	}
+	defer rows.Close()
+
+	resp.Trades = []models.Trade{}
+	for rows.Next() {
+		t, err := scanTrade(rows)
+		if err != nil {
+			return TradesResponse{}, err
+		}
+		resp.Trades = append(resp.Trades, t)
+	}
+	return resp, rows.Err()
+}
+
+// GetClosedTrades returns the trades closed in [from, to), oldest first.
+func (r *TradeRepository) GetClosedTrades(ctx context.Context, from, to time.Time) (trades []models.Trade, err error) {
+	query := `SELECT ` + tradeColumns + ` FROM trades WHERE status=$1 AND closed_at >= $2 AND closed_at < $3 ORDER BY closed_at`
+	rows, err := r.DB.Query(ctx, query, models.TradeStatusClosed, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := scanTrade(rows)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, t)
+	}
+	return trades, rows.Err()
+}
+
+// StreamTrades calls fn for every trade opened in [from, to), oldest first,
+// without loading them all into memory.
+func (r *TradeRepository) StreamTrades(ctx context.Context, from, to time.Time, fn func(models.Trade) error) error {
+	query := `SELECT ` + tradeColumns + ` FROM trades WHERE opened_at >= $1 AND opened_at < $2 ORDER BY opened_at`
+	rows, err := r.DB.Query(ctx, query, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := scanTrade(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
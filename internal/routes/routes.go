@@ -14,6 +14,15 @@ func RegisterRoutes(
 	orderHandler *handlers.OrderHandler,
 	stockQueryHandler *handlers.StockQueryHandler,
 	systemHandler *handlers.SystemHandler,
+	tradeHandler *handlers.TradeHandler,
+	analyticsHandler *handlers.AnalyticsHandler,
+	exportHandler *handlers.ExportHandler,
+	streamHandler *handlers.StreamHandler,
+	notificationHandler *handlers.NotificationHandler,
+	signalWebhookHandler *handlers.SignalWebhookHandler,
+	configHandler *handlers.ConfigHandler,
+	apiKeyHandler *handlers.APIKeyHandler,
+	backtestHandler *handlers.BacktestHandler,
 ) {
 	api := router.Group("/api/v1")
 
@@ -24,8 +33,11 @@ func RegisterRoutes(
 	// Kite Callback Route
 	api.GET("/kite/callback", kiteCallbackHandler.KiteCallback)
 
+	// Signal Webhook Route (authenticated by the alert's HMAC signature)
+	api.POST("/signals/webhook", signalWebhookHandler.Webhook)
+
 	protected := api.Group("/")
-	protected.Use(middleware.AuthMiddleware())
+	protected.Use(middleware.AuthMiddleware(apiKeyHandler.Repo))
 
 	// Tracking Stocks Routes
 	protected.POST("/tracking-stocks", trackingStockHandler.Add)
@@ -35,10 +47,46 @@ func RegisterRoutes(
 	protected.DELETE("/tracking-stocks/:id", trackingStockHandler.Delete)
 	protected.PATCH("/tracking-stocks/:id/start", trackingStockHandler.UpdateStatusToStart)
 	protected.PATCH("/tracking-stocks/:id/stop", trackingStockHandler.UpdateStatusToStop)
+	protected.POST("/tracking-stocks/:id/enter", trackingStockHandler.Enter)
+	protected.POST("/tracking-stocks/:id/exit", trackingStockHandler.Exit)
+	protected.PATCH("/tracking-stocks/:id/position", trackingStockHandler.AdjustPosition)
+	protected.POST("/tracking-stocks/:id/adopt", trackingStockHandler.Adopt)
+
+	// Broker Positions Route
+	protected.GET("/positions", trackingStockHandler.Positions)
 
 	// Order Routes
 	protected.GET("/orders", orderHandler.GetAllOrders)
 	protected.GET("/orders/tracking-stocks/:id", orderHandler.GetStockOrders)
+	protected.GET("/orders/tracking-stocks/:id/adjustments", orderHandler.GetPositionAdjustments)
+
+	// Trade Routes
+	protected.GET("/trades", tradeHandler.GetTrades)
+	protected.GET("/trades/:id", tradeHandler.GetTrade)
+	protected.GET("/trades/:id/logs", tradeHandler.GetTradeLogs)
+
+	// Analytics Routes
+	protected.GET("/analytics/performance", analyticsHandler.Performance)
+
+	// Export Routes
+	protected.GET("/exports/tradebook", exportHandler.ExportTradebook)
+
+	// Backtest Route
+	protected.GET("/tracking-stocks/:id/backtest", backtestHandler.Backtest)
+
+	// Live Stream Route
+	protected.GET("/stream", streamHandler.Stream)
+
+	// Notification Routes
+	protected.GET("/notifications", notificationHandler.GetNotifications)
+	protected.GET("/notifications/routes", notificationHandler.GetRoutes)
+	protected.POST("/notifications/routes", notificationHandler.CreateRoute)
+	protected.DELETE("/notifications/routes/:id", notificationHandler.DeleteRoute)
+	protected.POST("/notifications/routes/:id/test", notificationHandler.TestRoute)
+
+	// Signal Webhook Key and Alert Log Routes
+	protected.POST("/signals/webhook/key", signalWebhookHandler.RotateKey)
+	protected.GET("/signals/alerts", signalWebhookHandler.GetAlerts)
 
 	// Stock Query Route
 	protected.GET("/stocks/search", stockQueryHandler.GetSearchedStock)
@@ -47,4 +95,20 @@ func RegisterRoutes(
 	protected.GET("/user/profile", authHandler.Profile)
 
 	protected.GET("/system/status", systemHandler.SystemStatus)
+	protected.GET("/system/state-report", systemHandler.StateReport)
+	protected.POST("/system/reconcile", systemHandler.Reconcile)
+	protected.GET("/system/jobs", systemHandler.Jobs)
+	protected.POST("/system/jobs/:name/run", systemHandler.RunJob)
+
+	// API Key Routes
+	protected.GET("/api-keys", apiKeyHandler.GetKeys)
+	protected.POST("/api-keys", apiKeyHandler.CreateKey)
+	protected.DELETE("/api-keys/:id", apiKeyHandler.RevokeKey)
+
+	// Admin Config Routes (only for the users in ADMIN_USER_IDS)
+	admin := protected.Group("/admin", middleware.AdminMiddleware())
+	admin.GET("/config", configHandler.GetConfig)
+	admin.PUT("/config", configHandler.UpdateConfig)
+	admin.DELETE("/config/:key", configHandler.DeleteConfigOverride)
+	admin.POST("/config/reload", configHandler.ReloadConfig)
 }
@@ -2,10 +2,13 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/metrics"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/services"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/tracking"
@@ -21,12 +24,29 @@ type CronJob struct {
 	RunFunc func() error
 	LastRun time.Time
 	NextRun time.Time
+
+	// Outcome of the last run
+	LastDuration time.Duration
+	LastError    error
+}
+
+// JobStatus is a job's schedule and the outcome of its last run. LastRun is
+// zero when the job has not run since the process started.
+type JobStatus struct {
+	Name         string    `json:"name"`
+	NextRun      time.Time `json:"next_run"`
+	LastRun      time.Time `json:"last_run"`
+	LastDuration string    `json:"last_duration,omitempty"`
+	LastResult   string    `json:"last_result,omitempty"` // success or failure
+	LastError    string    `json:"last_error,omitempty"`
 }
 
 type Scheduler struct {
-	jobs     []*CronJob
-	stopChan chan struct{}
-	running  bool
+	jobs      []*CronJob
+	stopChan  chan struct{}
+	running   bool
+	onFailure func(job string, err error)
+	mu        sync.Mutex // guards the run times and results of jobs
 }
 
 func NewScheduler() *Scheduler {
@@ -46,6 +66,11 @@ func (s *Scheduler) AddJob(name string, hour, minute int, runFunc func() error)
 	s.jobs = append(s.jobs, job)
 }
 
+// SetFailureHandler registers fn to be called when a scheduled job fails.
+func (s *Scheduler) SetFailureHandler(fn func(job string, err error)) {
+	s.onFailure = fn
+}
+
 // Start begins the scheduler loop
 func (s *Scheduler) Start() {
 	if s.running {
@@ -80,6 +105,7 @@ func (s *Scheduler) runLoop() {
 
 		// Find next job to run
 		var nextJob *CronJob
+		s.mu.Lock()
 		for _, job := range s.jobs {
 			job.calculateNextRun(now)
 
@@ -87,6 +113,7 @@ func (s *Scheduler) runLoop() {
 				nextJob = job
 			}
 		}
+		s.mu.Unlock()
 
 		sleepDuration := time.Until(nextJob.NextRun)
 
@@ -96,8 +123,12 @@ func (s *Scheduler) runLoop() {
 		case <-time.After(sleepDuration):
 			log.Printf("⏰ Running job: %s", nextJob.Name)
 
-			if err := nextJob.RunFunc(); err != nil {
+			err := s.run(nextJob)
+			if err != nil {
 				log.Printf("❌ Job %s failed: %v", nextJob.Name, err)
+				if s.onFailure != nil {
+					s.onFailure(nextJob.Name, err)
+				}
 			} else {
 				log.Printf("✅ Job %s completed", nextJob.Name)
 			}
@@ -151,14 +182,58 @@ func (s *Scheduler) runLoop() {
 // 	}
 // }
 
+// ErrUnknownJob is returned by RunJobNow for a name no job has.
+var ErrUnknownJob = errors.New("unknown job")
+
+// RunJobNow runs the named job right away and returns its error.
 func (s *Scheduler) RunJobNow(name string) error {
 	for _, job := range s.jobs {
 		if job.Name == name {
 			log.Printf("⏰ Running cron job manually: %s", job.Name)
-			return job.RunFunc()
+			return s.run(job)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+}
+
+// run runs a job and records its outcome.
+func (s *Scheduler) run(job *CronJob) error {
+	start := time.Now()
+	err := job.RunFunc()
+	elapsed := time.Since(start)
+	metrics.SchedulerJobDuration.WithLabelValues(job.Name).Observe(elapsed.Seconds())
+	metrics.SchedulerJobs.WithLabelValues(job.Name, metrics.Outcome(err)).Inc()
+
+	s.mu.Lock()
+	job.LastRun = start
+	job.LastDuration = elapsed
+	job.LastError = err
+	s.mu.Unlock()
+	return err
+}
+
+// Status returns the schedule and last outcome of every job.
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().In(ist)
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		if job.NextRun.IsZero() {
+			job.calculateNextRun(now)
+		}
+		st := JobStatus{Name: job.Name, NextRun: job.NextRun, LastRun: job.LastRun}
+		if !job.LastRun.IsZero() {
+			st.LastDuration = job.LastDuration.Round(time.Millisecond).String()
+			st.LastResult = metrics.Outcome(job.LastError)
+			if job.LastError != nil {
+				st.LastError = job.LastError.Error()
+			}
 		}
+		statuses = append(statuses, st)
 	}
-	return nil
+	return statuses
 }
 
 func (j *CronJob) calculateNextRun(now time.Time) {
@@ -195,7 +270,7 @@ func CreateInstrumentFetchJob(instrumentSvc *services.InstrumentService) func()
 }
 
 func CreateMarketOpenJob(
-	trackingRepo *repository.TrackingStocksRepository,
+	trackingRepo repository.TrackingStockStore,
 	instrumentSvc *services.InstrumentService,
 	trackingManager *tracking.TrackingManager,
 	checkTokenValidationFunc func() bool,
@@ -259,7 +334,7 @@ func CreateMarketOpenJob(
 }
 
 func CreateMarketCloseJob(
-	trackingRepo *repository.TrackingStocksRepository,
+	trackingRepo repository.TrackingStockStore,
 	trackingManager *tracking.TrackingManager,
 	closeWebsocketFunc func(),
 	checkTokenValidationFunc func() bool,
@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository/memory"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/tracking"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/utils"
+)
+
+func TestStatusRecordsLastRun(t *testing.T) {
+	s := NewScheduler()
+	s.AddJob("ok", 8, 0, func() error { return nil })
+	s.AddJob("broken", 9, 0, func() error { return errors.New("kite is down") })
+
+	before := s.Status()
+	if before[0].NextRun.IsZero() || !before[0].LastRun.IsZero() || before[0].LastResult != "" {
+		t.Fatalf("status before any run = %+v", before[0])
+	}
+
+	s.RunJobNow("ok")
+	s.RunJobNow("broken")
+
+	after := s.Status()
+	if after[0].LastRun.IsZero() || after[0].LastResult != "success" || after[0].LastError != "" {
+		t.Fatalf("ok job status = %+v", after[0])
+	}
+	if after[1].LastResult != "failure" || after[1].LastError != "kite is down" {
+		t.Fatalf("broken job status = %+v", after[1])
+	}
+}
+
+func TestMarketCloseJobDeactivatesStocks(t *testing.T) {
+	ctx := context.Background()
+	stocks := memory.New().TrackingStocks()
+	for _, s := range []models.TrackingStock{
+		{TradingSymbol: "INFY", Status: "AUTO_ACTIVE"},
+		{TradingSymbol: "TCS", Status: "ACTIVE"},
+		{TradingSymbol: "WIPRO", Status: "INACTIVE"},
+	} {
+		if _, err := stocks.AddTrackingStock(ctx, &s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	closed, stopped := false, false
+	tokenValid := false
+	job := CreateMarketCloseJob(stocks, tracking.NewTrackingManager(nil, nil),
+		func() { closed = true }, func() bool { return tokenValid }, func() { stopped = true })
+
+	if err := job(); err == nil {
+		t.Fatal("job() with an invalid token error = nil")
+	}
+
+	tokenValid = true
+	tradingDay := utils.IsTradingDay()
+	if err := job(); err != nil {
+		t.Fatalf("job() error = %v", err)
+	}
+	if closed != tradingDay || stopped != tradingDay {
+		t.Errorf("websocket closed = %v, engines stopped = %v, want %v on a trading day only", closed, stopped, tradingDay)
+	}
+
+	want := map[string]string{"INFY": "AUTO_ACTIVE", "TCS": "ACTIVE", "WIPRO": "INACTIVE"}
+	if tradingDay {
+		want["INFY"], want["TCS"] = "AUTO_INACTIVE", "AUTO_INACTIVE"
+	}
+	all, _ := stocks.GetAllTrackingStocks(ctx)
+	for _, s := range all {
+		if s.Status != want[s.TradingSymbol] {
+			t.Errorf("%s status = %s, want %s", s.TradingSymbol, s.Status, want[s.TradingSymbol])
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+)
+
+type ClosedTradeRepo interface {
+	GetClosedTrades(ctx context.Context, from, to time.Time) ([]models.Trade, error)
+}
+
+// AnalyticsService computes strategy performance over closed trades.
+type AnalyticsService struct {
+	TradeRepo ClosedTradeRepo
+}
+
+// timeBucketMinutes is the width of the entry time buckets.
+const timeBucketMinutes = 30
+
+// PerformanceSummary holds the headline figures over a set of trades.
+// Expectancy is the average P&L per trade; ProfitFactor is gross profit over
+// gross loss and is nil when there were no losing trades. Wins, losses and
+// the ratios use gross P&L; Charges and NetPnL show what the costs took.
+type PerformanceSummary struct {
+	Trades         int      `json:"trades"`
+	Wins           int      `json:"wins"`
+	Losses         int      `json:"losses"`
+	WinRate        float64  `json:"win_rate"`
+	GrossPnL       float64  `json:"gross_pnl"`
+	GrossProfit    float64  `json:"gross_profit"`
+	GrossLoss      float64  `json:"gross_loss"`
+	Charges        float64  `json:"charges"`
+	NetPnL         float64  `json:"net_pnl"`
+	AvgWin         float64  `json:"avg_win"`
+	AvgLoss        float64  `json:"avg_loss"`
+	Expectancy     float64  `json:"expectancy"`
+	AvgR           *float64 `json:"avg_r"`
+	ProfitFactor   *float64 `json:"profit_factor"`
+	MaxDrawdown    float64  `json:"max_drawdown"`
+	AvgHoldingSecs float64  `json:"avg_holding_seconds"`
+}
+
+// PeriodPnL is the P&L of one day, ISO week or month.
+type PeriodPnL struct {
+	Period string  `json:"period"`
+	Trades int     `json:"trades"`
+	Wins   int     `json:"wins"`
+	PnL    float64 `json:"pnl"`
+	NetPnL float64 `json:"net_pnl"`
+}
+
+// EquityPoint is the cumulative P&L after one closed trade.
+type EquityPoint struct {
+	TradeID   int64     `json:"trade_id"`
+	ClosedAt  time.Time `json:"closed_at"`
+	PnL       float64   `json:"pnl"`
+	Equity    float64   `json:"equity"`
+	NetEquity float64   `json:"net_equity"`
+	Drawdown  float64   `json:"drawdown"`
+}
+
+// Breakdown is the performance of the trades sharing one key.
+type Breakdown struct {
+	Key     string   `json:"key"`
+	Trades  int      `json:"trades"`
+	Wins    int      `json:"wins"`
+	WinRate float64  `json:"win_rate"`
+	PnL     float64  `json:"pnl"`
+	NetPnL  float64  `json:"net_pnl"`
+	AvgR    *float64 `json:"avg_r"`
+}
+
+type PerformanceReport struct {
+	From         time.Time          `json:"from"`
+	To           time.Time          `json:"to"`
+	Summary      PerformanceSummary `json:"summary"`
+	Daily        []PeriodPnL        `json:"daily"`
+	Weekly       []PeriodPnL        `json:"weekly"`
+	Monthly      []PeriodPnL        `json:"monthly"`
+	EquityCurve  []EquityPoint      `json:"equity_curve"`
+	BySymbol     []Breakdown        `json:"by_symbol"`
+	ByWeekday    []Breakdown        `json:"by_weekday"`
+	ByTimeBucket []Breakdown        `json:"by_time_bucket"`
+	ByExitReason []Breakdown        `json:"by_exit_reason"`
+}
+
+// Performance reports on the trades closed in [from, to).
+func (s *AnalyticsService) Performance(ctx context.Context, from, to time.Time) (*PerformanceReport, error) {
+	trades, err := s.TradeRepo.GetClosedTrades(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	report := buildPerformanceReport(trades)
+	report.From, report.To = from, to
+	return report, nil
+}
+
+// buildPerformanceReport computes the report over trades sorted by close time.
+// Periods, weekdays and time buckets are in IST.
+func buildPerformanceReport(trades []models.Trade) *PerformanceReport {
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+
+	report := &PerformanceReport{EquityCurve: []EquityPoint{}}
+	daily := newPeriodAgg()
+	weekly := newPeriodAgg()
+	monthly := newPeriodAgg()
+	bySymbol := newBreakdownAgg()
+	byWeekday := newBreakdownAgg()
+	byBucket := newBreakdownAgg()
+	byReason := newBreakdownAgg()
+
+	sum := &report.Summary
+	var equity, netEquity, peak, rTotal, holdingTotal float64
+	var rCount, holdingCount int
+
+	for _, t := range trades {
+		closedAt := t.OpenedAt
+		if t.ClosedAt != nil {
+			closedAt = *t.ClosedAt
+		}
+		closedIST := closedAt.In(ist)
+		openedIST := t.OpenedAt.In(ist)
+		win := t.GrossPnL > 0
+
+		sum.Trades++
+		sum.GrossPnL += t.GrossPnL
+		sum.Charges += t.Charges
+		sum.NetPnL += t.NetPnL
+		switch {
+		case t.GrossPnL > 0:
+			sum.Wins++
+			sum.GrossProfit += t.GrossPnL
+		case t.GrossPnL < 0:
+			sum.Losses++
+			sum.GrossLoss -= t.GrossPnL
+		}
+		if t.RMultiple != nil {
+			rTotal += *t.RMultiple
+			rCount++
+		}
+		if t.HoldingSeconds != nil {
+			holdingTotal += float64(*t.HoldingSeconds)
+			holdingCount++
+		}
+
+		equity += t.GrossPnL
+		netEquity += t.NetPnL
+		peak = math.Max(peak, equity)
+		drawdown := peak - equity
+		sum.MaxDrawdown = math.Max(sum.MaxDrawdown, drawdown)
+		report.EquityCurve = append(report.EquityCurve, EquityPoint{
+			TradeID:   t.ID,
+			ClosedAt:  closedAt,
+			PnL:       t.GrossPnL,
+			Equity:    equity,
+			NetEquity: netEquity,
+			Drawdown:  drawdown,
+		})
+
+		year, week := closedIST.ISOWeek()
+		daily.add(closedIST.Format("2006-01-02"), t, win)
+		weekly.add(fmt.Sprintf("%d-W%02d", year, week), t, win)
+		monthly.add(closedIST.Format("2006-01"), t, win)
+
+		reason := "UNKNOWN"
+		if t.ExitReason != nil && *t.ExitReason != "" {
+			reason = *t.ExitReason
+		}
+		bucketMinute := (openedIST.Hour()*60 + openedIST.Minute()) / timeBucketMinutes * timeBucketMinutes
+		bySymbol.add(t.TradingSymbol, t)
+		byWeekday.add(openedIST.Weekday().String(), t)
+		byBucket.add(fmt.Sprintf("%02d:%02d", bucketMinute/60, bucketMinute%60), t)
+		byReason.add(reason, t)
+	}
+
+	if sum.Trades > 0 {
+		sum.WinRate = float64(sum.Wins) / float64(sum.Trades)
+		sum.Expectancy = sum.GrossPnL / float64(sum.Trades)
+	}
+	if sum.Wins > 0 {
+		sum.AvgWin = sum.GrossProfit / float64(sum.Wins)
+	}
+	if sum.Losses > 0 {
+		sum.AvgLoss = sum.GrossLoss / float64(sum.Losses)
+		pf := sum.GrossProfit / sum.GrossLoss
+		sum.ProfitFactor = &pf
+	}
+	if rCount > 0 {
+		avg := rTotal / float64(rCount)
+		sum.AvgR = &avg
+	}
+	if holdingCount > 0 {
+		sum.AvgHoldingSecs = holdingTotal / float64(holdingCount)
+	}
+
+	report.Daily = daily.list()
+	report.Weekly = weekly.list()
+	report.Monthly = monthly.list()
+	report.BySymbol = bySymbol.list()
+	report.ByWeekday = byWeekday.list()
+	report.ByTimeBucket = byBucket.list()
+	report.ByExitReason = byReason.list()
+	return report
+}
+
+type periodAgg map[string]*PeriodPnL
+
+func newPeriodAgg() periodAgg { return periodAgg{} }
+
+func (a periodAgg) add(period string, t models.Trade, win bool) {
+	p, ok := a[period]
+	if !ok {
+		p = &PeriodPnL{Period: period}
+		a[period] = p
+	}
+	p.Trades++
+	p.PnL += t.GrossPnL
+	p.NetPnL += t.NetPnL
+	if win {
+		p.Wins++
+	}
+}
+
+func (a periodAgg) list() []PeriodPnL {
+	out := make([]PeriodPnL, 0, len(a))
+	for _, p := range a {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Period < out[j].Period })
+	return out
+}
+
+type breakdownAgg map[string]*breakdownAcc
+
+type breakdownAcc struct {
+	Breakdown
+	rTotal float64
+	rCount int
+}
+
+func newBreakdownAgg() breakdownAgg { return breakdownAgg{} }
+
+func (a breakdownAgg) add(key string, t models.Trade) {
+	b, ok := a[key]
+	if !ok {
+		b = &breakdownAcc{Breakdown: Breakdown{Key: key}}
+		a[key] = b
+	}
+	b.Trades++
+	b.PnL += t.GrossPnL
+	b.NetPnL += t.NetPnL
+	if t.GrossPnL > 0 {
+		b.Wins++
+	}
+	if t.RMultiple != nil {
+		b.rTotal += *t.RMultiple
+		b.rCount++
+	}
+}
+
+// list returns the breakdowns by key, sorted by P&L, best first.
+func (a breakdownAgg) list() []Breakdown {
+	out := make([]Breakdown, 0, len(a))
+	for _, b := range a {
+		bd := b.Breakdown
+		bd.WinRate = float64(bd.Wins) / float64(bd.Trades)
+		if b.rCount > 0 {
+			avg := b.rTotal / float64(b.rCount)
+			bd.AvgR = &avg
+		}
+		out = append(out, bd)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].PnL != out[j].PnL {
+			return out[i].PnL > out[j].PnL
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
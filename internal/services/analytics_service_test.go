@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+)
+
+func TestBuildPerformanceReport(t *testing.T) {
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	day := time.Date(2026, 3, 2, 9, 40, 0, 0, ist) // a Monday
+
+	trade := func(id int64, symbol string, openedAt time.Time, pnl, r float64, reason string) models.Trade {
+		closedAt := openedAt.Add(time.Hour)
+		return models.Trade{
+			ID: id, TradingSymbol: symbol, Status: models.TradeStatusClosed, GrossPnL: pnl,
+			RMultiple: &r, ExitReason: &reason, OpenedAt: openedAt, ClosedAt: &closedAt,
+		}
+	}
+	trades := []models.Trade{
+		trade(1, "INFY", day, 300, 1.5, "TARGET_HIT"),
+		trade(2, "TCS", day.Add(45*time.Minute), -200, -1, "STOPLOSS_HIT"),
+		trade(3, "INFY", day.AddDate(0, 0, 1), -200, -1, "STOPLOSS_HIT"),
+		trade(4, "TCS", day.AddDate(0, 0, 1).Add(time.Hour), 500, 2.5, "TARGET_HIT"),
+	}
+
+	report := buildPerformanceReport(trades)
+	sum := report.Summary
+
+	if sum.Trades != 4 || sum.Wins != 2 || sum.Losses != 2 {
+		t.Fatalf("trades/wins/losses = %d/%d/%d, want 4/2/2", sum.Trades, sum.Wins, sum.Losses)
+	}
+	if sum.GrossPnL != 400 || sum.Expectancy != 100 || sum.WinRate != 0.5 {
+		t.Fatalf("pnl/expectancy/win rate = %.2f/%.2f/%.2f, want 400/100/0.5", sum.GrossPnL, sum.Expectancy, sum.WinRate)
+	}
+	if sum.ProfitFactor == nil || *sum.ProfitFactor != 2 {
+		t.Fatalf("profit factor = %v, want 2", sum.ProfitFactor)
+	}
+	if sum.AvgR == nil || *sum.AvgR != 0.5 {
+		t.Fatalf("avg R = %v, want 0.5", sum.AvgR)
+	}
+	// Equity: 300, 100, -100, 400; the peak of 300 falls to -100.
+	if sum.MaxDrawdown != 400 {
+		t.Fatalf("max drawdown = %.2f, want 400", sum.MaxDrawdown)
+	}
+	if len(report.Daily) != 2 || report.Daily[0].Period != "2026-03-02" || report.Daily[0].PnL != 100 {
+		t.Fatalf("daily = %+v", report.Daily)
+	}
+	if len(report.Weekly) != 1 || report.Weekly[0].Period != "2026-W10" {
+		t.Fatalf("weekly = %+v", report.Weekly)
+	}
+	if len(report.ByTimeBucket) != 3 {
+		t.Fatalf("time buckets = %+v, want 09:30, 10:00 and 10:30", report.ByTimeBucket)
+	}
+	if report.BySymbol[0].Key != "TCS" || report.BySymbol[0].PnL != 300 {
+		t.Fatalf("best symbol = %+v, want TCS with 300", report.BySymbol[0])
+	}
+}
@@ -1,9 +1,15 @@
 package services
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
 
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/config"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -29,3 +35,39 @@ func GenerateToken(userID int64) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtSecret)
 }
+
+// ---------- API KEYS ----------
+
+// apiKeyPrefixLen is how much of a key is kept in clear to tell keys apart.
+const apiKeyPrefixLen = 12
+
+// GenerateAPIKey returns a new API key, the prefix shown in key listings and
+// the hash that is stored. The key itself is never stored.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	key = "sck_" + hex.EncodeToString(b)
+	return key, key[:apiKeyPrefixLen], HashAPIKey(key), nil
+}
+
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey generates and stores a named API key for the user. The
+// returned key carries the key itself, which cannot be recovered later.
+func CreateAPIKey(ctx context.Context, store repository.APIKeyStore, userID int64, name string) (*models.APIKey, error) {
+	key, prefix, hash, err := GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	apiKey := &models.APIKey{UserID: userID, Name: name, KeyPrefix: prefix, KeyHash: hash}
+	if _, err := store.CreateKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+	apiKey.Key = key
+	return apiKey, nil
+}
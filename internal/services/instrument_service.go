@@ -9,19 +9,19 @@ import (
 	"strings"
 	"time"
 
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/kite"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
 	"github.com/jackc/pgx/v5"
-	kiteconnect "github.com/zerodha/gokiteconnect/v4"
-	"github.com/zerodha/gokiteconnect/v4/models"
 )
 
 type InstrumentService struct {
 	Kite                  *kite.KiteClient
-	Repo                  *repository.InstrumentRepository
-	NSEInstruments        kiteconnect.Instruments
-	NSESymbolToInstrument map[string]kiteconnect.Instrument
-	NSETokenToInstrument  map[uint32]kiteconnect.Instrument
+	Broker                broker.Broker
+	Repo                  repository.InstrumentStore
+	NSEInstruments        []broker.Instrument
+	NSESymbolToInstrument map[string]broker.Instrument
+	NSETokenToInstrument  map[uint32]broker.Instrument
 }
 
 type DBInstrument struct {
@@ -71,7 +71,7 @@ func (s *InstrumentService) FetchAndLoadInstruments() error {
 	}
 
 	var err error
-	s.NSEInstruments, err = s.Kite.GetInstrumentsByExchange("NSE")
+	s.NSEInstruments, err = s.Broker.GetInstruments("NSE")
 	if err != nil {
 		return fmt.Errorf("failed to load NSE instruments: %v", err)
 	}
@@ -105,7 +105,7 @@ func (s *InstrumentService) LoadInstrumentFromDB() error {
 		return fmt.Errorf("failed to get instruments for exchange %s: %v", "NSE", err)
 	}
 
-	// deserialize JSON → []kiteconnect.Instrument
+	// deserialize JSON → []broker.Instrument
 	// json.Unmarshal(inst.InstrumentsData, &s.NSEInstruments)
 	// s.SaveInstrumentsToFile()
 	var temp []DBInstrument
@@ -122,19 +122,19 @@ func (s *InstrumentService) LoadInstrumentFromDB() error {
 	// 	return fmt.Errorf("unmarshal failed: %v", err)
 	// }
 
-	s.NSEInstruments = make(kiteconnect.Instruments, len(temp))
+	s.NSEInstruments = make([]broker.Instrument, len(temp))
 
 	for i, dbInst := range temp {
 
-		var expiry models.Time
+		var expiry time.Time
 		if dbInst.Expiry != "" {
 			t, err := time.Parse(time.RFC3339, dbInst.Expiry)
 			if err == nil {
-				expiry = models.Time{Time: t}
+				expiry = t
 			}
 		}
 
-		s.NSEInstruments[i] = kiteconnect.Instrument{
+		s.NSEInstruments[i] = broker.Instrument{
 			InstrumentToken: dbInst.InstrumentToken,
 			ExchangeToken:   dbInst.ExchangeToken,
 			Tradingsymbol:   dbInst.Tradingsymbol,
@@ -155,8 +155,8 @@ func (s *InstrumentService) LoadInstrumentFromDB() error {
 }
 
 func (s *InstrumentService) BuildInstrumentMaps() {
-	s.NSESymbolToInstrument = make(map[string]kiteconnect.Instrument)
-	s.NSETokenToInstrument = make(map[uint32]kiteconnect.Instrument)
+	s.NSESymbolToInstrument = make(map[string]broker.Instrument)
+	s.NSETokenToInstrument = make(map[uint32]broker.Instrument)
 
 	log.Printf("🧭 building symbol/token index maps for %d NSE instruments", len(s.NSEInstruments))
 
@@ -193,9 +193,9 @@ func (s *InstrumentService) IsInstrumentsDataStale() (bool, error) {
 	return false, nil
 }
 
-func (s *InstrumentService) GetSearchedInstrumentByName(name string) ([]kiteconnect.Instrument, error) {
+func (s *InstrumentService) GetSearchedInstrumentByName(name string) ([]broker.Instrument, error) {
 	search := strings.ToUpper(name)
-	var results []kiteconnect.Instrument
+	var results []broker.Instrument
 
 	log.Printf("🔎 searching in %d NSE instruments", len(s.NSEInstruments))
 
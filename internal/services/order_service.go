@@ -5,15 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/charges"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/logging"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/utils"
 	"github.com/jackc/pgx/v5"
-
-	kiteconnect "github.com/zerodha/gokiteconnect/v4"
 )
 
 // BasePriceUpdater is implemented by TrackingManager to update base price. when an order completes. Using an interface avoids circular dependency.
@@ -27,8 +29,12 @@ type Manager interface {
 	GetBuyAndSellQuantityByToken(token uint32) (uint32, uint32, bool)
 	GetBasePriceByToken(token uint32) (float64, bool)
 	DecrementMaxExecutableOrders(instrumentToken uint32)
+	ClearPendingExit(instrumentToken uint32)
+	GetStopLossByToken(token uint32) (float64, bool)
 }
 
+var serviceLog = logging.For("orders")
+
 type OrderRepo interface {
 	AddOrder(ctx context.Context, o *models.Order) (ID int64, err error)
 	UpdateOrder(ctx context.Context, o *models.Order, ID int64) error
@@ -37,6 +43,8 @@ type OrderRepo interface {
 	GetDailyTradeStats(ctx context.Context, trackingStockIds []int64) (stats []repository.TradeStats, err error)
 	GetRecoverableEntryOrders(ctx context.Context) (orders []models.Order, err error)
 	UpsertOrder(ctx context.Context, o *models.Order) (int64, error)
+	AddOrderEvent(ctx context.Context, e *models.OrderEvent) (int64, error)
+	AddPositionAdjustment(ctx context.Context, a *models.PositionAdjustment) (int64, error)
 }
 
 type TrackingStockRepo interface {
@@ -46,17 +54,20 @@ type TrackingStockRepo interface {
 type OrderService struct {
 	OrderRepo         OrderRepo
 	TrackingStockRepo TrackingStockRepo
+	TradeRepo         TradeRepo
 	Manager           Manager
 
 	now            func() time.Time
 	pendingUpdates map[string]pendingOrderUpdate
+	onTransition   []func(OrderTransition)
 	mu             sync.Mutex
+	tradeMu        sync.Mutex
 }
 
 const pendingUpdateTTL = 2 * time.Minute
 
 type pendingOrderUpdate struct {
-	update     kiteconnect.Order
+	update     broker.Order
 	receivedAt time.Time
 }
 
@@ -72,7 +83,7 @@ func (s *OrderService) nowTime() time.Time {
 	return time.Now()
 }
 
-func (s *OrderService) cachePendingUpdate(orderUpdate kiteconnect.Order) {
+func (s *OrderService) cachePendingUpdate(orderUpdate broker.Order) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.pendingUpdates == nil {
@@ -86,11 +97,11 @@ func (s *OrderService) cachePendingUpdate(orderUpdate kiteconnect.Order) {
 	}
 }
 
-func (s *OrderService) popPendingUpdate(orderID string) (kiteconnect.Order, bool) {
+func (s *OrderService) popPendingUpdate(orderID string) (broker.Order, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.pendingUpdates == nil {
-		return kiteconnect.Order{}, false
+		return broker.Order{}, false
 	}
 	s.prunePendingUpdatesLocked(s.nowTime())
 	pending, ok := s.pendingUpdates[orderID]
@@ -120,7 +131,19 @@ func (s *OrderService) AddPlacedOrder(ctx context.Context, order *models.Order)
 	return nil
 }
 
-func (s *OrderService) ProcessOrderUpdate(ctx context.Context, orderUpdate kiteconnect.Order) error {
+// RecordOrderEvent persists a step taken by the order engine on a broker order.
+func (s *OrderService) RecordOrderEvent(ctx context.Context, event *models.OrderEvent) error {
+	_, err := s.OrderRepo.AddOrderEvent(ctx, event)
+	return err
+}
+
+// RecordPositionAdjustment persists a manual change to an open position's exit levels.
+func (s *OrderService) RecordPositionAdjustment(ctx context.Context, adjustment *models.PositionAdjustment) error {
+	_, err := s.OrderRepo.AddPositionAdjustment(ctx, adjustment)
+	return err
+}
+
+func (s *OrderService) ProcessOrderUpdate(ctx context.Context, orderUpdate broker.Order) error {
 	dbOrder, err := s.OrderRepo.GetOrderByKiteOrderID(ctx, orderUpdate.OrderID)
 	if err != nil {
 		if !errors.Is(err, pgx.ErrNoRows) {
@@ -129,6 +152,16 @@ func (s *OrderService) ProcessOrderUpdate(ctx context.Context, orderUpdate kitec
 		}
 		log.Printf("Failed to get order but continued %s: %v", orderUpdate.OrderID, err)
 	}
+	// A terminal update already applied, repeated by Kite or found by the exit
+	// supervisor before its postback arrived, must not close the position twice.
+	if dbOrder != nil && orderUpdate.IsTerminal() && dbOrder.Status == orderUpdate.Status &&
+		dbOrder.Quantity == orderUpdate.FilledQuantity {
+		return nil
+	}
+	if dbOrder != nil && dbOrder.CorrelationID != nil {
+		ctx = logging.WithCorrelationID(ctx, *dbOrder.CorrelationID)
+	}
+	orderLog := serviceLog.With("order_id", orderUpdate.OrderID, "symbol", orderUpdate.TradingSymbol)
 
 	stockID, exists := s.Manager.GetStockIDByTradingSymbol(orderUpdate.TradingSymbol)
 	buyQuantity, sellQuantity, exists := s.Manager.GetBuyAndSellQuantityByToken(uint32(orderUpdate.InstrumentToken))
@@ -138,22 +171,22 @@ func (s *OrderService) ProcessOrderUpdate(ctx context.Context, orderUpdate kitec
 		return nil
 	}
 
-	// isBuyTransaction := orderUpdate.TransactionType == kiteconnect.TransactionTypeBuy
+	// isBuyTransaction := orderUpdate.TransactionType == broker.TransactionTypeBuy
 	// Determine if this is an entry order from our saved event type.
 	isEntryOrder := false
 	if dbOrder != nil {
 		isEntryOrder = dbOrder.EventType == "ENTRY_BUY" || dbOrder.EventType == "ENTRY_SELL"
 	} else {
 		// If DB record doesn't exist yet, we infer intent from our Manager's current state
-		if orderUpdate.TransactionType == kiteconnect.TransactionTypeBuy {
+		if orderUpdate.TransactionType == broker.TransactionTypeBuy {
 			// If we are buying and have no current sell obligations, it's likely an entry
 			isEntryOrder = sellQuantity == 0
-		} else if orderUpdate.TransactionType == kiteconnect.TransactionTypeSell {
+		} else if orderUpdate.TransactionType == broker.TransactionTypeSell {
 			// If we are selling and have no current buy holdings, it's likely a short entry
 			isEntryOrder = buyQuantity == 0
 		}
 	}
-	isBuyTransaction := orderUpdate.TransactionType == kiteconnect.TransactionTypeBuy
+	isBuyTransaction := orderUpdate.TransactionType == broker.TransactionTypeBuy
 	token := uint32(orderUpdate.InstrumentToken)
 
 	updatedOrder := &models.Order{
@@ -171,27 +204,36 @@ func (s *OrderService) ProcessOrderUpdate(ctx context.Context, orderUpdate kitec
 		TriggerPrice:    utils.ToNullFloat(orderUpdate.TriggerPrice),
 		PurchasePrice:   utils.ToNullFloat(orderUpdate.AveragePrice),
 		StatusMessage:   utils.ToNullString(orderUpdate.StatusMessage),
-		Status:          orderUpdate.Status,
-		PlacedAt:        orderUpdate.OrderTimestamp.Time,
+		Status:          models.StoredOrderStatus(orderUpdate.Status),
+		PlacedAt:        orderUpdate.OrderTimestamp,
 	}
 
-	log.Printf(
-		"UPSERT order=%s status=%s UpdatedQuantity=%f ResponseQuantity=%f and is it an entry order? %t",
-		orderUpdate.OrderID,
-		orderUpdate.Status,
-		updatedOrder.Quantity,
-		orderUpdate.FilledQuantity,
-		isEntryOrder,
+	orderLog.InfoContext(ctx, "Order update",
+		"status", orderUpdate.Status,
+		"filled_qty", orderUpdate.FilledQuantity,
+		"pending_qty", orderUpdate.PendingQuantity,
+		"average_price", orderUpdate.AveragePrice,
+		"status_message", orderUpdate.StatusMessage,
+		"entry", isEntryOrder,
 	)
 
 	if _, err := s.OrderRepo.UpsertOrder(ctx, updatedOrder); err != nil {
 		return fmt.Errorf("failed to update order %s: %v", orderUpdate.OrderID, err)
 	}
+	s.notifyTransition(ctx, dbOrder, stockID, updatedOrder.EventType, orderUpdate)
 
 	if s.Manager == nil {
 		return nil
 	}
 
+	// The event type and source saved when the order was placed say why it was
+	// placed; the inferred event type is only a fallback for unknown orders.
+	eventType, source := updatedOrder.EventType, ""
+	if dbOrder != nil {
+		eventType, source = dbOrder.EventType, dbOrder.Source
+	}
+	s.recordTradeFill(ctx, stockID, orderUpdate, isEntryOrder, eventType, source)
+
 	switch orderUpdate.Status {
 	case "COMPLETE":
 		if isEntryOrder {
@@ -214,6 +256,7 @@ func (s *OrderService) ProcessOrderUpdate(ctx context.Context, orderUpdate kitec
 				s.Manager.SetSellQuantity(token, newQty)
 				if newQty == 0 {
 					s.Manager.SetDirection(token, "")
+					s.Manager.UpdateBasePrice(token, 0)
 				}
 			} else {
 				// Selling to close a long
@@ -224,14 +267,17 @@ func (s *OrderService) ProcessOrderUpdate(ctx context.Context, orderUpdate kitec
 				s.Manager.SetBuyQuantity(token, newQty)
 				if newQty == 0 {
 					s.Manager.SetDirection(token, "")
+					s.Manager.UpdateBasePrice(token, 0)
 				}
 			}
-			s.Manager.UpdateBasePrice(token, 0)
+			// A partial exit leaves the rest of the position, and its base
+			// price, in place for the stoploss.
+			s.Manager.ClearPendingExit(token)
 		}
 
 		s.Manager.DecrementMaxExecutableOrders(token)
 		s.Manager.UnlockStock(token)
-		log.Printf("✅ Order complete: token=%d filled=%f at price=%f", token, orderUpdate.FilledQuantity, orderUpdate.AveragePrice)
+		orderLog.InfoContext(ctx, "✅ Order complete", "filled_qty", orderUpdate.FilledQuantity, "average_price", orderUpdate.AveragePrice)
 
 	case "PARTIALLY_FILLED":
 		// Update the quantities based on what has been filled so far
@@ -245,10 +291,10 @@ func (s *OrderService) ProcessOrderUpdate(ctx context.Context, orderUpdate kitec
 			}
 		}
 		// Note: We don't unlock here because the order is still "active"
-		log.Printf("⏳ Partial fill: token=%d filled=%f", token, orderUpdate.FilledQuantity)
+		orderLog.InfoContext(ctx, "⏳ Partial fill", "filled_qty", orderUpdate.FilledQuantity)
 
 	case "REJECTED", "CANCELLED":
-		log.Printf("❌ Order %s: token=%d", orderUpdate.Status, token)
+		orderLog.WarnContext(ctx, "❌ Order "+strings.ToLower(orderUpdate.Status), "status_message", orderUpdate.StatusMessage)
 		// If an entry order is cancelled/rejected before any fill,
 		// we should ensure the manager reflects 0 quantity.
 		if orderUpdate.FilledQuantity == 0 && isEntryOrder {
@@ -259,6 +305,9 @@ func (s *OrderService) ProcessOrderUpdate(ctx context.Context, orderUpdate kitec
 			}
 			s.Manager.SetDirection(token, "")
 		}
+		if !isEntryOrder {
+			s.Manager.ClearPendingExit(token)
+		}
 		s.Manager.UnlockStock(token)
 	}
 
@@ -344,32 +393,40 @@ func (s *OrderService) SyncOrder(order *models.Order) error {
 // 	SignalNone        SignalType = "NONE"
 // )
 
-func (s *OrderService) buildEventType(isEntryOrder bool, orderUpdate kiteconnect.Order, basePrice float64) string {
+func (s *OrderService) buildEventType(isEntryOrder bool, orderUpdate broker.Order, basePrice float64) string {
 	if isEntryOrder {
-		if orderUpdate.TransactionType == kiteconnect.TransactionTypeBuy {
+		if orderUpdate.TransactionType == broker.TransactionTypeBuy {
 			return "ENTRY_BUY"
 		}
 		return "ENTRY_SELL"
 	}
 
-	// For Exits, we compare Fill Price vs Entry Price
+	// For Exits, we compare Fill Price vs Entry Price, net of the round-trip
+	// charges: an exit that only covers part of its costs lost money.
 	fillPrice := orderUpdate.AveragePrice
 	if fillPrice == 0 {
 		return "NONE"
 	}
 
-	if orderUpdate.TransactionType == kiteconnect.TransactionTypeSell {
+	product := orderUpdate.Product
+	if product == "" {
+		product = broker.ProductMIS
+	}
+
+	if orderUpdate.TransactionType == broker.TransactionTypeSell {
 		// We are exiting a LONG position
-		if fillPrice >= basePrice {
-			return "TARGET_HIT" // Sold higher than we bought
+		cost := charges.Default().BreakEvenPoints(orderUpdate.Exchange, product, broker.TransactionTypeBuy, int(orderUpdate.FilledQuantity), basePrice)
+		if fillPrice-cost >= basePrice {
+			return "TARGET_HIT" // Sold higher than we bought, after charges
 		}
 		return "STOPLOSS_HIT" // Sold lower than we bought
 	}
 
-	if orderUpdate.TransactionType == kiteconnect.TransactionTypeBuy {
+	if orderUpdate.TransactionType == broker.TransactionTypeBuy {
 		// We are exiting (covering) a SHORT position
-		if fillPrice <= basePrice {
-			return "TARGET_HIT" // Bought back lower than we sold
+		cost := charges.Default().BreakEvenPoints(orderUpdate.Exchange, product, broker.TransactionTypeSell, int(orderUpdate.FilledQuantity), basePrice)
+		if fillPrice+cost <= basePrice {
+			return "TARGET_HIT" // Bought back lower than we sold, after charges
 		}
 		return "STOPLOSS_HIT" // Bought back higher than we sold
 	}
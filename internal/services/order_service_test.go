@@ -2,201 +2,359 @@ package services
 
 import (
 	"context"
-	// "testing"
-	// "time"
+	"errors"
+	"sync"
+	"testing"
+	"time"
 
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
 	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository"
-	"github.com/jackc/pgx/v5"
-	// kiteconnect "github.com/zerodha/gokiteconnect/v4"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/repository/memory"
 )
 
-type fakeOrderRepo struct {
-	orders      map[string]*models.Order
-	updated     *models.Order
-	addCalls    int
-	updateCalls int
-	getErr      error
+const testToken = 738561
+
+// fakeManager keeps the tracking state of one stock, RELIANCE.
+type fakeManager struct {
+	mu        sync.Mutex
+	buyQty    uint32
+	sellQty   uint32
+	basePrice float64
+	direction string
+	locked    bool
+	maxOrders uint32
+}
+
+func (m *fakeManager) UpdateBasePrice(_ uint32, price float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.basePrice = price
+}
+func (m *fakeManager) UnlockStock(uint32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.locked = false
+}
+func (m *fakeManager) SetSellQuantity(_ uint32, qty uint32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sellQty = qty
+}
+func (m *fakeManager) SetBuyQuantity(_ uint32, qty uint32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.buyQty = qty
+}
+func (m *fakeManager) SetDirection(_ uint32, direction string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.direction = direction
+}
+func (m *fakeManager) GetStockIDByTradingSymbol(symbol string) (int64, bool) {
+	return 1, symbol == "RELIANCE"
+}
+func (m *fakeManager) GetBuyAndSellQuantityByToken(uint32) (uint32, uint32, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.buyQty, m.sellQty, true
+}
+func (m *fakeManager) GetBasePriceByToken(uint32) (float64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.basePrice, true
+}
+func (m *fakeManager) DecrementMaxExecutableOrders(uint32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.maxOrders > 0 {
+		m.maxOrders--
+	}
+}
+func (m *fakeManager) ClearPendingExit(uint32)                   {}
+func (m *fakeManager) GetStopLossByToken(uint32) (float64, bool) { return 5, true }
+
+// flakyOrderStore fails the next order lookup, like a dropped connection.
+type flakyOrderStore struct {
+	*memory.OrderStore
+	failNext bool
+}
+
+func (f *flakyOrderStore) GetOrderByKiteOrderID(ctx context.Context, orderID string) (*models.Order, error) {
+	if f.failNext {
+		f.failNext = false
+		return nil, errors.New("connection reset")
+	}
+	return f.OrderStore.GetOrderByKiteOrderID(ctx, orderID)
+}
+
+func newTestService(db *memory.DB) (*OrderService, *fakeManager) {
+	manager := &fakeManager{locked: true, maxOrders: 1}
+	svc := &OrderService{
+		OrderRepo:         db.Orders(),
+		TrackingStockRepo: db.TrackingStocks(),
+		TradeRepo:         db.Trades(),
+		Manager:           manager,
+	}
+	return svc, manager
+}
+
+func fill(orderID, side string, qty, price float64) broker.Order {
+	return broker.Order{
+		OrderID:         orderID,
+		Status:          broker.OrderStatusComplete,
+		Exchange:        "NSE",
+		TradingSymbol:   "RELIANCE",
+		InstrumentToken: testToken,
+		OrderType:       "LIMIT",
+		TransactionType: side,
+		Product:         broker.ProductMIS,
+		Quantity:        qty,
+		FilledQuantity:  qty,
+		AveragePrice:    price,
+		OrderTimestamp:  time.Now(),
+	}
+}
+
+func TestOrderService_EntryAndExitFillsMakeATrade(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	svc, manager := newTestService(db)
+
+	entry := &models.Order{TrackingStockID: 1, OrderID: "entry-1", OrderType: "LIMIT", EventType: "ENTRY_BUY", Status: "OPEN", PlacedAt: time.Now()}
+	if err := svc.AddPlacedOrder(ctx, entry); err != nil {
+		t.Fatalf("AddPlacedOrder() error = %v", err)
+	}
+	if err := svc.ProcessOrderUpdate(ctx, fill("entry-1", broker.TransactionTypeBuy, 10, 2500)); err != nil {
+		t.Fatalf("ProcessOrderUpdate(entry) error = %v", err)
+	}
+
+	saved, err := db.Orders().GetOrderByKiteOrderID(ctx, "entry-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Status != broker.OrderStatusComplete || saved.PurchasePrice == nil || *saved.PurchasePrice != 2500 {
+		t.Errorf("saved entry = status %s price %v", saved.Status, saved.PurchasePrice)
+	}
+	if manager.buyQty != 10 || manager.direction != "BUY" || manager.basePrice != 2500 || manager.locked {
+		t.Errorf("manager after entry = %+v", manager)
+	}
+
+	exit := &models.Order{TrackingStockID: 1, OrderID: "exit-1", OrderType: "LIMIT", EventType: "TARGET_HIT", Status: "OPEN", PlacedAt: time.Now()}
+	if err := svc.AddPlacedOrder(ctx, exit); err != nil {
+		t.Fatalf("AddPlacedOrder() error = %v", err)
+	}
+	if err := svc.ProcessOrderUpdate(ctx, fill("exit-1", broker.TransactionTypeSell, 10, 2520)); err != nil {
+		t.Fatalf("ProcessOrderUpdate(exit) error = %v", err)
+	}
+	if manager.buyQty != 0 || manager.direction != "" {
+		t.Errorf("manager after exit = %+v", manager)
+	}
+
+	trades, err := db.Trades().GetTrades(ctx, repository.TradeFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trades.TotalCount != 1 {
+		t.Fatalf("trades = %d, want 1", trades.TotalCount)
+	}
+	trade := trades.Trades[0]
+	if trade.Status != models.TradeStatusClosed || trade.Quantity != 10 || trade.GrossPnL != 200 {
+		t.Errorf("trade = status %s qty %d gross %.2f, want CLOSED 10 200.00", trade.Status, trade.Quantity, trade.GrossPnL)
+	}
+	if trade.ExitReason == nil || *trade.ExitReason != "TARGET_HIT" {
+		t.Errorf("exit reason = %v, want TARGET_HIT", trade.ExitReason)
+	}
+}
+
+func TestOrderService_WorkingOrderPostbacksAreStoredAsOpen(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	svc, manager := newTestService(db)
+
+	entry := &models.Order{TrackingStockID: 1, OrderID: "entry-1", OrderType: "LIMIT", EventType: "ENTRY_BUY", Status: "PENDING", PlacedAt: time.Now()}
+	if err := svc.AddPlacedOrder(ctx, entry); err != nil {
+		t.Fatalf("AddPlacedOrder() error = %v", err)
+	}
+
+	// A chased entry: on its way to the exchange, modified, then filled.
+	for _, status := range []string{"OPEN PENDING", broker.OrderStatusOpen, "MODIFY VALIDATION PENDING", "UPDATE"} {
+		update := fill("entry-1", broker.TransactionTypeBuy, 10, 0)
+		update.Status, update.FilledQuantity = status, 0
+		if err := svc.ProcessOrderUpdate(ctx, update); err != nil {
+			t.Fatalf("ProcessOrderUpdate(%s) error = %v", status, err)
+		}
+		saved, err := db.Orders().GetOrderByKiteOrderID(ctx, "entry-1")
+		if err != nil || saved.Status != models.OrderStatusOpen {
+			t.Fatalf("after %s saved status = %v, %v, want OPEN", status, saved, err)
+		}
+	}
+	if manager.buyQty != 0 || manager.direction != "" || !manager.locked {
+		t.Errorf("manager before the fill = %+v, want no position and still locked", manager)
+	}
+
+	if err := svc.ProcessOrderUpdate(ctx, fill("entry-1", broker.TransactionTypeBuy, 10, 2501)); err != nil {
+		t.Fatalf("ProcessOrderUpdate(COMPLETE) error = %v", err)
+	}
+	if saved, _ := db.Orders().GetOrderByKiteOrderID(ctx, "entry-1"); saved.Status != models.OrderStatusComplete {
+		t.Errorf("saved status = %s, want COMPLETE", saved.Status)
+	}
+	if manager.buyQty != 10 || manager.direction != "BUY" || manager.locked {
+		t.Errorf("manager after the fill = %+v", manager)
+	}
+}
+
+func TestOrderService_AdoptedPositionTradeClosesOnExit(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	svc, manager := newTestService(db)
+	manager.buyQty, manager.direction, manager.basePrice, manager.locked = 10, "BUY", 2500, false
+
+	svc.OpenAdoptedTrade(ctx, 1, testToken, "RELIANCE", "NSE", broker.TransactionTypeBuy, 10, 2500)
+
+	exit := &models.Order{TrackingStockID: 1, OrderID: "exit-1", OrderType: "MARKET", EventType: "STOPLOSS_HIT", Status: "OPEN", PlacedAt: time.Now()}
+	if err := svc.AddPlacedOrder(ctx, exit); err != nil {
+		t.Fatalf("AddPlacedOrder() error = %v", err)
+	}
+	if err := svc.ProcessOrderUpdate(ctx, fill("exit-1", broker.TransactionTypeSell, 10, 2490)); err != nil {
+		t.Fatalf("ProcessOrderUpdate(exit) error = %v", err)
+	}
+
+	trades, err := db.Trades().GetTrades(ctx, repository.TradeFilter{Source: models.OrderSourceAdopt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trades.TotalCount != 1 {
+		t.Fatalf("adopted trades = %d, want 1", trades.TotalCount)
+	}
+	trade := trades.Trades[0]
+	if trade.Status != models.TradeStatusClosed || trade.Quantity != 10 || trade.EntryPrice != 2500 || trade.GrossPnL != -100 {
+		t.Errorf("trade = status %s qty %d entry %.2f gross %.2f, want CLOSED 10 2500.00 -100.00",
+			trade.Status, trade.Quantity, trade.EntryPrice, trade.GrossPnL)
+	}
+}
+
+func TestOrderService_TradeTimesComeFromTheBroker(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	svc, _ := newTestService(db)
+	filledAt := time.Date(2026, 3, 2, 9, 20, 0, 0, time.UTC)
+	svc.now = func() time.Time { return filledAt.Add(3 * time.Hour) }
+
+	for _, o := range []*models.Order{
+		{TrackingStockID: 1, OrderID: "entry-1", OrderType: "LIMIT", EventType: "ENTRY_BUY", Status: "OPEN", PlacedAt: filledAt},
+		{TrackingStockID: 1, OrderID: "exit-1", OrderType: "LIMIT", EventType: "TARGET_HIT", Status: "OPEN", PlacedAt: filledAt},
+		{TrackingStockID: 1, OrderID: "manual", OrderType: "MARKET", EventType: "NONE", Status: "OPEN", PlacedAt: filledAt},
+	} {
+		if _, err := db.Orders().AddOrder(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Fills the startup sync picked up, newest first, after the server was down.
+	entry := fill("entry-1", broker.TransactionTypeBuy, 10, 2500)
+	entry.ExchangeTimestamp = filledAt
+	exit := fill("exit-1", broker.TransactionTypeSell, 10, 2520)
+	exit.ExchangeTimestamp = filledAt.Add(time.Hour)
+	manual := fill("manual", broker.TransactionTypeSell, 5, 2510)
+	svc.RecordSyncedFills(ctx, []broker.Order{exit, manual, entry})
+	// A late replay of the exit postback does not move the close.
+	replayed := exit
+	replayed.ExchangeTimestamp = filledAt.Add(2 * time.Hour)
+	svc.RecordSyncedFills(ctx, []broker.Order{replayed})
+
+	trades, err := db.Trades().GetTrades(ctx, repository.TradeFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trades.TotalCount != 1 {
+		t.Fatalf("trades = %d, want 1", trades.TotalCount)
+	}
+	trade := trades.Trades[0]
+	if trade.Status != models.TradeStatusClosed || trade.Quantity != 10 || trade.GrossPnL != 200 {
+		t.Errorf("trade = status %s qty %d gross %.2f, want CLOSED 10 200.00", trade.Status, trade.Quantity, trade.GrossPnL)
+	}
+	if !trade.OpenedAt.Equal(filledAt) || trade.HoldingSeconds == nil || *trade.HoldingSeconds != 3600 {
+		t.Errorf("trade opened %v held %v, want %v and 3600s", trade.OpenedAt, trade.HoldingSeconds, filledAt)
+	}
 }
 
-func (f *fakeOrderRepo) AddOrder(_ context.Context, o *models.Order) (int64, error) {
-	if f.orders == nil {
-		f.orders = make(map[string]*models.Order)
+func TestOrderService_ReplaysPendingUpdateAfterAdd(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	svc, _ := newTestService(db)
+	orders := &flakyOrderStore{OrderStore: db.Orders(), failNext: true}
+	svc.OrderRepo = orders
+
+	if err := svc.ProcessOrderUpdate(ctx, fill("order-123", broker.TransactionTypeBuy, 10, 123.45)); err == nil {
+		t.Fatal("ProcessOrderUpdate() error = nil, want the lookup error")
+	}
+
+	order := &models.Order{TrackingStockID: 1, OrderID: "order-123", OrderType: "LIMIT", EventType: "ENTRY_BUY", Status: "PENDING", PlacedAt: time.Now()}
+	if err := svc.AddPlacedOrder(ctx, order); err != nil {
+		t.Fatalf("AddPlacedOrder() error = %v", err)
 	}
-	f.addCalls++
-	if o.ID == 0 {
-		o.ID = int64(len(f.orders) + 1)
+
+	saved, err := db.Orders().GetOrderByKiteOrderID(ctx, "order-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Status != broker.OrderStatusComplete {
+		t.Fatalf("status = %s, want the replayed COMPLETE", saved.Status)
 	}
-	copied := *o
-	f.orders[o.OrderID] = &copied
-	return o.ID, nil
 }
 
-func (f *fakeOrderRepo) UpdateOrder(_ context.Context, o *models.Order, _ int64) error {
-	f.updateCalls++
-	copied := *o
-	f.updated = &copied
-	return nil
-}
-
-func (f *fakeOrderRepo) GetOrderByKiteOrderID(_ context.Context, orderID string) (*models.Order, error) {
-	if f.getErr != nil {
-		return nil, f.getErr
-	}
-	if f.orders == nil {
-		return nil, pgx.ErrNoRows
-	}
-	order, ok := f.orders[orderID]
-	if !ok {
-		return nil, pgx.ErrNoRows
-	}
-	copied := *order
-	return &copied, nil
-}
-
-func (f *fakeOrderRepo) GetAllStocksOrderImbalance(_ context.Context, _ []int64) ([]repository.OrderImabalance, error) {
-	return nil, nil
-}
-
-func (f *fakeOrderRepo) GetLastBuyPricesForStocks(_ context.Context, _ []int64) (map[int64]float64, error) {
-	return make(map[int64]float64), nil
-}
-
-type fakeTrackingRepo struct {
-	trackingStock *models.TrackingStock
-}
-
-func (f *fakeTrackingRepo) GetTrackingStockByID(_ context.Context, _ int64) (*models.TrackingStock, error) {
-	return f.trackingStock, nil
-}
-
-// func TestOrderService_ReplaysPendingUpdateAfterAdd(t *testing.T) {
-// 	ctx := context.Background()
-// 	repo := &fakeOrderRepo{}
-// 	svc := &OrderService{
-// 		OrderRepo:         repo,
-// 		TrackingStockRepo: &fakeTrackingRepo{},
-// 	}
-
-// 	update := kiteconnect.Order{
-// 		OrderID:      "order-123",
-// 		Status:       "COMPLETE",
-// 		AveragePrice: 123.45,
-// 		Quantity:     10,
-// 		Exchange:     "NSE",
-// 	}
-
-// 	if err := svc.ProcessOrderUpdate(ctx, update); err != nil {
-// 		t.Fatalf("ProcessOrderUpdate returned error: %v", err)
-// 	}
-
-// 	order := &models.Order{
-// 		TrackingStockID: 1,
-// 		OrderID:         "order-123",
-// 		OrderType:       "BUY",
-// 		Status:          "PENDING",
-// 		PlacedAt:        time.Now(),
-// 	}
-
-// 	if err := svc.AddPlacedOrder(ctx, order); err != nil {
-// 		t.Fatalf("AddPlacedOrder returned error: %v", err)
-// 	}
-
-// 	if repo.updateCalls != 1 {
-// 		t.Fatalf("expected UpdateOrder to be called once, got %d", repo.updateCalls)
-// 	}
-
-// 	if repo.updated == nil || repo.updated.Status != "COMPLETE" {
-// 		t.Fatalf("expected order status to be COMPLETE, got %+v", repo.updated)
-// 	}
-// }
-
-// func TestOrderService_ProcessOrderUpdateUpdatesExistingOrder(t *testing.T) {
-// 	ctx := context.Background()
-// 	repo := &fakeOrderRepo{
-// 		orders: map[string]*models.Order{
-// 			"order-456": {
-// 				ID:              5,
-// 				TrackingStockID: 2,
-// 				OrderID:         "order-456",
-// 				Status:          "PENDING",
-// 				PlacedAt:        time.Now().Add(-time.Minute),
-// 			},
-// 		},
-// 	}
-// 	svc := &OrderService{
-// 		OrderRepo:         repo,
-// 		TrackingStockRepo: &fakeTrackingRepo{},
-// 	}
-
-// 	before := time.Now()
-// 	update := kiteconnect.Order{
-// 		OrderID:         "order-456",
-// 		Status:          "REJECTED",
-// 		StatusMessage:   "Insufficient funds",
-// 		Exchange:        "NSE",
-// 		Product:         "MIS",
-// 		Quantity:        5,
-// 		AveragePrice:    99.5,
-// 		TriggerPrice:    98.0,
-// 		ExchangeOrderID: "exch-1",
-// 		ParentOrderID:   "parent-1",
-// 		TransactionType: "BUY",
-// 	}
-
-// 	if err := svc.ProcessOrderUpdate(ctx, update); err != nil {
-// 		t.Fatalf("ProcessOrderUpdate returned error: %v", err)
-// 	}
-
-// 	if repo.updateCalls != 1 {
-// 		t.Fatalf("expected UpdateOrder to be called once, got %d", repo.updateCalls)
-// 	}
-
-// 	if repo.updated == nil {
-// 		t.Fatal("expected updated order to be set")
-// 	}
-// 	if repo.updated.Status != "REJECTED" || repo.updated.StatusMessage != "Insufficient funds" {
-// 		t.Fatalf("unexpected updated status: %+v", repo.updated)
-// 	}
-// 	if !repo.updated.UpdatedAt.After(before) {
-// 		t.Fatalf("expected UpdatedAt to be set, got %v", repo.updated.UpdatedAt)
-// 	}
-// }
-
-// func TestOrderService_DropsExpiredPendingUpdates(t *testing.T) {
-// 	base := time.Now()
-// 	ctx := context.Background()
-// 	repo := &fakeOrderRepo{}
-// 	svc := &OrderService{
-// 		OrderRepo:         repo,
-// 		TrackingStockRepo: &fakeTrackingRepo{},
-// 		now:               func() time.Time { return base },
-// 	}
-
-// 	update := kiteconnect.Order{
-// 		OrderID: "order-expired",
-// 		Status:  "COMPLETE",
-// 	}
-
-// 	if err := svc.ProcessOrderUpdate(ctx, update); err != nil {
-// 		t.Fatalf("ProcessOrderUpdate returned error: %v", err)
-// 	}
-
-// 	svc.now = func() time.Time { return base.Add(pendingUpdateTTL + time.Second) }
-
-// 	order := &models.Order{
-// 		TrackingStockID: 1,
-// 		OrderID:         "order-expired",
-// 		OrderType:       "BUY",
-// 		Status:          "PENDING",
-// 		PlacedAt:        time.Now(),
-// 	}
-
-// 	if err := svc.AddPlacedOrder(ctx, order); err != nil {
-// 		t.Fatalf("AddPlacedOrder returned error: %v", err)
-// 	}
-
-// 	if repo.updateCalls != 0 {
-// 		t.Fatalf("expected UpdateOrder not to be called for expired pending update, got %d", repo.updateCalls)
-// 	}
-// }
+func TestOrderService_DropsExpiredPendingUpdates(t *testing.T) {
+	ctx := context.Background()
+	base := time.Now()
+	db := memory.New()
+	svc, _ := newTestService(db)
+	svc.OrderRepo = &flakyOrderStore{OrderStore: db.Orders(), failNext: true}
+	svc.now = func() time.Time { return base }
+
+	_ = svc.ProcessOrderUpdate(ctx, fill("order-expired", broker.TransactionTypeBuy, 10, 100))
+	svc.now = func() time.Time { return base.Add(pendingUpdateTTL + time.Second) }
+
+	order := &models.Order{TrackingStockID: 1, OrderID: "order-expired", OrderType: "LIMIT", EventType: "ENTRY_BUY", Status: "PENDING", PlacedAt: base}
+	if err := svc.AddPlacedOrder(ctx, order); err != nil {
+		t.Fatalf("AddPlacedOrder() error = %v", err)
+	}
+
+	saved, err := db.Orders().GetOrderByKiteOrderID(ctx, "order-expired")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Status != "PENDING" {
+		t.Fatalf("status = %s, want PENDING as the update expired", saved.Status)
+	}
+}
+
+func TestOrderService_TradeStatsFromTodaysOrders(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	svc, _ := newTestService(db)
+
+	buy := "BUY"
+	yesterday := time.Now().AddDate(0, 0, -1)
+	for _, o := range []models.Order{
+		{TrackingStockID: 1, OrderID: "old", EventType: "ENTRY_BUY", TransactionType: &buy, Quantity: 5, Status: "COMPLETE", PlacedAt: yesterday},
+		{TrackingStockID: 1, OrderID: "today", EventType: "ENTRY_BUY", TransactionType: &buy, Quantity: 10, BasePrice: 2500, Status: "COMPLETE", PlacedAt: time.Now()},
+		{TrackingStockID: 1, OrderID: "rejected", EventType: "ENTRY_BUY", TransactionType: &buy, Quantity: 10, Status: "REJECTED", PlacedAt: time.Now()},
+	} {
+		if _, err := db.Orders().AddOrder(ctx, &o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := svc.AllStocksTradeStats([]int64{1, 2})
+	if err != nil {
+		t.Fatalf("AllStocksTradeStats() error = %v", err)
+	}
+	got, ok := stats[1]
+	if !ok || got.TotalBuy != 10 || got.EntryCount != 1 || got.LastPrice == nil || *got.LastPrice != 2500 {
+		t.Fatalf("stats[1] = %+v", got)
+	}
+	if _, ok := stats[2]; ok {
+		t.Error("stats for a stock without orders today")
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/logging"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+)
+
+// OrderTransition is a change in a broker order's status as seen by
+// ProcessOrderUpdate. PreviousStatus is empty for an order not seen before.
+type OrderTransition struct {
+	OrderID         string    `json:"order_id"`
+	TrackingStockID int64     `json:"tracking_stock_id"`
+	TradingSymbol   string    `json:"trading_symbol"`
+	TransactionType string    `json:"transaction_type"`
+	OrderType       string    `json:"order_type"`
+	EventType       string    `json:"event_type"`
+	Source          string    `json:"source"`
+	PreviousStatus  string    `json:"previous_status"`
+	Status          string    `json:"status"`
+	Quantity        float64   `json:"quantity"`
+	FilledQuantity  float64   `json:"filled_quantity"`
+	AveragePrice    float64   `json:"average_price"`
+	StatusMessage   string    `json:"status_message,omitempty"`
+	CorrelationID   string    `json:"correlation_id,omitempty"`
+	At              time.Time `json:"at"`
+}
+
+// AddOrderListener registers fn to be called on every order status transition.
+func (s *OrderService) AddOrderListener(fn func(OrderTransition)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onTransition = append(s.onTransition, fn)
+}
+
+// notifyTransition reports orderUpdate to the listener when its status differs
+// from the one saved before the update.
+func (s *OrderService) notifyTransition(ctx context.Context, dbOrder *models.Order, stockID int64, eventType string, orderUpdate broker.Order) {
+	s.mu.Lock()
+	listeners := s.onTransition
+	s.mu.Unlock()
+	if len(listeners) == 0 {
+		return
+	}
+
+	t := OrderTransition{
+		OrderID:         orderUpdate.OrderID,
+		TrackingStockID: stockID,
+		TradingSymbol:   orderUpdate.TradingSymbol,
+		TransactionType: orderUpdate.TransactionType,
+		OrderType:       orderUpdate.OrderType,
+		EventType:       eventType,
+		Status:          orderUpdate.Status,
+		Quantity:        orderUpdate.Quantity,
+		FilledQuantity:  orderUpdate.FilledQuantity,
+		AveragePrice:    orderUpdate.AveragePrice,
+		StatusMessage:   orderUpdate.StatusMessage,
+		CorrelationID:   logging.CorrelationID(ctx),
+		At:              s.nowTime(),
+	}
+	if dbOrder != nil {
+		t.PreviousStatus = dbOrder.Status
+		t.EventType = dbOrder.EventType
+		t.Source = dbOrder.Source
+		if t.PreviousStatus == t.Status && dbOrder.Quantity == orderUpdate.FilledQuantity {
+			return
+		}
+	}
+	for _, fn := range listeners {
+		fn(t)
+	}
+}
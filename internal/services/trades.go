@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/charges"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/metrics"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+type TradeRepo interface {
+	AttachOrder(ctx context.Context, seed *models.Trade, o models.TradeOrder) (tradeID int64, err error)
+	GetTradeByID(ctx context.Context, id int64) (*models.Trade, error)
+	GetTradeOrders(ctx context.Context, tradeID int64) ([]models.TradeOrder, error)
+	UpdateTradeSummary(ctx context.Context, t *models.Trade) error
+}
+
+// recordTradeFill folds a filled order update into its round-trip trade and
+// recomputes the trade's figures from all of its orders, so repeated or
+// out-of-order updates for the same order converge on the same result.
+func (s *OrderService) recordTradeFill(ctx context.Context, stockID int64, orderUpdate broker.Order, isEntryOrder bool, eventType, source string) {
+	if s.TradeRepo == nil || stockID == 0 || orderUpdate.FilledQuantity <= 0 {
+		return
+	}
+
+	s.tradeMu.Lock()
+	defer s.tradeMu.Unlock()
+
+	leg := models.TradeOrder{
+		OrderID:      orderUpdate.OrderID,
+		Leg:          models.TradeLegExit,
+		EventType:    eventType,
+		Quantity:     int(orderUpdate.FilledQuantity),
+		AveragePrice: orderUpdate.AveragePrice,
+		FilledAt:     s.fillTime(orderUpdate),
+	}
+	product := orderUpdate.Product
+	if product == "" {
+		product = broker.ProductMIS
+	}
+	leg.Charges = charges.Default().Order(orderUpdate.Exchange, product, orderUpdate.TransactionType,
+		leg.Quantity, leg.AveragePrice).Total
+	seed := &models.Trade{
+		TrackingStockID: stockID,
+		TradingSymbol:   orderUpdate.TradingSymbol,
+		Exchange:        orderUpdate.Exchange,
+		Source:          source,
+	}
+	if isEntryOrder {
+		leg.Leg = models.TradeLegEntry
+		seed.Direction = orderUpdate.TransactionType
+		if seed.Source == "" {
+			seed.Source = models.OrderSourceAlgo
+		}
+		if s.Manager != nil {
+			seed.RiskPoints, _ = s.Manager.GetStopLossByToken(orderUpdate.InstrumentToken)
+		}
+	}
+
+	tradeID, err := s.TradeRepo.AttachOrder(ctx, seed, leg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("⚠️ Exit order %s for %s has no open trade to close", orderUpdate.OrderID, orderUpdate.TradingSymbol)
+			return
+		}
+		log.Printf("⚠️ Failed to attach order %s to trade: %v", orderUpdate.OrderID, err)
+		return
+	}
+
+	trade, err := s.TradeRepo.GetTradeByID(ctx, tradeID)
+	if err != nil {
+		log.Printf("⚠️ Failed to load trade %d: %v", tradeID, err)
+		return
+	}
+	orders, err := s.TradeRepo.GetTradeOrders(ctx, tradeID)
+	if err != nil {
+		log.Printf("⚠️ Failed to load orders of trade %d: %v", tradeID, err)
+		return
+	}
+
+	wasClosed := trade.Status == models.TradeStatusClosed
+	summarizeTrade(trade, orders)
+	if err := s.TradeRepo.UpdateTradeSummary(ctx, trade); err != nil {
+		log.Printf("⚠️ Failed to update trade %d: %v", tradeID, err)
+		return
+	}
+
+	if trade.Status == models.TradeStatusClosed && !wasClosed {
+		metrics.RealizedPnL.Add(trade.NetPnL)
+		serviceLog.InfoContext(ctx, "📒 Trade closed",
+			"trade_id", trade.ID, "order_id", orderUpdate.OrderID, "symbol", trade.TradingSymbol,
+			"direction", trade.Direction, "qty", trade.Quantity, "entry_price", trade.EntryPrice,
+			"exit_price", *trade.ExitPrice, "gross_pnl", trade.GrossPnL, "charges", trade.Charges, "net_pnl", trade.NetPnL)
+	}
+}
+
+// RecordSyncedFills folds the fills picked up by the startup order sync into
+// their trades, oldest first, so a trade entered or exited while the server
+// was down is still recorded. Orders the bot did not place are left out.
+func (s *OrderService) RecordSyncedFills(ctx context.Context, orders []broker.Order) {
+	filled := make([]broker.Order, 0, len(orders))
+	for _, o := range orders {
+		if o.FilledQuantity > 0 {
+			filled = append(filled, o)
+		}
+	}
+	sort.SliceStable(filled, func(i, j int) bool { return s.fillTime(filled[i]).Before(s.fillTime(filled[j])) })
+
+	for _, o := range filled {
+		dbOrder, err := s.OrderRepo.GetOrderByKiteOrderID(ctx, o.OrderID)
+		if err != nil {
+			if !errors.Is(err, pgx.ErrNoRows) {
+				log.Printf("⚠️ Failed to load synced order %s: %v", o.OrderID, err)
+			}
+			continue
+		}
+		if dbOrder.TrackingStockID == 0 || dbOrder.EventType == "" || dbOrder.EventType == "NONE" {
+			continue
+		}
+		isEntryOrder := dbOrder.EventType == "ENTRY_BUY" || dbOrder.EventType == "ENTRY_SELL"
+		s.recordTradeFill(ctx, dbOrder.TrackingStockID, o, isEntryOrder, dbOrder.EventType, dbOrder.Source)
+	}
+}
+
+// fillTime is when the broker says an order filled, so a late or replayed
+// update still dates the fill correctly. It falls back to when the order was
+// placed, and then to now.
+func (s *OrderService) fillTime(o broker.Order) time.Time {
+	if !o.ExchangeTimestamp.IsZero() {
+		return o.ExchangeTimestamp
+	}
+	if !o.OrderTimestamp.IsZero() {
+		return o.OrderTimestamp
+	}
+	return s.nowTime()
+}
+
+// OpenAdoptedTrade opens the trade for a broker position the bot adopted, as if
+// the position had been entered at its broker average price when adopted, so
+// the exit that later closes it has a trade to close.
+func (s *OrderService) OpenAdoptedTrade(ctx context.Context, stockID int64, token uint32, tradingSymbol, exchange, direction string, quantity uint32, averagePrice float64) {
+	adopted := broker.Order{
+		OrderID:           fmt.Sprintf("ADOPT-%d-%d", stockID, s.nowTime().Unix()),
+		Status:            broker.OrderStatusComplete,
+		Exchange:          exchange,
+		TradingSymbol:     tradingSymbol,
+		InstrumentToken:   token,
+		TransactionType:   direction,
+		Product:           broker.ProductMIS,
+		Quantity:          float64(quantity),
+		FilledQuantity:    float64(quantity),
+		AveragePrice:      averagePrice,
+		OrderTimestamp:    s.nowTime(),
+		ExchangeTimestamp: s.nowTime(),
+	}
+	s.recordTradeFill(ctx, stockID, adopted, true, models.AdjustmentKindAdopt, models.OrderSourceAdopt)
+}
+
+// summarizeTrade computes a trade's VWAPs, quantities, P&L, R-multiple and
+// holding time from its orders. P&L is realized on the exited quantity only;
+// net P&L takes off the charges on every order of the trade so far.
+func summarizeTrade(trade *models.Trade, orders []models.TradeOrder) {
+	var entryQty, exitQty int
+	var entryValue, exitValue, totalCharges float64
+	var lastExit *models.TradeOrder
+
+	for i, o := range orders {
+		totalCharges += o.Charges
+		switch o.Leg {
+		case models.TradeLegEntry:
+			if entryQty == 0 || o.FilledAt.Before(trade.OpenedAt) {
+				trade.OpenedAt = o.FilledAt
+			}
+			entryQty += o.Quantity
+			entryValue += float64(o.Quantity) * o.AveragePrice
+		case models.TradeLegExit:
+			exitQty += o.Quantity
+			exitValue += float64(o.Quantity) * o.AveragePrice
+			if lastExit == nil || !o.FilledAt.Before(lastExit.FilledAt) {
+				lastExit = &orders[i]
+			}
+		}
+	}
+
+	trade.Quantity = entryQty
+	trade.ExitQuantity = exitQty
+	trade.Charges = totalCharges
+	trade.NetPnL = -totalCharges
+	if entryQty > 0 {
+		trade.EntryPrice = entryValue / float64(entryQty)
+	}
+	if exitQty == 0 {
+		return
+	}
+
+	exitPrice := exitValue / float64(exitQty)
+	trade.ExitPrice = &exitPrice
+	trade.ExitReason = &lastExit.EventType
+
+	trade.GrossPnL = (exitPrice - trade.EntryPrice) * float64(exitQty)
+	if trade.Direction == broker.TransactionTypeSell {
+		trade.GrossPnL = -trade.GrossPnL
+	}
+	trade.NetPnL = trade.GrossPnL - totalCharges
+	if trade.RiskPoints > 0 && entryQty > 0 {
+		r := trade.GrossPnL / (trade.RiskPoints * float64(entryQty))
+		trade.RMultiple = &r
+	}
+
+	if entryQty > 0 && exitQty >= entryQty {
+		trade.Status = models.TradeStatusClosed
+		closedAt := lastExit.FilledAt
+		holding := int64(closedAt.Sub(trade.OpenedAt).Seconds())
+		trade.ClosedAt = &closedAt
+		trade.HoldingSeconds = &holding
+	}
+}
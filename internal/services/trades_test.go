@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+)
+
+func TestSummarizeTrade(t *testing.T) {
+	open := time.Date(2026, 3, 2, 9, 35, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		direction  string
+		orders     []models.TradeOrder
+		wantStatus string
+		wantEntry  float64
+		wantPnL    float64
+		wantR      float64
+		wantReason string
+	}{
+		{
+			name:      "long closed at target over two entry fills",
+			direction: "BUY",
+			orders: []models.TradeOrder{
+				{Leg: models.TradeLegEntry, Quantity: 10, AveragePrice: 100, FilledAt: open},
+				{Leg: models.TradeLegEntry, Quantity: 10, AveragePrice: 102, FilledAt: open.Add(time.Minute)},
+				{Leg: models.TradeLegExit, Quantity: 20, AveragePrice: 106, EventType: "TARGET_HIT", FilledAt: open.Add(time.Hour)},
+			},
+			wantStatus: models.TradeStatusClosed,
+			wantEntry:  101,
+			wantPnL:    100,
+			wantR:      2.5,
+			wantReason: "TARGET_HIT",
+		},
+		{
+			name:      "short partially exited stays open",
+			direction: "SELL",
+			orders: []models.TradeOrder{
+				{Leg: models.TradeLegEntry, Quantity: 20, AveragePrice: 100, FilledAt: open},
+				{Leg: models.TradeLegExit, Quantity: 10, AveragePrice: 102, EventType: "MANUAL_EXIT", FilledAt: open.Add(time.Hour)},
+			},
+			wantStatus: models.TradeStatusOpen,
+			wantEntry:  100,
+			wantPnL:    -20,
+			wantR:      -0.5,
+			wantReason: "MANUAL_EXIT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trade := &models.Trade{Direction: tt.direction, Status: models.TradeStatusOpen, RiskPoints: 2}
+			summarizeTrade(trade, tt.orders)
+
+			if trade.Status != tt.wantStatus {
+				t.Fatalf("status = %s, want %s", trade.Status, tt.wantStatus)
+			}
+			if trade.EntryPrice != tt.wantEntry {
+				t.Fatalf("entry price = %.2f, want %.2f", trade.EntryPrice, tt.wantEntry)
+			}
+			if trade.GrossPnL != tt.wantPnL {
+				t.Fatalf("gross pnl = %.2f, want %.2f", trade.GrossPnL, tt.wantPnL)
+			}
+			if trade.RMultiple == nil || *trade.RMultiple != tt.wantR {
+				t.Fatalf("r multiple = %v, want %.2f", trade.RMultiple, tt.wantR)
+			}
+			if trade.ExitReason == nil || *trade.ExitReason != tt.wantReason {
+				t.Fatalf("exit reason = %v, want %s", trade.ExitReason, tt.wantReason)
+			}
+			if tt.wantStatus == models.TradeStatusClosed && (trade.HoldingSeconds == nil || *trade.HoldingSeconds != 3600) {
+				t.Fatalf("holding seconds = %v, want 3600", trade.HoldingSeconds)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Package stream fans live runtime events out to dashboard clients.
+package stream
+
+import (
+	"sync"
+	"time"
+)
+
+// Topics a client can subscribe to.
+const (
+	TopicSnapshots = "snapshots" // tracked-stock state from TrackingManager
+	TopicTicks     = "ticks"     // throttled LTP per instrument
+	TopicSignals   = "signals"   // trade signals consumed by the OrderEngine
+	TopicOrders    = "orders"    // order status transitions from OrderService
+	TopicEngine    = "engine"    // engine and connection state changes
+)
+
+// Topics lists every topic, in the order a client subscribing to all of them gets.
+var Topics = []string{TopicSnapshots, TopicTicks, TopicSignals, TopicOrders, TopicEngine}
+
+// IsTopic reports whether name is a known topic.
+func IsTopic(name string) bool {
+	for _, t := range Topics {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is one message on a topic.
+type Event struct {
+	Topic string    `json:"topic"`
+	Time  time.Time `json:"time"`
+	Data  any       `json:"data"`
+}
+
+// Subscription receives the events of the topics it was opened with.
+type Subscription struct {
+	C      chan Event
+	topics map[string]struct{}
+}
+
+// Hub delivers published events to every subscription of the topic. A client
+// that falls behind loses events rather than holding up the publisher.
+type Hub struct {
+	mu       sync.RWMutex
+	subs     map[*Subscription]struct{}
+	retained map[string]Event
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subs:     make(map[*Subscription]struct{}),
+		retained: make(map[string]Event),
+	}
+}
+
+// Subscribe opens a subscription to topics with a buffer of the given size.
+// The last retained event of each topic is delivered first.
+func (h *Hub) Subscribe(topics []string, buffer int) *Subscription {
+	sub := &Subscription{
+		C:      make(chan Event, buffer),
+		topics: make(map[string]struct{}, len(topics)),
+	}
+	for _, t := range topics {
+		sub.topics[t] = struct{}{}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, t := range topics {
+		if event, ok := h.retained[t]; ok {
+			select {
+			case sub.C <- event:
+			default:
+			}
+		}
+	}
+	h.subs[sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe closes a subscription.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.C)
+	}
+}
+
+// HasSubscribers reports whether anyone listens on topic, so producers can
+// skip building events nobody will read.
+func (h *Hub) HasSubscribers(topic string) bool {
+	if h == nil {
+		return false
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subs {
+		if _, ok := sub.topics[topic]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish sends data on topic to its subscribers.
+func (h *Hub) Publish(topic string, data any) {
+	if h == nil {
+		return
+	}
+	event := Event{Topic: topic, Time: time.Now(), Data: data}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	h.deliver(event)
+}
+
+// PublishRetained publishes data and keeps it as the topic's current value for
+// clients that subscribe later.
+func (h *Hub) PublishRetained(topic string, data any) {
+	if h == nil {
+		return
+	}
+	event := Event{Topic: topic, Time: time.Now(), Data: data}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.retained[topic] = event
+	h.deliver(event)
+}
+
+func (h *Hub) deliver(event Event) {
+	topic := event.Topic
+	for sub := range h.subs {
+		if _, ok := sub.topics[topic]; !ok {
+			continue
+		}
+		select {
+		case sub.C <- event:
+		default:
+			// drop if slow
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package stream
+
+import "testing"
+
+func TestHubDeliversSubscribedTopicsOnly(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe([]string{TopicOrders}, 4)
+	defer hub.Unsubscribe(sub)
+
+	hub.Publish(TopicTicks, "tick")
+	hub.Publish(TopicOrders, "order")
+
+	event := <-sub.C
+	if event.Topic != TopicOrders || event.Data != "order" {
+		t.Fatalf("got %+v, want the order event", event)
+	}
+	if len(sub.C) != 0 {
+		t.Fatalf("got %d extra events", len(sub.C))
+	}
+}
+
+func TestHubDropsEventsForSlowSubscribers(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe([]string{TopicTicks}, 1)
+	defer hub.Unsubscribe(sub)
+
+	hub.Publish(TopicTicks, 1)
+	hub.Publish(TopicTicks, 2)
+
+	if event := <-sub.C; event.Data != 1 {
+		t.Fatalf("got %v, want the first event", event.Data)
+	}
+	if len(sub.C) != 0 {
+		t.Fatal("expected the second event to be dropped")
+	}
+}
+
+func TestHubReplaysRetainedEventOnSubscribe(t *testing.T) {
+	hub := NewHub()
+	hub.PublishRetained(TopicEngine, "running")
+
+	sub := hub.Subscribe([]string{TopicEngine, TopicSignals}, 2)
+	defer hub.Unsubscribe(sub)
+
+	if event := <-sub.C; event.Data != "running" {
+		t.Fatalf("got %v, want the retained engine state", event.Data)
+	}
+	if hub.HasSubscribers(TopicOrders) {
+		t.Fatal("no one subscribed to orders")
+	}
+}
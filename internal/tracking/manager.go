@@ -1,11 +1,12 @@
 package tracking
 
 import (
-	"github.com/SM-Sclass/stock_client2-go_backend/internal/kite"
-	kcws "github.com/SM-Sclass/stock_client2-go_backend/internal/kite/ws"
 	"log"
 	"sync"
 	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/broker"
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/utils"
 )
 
 type TrackedStock struct {
@@ -34,13 +35,30 @@ type TrackedStock struct {
 	Direction      string // "BUY" or "SELL" – direction of the open position
 	SignalFired    bool   // true once today's entry signal has been sent
 	TradingAllowed bool   // false if we have hit the max trades for the day and should ignore further signals
+
+	// Exit order state while a target LIMIT exit is working at the broker
+	PendingExitOrderID string // broker order ID of the unfilled exit
+	ExitEscalated      bool   // true once the pending exit was sent to MARKET
+	exitPlacing        bool   // a pending exit is reserved while its order is being placed
+
+	// TrailingStopLoss is the trail distance in points when the open position
+	// is in trailing mode; StopLoss then follows the price and only tightens.
+	TrailingStopLoss float64
 }
 
 type TrackingManager struct {
-	tracked    map[uint32]TrackedStock
-	kiteClient *kite.KiteClient
-	mu         sync.RWMutex
-	ws         *kcws.KiteWS
+	tracked map[uint32]TrackedStock
+	broker  broker.Broker
+	mu      sync.RWMutex
+	ws      TokenSubscriber
+
+	// State persistence: tokens changed since the last flush to the StateStore.
+	dirty        map[uint32]struct{}
+	store        StateStore
+	persistStop  chan struct{}
+	persistWg    sync.WaitGroup
+	persistMu    sync.Mutex
+	persistFlush sync.Mutex
 }
 
 type TokenSubscriber interface {
@@ -48,17 +66,19 @@ type TokenSubscriber interface {
 	UnsubscribeToken(token uint32)
 }
 
-func NewTrackingManager(ws *kcws.KiteWS, kiteClient *kite.KiteClient) *TrackingManager {
+func NewTrackingManager(ws TokenSubscriber, brk broker.Broker) *TrackingManager {
 	return &TrackingManager{
-		tracked:    make(map[uint32]TrackedStock),
-		kiteClient: kiteClient,
-		ws:         ws,
+		tracked: make(map[uint32]TrackedStock),
+		broker:  brk,
+		ws:      ws,
+		dirty:   make(map[uint32]struct{}),
 	}
 }
 
 func (tm *TrackingManager) AddTrackingStock(stock TrackedStock) bool {
 	tm.mu.Lock()
 	tm.tracked[stock.InstrumentToken] = stock
+	tm.markDirty(stock.InstrumentToken)
 	tm.mu.Unlock()
 
 	tm.loadFifteenCandlesForTS(stock)
@@ -82,6 +102,7 @@ func (tm *TrackingManager) AddTrackingStock(stock TrackedStock) bool {
 		existing.StopLoss = rangeSize * 0.5
 		tm.mu.Lock()
 		tm.tracked[stock.InstrumentToken] = existing
+		tm.markDirty(stock.InstrumentToken)
 		tm.mu.Unlock()
 	}
 
@@ -95,6 +116,7 @@ func (tm *TrackingManager) RemoveStockFromTracking(token uint32) {
 	defer tm.mu.Unlock()
 
 	delete(tm.tracked, token)
+	delete(tm.dirty, token)
 	tm.ws.UnsubscribeToken(token)
 }
 
@@ -103,12 +125,17 @@ func (tm *TrackingManager) UpdateStockParameters(stock TrackedStock) {
 	defer tm.mu.Unlock()
 
 	if existing, exists := tm.tracked[stock.InstrumentToken]; exists {
-		// existing.Target = stock.Target
-		// existing.StopLoss = stock.StopLoss
+		// The exit levels of an open position are changed through
+		// AdjustTradeParams, which validates them against the LTP first.
+		if existing.Direction == "" {
+			existing.Target = stock.Target
+			existing.StopLoss = stock.StopLoss
+		}
 		existing.OrderPriceLimit = stock.OrderPriceLimit
 		// existing.Locked = stock.Locked
 
 		tm.tracked[stock.InstrumentToken] = existing
+		tm.markDirty(stock.InstrumentToken)
 	}
 }
 
@@ -186,6 +213,16 @@ func (tm *TrackingManager) GetBasePriceByToken(token uint32) (float64, bool) {
 	return 0, false
 }
 
+func (tm *TrackingManager) GetStopLossByToken(token uint32) (float64, bool) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if stock, exists := tm.tracked[token]; exists {
+		return stock.StopLoss, true
+	}
+	return 0, false
+}
+
 // get tracking stock LTP from current candle close price.
 func (tm *TrackingManager) GetTSLtpByToken(token uint32) (float64, bool) {
 	tm.mu.RLock()
@@ -206,6 +243,7 @@ func (tm *TrackingManager) SetSellQuantity(token uint32, quantity uint32) {
 	if stock, exists := tm.tracked[token]; exists {
 		stock.SellQuantity = quantity
 		tm.tracked[token] = stock
+		tm.markDirty(token)
 	}
 }
 
@@ -215,6 +253,7 @@ func (tm *TrackingManager) SetBuyQuantity(token uint32, quantity uint32) {
 	if stock, exists := tm.tracked[token]; exists {
 		stock.BuyQuantity = quantity
 		tm.tracked[token] = stock
+		tm.markDirty(token)
 	}
 }
 
@@ -225,6 +264,7 @@ func (tm *TrackingManager) ToggleLock(token uint32) {
 	if stock, exists := tm.tracked[token]; exists {
 		stock.Locked = !stock.Locked
 		tm.tracked[token] = stock
+		tm.markDirty(token)
 	}
 }
 
@@ -235,6 +275,7 @@ func (tm *TrackingManager) LockStock(token uint32) {
 	if stock, exists := tm.tracked[token]; exists {
 		stock.Locked = true
 		tm.tracked[token] = stock
+		tm.markDirty(token)
 	}
 }
 
@@ -253,6 +294,7 @@ func (tm *TrackingManager) TryLockStock(token uint32) bool {
 
 	stock.Locked = true
 	tm.tracked[token] = stock
+	tm.markDirty(token)
 
 	return true // We successfully claimed the stock!
 }
@@ -264,6 +306,7 @@ func (tm *TrackingManager) UnlockStock(token uint32) {
 	if stock, exists := tm.tracked[token]; exists {
 		stock.Locked = false
 		tm.tracked[token] = stock
+		tm.markDirty(token)
 	}
 }
 func (tm *TrackingManager) CountStocks() int {
@@ -279,6 +322,7 @@ func (tm *TrackingManager) SetFifteenCandle(token uint32, candle Candle) {
 	if stock, exists := tm.tracked[token]; exists {
 		stock.FifteenCandle = candle
 		tm.tracked[token] = stock
+		tm.markDirty(token)
 	}
 }
 
@@ -289,6 +333,7 @@ func (tm *TrackingManager) SetCurrentCandle(token uint32, candle Candle) {
 	if stock, exists := tm.tracked[token]; exists {
 		stock.Candles.Current = candle
 		tm.tracked[token] = stock
+		tm.markDirty(token)
 	}
 }
 
@@ -299,6 +344,7 @@ func (tm *TrackingManager) UpdateCurrentCandle(token uint32, price float64) {
 	if stock, exists := tm.tracked[token]; exists {
 		stock.Candles.Current.Update(price)
 		tm.tracked[token] = stock
+		tm.markDirty(token)
 	}
 }
 
@@ -311,6 +357,7 @@ func (tm *TrackingManager) RollCandle(token uint32) {
 		stock.LastLTP = stock.Candles.Previous.Close
 		stock.Candles.Roll()
 		tm.tracked[token] = stock
+		tm.markDirty(token)
 	}
 }
 
@@ -321,6 +368,7 @@ func (tm *TrackingManager) SetSignalFired(token uint32) {
 	if stock, exists := tm.tracked[token]; exists {
 		stock.SignalFired = true
 		tm.tracked[token] = stock
+		tm.markDirty(token)
 	}
 }
 
@@ -331,6 +379,7 @@ func (tm *TrackingManager) SetDirection(token uint32, direction string) {
 	if stock, exists := tm.tracked[token]; exists {
 		stock.Direction = direction
 		tm.tracked[token] = stock
+		tm.markDirty(token)
 	}
 }
 
@@ -340,6 +389,7 @@ func (tm *TrackingManager) SetTradingAllowed(token uint32, allowed bool) {
 	if stock, exists := tm.tracked[token]; exists {
 		stock.TradingAllowed = allowed
 		tm.tracked[token] = stock
+		tm.markDirty(token)
 	}
 }
 
@@ -353,10 +403,166 @@ func (tm *TrackingManager) UpdateTradeParams(token uint32, target, stopLoss floa
 		stock.Target = target
 		stock.StopLoss = stopLoss
 		stock.Direction = direction
+		stock.TrailingStopLoss = 0
+		tm.tracked[token] = stock
+		tm.markDirty(token)
+	}
+}
+
+// AdjustTradeParams replaces the target, stoploss and trail distance of an open
+// position in one step. It returns false when the stock is no longer tracked.
+func (tm *TrackingManager) AdjustTradeParams(token uint32, target, stopLoss, trailingStopLoss float64) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	stock, exists := tm.tracked[token]
+	if !exists {
+		return false
+	}
+	stock.Target = target
+	stock.StopLoss = stopLoss
+	stock.TrailingStopLoss = trailingStopLoss
+	tm.tracked[token] = stock
+	tm.markDirty(token)
+	return true
+}
+
+// AdoptPosition hands a position opened outside the bot over to the exit logic,
+// exactly as if the bot's own entry had filled at basePrice. It refuses a stock
+// that already has a position, such as one from an entry that just filled.
+func (tm *TrackingManager) AdoptPosition(token uint32, direction string, quantity uint32, basePrice, target, stopLoss, trailingStopLoss float64) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	stock, exists := tm.tracked[token]
+	if !exists || stock.Direction != "" || stock.BuyQuantity > 0 || stock.SellQuantity > 0 {
+		return false
+	}
+	if direction == "BUY" {
+		stock.BuyQuantity = quantity
+	} else {
+		stock.SellQuantity = quantity
+	}
+	stock.Direction = direction
+	stock.BasePrice = basePrice
+	stock.Target = target
+	stock.StopLoss = stopLoss
+	stock.TrailingStopLoss = trailingStopLoss
+	stock.SignalFired = true
+	tm.tracked[token] = stock
+	tm.markDirty(token)
+	return true
+}
+
+// TrailStopLoss pulls the stoploss of a position in trailing mode up behind the
+// price. The stop only ever tightens, so it may end up past the base price.
+func (tm *TrackingManager) TrailStopLoss(token uint32, price float64) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	stock, exists := tm.tracked[token]
+	if !exists || stock.TrailingStopLoss <= 0 || stock.BasePrice == 0 {
+		return
+	}
+
+	var stopLoss float64
+	switch stock.Direction {
+	case "BUY":
+		stopLoss = stock.BasePrice - (price - stock.TrailingStopLoss)
+	case "SELL":
+		stopLoss = (price + stock.TrailingStopLoss) - stock.BasePrice
+	default:
+		return
+	}
+	if stopLoss < stock.StopLoss {
+		stock.StopLoss = stopLoss
 		tm.tracked[token] = stock
+		tm.markDirty(token)
 	}
 }
 
+// ReservePendingExit is called before an exit LIMIT order is sent to the
+// broker, so that a postback for it arriving before SetPendingExit, which
+// clears the reservation, is not lost.
+func (tm *TrackingManager) ReservePendingExit(token uint32) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if stock, exists := tm.tracked[token]; exists {
+		stock.exitPlacing = true
+		tm.tracked[token] = stock
+	}
+}
+
+// ReleasePendingExit drops a reservation whose order could not be placed.
+func (tm *TrackingManager) ReleasePendingExit(token uint32) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if stock, exists := tm.tracked[token]; exists {
+		stock.exitPlacing = false
+		tm.tracked[token] = stock
+	}
+}
+
+// SetPendingExit records the broker order ID of a placed exit LIMIT order
+// that has not filled yet. It returns false, and records nothing, when the
+// reservation was cleared in the meantime because the order already finished.
+func (tm *TrackingManager) SetPendingExit(token uint32, orderID string) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	stock, exists := tm.tracked[token]
+	if !exists || !stock.exitPlacing {
+		return false
+	}
+	stock.exitPlacing = false
+	stock.PendingExitOrderID = orderID
+	stock.ExitEscalated = false
+	tm.tracked[token] = stock
+	tm.markDirty(token)
+	return true
+}
+
+// RearmPendingExit lets the pending exit orderID be escalated again after a
+// failed conversion to MARKET. It does nothing if that exit is no longer pending.
+func (tm *TrackingManager) RearmPendingExit(token uint32, orderID string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if stock, exists := tm.tracked[token]; exists && stock.PendingExitOrderID == orderID {
+		stock.ExitEscalated = false
+		tm.tracked[token] = stock
+		tm.markDirty(token)
+	}
+}
+
+// ClearPendingExit forgets the pending exit, or the reservation for one,
+// once it is complete, cancelled or rejected.
+func (tm *TrackingManager) ClearPendingExit(token uint32) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if stock, exists := tm.tracked[token]; exists {
+		stock.PendingExitOrderID = ""
+		stock.ExitEscalated = false
+		stock.exitPlacing = false
+		tm.tracked[token] = stock
+		tm.markDirty(token)
+	}
+}
+
+// TryEscalateExit claims the right to convert the pending exit to MARKET. Only
+// the first caller (stoploss tick, timeout or retrace check) gets true.
+func (tm *TrackingManager) TryEscalateExit(token uint32) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	stock, exists := tm.tracked[token]
+	if !exists || stock.PendingExitOrderID == "" || stock.ExitEscalated {
+		return false
+	}
+	stock.ExitEscalated = true
+	tm.tracked[token] = stock
+	tm.markDirty(token)
+	return true
+}
+
 func (tm *TrackingManager) IsStockTracked(token uint32) bool {
 	tm.mu.RLock()
 	defer tm.mu.RUnlock()
@@ -373,6 +579,7 @@ func (tm *TrackingManager) UpdateBasePrice(instrumentToken uint32, price float64
 	if stock, exists := tm.tracked[instrumentToken]; exists {
 		stock.BasePrice = price
 		tm.tracked[instrumentToken] = stock
+		tm.markDirty(instrumentToken)
 	}
 }
 
@@ -385,6 +592,7 @@ func (tm *TrackingManager) DecrementMaxExecutableOrders(token uint32) {
 			stock.MaxExecutableOrders--
 		}
 		tm.tracked[token] = stock
+		tm.markDirty(token)
 	}
 }
 
@@ -400,6 +608,7 @@ func (tm *TrackingManager) ResetParameters(token uint32) {
 		stock.SignalFired = false
 		stock.TradingAllowed = false
 		tm.tracked[token] = stock
+		tm.markDirty(token)
 	}
 }
 
@@ -411,6 +620,7 @@ func (tm *TrackingManager) ResetFiringAndDirection(token uint32) {
 		stock.SignalFired = false
 		stock.Direction = ""
 		tm.tracked[token] = stock
+		tm.markDirty(token)
 	}
 }
 
@@ -418,16 +628,18 @@ func (tm *TrackingManager) ResetFiringAndDirection(token uint32) {
 func (tm *TrackingManager) loadFifteenCandlesForTS(stock TrackedStock) {
 	ist, _ := time.LoadLocation("Asia/Kolkata")
 	now := time.Now().In(ist)
-	from := time.Date(now.Year(), now.Month(), now.Day(), 9, 15, 0, 0, ist)
-	to := time.Date(now.Year(), now.Month(), now.Day(), 9, 30, 0, 0, ist)
+	session := utils.CurrentSession()
+	from := session.At(now, session.Open)
+	to := session.At(now, session.OpeningRangeEnd)
 
-	data, err := tm.kiteClient.GetHistoricOHLC(int64(stock.InstrumentToken), "15minute", from, to)
+	data, err := tm.broker.GetHistorical(stock.InstrumentToken, "15minute", from, to)
 	if err != nil {
 		log.Printf("⚠️ Cannot load fifteen candle for %s: %v", stock.TradingSymbol, err)
-
+		return
 	}
 	if len(data) == 0 {
 		log.Printf("⚠️ No fifteen candle data for %s (market not yet opened?)", stock.TradingSymbol)
+		return
 	}
 	candle := Candle{
 		Open:  data[0].Open,
@@ -455,13 +667,14 @@ func (tm *TrackingManager) loadCurrentCandlesForTS(stock TrackedStock) {
 		return
 	}
 
-	data, err := tm.kiteClient.GetHistoricOHLC(int64(stock.InstrumentToken), "5minute", intervalStart, now)
+	data, err := tm.broker.GetHistorical(stock.InstrumentToken, "5minute", intervalStart, now)
 	if err != nil {
 		log.Printf("⚠️ Cannot load current candle for %s: %v", stock.TradingSymbol, err)
-
+		return
 	}
 	if len(data) == 0 {
 		log.Printf("⚠️ No Current candle data for %s (market not yet opened?)", stock.TradingSymbol)
+		return
 	}
 	candle := Candle{
 		Open:  data[0].Open,
@@ -0,0 +1,47 @@
+package tracking
+
+import "testing"
+
+func TestPendingExitClearedBeforeItIsSet(t *testing.T) {
+	tm := NewTrackingManager(nil, nil)
+	tm.tracked[408065] = TrackedStock{TradingSymbol: "INFY", InstrumentToken: 408065, Direction: "BUY", BuyQuantity: 10}
+
+	// The exit fills, and its postback clears the pending exit, before
+	// placeOrder has returned the order ID.
+	tm.ReservePendingExit(408065)
+	tm.ClearPendingExit(408065)
+	if tm.SetPendingExit(408065, "order-1") {
+		t.Fatal("SetPendingExit() = true after the exit was already cleared")
+	}
+	if stock, _ := tm.GetStock(408065); stock.PendingExitOrderID != "" {
+		t.Fatalf("PendingExitOrderID = %q, want none", stock.PendingExitOrderID)
+	}
+
+	tm.ReservePendingExit(408065)
+	if !tm.SetPendingExit(408065, "order-2") {
+		t.Fatal("SetPendingExit() = false for a reserved exit")
+	}
+	if !tm.TryEscalateExit(408065) {
+		t.Fatal("TryEscalateExit() = false for a pending exit")
+	}
+	tm.RearmPendingExit(408065, "order-2")
+	if stock, _ := tm.GetStock(408065); stock.PendingExitOrderID != "order-2" || stock.ExitEscalated {
+		t.Fatalf("after re-arm: pending=%q escalated=%v", stock.PendingExitOrderID, stock.ExitEscalated)
+	}
+}
+
+func TestAdoptPositionRefusesAnOpenPosition(t *testing.T) {
+	tm := NewTrackingManager(nil, nil)
+	tm.tracked[408065] = TrackedStock{TradingSymbol: "INFY", InstrumentToken: 408065}
+
+	if !tm.AdoptPosition(408065, "SELL", 10, 1500, 10, 5, 0) {
+		t.Fatal("AdoptPosition() = false for a flat stock")
+	}
+	// An entry filled between the caller's check and the adoption.
+	if tm.AdoptPosition(408065, "BUY", 20, 1490, 10, 5, 0) {
+		t.Fatal("AdoptPosition() = true over an open position")
+	}
+	if stock, _ := tm.GetStock(408065); stock.Direction != "SELL" || stock.SellQuantity != 10 || stock.BuyQuantity != 0 {
+		t.Errorf("position = %s buy=%d sell=%d, want the first adoption kept", stock.Direction, stock.BuyQuantity, stock.SellQuantity)
+	}
+}
@@ -0,0 +1,188 @@
+package tracking
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/SM-Sclass/stock_client2-go_backend/internal/models"
+)
+
+// StateStore persists snapshots of the intraday tracking state.
+type StateStore interface {
+	SaveStates(ctx context.Context, states []models.TrackedStockState) error
+}
+
+// TradingDate returns the IST calendar date of t, at midnight UTC, which is
+// the key tracking state snapshots are stored under.
+func TradingDate(t time.Time) time.Time {
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	y, m, d := t.In(ist).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// markDirty queues a stock's state for the next flush. Callers hold tm.mu.
+func (tm *TrackingManager) markDirty(token uint32) {
+	tm.dirty[token] = struct{}{}
+}
+
+// StartStatePersistence writes changed stock states to store every interval
+// until StopStatePersistence is called. Changes are batched, so a burst of
+// ticks on one stock costs a single upsert per interval.
+func (tm *TrackingManager) StartStatePersistence(store StateStore, interval time.Duration) {
+	tm.persistMu.Lock()
+	defer tm.persistMu.Unlock()
+	if tm.persistStop != nil {
+		return
+	}
+
+	tm.store = store
+	tm.persistStop = make(chan struct{})
+	tm.persistWg.Add(1)
+	go func(stop <-chan struct{}) {
+		defer tm.persistWg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := tm.FlushState(); err != nil {
+					log.Printf("⚠️ Failed to persist tracking state: %v", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}(tm.persistStop)
+	log.Printf("💾 Tracking state persistence started (every %s)", interval)
+}
+
+// StopStatePersistence stops the background writer and flushes whatever
+// changed since its last run.
+func (tm *TrackingManager) StopStatePersistence() {
+	tm.persistMu.Lock()
+	if tm.persistStop == nil {
+		tm.persistMu.Unlock()
+		return
+	}
+	close(tm.persistStop)
+	tm.persistStop = nil
+	tm.persistMu.Unlock()
+
+	tm.persistWg.Wait()
+	if err := tm.FlushState(); err != nil {
+		log.Printf("⚠️ Failed to persist tracking state on stop: %v", err)
+	}
+}
+
+// FlushState writes the state of every stock changed since the last flush.
+// On failure the stocks stay dirty and are retried on the next flush.
+func (tm *TrackingManager) FlushState() error {
+	tm.persistFlush.Lock()
+	defer tm.persistFlush.Unlock()
+
+	if tm.store == nil {
+		return nil
+	}
+
+	now := time.Now()
+	tm.mu.Lock()
+	if len(tm.dirty) == 0 {
+		tm.mu.Unlock()
+		return nil
+	}
+	states := make([]models.TrackedStockState, 0, len(tm.dirty))
+	for token := range tm.dirty {
+		if stock, exists := tm.tracked[token]; exists {
+			states = append(states, stock.toState(now))
+		}
+	}
+	tm.dirty = make(map[uint32]struct{})
+	tm.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tm.store.SaveStates(ctx, states); err != nil {
+		tm.mu.Lock()
+		for _, state := range states {
+			if _, exists := tm.tracked[state.InstrumentToken]; exists {
+				tm.markDirty(state.InstrumentToken)
+			}
+		}
+		tm.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// toState snapshots the intraday state of a tracked stock.
+func (s TrackedStock) toState(now time.Time) models.TrackedStockState {
+	fifteen, _ := json.Marshal(s.FifteenCandle)
+	candles, _ := json.Marshal(s.Candles)
+	return models.TrackedStockState{
+		TrackingStockID:     s.ID,
+		TradingDate:         TradingDate(now),
+		InstrumentToken:     s.InstrumentToken,
+		TradingSymbol:       s.TradingSymbol,
+		Direction:           s.Direction,
+		BuyQuantity:         s.BuyQuantity,
+		SellQuantity:        s.SellQuantity,
+		BasePrice:           s.BasePrice,
+		Target:              s.Target,
+		StopLoss:            s.StopLoss,
+		TrailingStopLoss:    s.TrailingStopLoss,
+		SignalFired:         s.SignalFired,
+		Locked:              s.Locked,
+		MaxExecutableOrders: s.MaxExecutableOrders,
+		PendingExitOrderID:  s.PendingExitOrderID,
+		ExitEscalated:       s.ExitEscalated,
+		FifteenCandle:       fifteen,
+		Candles:             candles,
+		UpdatedAt:           now,
+	}
+}
+
+// RestoreState overwrites the intraday state of a tracked stock with a
+// persisted snapshot. Candles loaded from the broker at startup win over the
+// snapshot's, since they also cover the ticks missed while the process was down.
+func (tm *TrackingManager) RestoreState(token uint32, state models.TrackedStockState) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	stock, exists := tm.tracked[token]
+	if !exists {
+		return false
+	}
+
+	stock.Direction = state.Direction
+	stock.BuyQuantity = state.BuyQuantity
+	stock.SellQuantity = state.SellQuantity
+	stock.BasePrice = state.BasePrice
+	stock.Target = state.Target
+	stock.StopLoss = state.StopLoss
+	stock.TrailingStopLoss = state.TrailingStopLoss
+	stock.SignalFired = state.SignalFired
+	stock.Locked = state.Locked
+	stock.MaxExecutableOrders = state.MaxExecutableOrders
+	stock.PendingExitOrderID = state.PendingExitOrderID
+	stock.ExitEscalated = state.ExitEscalated
+
+	var fifteen Candle
+	if !stock.FifteenCandle.IsValid() && json.Unmarshal(state.FifteenCandle, &fifteen) == nil {
+		stock.FifteenCandle = fifteen
+	}
+	var candles CandleState
+	if json.Unmarshal(state.Candles, &candles) == nil {
+		if !stock.Candles.Previous.IsValid() {
+			stock.Candles.Previous = candles.Previous
+		}
+		if !stock.Candles.Current.IsValid() {
+			stock.Candles.Current = candles.Current
+		}
+	}
+
+	tm.tracked[token] = stock
+	tm.markDirty(token)
+	return true
+}
@@ -2,27 +2,23 @@ package utils
 
 import "time"
 
-const (
-    marketStartMinutes = 9*60 + 15  // 9:15 AM = 555 minutes
-    marketEndMinutes   = 15*60 + 25 // 3:25 PM = 930 minutes
-)
-
 var ist = time.FixedZone("IST", 5*60*60+30*60) // UTC+5:30 (19800 seconds) ✓
 
 func IsMarketTime() bool {
-    now := time.Now().In(ist)
+    now := clockNow().In(ist)
     currentMinutes := now.Hour()*60 + now.Minute()
-    return currentMinutes >= marketStartMinutes && currentMinutes <= marketEndMinutes
+    session := CurrentSession()
+    return currentMinutes >= session.Open && currentMinutes <= session.MarketClose
 }
 
 func IsAfterMarketClose() bool {  // Exported + camelCase
-    now := time.Now().In(ist)
+    now := clockNow().In(ist)
     currentMinutes := now.Hour()*60 + now.Minute()
-    return currentMinutes > marketEndMinutes
+    return currentMinutes > CurrentSession().MarketClose
 }
 
 func IsWeekend() bool {
-    now := time.Now().In(ist)
+    now := clockNow().In(ist)
     return now.Weekday() == time.Saturday || now.Weekday() == time.Sunday
 }
 
@@ -5,6 +5,7 @@ import "time"
 // MarketPhase represents the current algorithmic phase of the trading day.
 type MarketPhase int
 
+// The times below are those of the default session; see Session.
 const (
 	PhasePreMarket  MarketPhase = iota // before 9:15
 	PhaseFifteen                       // 9:15–9:30: build opening-range candle
@@ -14,6 +15,25 @@ const (
 	PhasePostMarket                    // after 15:15
 )
 
+func (p MarketPhase) String() string {
+	switch p {
+	case PhasePreMarket:
+		return "PRE_MARKET"
+	case PhaseFifteen:
+		return "OPENING_RANGE"
+	case PhaseSignal:
+		return "SIGNAL"
+	case PhaseMonitor:
+		return "MONITOR"
+	case PhaseExit:
+		return "EXIT"
+	case PhasePostMarket:
+		return "POST_MARKET"
+	default:
+		return "UNKNOWN"
+	}
+}
+
 // GetMarketPhase returns the current algorithmic phase based on IST time.
 func GetMarketPhase(now time.Time) MarketPhase {
 	ist, _ := time.LoadLocation("Asia/Kolkata")
@@ -21,16 +41,17 @@ func GetMarketPhase(now time.Time) MarketPhase {
 	h, m, _ := t.Clock()
 	mins := h*60 + m
 
+	session := CurrentSession()
 	switch {
-	case mins < 9*60+15:
+	case mins < session.Open:
 		return PhasePreMarket
-	case mins < 9*60+30:
+	case mins < session.OpeningRangeEnd:
 		return PhaseFifteen
-	case mins < 9*60+35:
+	case mins < session.SignalWindowEnd:
 		return PhaseSignal
-	case mins < 15*60+10:
+	case mins < session.ForceExitAt:
 		return PhaseMonitor
-	case mins < 15*60+15:
+	case mins < session.Close:
 		return PhaseExit
 	default:
 		return PhasePostMarket
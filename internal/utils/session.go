@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Session is the trading day's timetable in IST, in minutes since midnight.
+type Session struct {
+	Open            int // market open, start of the opening range
+	OpeningRangeEnd int // end of the opening range, start of the signal window
+	SignalWindowEnd int // end of the signal window
+	ForceExitAt     int // open positions are force-exited from here
+	Close           int // end of the force-exit window, post market after
+	MarketClose     int // end of market hours
+}
+
+// DefaultSession is the NSE timetable the strategy was built for.
+func DefaultSession() Session {
+	return Session{
+		Open:            9*60 + 15,
+		OpeningRangeEnd: 9*60 + 30,
+		SignalWindowEnd: 9*60 + 35,
+		ForceExitAt:     15*60 + 10,
+		Close:           15*60 + 15,
+		MarketClose:     15*60 + 25,
+	}
+}
+
+var session atomic.Pointer[Session]
+
+// SetSession replaces the timetable used by the market clock and phases.
+func SetSession(s Session) {
+	session.Store(&s)
+}
+
+// CurrentSession returns the timetable in effect.
+func CurrentSession() Session {
+	if s := session.Load(); s != nil {
+		return *s
+	}
+	return DefaultSession()
+}
+
+// At returns the time minutes after midnight on day, in day's location.
+func (s Session) At(day time.Time, minutes int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), minutes/60, minutes%60, 0, 0, day.Location())
+}
+
+var clock atomic.Pointer[func() time.Time]
+
+// SetClock replaces the time source of the market clock, as tests that run
+// outside market hours do. Nil restores the wall clock.
+func SetClock(now func() time.Time) {
+	if now == nil {
+		clock.Store(nil)
+		return
+	}
+	clock.Store(&now)
+}
+
+// clockNow returns the market clock's current time.
+func clockNow() time.Time {
+	if now := clock.Load(); now != nil {
+		return (*now)()
+	}
+	return time.Now()
+}